@@ -0,0 +1,107 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+)
+
+// Workload describes how a filter will be used, for Advise to recommend its parameters.
+type Workload struct {
+	// ExpectedInserts is the number of distinct items expected to be added.
+	ExpectedInserts uint64
+	// InsertsPerSecond is the expected rate of Add calls, 0 if unknown.
+	InsertsPerSecond float64
+	// QueriesPerSecond is the expected rate of Test calls, 0 if unknown.
+	QueriesPerSecond float64
+	// FalsePositiveRate is the acceptable false positive rate once all the items are added.
+	FalsePositiveRate float64
+	// MemoryCeiling is the maximum size of the bit set in bytes, 0 for no limit.
+	MemoryCeiling uint64
+}
+
+// Advice holds the parameters recommended by Advise.
+type Advice struct {
+	// Params are the parameters of a BloomFilter sized for the expected inserts.
+	Params Params
+	// Bits is the number of bits of the BloomFilter, m = -n*ln(p)/ln(2)^2.
+	Bits uint64
+	// K is the number of hash functions of the BloomFilter, k = m/n*ln(2), rounded up.
+	K uint64
+	// MemoryBytes is the size of the bit set of the BloomFilter.
+	MemoryBytes uint64
+
+	// Scalable are the parameters of a ScalableBloomFilter, for when the number of inserts is uncertain.
+	// Its layers double in capacity and halve their false positive rate, so the overall false positive
+	// rate stays below the acceptable one whatever the number of layers. See Advise.
+	Scalable ParamsScalable
+	// ScalableMemoryBytes is the size of the bit sets of the ScalableBloomFilter once the expected items are added.
+	ScalableMemoryBytes uint64
+}
+
+// Scalable filters recommended by Advise start at 1/scalableInitialShare of the expected inserts,
+// with layers doubling in capacity, so three layers hold them all.
+const (
+	scalableInitialShare = 7
+	scalableTightening   = 0.5
+)
+
+// Advise recommends the parameters of a filter for the workload, and returns an error if the workload
+// is invalid, or if its false positive rate can't be reached within the memory ceiling, with the
+// best false positive rate reachable.
+//
+// The lock type is LockTypeReadWrite when queries are at least 10 times more frequent than inserts,
+// LockTypeAtomic when there are more than 100000 inserts per second, or LockTypeStriped for scalable
+// filters, and LockTypeExclusive otherwise.
+//
+// The layers of the recommended scalable filter have false positive rates p/2, p/4, p/8..., whose sum
+// never exceeds p. The tightening needs MaxExpectedItems to be set, which it is, since the growth
+// trigger used without it only supports a FalsePositiveGrowth greater than 1.
+func Advise(w Workload) (Advice, error) {
+	if w.ExpectedInserts == 0 {
+		return Advice{}, fmt.Errorf("expected inserts cannot be 0")
+	}
+	if w.FalsePositiveRate <= 0 || w.FalsePositiveRate >= 1 {
+		return Advice{}, fmt.Errorf("false positive rate must be between 0 and 1")
+	}
+
+	m, bytes, k := EstimateMemory(w.ExpectedInserts, w.FalsePositiveRate)
+	a := Advice{Bits: m, K: k, MemoryBytes: bytes}
+	if w.MemoryCeiling > 0 && a.MemoryBytes > w.MemoryCeiling {
+		// With the optimal k, the false positive rate of m bits is e^(-m/n*ln(2)^2).
+		best := math.Exp(-float64(w.MemoryCeiling*8) / float64(w.ExpectedInserts) * math.Ln2 * math.Ln2)
+		return Advice{}, fmt.Errorf("a false positive rate of %g needs %d bytes, above the ceiling of %d bytes, "+
+			"which allows a false positive rate of %.3g", w.FalsePositiveRate, a.MemoryBytes, w.MemoryCeiling, best)
+	}
+
+	lockType, scalableLockType := LockTypeExclusive, LockTypeExclusive
+	switch {
+	case w.QueriesPerSecond >= 10*w.InsertsPerSecond && w.QueriesPerSecond > 0:
+		lockType, scalableLockType = LockTypeReadWrite, LockTypeReadWrite
+	case w.InsertsPerSecond > 100000:
+		lockType, scalableLockType = LockTypeAtomic, LockTypeStriped
+	}
+	a.Params = Params{N: w.ExpectedInserts, FalsePositiveRate: w.FalsePositiveRate, LockType: lockType}
+
+	initial := (w.ExpectedInserts + scalableInitialShare - 1) / scalableInitialShare
+	a.Scalable = ParamsScalable{
+		InitialSize:         initial,
+		FalsePositiveRate:   w.FalsePositiveRate * (1 - scalableTightening),
+		FalsePositiveGrowth: scalableTightening,
+		LockType:            scalableLockType,
+		MaxExpectedItems:    w.ExpectedInserts,
+		LayerGrowth:         2,
+	}
+	fp := a.Scalable.FalsePositiveRate
+	for size, added := initial, uint64(0); added < w.ExpectedInserts; size *= 2 {
+		_, bytes, _ := EstimateMemory(size, fp)
+		a.ScalableMemoryBytes += bytes
+		added += size
+		fp *= scalableTightening
+	}
+	return a, nil
+}
+
+// bitSetBytes returns the size in bytes of a bit set of m bits, rounded up to whole words.
+func bitSetBytes(m uint64) uint64 {
+	return (m + 63) / 64 * 8
+}
@@ -0,0 +1,61 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdvise(t *testing.T) {
+	t.Parallel()
+	a, err := Advise(Workload{ExpectedInserts: 100000, FalsePositiveRate: 0.01, QueriesPerSecond: 1000, InsertsPerSecond: 10})
+	assert.NoError(t, err, "Failed to advise")
+	bf, err := New(a.Params)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Bits, bf.M())
+	assert.Equal(t, a.K, bf.K())
+	assert.Equal(t, a.MemoryBytes, bf.Stats().MemoryBytes)
+	assert.Equal(t, LockTypeReadWrite, a.Params.LockType, "Read-heavy workloads should use read-write locks")
+
+	// The scalable filter stays below the false positive rate once the expected items are added.
+	sbf, err := NewScalable(a.Scalable)
+	assert.NoError(t, err)
+	for i := uint64(0); i < 100000; i++ {
+		assert.NoError(t, sbf.Add([]byte(fmt.Sprint(i))))
+	}
+	assert.Len(t, sbf.filters(), 3)
+	assert.Equal(t, a.ScalableMemoryBytes, sbf.Stats().MemoryBytes)
+	falsePositives := 0
+	for i := 0; i < 100000; i++ {
+		if b, _ := sbf.Test([]byte(fmt.Sprintf("missing-%d", i))); b {
+			falsePositives++
+		}
+	}
+	assert.Less(t, float64(falsePositives)/100000, 0.01)
+}
+
+func TestAdvise_LockType(t *testing.T) {
+	t.Parallel()
+	a, err := Advise(Workload{ExpectedInserts: 1000, FalsePositiveRate: 0.01, InsertsPerSecond: 1e6})
+	assert.NoError(t, err)
+	assert.Equal(t, LockTypeAtomic, a.Params.LockType)
+	assert.Equal(t, LockTypeStriped, a.Scalable.LockType, "Scalable filters don't support atomic locks")
+	a, err = Advise(Workload{ExpectedInserts: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	assert.Equal(t, LockTypeExclusive, a.Params.LockType)
+}
+
+func TestAdvise_MemoryCeiling(t *testing.T) {
+	t.Parallel()
+	_, err := Advise(Workload{ExpectedInserts: 1000000, FalsePositiveRate: 0.001, MemoryCeiling: 1 << 20})
+	assert.ErrorContains(t, err, "ceiling", "A ceiling below the needed memory should be reported")
+	a, err := Advise(Workload{ExpectedInserts: 1000000, FalsePositiveRate: 0.01, MemoryCeiling: 2 << 20})
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, a.MemoryBytes, uint64(2<<20))
+
+	_, err = Advise(Workload{FalsePositiveRate: 0.01})
+	assert.Error(t, err)
+	_, err = Advise(Workload{ExpectedInserts: 1000})
+	assert.Error(t, err)
+}
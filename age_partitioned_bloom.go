@@ -0,0 +1,164 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+var _ Interface = (*AgePartitionedBloomFilter)(nil)
+
+// AgePartitionedBloomFilter is a Bloom filter that forgets items after a configured time window.
+// The bit set is split into k+l slices kept in a circular buffer. Items are added to the k newest
+// slices, and every generation the oldest slice is cleared and becomes the newest one. An item is
+// present while k consecutive slices still contain it, which lasts between l and l+1 generations.
+type AgePartitionedBloomFilter struct {
+	k          uint64        // The number of slices each item is added to
+	l          uint64        // The number of generations an item is remembered for
+	m          uint64        // The number of bits per slice
+	slices     [][]uint64    // The slices, each one a bit array
+	head       uint64        // The index of the newest slice
+	hasher64   Hasher64      // The hasher computing the hashes of an item, one per slice
+	generation time.Duration // The duration of a generation
+	lastShift  time.Time     // The time the current generation started
+	now        func() time.Time
+	mutex      Mutex // Mutex to ensure thread safety
+}
+
+// ParamsAgePartitioned represents the parameters for creating a new age-partitioned Bloom filter.
+type ParamsAgePartitioned struct {
+	// N is the number of elements expected to be added during each generation.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate.
+	FalsePositiveRate float64
+	// Window is the time after which items are forgotten.
+	Window time.Duration
+	// Generations is the number of generations the window is split into. Defaults to 4.
+	// Items are forgotten between Window and Window*(Generations+1)/Generations after they were added,
+	// so more generations make expiration more precise, at the cost of more memory.
+	Generations uint64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewAgePartitioned creates a new age-partitioned Bloom filter.
+func NewAgePartitioned(p ParamsAgePartitioned) (*AgePartitionedBloomFilter, error) {
+	applyDefaultsAgePartitioned(&p)
+	if p.N == 0 {
+		return nil, fmt.Errorf("number of elements cannot be 0")
+	}
+	if p.FalsePositiveRate <= 0 || p.FalsePositiveRate >= 1 {
+		return nil, fmt.Errorf("false positive rate must be between 0 and 1")
+	}
+	if p.Window <= 0 {
+		return nil, fmt.Errorf("invalid window, must be greater than 0, got %s", p.Window)
+	}
+	if uint64(p.Window) < p.Generations {
+		return nil, fmt.Errorf("invalid window, must be at least 1ns per generation, got %s for %d generations",
+			p.Window, p.Generations)
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	// Slices are kept half full, so each of the l+1 windows of k slices has a 2^-k false positive rate.
+	l := p.Generations
+	k := uint64(math.Ceil(math.Log2(float64(l+1) / p.FalsePositiveRate)))
+	// Each slice holds the items of k generations.
+	m := uint64(math.Ceil(float64(k*p.N) / math.Ln2))
+	slices := make([][]uint64, k+l)
+	for i := range slices {
+		slices[i] = make([]uint64, (m+63)/64)
+	}
+	now := time.Now
+	return &AgePartitionedBloomFilter{
+		k:          k,
+		l:          l,
+		m:          m,
+		slices:     slices,
+		hasher64:   asHasher64(p.Hasher),
+		generation: p.Window / time.Duration(l),
+		lastShift:  now(),
+		now:        now,
+		mutex:      mu,
+	}, nil
+}
+
+// applyDefaultsAgePartitioned applies the default values to the parameters if they are not set.
+func applyDefaultsAgePartitioned(p *ParamsAgePartitioned) {
+	if p.Generations == 0 {
+		p.Generations = 4
+	}
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	if p.LockType == LockTypeDefault {
+		p.LockType = LockTypeExclusive
+	}
+}
+
+// advance retires the slices of all generations that ended since the last call.
+// The caller must hold the write lock.
+func (apbf *AgePartitionedBloomFilter) advance() {
+	shifts := uint64(apbf.now().Sub(apbf.lastShift) / apbf.generation)
+	if shifts == 0 {
+		return
+	}
+	apbf.lastShift = apbf.lastShift.Add(time.Duration(shifts) * apbf.generation)
+	n := uint64(len(apbf.slices))
+	if shifts > n {
+		shifts = n
+	}
+	for i := uint64(0); i < shifts; i++ {
+		apbf.head = (apbf.head + n - 1) % n
+		clear(apbf.slices[apbf.head])
+	}
+}
+
+// Add adds an item to the age-partitioned Bloom filter.
+func (apbf *AgePartitionedBloomFilter) Add(data []byte) error {
+	if apbf.mutex != nil {
+		apbf.mutex.WLock()
+		defer apbf.mutex.WUnlock()
+	}
+	apbf.advance()
+	probes := pooledLocations(apbf.hasher64, data, apbf.k+apbf.l, apbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	n := uint64(len(apbf.slices))
+	for i := uint64(0); i < apbf.k; i++ {
+		s := (apbf.head + i) % n
+		apbf.slices[s][locs[s]/64] |= 1 << (locs[s] % 64)
+	}
+	return nil
+}
+
+// Test checks if an item was added to the age-partitioned Bloom filter within the time window.
+func (apbf *AgePartitionedBloomFilter) Test(data []byte) (bool, error) {
+	// Expired generations are retired, so a write lock is needed.
+	if apbf.mutex != nil {
+		apbf.mutex.WLock()
+		defer apbf.mutex.WUnlock()
+	}
+	apbf.advance()
+	probes := pooledLocations(apbf.hasher64, data, apbf.k+apbf.l, apbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	// Look for k consecutive slices containing the item, from the newest to the oldest.
+	n := uint64(len(apbf.slices))
+	consecutive := uint64(0)
+	for i := uint64(0); i < n; i++ {
+		s := (apbf.head + i) % n
+		if apbf.slices[s][locs[s]/64]&(1<<(locs[s]%64)) == 0 {
+			consecutive = 0
+			continue
+		}
+		consecutive++
+		if consecutive == apbf.k {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,86 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgePartitionedBloomFilter_Expiration(t *testing.T) {
+	t.Parallel()
+	apbf, err := NewAgePartitioned(ParamsAgePartitioned{
+		N:                 100,
+		FalsePositiveRate: 0.01,
+		Window:            4 * time.Minute,
+		Generations:       4,
+	})
+	assert.NoError(t, err, "Failed to create age-partitioned Bloom filter")
+
+	now := time.Now()
+	apbf.now = func() time.Time { return now }
+	apbf.lastShift = now
+
+	item := []byte("test-item")
+	assert.NoError(t, apbf.Add(item))
+
+	// The item is remembered for at least the window.
+	for i := 0; i < 4; i++ {
+		b, err := apbf.Test(item)
+		assert.NoError(t, err)
+		assert.True(t, b, "Item should be present after %d generations", i)
+		now = now.Add(time.Minute)
+	}
+
+	// And forgotten after one more generation.
+	now = now.Add(time.Minute)
+	b, err := apbf.Test(item)
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should have expired")
+}
+
+func TestAgePartitionedBloomFilter_FalsePositiveRate(t *testing.T) {
+	t.Parallel()
+	n := 1000
+	p := 0.01
+	apbf, err := NewAgePartitioned(ParamsAgePartitioned{
+		N:                 uint64(n),
+		FalsePositiveRate: p,
+		Window:            time.Hour,
+	})
+	assert.NoError(t, err, "Failed to create age-partitioned Bloom filter")
+
+	now := time.Now()
+	apbf.now = func() time.Time { return now }
+	apbf.lastShift = now
+
+	// Fill every generation to its capacity.
+	for g := 0; g < 6; g++ {
+		for i := 0; i < n; i++ {
+			assert.NoError(t, apbf.Add([]byte(fmt.Sprintf("item-%d-%d", g, i))))
+		}
+		now = now.Add(15 * time.Minute)
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10*n; i++ {
+		b, err := apbf.Test([]byte(fmt.Sprintf("different-item-%d", i)))
+		assert.NoError(t, err)
+		if b {
+			falsePositives++
+		}
+	}
+	assert.LessOrEqual(t, float64(falsePositives)/float64(10*n), p*1.15)
+}
+
+func TestAgePartitionedBloomFilter_WindowShorterThanGenerations(t *testing.T) {
+	t.Parallel()
+	_, err := NewAgePartitioned(ParamsAgePartitioned{
+		N:                 100,
+		FalsePositiveRate: 0.01,
+		Window:            3,
+		Generations:       4,
+	})
+	assert.Error(t, err, "A window shorter than one nanosecond per generation should be rejected")
+}
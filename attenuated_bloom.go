@@ -0,0 +1,129 @@
+package gobloom
+
+import "fmt"
+
+var _ Interface = (*AttenuatedBloomFilter)(nil)
+
+// AttenuatedBloomFilter is an array of Bloom filters, one per level, used for routing in peer-to-peer
+// and mesh networks: a node keeps one per link, whose level i holds the items reachable through the
+// link in i+1 hops. Merging the filter of a neighbor shifts its levels one hop further, so the levels
+// attenuate with distance, and queries are routed to the link reaching the item in the fewest hops.
+type AttenuatedBloomFilter struct {
+	levels []*BloomFilter // The filters, from the nearest to the farthest level
+}
+
+// ParamsAttenuated represents the parameters for creating a new attenuated Bloom filter.
+type ParamsAttenuated struct {
+	// Depth is the number of levels, the maximum distance tracked.
+	Depth int
+	// N is the number of elements expected to be added to each level.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate of each level.
+	FalsePositiveRate float64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	// Filters merged together must use the same hasher.
+	Hasher Hasher
+	// LockType is the lock type to use for each level. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewAttenuated creates a new attenuated Bloom filter with empty levels.
+func NewAttenuated(p ParamsAttenuated) (*AttenuatedBloomFilter, error) {
+	if p.Depth <= 0 {
+		return nil, fmt.Errorf("invalid depth, must be greater than 0, got %d", p.Depth)
+	}
+	levels := make([]*BloomFilter, p.Depth)
+	for i := range levels {
+		bf, err := New(Params{N: p.N, FalsePositiveRate: p.FalsePositiveRate, Hasher: p.Hasher, LockType: p.LockType})
+		if err != nil {
+			return nil, err
+		}
+		levels[i] = bf
+	}
+	return &AttenuatedBloomFilter{levels: levels}, nil
+}
+
+// Depth returns the number of levels.
+func (abf *AttenuatedBloomFilter) Depth() int {
+	return len(abf.levels)
+}
+
+// Level returns the Bloom filter of the level.
+func (abf *AttenuatedBloomFilter) Level(level int) *BloomFilter {
+	return abf.levels[level]
+}
+
+// Add adds an item to the nearest level.
+func (abf *AttenuatedBloomFilter) Add(data []byte) error {
+	return abf.AddAt(0, data)
+}
+
+// AddAt adds an item to the level.
+func (abf *AttenuatedBloomFilter) AddAt(level int, data []byte) error {
+	if err := abf.checkLevel(level); err != nil {
+		return err
+	}
+	return abf.levels[level].Add(data)
+}
+
+// Test checks if an item is in any level.
+func (abf *AttenuatedBloomFilter) Test(data []byte) (bool, error) {
+	_, ok, err := abf.Distance(data)
+	return ok, err
+}
+
+// TestAt checks if an item is in the level.
+func (abf *AttenuatedBloomFilter) TestAt(level int, data []byte) (bool, error) {
+	if err := abf.checkLevel(level); err != nil {
+		return false, err
+	}
+	return abf.levels[level].Test(data)
+}
+
+// Distance returns the nearest level the item is in, and false if it is in none.
+func (abf *AttenuatedBloomFilter) Distance(data []byte) (int, bool, error) {
+	for i, bf := range abf.levels {
+		b, err := bf.Test(data)
+		if err != nil {
+			return 0, false, err
+		}
+		if b {
+			return i, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// Merge adds the items of the attenuated Bloom filter of a neighbor one level further: its level i is
+// ORed into level i+1, and its farthest level is dropped. The filters must have the same depth,
+// and their levels the same m, k and hasher.
+func (abf *AttenuatedBloomFilter) Merge(neighbor *AttenuatedBloomFilter) error {
+	if len(neighbor.levels) != len(abf.levels) {
+		return fmt.Errorf("incompatible filters, depths %d and %d differ", len(abf.levels), len(neighbor.levels))
+	}
+	for i := range abf.levels {
+		if err := abf.levels[i].checkCompatible(neighbor.levels[i]); err != nil {
+			return err
+		}
+	}
+	for i := 1; i < len(abf.levels); i++ {
+		if err := abf.levels[i].Union(neighbor.levels[i-1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear removes all the items from every level.
+func (abf *AttenuatedBloomFilter) Clear() {
+	for _, bf := range abf.levels {
+		bf.Clear()
+	}
+}
+
+func (abf *AttenuatedBloomFilter) checkLevel(level int) error {
+	if level < 0 || level >= len(abf.levels) {
+		return fmt.Errorf("invalid level %d, must be between 0 and %d", level, len(abf.levels)-1)
+	}
+	return nil
+}
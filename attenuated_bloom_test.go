@@ -0,0 +1,65 @@
+package gobloom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttenuatedBloomFilter_Levels(t *testing.T) {
+	t.Parallel()
+	abf, err := NewAttenuated(ParamsAttenuated{Depth: 3, N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create attenuated Bloom filter")
+	assert.Equal(t, 3, abf.Depth())
+
+	assert.NoError(t, abf.Add([]byte("local")))
+	assert.NoError(t, abf.AddAt(2, []byte("far")))
+	assert.Error(t, abf.AddAt(3, []byte("too-far")))
+
+	level, ok, err := abf.Distance([]byte("far"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, level)
+	b, err := abf.TestAt(0, []byte("far"))
+	assert.NoError(t, err)
+	assert.False(t, b)
+	b, _ = abf.Test([]byte("local"))
+	assert.True(t, b)
+	_, ok, _ = abf.Distance([]byte("missing"))
+	assert.False(t, ok)
+
+	abf.Clear()
+	b, _ = abf.Test([]byte("local"))
+	assert.False(t, b, "Cleared filter should be empty")
+}
+
+func TestAttenuatedBloomFilter_Merge(t *testing.T) {
+	t.Parallel()
+	// A chain of nodes a - b - c: a learns what c holds, two hops away.
+	params := ParamsAttenuated{Depth: 3, N: 100, FalsePositiveRate: 0.01}
+	c, _ := NewAttenuated(params)
+	assert.NoError(t, c.Add([]byte("on-c")))
+	b, _ := NewAttenuated(params)
+	assert.NoError(t, b.Add([]byte("on-b")))
+	assert.NoError(t, b.Merge(c))
+	a, _ := NewAttenuated(params)
+	assert.NoError(t, a.Merge(b))
+
+	level, ok, _ := a.Distance([]byte("on-b"))
+	assert.True(t, ok)
+	assert.Equal(t, 1, level, "Items of the neighbor should be one hop away")
+	level, ok, _ = a.Distance([]byte("on-c"))
+	assert.True(t, ok)
+	assert.Equal(t, 2, level, "Items of the neighbor's neighbor should be two hops away")
+
+	// Items beyond the depth are dropped.
+	d, _ := NewAttenuated(params)
+	assert.NoError(t, d.Merge(a))
+	_, ok, _ = d.Distance([]byte("on-c"))
+	assert.False(t, ok, "Items farther than the depth should be dropped")
+
+	shallow, _ := NewAttenuated(ParamsAttenuated{Depth: 2, N: 100, FalsePositiveRate: 0.01})
+	assert.Error(t, a.Merge(shallow))
+	_, err := NewAttenuated(ParamsAttenuated{N: 100, FalsePositiveRate: 0.01})
+	assert.Error(t, err)
+}
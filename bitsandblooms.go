@@ -0,0 +1,140 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+
+	"github.com/spaolacci/murmur3"
+)
+
+const bitsAndBloomsHasherName = "bitsandblooms"
+
+// BitsAndBloomsHasher is a hasher compatible with the github.com/bits-and-blooms/bloom package.
+// It derives the probe positions from two 128-bit murmur3 hashes, the way that package does,
+// so filters using it can be exchanged with it.
+type BitsAndBloomsHasher struct{}
+
+var (
+	_ NamedHasher = (*BitsAndBloomsHasher)(nil)
+	_ Hasher64    = (*BitsAndBloomsHasher)(nil)
+)
+
+func NewBitsAndBloomsHasher() *BitsAndBloomsHasher {
+	return &BitsAndBloomsHasher{}
+}
+
+func (h *BitsAndBloomsHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashers := make([]hash.Hash64, n)
+	for i := range hashers {
+		hashers[i] = &bitsAndBloomsHash{i: uint64(i)}
+	}
+	return hashers
+}
+
+func (h *BitsAndBloomsHasher) HashK(data []byte, out []uint64) {
+	var base [4]uint64
+	base[0], base[1] = murmur3.Sum128(data)
+	// The 1 byte is written to a pooled streaming hash rather than appended, so hashing doesn't allocate.
+	s := bitsAndBloomsPool.Get().(*bitsAndBloomsState)
+	s.hash.Reset()
+	_, _ = s.hash.Write(data)
+	_, _ = s.hash.Write(s.one[:])
+	base[2], base[3] = s.hash.Sum128()
+	bitsAndBloomsPool.Put(s)
+	for i := range out {
+		j := uint64(i)
+		out[i] = base[j%2] + j*base[2+((j+j%2)%4)/2]
+	}
+}
+
+func (h *BitsAndBloomsHasher) Name() string {
+	return bitsAndBloomsHasherName
+}
+
+// bitsAndBloomsState is a murmur3 hash and the byte appended to the data for the second base hashes.
+type bitsAndBloomsState struct {
+	hash murmur3.Hash128
+	one  [1]byte
+}
+
+var bitsAndBloomsPool = sync.Pool{
+	New: func() any { return &bitsAndBloomsState{hash: murmur3.New128(), one: [1]byte{1}} },
+}
+
+// bitsAndBloomsHash is a hash.Hash64 whose sum is the i-th probe position of the written data,
+// before it is reduced to the size of the bit set.
+type bitsAndBloomsHash struct {
+	i    uint64
+	data []byte
+}
+
+func (h *bitsAndBloomsHash) Write(p []byte) (int, error) {
+	h.data = append(h.data, p...)
+	return len(p), nil
+}
+
+func (h *bitsAndBloomsHash) Sum64() uint64 {
+	// The base hashes are the murmur3 hashes of the data, and of the data with a 1 byte appended.
+	var base [4]uint64
+	base[0], base[1] = murmur3.Sum128(h.data)
+	base[2], base[3] = murmur3.Sum128(append(h.data[:len(h.data):len(h.data)], 1))
+	return base[h.i%2] + h.i*base[2+((h.i+h.i%2)%4)/2]
+}
+
+func (h *bitsAndBloomsHash) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func (h *bitsAndBloomsHash) Reset() {
+	h.data = h.data[:0]
+}
+
+func (h *bitsAndBloomsHash) Size() int {
+	return 8
+}
+
+func (h *bitsAndBloomsHash) BlockSize() int {
+	return 1
+}
+
+// ImportBitsAndBlooms reads a Bloom filter written by the WriteTo method of the
+// github.com/bits-and-blooms/bloom package. The filter uses BitsAndBloomsHasher and ExclusiveLock.
+func ImportBitsAndBlooms(r io.Reader) (*BloomFilter, error) {
+	var header [3]uint64 // m, k, and the length of the bit set
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, err
+	}
+	m, k, length := header[0], header[1], header[2]
+	if m == 0 || k == 0 || length != m {
+		return nil, fmt.Errorf("%w: invalid parameters m=%d, k=%d, length=%d", ErrInvalidEncoding, m, k, length)
+	}
+	bitSet, err := readWordsFrom(r, binary.BigEndian, wordsFor(m))
+	if err != nil {
+		return nil, err
+	}
+	bf := &BloomFilter{}
+	if err := bf.restore(m, k, NewBitsAndBloomsHasher(), bitSet); err != nil {
+		return nil, err
+	}
+	return bf, nil
+}
+
+// ExportBitsAndBlooms writes the Bloom filter in the format read by the ReadFrom method of the
+// github.com/bits-and-blooms/bloom package. The filter must use BitsAndBloomsHasher, otherwise
+// the exported filter would not find the items that were added.
+func (bf *BloomFilter) ExportBitsAndBlooms(w io.Writer) error {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	if _, ok := bf.hasher.(*BitsAndBloomsHasher); !ok {
+		return fmt.Errorf("hasher %T is not compatible, the filter must use BitsAndBloomsHasher", bf.hasher)
+	}
+	if err := binary.Write(w, binary.BigEndian, [3]uint64{bf.m, bf.k, bf.m}); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, bf.bitSet)
+}
@@ -0,0 +1,61 @@
+package gobloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testdata/bitsandblooms.bin was written by github.com/bits-and-blooms/bloom/v3 v3.7.0,
+// with bloom.NewWithEstimates(1000, 0.01) and the items "item-0" to "item-999".
+
+func TestImportBitsAndBlooms(t *testing.T) {
+	t.Parallel()
+	data, err := os.ReadFile("testdata/bitsandblooms.bin")
+	assert.NoError(t, err)
+
+	bf, err := ImportBitsAndBlooms(bytes.NewReader(data))
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		b, err := bf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should be present in the imported filter", i)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, bf.ExportBitsAndBlooms(&buf))
+	assert.Equal(t, data, buf.Bytes(), "Exported filter should match the original")
+
+	_, err = ImportBitsAndBlooms(bytes.NewReader(data[:len(data)-1]))
+	assert.Error(t, err)
+
+	// A header claiming a huge filter fails on the short stream, instead of allocating the whole bit set.
+	huge := binary.BigEndian.AppendUint64(nil, 1<<63)
+	huge = binary.BigEndian.AppendUint64(huge, 7)
+	huge = binary.BigEndian.AppendUint64(huge, 1<<63)
+	_, err = ImportBitsAndBlooms(bytes.NewReader(huge))
+	assert.Error(t, err)
+}
+
+func TestExportBitsAndBlooms(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: NewBitsAndBloomsHasher()})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	data, err := os.ReadFile("testdata/bitsandblooms.bin")
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, bf.ExportBitsAndBlooms(&buf))
+	assert.Equal(t, data, buf.Bytes(), "Filters built by both packages should be identical")
+
+	other, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Error(t, other.ExportBitsAndBlooms(&buf), "Filters with other hashers should not be exported")
+}
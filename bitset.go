@@ -0,0 +1,68 @@
+package gobloom
+
+import (
+	"context"
+	"fmt"
+)
+
+var _ ContextInterface = (*BitSetBloomFilter)(nil)
+
+// BitSet is a storage for the bits of a Bloom filter, for filters whose bits don't live in the
+// process memory, like bits shared by several processes.
+type BitSet interface {
+	// Set sets the bits at the given positions.
+	Set(ctx context.Context, positions []uint64) error
+	// Test reports whether all the bits at the given positions are set.
+	Test(ctx context.Context, positions []uint64) (bool, error)
+}
+
+// BitSetBloomFilter is a Bloom filter whose bits are stored in a BitSet.
+// It holds no state besides its parameters, so it is as safe for concurrent use as its BitSet.
+type BitSetBloomFilter struct {
+	bits     BitSet
+	m        uint64   // The number of bits in the bit set
+	k        uint64   // The number of hash functions to use
+	hasher64 Hasher64 // The hasher computing the hashes of an item
+}
+
+// NewWithBitSet creates a new Bloom filter with the given number of elements (n) and false positive rate (p),
+// storing its bits in bits. The lock type is ignored, since the filter holds no state to protect.
+// All the filters sharing the same bits must be created with the same parameters and hasher.
+func NewWithBitSet(p Params, bits BitSet) (*BitSetBloomFilter, error) {
+	applyDefaults(&p)
+	if err := validateParams(p); err != nil {
+		return nil, err
+	}
+	if bits == nil {
+		return nil, fmt.Errorf("bit set cannot be nil")
+	}
+	m, k := getOptimalParams(p.N, p.FalsePositiveRate)
+	return &BitSetBloomFilter{
+		bits:     bits,
+		m:        m,
+		k:        k,
+		hasher64: asHasher64(p.Hasher),
+	}, nil
+}
+
+// Add adds an item to the Bloom filter.
+func (bbf *BitSetBloomFilter) Add(data []byte) error {
+	return bbf.AddCtx(context.Background(), data)
+}
+
+// Test checks if an item is in the Bloom filter.
+func (bbf *BitSetBloomFilter) Test(data []byte) (bool, error) {
+	return bbf.TestCtx(context.Background(), data)
+}
+
+// AddCtx adds an item to the Bloom filter, with a context passed to the bit set.
+func (bbf *BitSetBloomFilter) AddCtx(ctx context.Context, data []byte) error {
+	locs := locations(bbf.hasher64, data, bbf.k, bbf.m)
+	return bbf.bits.Set(ctx, locs)
+}
+
+// TestCtx checks if an item is in the Bloom filter, with a context passed to the bit set.
+func (bbf *BitSetBloomFilter) TestCtx(ctx context.Context, data []byte) (bool, error) {
+	locs := locations(bbf.hasher64, data, bbf.k, bbf.m)
+	return bbf.bits.Test(ctx, locs)
+}
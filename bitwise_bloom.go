@@ -0,0 +1,199 @@
+package gobloom
+
+var (
+	_ Interface = (*BitwiseBloomFilter)(nil)
+	_ Remover   = (*BitwiseBloomFilter)(nil)
+	_ Clearer   = (*BitwiseBloomFilter)(nil)
+)
+
+// bitwiseMaxPlanes is the maximum number of bit planes of a bitwise Bloom filter, so counters
+// saturate at 255 like the counters of CountingBloomFilter.
+const bitwiseMaxPlanes = 8
+
+// BitwiseBloomFilter is a counting Bloom filter whose counters are sliced into bit planes, the p-th plane
+// being a bit array holding the p-th bit of every counter. Planes are only allocated once a counter
+// carries into them, so a filter whose items are mostly added once uses little more than one bit per
+// counter, a middle ground between BloomFilter and the 8 bits per counter of CountingBloomFilter,
+// while still supporting removal and counting.
+type BitwiseBloomFilter struct {
+	m        uint64     // The number of counters
+	k        uint64     // The number of hash functions to use
+	planes   [][]uint64 // The bit planes, from the least significant bit of the counters
+	hasher   Hasher     // The hash provider the hash functions come from
+	hasher64 Hasher64   // The hasher computing the hashes of an item
+	mutex    Mutex      // Mutex to ensure thread safety
+}
+
+// NewBitwise creates a new bitwise Bloom filter with the given parameters.
+func NewBitwise(p Params) (*BitwiseBloomFilter, error) {
+	applyDefaults(&p)
+	if err := validateParams(p); err != nil {
+		return nil, err
+	}
+	m, k := getOptimalParams(p.N, p.FalsePositiveRate)
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	return &BitwiseBloomFilter{
+		m:        m,
+		k:        k,
+		planes:   [][]uint64{make([]uint64, (m+63)/64)},
+		hasher:   p.Hasher,
+		hasher64: asHasher64(p.Hasher),
+		mutex:    mu,
+	}, nil
+}
+
+// Add adds an item to the bitwise Bloom filter, incrementing its counters.
+// Counters saturate at their maximum value instead of overflowing.
+func (bwf *BitwiseBloomFilter) Add(data []byte) error {
+	if bwf.mutex != nil {
+		bwf.mutex.WLock()
+		defer bwf.mutex.WUnlock()
+	}
+	probes := pooledLocations(bwf.hasher64, data, bwf.k, bwf.m)
+	defer probePool.Put(probes)
+	for _, l := range *probes {
+		bwf.increment(l)
+	}
+	return nil
+}
+
+// Test checks if an item is in the bitwise Bloom filter.
+func (bwf *BitwiseBloomFilter) Test(data []byte) (bool, error) {
+	if bwf.mutex != nil {
+		bwf.mutex.RLock()
+		defer bwf.mutex.RUnlock()
+	}
+	probes := pooledLocations(bwf.hasher64, data, bwf.k, bwf.m)
+	defer probePool.Put(probes)
+	for _, l := range *probes {
+		if !bwf.nonZero(l) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Count estimates the number of times an item was added, as its smallest counter.
+// It may overestimate the count, like a count-min sketch, but never underestimates it.
+func (bwf *BitwiseBloomFilter) Count(data []byte) uint64 {
+	if bwf.mutex != nil {
+		bwf.mutex.RLock()
+		defer bwf.mutex.RUnlock()
+	}
+	probes := pooledLocations(bwf.hasher64, data, bwf.k, bwf.m)
+	defer probePool.Put(probes)
+	count := uint64(1<<bitwiseMaxPlanes - 1)
+	for _, l := range *probes {
+		count = min(count, bwf.counter(l))
+	}
+	return count
+}
+
+// Remove removes an item from the bitwise Bloom filter, decrementing its counters.
+// It returns ErrNotFound if the item is definitely not in the filter, in which case
+// no counter is changed. Removing an item that was never added (but tests positive)
+// may introduce false negatives for other items.
+func (bwf *BitwiseBloomFilter) Remove(data []byte) error {
+	if bwf.mutex != nil {
+		bwf.mutex.WLock()
+		defer bwf.mutex.WUnlock()
+	}
+	probes := pooledLocations(bwf.hasher64, data, bwf.k, bwf.m)
+	defer probePool.Put(probes)
+	for _, l := range *probes {
+		if !bwf.nonZero(l) {
+			return ErrNotFound
+		}
+	}
+	for _, l := range *probes {
+		bwf.decrement(l)
+	}
+	return nil
+}
+
+// Clear removes all the items from the bitwise Bloom filter, releasing the planes above the first one.
+func (bwf *BitwiseBloomFilter) Clear() {
+	if bwf.mutex != nil {
+		bwf.mutex.WLock()
+		defer bwf.mutex.WUnlock()
+	}
+	bwf.planes = [][]uint64{make([]uint64, (bwf.m+63)/64)}
+}
+
+// Planes returns the number of allocated bit planes, which is the number of bits of the largest counter.
+func (bwf *BitwiseBloomFilter) Planes() int {
+	if bwf.mutex != nil {
+		bwf.mutex.RLock()
+		defer bwf.mutex.RUnlock()
+	}
+	return len(bwf.planes)
+}
+
+// MemoryBytes returns the size of the allocated bit planes in bytes.
+func (bwf *BitwiseBloomFilter) MemoryBytes() uint64 {
+	if bwf.mutex != nil {
+		bwf.mutex.RLock()
+		defer bwf.mutex.RUnlock()
+	}
+	return 8 * uint64(len(bwf.planes)) * ((bwf.m + 63) / 64)
+}
+
+// counter returns the value of the counter at the position.
+func (bwf *BitwiseBloomFilter) counter(pos uint64) uint64 {
+	var v uint64
+	for p, plane := range bwf.planes {
+		v |= (plane[pos/64] >> (pos % 64) & 1) << p
+	}
+	return v
+}
+
+// nonZero reports whether the counter at the position is not zero.
+func (bwf *BitwiseBloomFilter) nonZero(pos uint64) bool {
+	for _, plane := range bwf.planes {
+		if plane[pos/64]&(1<<(pos%64)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// increment increments the counter at the position, carrying into the next planes,
+// which are allocated when needed.
+func (bwf *BitwiseBloomFilter) increment(pos uint64) {
+	if bwf.counter(pos) == 1<<bitwiseMaxPlanes-1 {
+		return // Saturated
+	}
+	bit := uint64(1) << (pos % 64)
+	for p := 0; ; p++ {
+		if p == len(bwf.planes) {
+			bwf.planes = append(bwf.planes, make([]uint64, len(bwf.planes[0])))
+		}
+		word := &bwf.planes[p][pos/64]
+		if *word&bit == 0 {
+			*word |= bit
+			return
+		}
+		*word &^= bit // Carry into the next plane
+	}
+}
+
+// decrement decrements the counter at the position, borrowing from the next planes.
+// Saturated counters are never decremented, since the true count is unknown, and zero counters,
+// which an item whose hashes share a position reaches, are left at zero.
+func (bwf *BitwiseBloomFilter) decrement(pos uint64) {
+	if c := bwf.counter(pos); c == 0 || c == 1<<bitwiseMaxPlanes-1 {
+		return
+	}
+	bit := uint64(1) << (pos % 64)
+	for _, plane := range bwf.planes {
+		word := &plane[pos/64]
+		if *word&bit != 0 {
+			*word &^= bit
+			return
+		}
+		*word |= bit // Borrow from the next plane
+	}
+}
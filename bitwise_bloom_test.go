@@ -0,0 +1,78 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitwiseBloomFilter_AddTestRemove(t *testing.T) {
+	t.Parallel()
+	bwf, err := NewBitwise(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create bitwise Bloom filter")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, bwf.Add([]byte(strconv.Itoa(i))))
+	}
+	for i := 0; i < 1000; i++ {
+		b, err := bwf.Test([]byte(strconv.Itoa(i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item %d should be present", i)
+	}
+	for i := 0; i < 500; i++ {
+		assert.NoError(t, bwf.Remove([]byte(strconv.Itoa(i))))
+	}
+	for i := 500; i < 1000; i++ {
+		b, _ := bwf.Test([]byte(strconv.Itoa(i)))
+		assert.True(t, b, "Item %d should still be present", i)
+	}
+	removed := 0
+	for i := 0; i < 500; i++ {
+		if b, _ := bwf.Test([]byte(strconv.Itoa(i))); !b {
+			removed++
+		}
+	}
+	assert.Greater(t, removed, 450, "Most removed items should be absent")
+	assert.ErrorIs(t, bwf.Remove([]byte("never-added-item-xyz")), ErrNotFound)
+
+	bwf.Clear()
+	assert.Equal(t, 1, bwf.Planes())
+	b, _ := bwf.Test([]byte("600"))
+	assert.False(t, b, "Cleared filter should be empty")
+}
+
+func TestBitwiseBloomFilter_Count(t *testing.T) {
+	t.Parallel()
+	bwf, err := NewBitwise(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create bitwise Bloom filter")
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, bwf.Add([]byte("hot")))
+	}
+	assert.NoError(t, bwf.Add([]byte("cold")))
+	assert.Equal(t, uint64(5), bwf.Count([]byte("hot")))
+	assert.Equal(t, uint64(1), bwf.Count([]byte("cold")))
+	assert.Equal(t, uint64(0), bwf.Count([]byte("missing")))
+	assert.Equal(t, 3, bwf.Planes(), "A count of 5 needs 3 planes")
+	assert.NoError(t, bwf.Remove([]byte("hot")))
+	assert.Equal(t, uint64(4), bwf.Count([]byte("hot")))
+
+	// Counters saturate.
+	for i := 0; i < 300; i++ {
+		assert.NoError(t, bwf.Add([]byte("hot")))
+	}
+	assert.Equal(t, uint64(255), bwf.Count([]byte("hot")))
+	assert.NoError(t, bwf.Remove([]byte("hot")))
+	assert.Equal(t, uint64(255), bwf.Count([]byte("hot")), "Saturated counters should not be decremented")
+}
+
+func TestBitwiseBloomFilter_Memory(t *testing.T) {
+	t.Parallel()
+	bwf, _ := NewBitwise(Params{N: 10000, FalsePositiveRate: 0.01})
+	cbf, _ := NewCounting(Params{N: 10000, FalsePositiveRate: 0.01})
+	for i := 0; i < 10000; i++ {
+		_ = bwf.Add([]byte(strconv.Itoa(i)))
+	}
+	// Distinct items only raise a few counters above 1, so few planes are needed.
+	assert.LessOrEqual(t, bwf.Planes(), 4)
+	assert.Less(t, bwf.MemoryBytes(), uint64(len(cbf.counters))/2, "Planes should use less memory than byte counters")
+}
@@ -1,12 +1,19 @@
 package gobloom
 
 import (
+	"context"
 	"fmt"
-	"hash"
 	"math"
+	"sync/atomic"
+	"unsafe"
 )
 
-var _ Interface = (*BloomFilter)(nil)
+var (
+	_ ContextInterface     = (*BloomFilter)(nil)
+	_ Clearer              = (*BloomFilter)(nil)
+	_ Merger[*BloomFilter] = (*BloomFilter)(nil)
+	_ Serializer           = (*BloomFilter)(nil)
+)
 
 // LockType represents the type of lock to use.
 type LockType uint
@@ -16,15 +23,35 @@ const (
 	LockTypeNone
 	LockTypeExclusive
 	LockTypeReadWrite
+	// LockTypeAtomic uses atomic operations on the words of the bit set instead of a lock, so concurrent
+	// Add and Test calls never wait for each other. It is only supported by BloomFilter, where Add, Test,
+	// Bits and Snapshot are safe for concurrent use. The other methods are not synchronized,
+	// as with LockTypeNone.
+	LockTypeAtomic
+	// LockTypeStriped partitions the bit set into stripes, each guarded by its own mutex, so concurrent
+	// Add and Test calls touching different stripes don't wait for each other. See StripedMutex.
+	LockTypeStriped
+	// LockTypeAuto picks the lock type from GOMAXPROCS and the ReadWriteRatio hint of Params when the
+	// filter is created, see autoLockType. Filters without the hint, and the filters not supporting
+	// LockTypeAtomic, pick between ExclusiveLock and ReadWriteLock.
+	LockTypeAuto
 )
 
 // BloomFilter represents a single Bloom filter structure.
 type BloomFilter struct {
-	m      uint64        // The number of bits in the bit set
-	bitSet []uint64      // The bit array represented as a slice of uint64
-	k      uint64        // The number of hash functions to use
-	hashes []hash.Hash64 // The hash functions to use
-	mutex  Mutex         // Mutex to ensure thread safety
+	m        uint64      // The number of bits in the bit set
+	bitSet   []uint64    // The bit array represented as a slice of uint64
+	k        uint64      // The number of hash functions to use
+	fpRate   float64     // The false positive rate the filter was sized for
+	hasher   Hasher      // The hash provider the hash functions come from
+	hasher64 Hasher64    // The hasher computing the hashes of an item, safe for concurrent use
+	mutex    Mutex       // Mutex to ensure thread safety
+	atomic   bool        // Whether the bit set is accessed with atomic operations, see LockTypeAtomic
+	cache    *probeCache // The hashes of recently used items, nil if disabled
+	observer Observer    // The observer of the operations, nil if disabled
+
+	generation uint64   // The current generation, used to track changes for Diff
+	stamps     []uint64 // The generation each block of the bit set was last changed in, nil until Generation is called
 }
 
 // Params represents the parameters for creating a new Bloom filter.
@@ -40,32 +67,48 @@ type Params struct {
 	// The use of ReadWriteLock can improve performance when there are many concurrent reads.
 	// If you have much more writes, avoid using ReadWriteLock, cause it may lead to reader starvation.
 	LockType LockType
+	// ReadWriteRatio is the expected number of Test calls per Add call, 0 if unknown.
+	// It is a hint for LockTypeAuto, and is ignored by the other lock types.
+	ReadWriteRatio float64
+	// Deterministic seeds the random rounding of Decay with a fixed seed instead of the time, so decaying
+	// the same counting or spectral filters gives identical counters. The other filters ignore it.
+	Deterministic bool
 }
 
 // New creates a new Bloom filter with the given number of elements (n) and false positive rate (p).
 func New(p Params) (*BloomFilter, error) {
 	applyDefaults(&p)
-	if p.N == 0 {
-		return nil, fmt.Errorf("number of elements cannot be 0")
-	}
-	if p.FalsePositiveRate <= 0 || p.FalsePositiveRate >= 1 {
-		return nil, fmt.Errorf("false positive rate must be between 0 and 1")
-	}
-	if p.Hasher == nil {
-		return nil, fmt.Errorf("hasher cannot be nil")
+	if err := validateParams(p); err != nil {
+		return nil, err
 	}
 	m, k := getOptimalParams(p.N, p.FalsePositiveRate)
+	return newBloomFilter(m, k, p)
+}
+
+// newBloomFilter creates a new Bloom filter with the given number of bits and hash functions,
+// and the other parameters from p.
+func newBloomFilter(m, k uint64, p Params) (*BloomFilter, error) {
 	bitSetSize := (m + 63) / 64 // Round up to the nearest 64 bits
-	mu, err := NewMutex(p.LockType)
-	if err != nil {
-		return nil, err
+	if p.LockType == LockTypeAuto {
+		p.LockType = autoLockType(p.ReadWriteRatio, true)
+	}
+	var mu Mutex
+	if p.LockType != LockTypeAtomic {
+		var err error
+		mu, err = NewMutex(p.LockType)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &BloomFilter{
-		m:      m,
-		k:      k,
-		bitSet: make([]uint64, bitSetSize),
-		hashes: p.Hasher.GetHashes(k),
-		mutex:  mu,
+		m:        m,
+		k:        k,
+		fpRate:   p.FalsePositiveRate,
+		bitSet:   make([]uint64, bitSetSize),
+		hasher:   p.Hasher,
+		hasher64: asHasher64(p.Hasher),
+		mutex:    mu,
+		atomic:   p.LockType == LockTypeAtomic,
 	}, nil
 }
 
@@ -79,6 +122,20 @@ func applyDefaults(p *Params) {
 	}
 }
 
+// validateParams validates the parameters, after the defaults are applied.
+func validateParams(p Params) error {
+	if p.N == 0 {
+		return fmt.Errorf("number of elements cannot be 0")
+	}
+	if p.FalsePositiveRate <= 0 || p.FalsePositiveRate >= 1 {
+		return fmt.Errorf("false positive rate must be between 0 and 1")
+	}
+	if p.Hasher == nil {
+		return fmt.Errorf("hasher cannot be nil")
+	}
+	return nil
+}
+
 // getOptimalParams calculates the optimal parameters for the Bloom filter,
 // the number of bits in the bit set (m) and the number of hash functions (k).
 func getOptimalParams(n uint64, p float64) (uint64, uint64) {
@@ -95,42 +152,315 @@ func getOptimalParams(n uint64, p float64) (uint64, uint64) {
 
 // Add adds an item to the Bloom filter.
 func (bf *BloomFilter) Add(data []byte) error {
+	// The hashes are computed before locking, so concurrent operations only wait for the bit set accesses.
+	probes := getProbes(bf.k)
+	defer probePool.Put(probes)
+	bf.hash(data, *probes)
+	bf.addHashes(*probes)
+	if bf.observer != nil {
+		bf.observer.Added(1)
+	}
+	return nil
+}
+
+// addHashes sets the bits of the hashes of one or more items, with the locking of the lock type.
+func (bf *BloomFilter) addHashes(hashes []uint64) {
+	if bf.atomic {
+		bf.addAtomic(hashes)
+		return
+	}
+	if sm, ok := bf.mutex.(*StripedMutex); ok {
+		bf.addStriped(sm, hashes)
+		return
+	}
 	if bf.mutex != nil {
 		bf.mutex.WLock()
 		defer bf.mutex.WUnlock()
 	}
-	for _, hash := range bf.hashes {
-		hash.Reset()
-		_, err := hash.Write(data)
-		if err != nil {
-			return err
-		}
-		hashValue := hash.Sum64() % bf.m
+	bf.setHashes(hashes)
+}
+
+// setHashes sets the bits of the hashes. The caller must hold the lock.
+func (bf *BloomFilter) setHashes(hashes []uint64) {
+	for _, h := range hashes {
+		hashValue := h % bf.m
 		index := hashValue / 64    // Find the index in the bitSet
 		position := hashValue % 64 // Find the position in the uint64
 		bf.bitSet[index] |= 1 << position
+		if bf.stamps != nil {
+			bf.stamps[index/diffBlockWords] = bf.generation
+		}
+	}
+}
+
+// AddMany adds the items to the Bloom filter. The hashes of all the items are computed first,
+// then the filter is locked once to set their bits, which is much faster than calling Add
+// for each item when loading many items.
+func (bf *BloomFilter) AddMany(items [][]byte) error {
+	return bf.addMany(context.Background(), items)
+}
+
+// AddManyCtx adds the items to the Bloom filter like AddMany, unless the context is done.
+// The context is passed to the observer of the filter, so the batch joins the trace of the caller.
+func (bf *BloomFilter) AddManyCtx(ctx context.Context, items [][]byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return bf.addMany(ctx, items)
+}
+
+// addMany adds the items to the Bloom filter, reporting the batch to the observer with the context.
+func (bf *BloomFilter) addMany(ctx context.Context, items [][]byte) error {
+	if end := observeBatch(ctx, bf.observer, "AddMany", len(items)); end != nil {
+		defer end(nil)
+	}
+	probes := make([]uint64, bf.k*uint64(len(items)))
+	for i, item := range items {
+		bf.hash(item, probes[uint64(i)*bf.k:uint64(i+1)*bf.k])
+	}
+	bf.addHashes(probes)
+	if bf.observer != nil {
+		bf.observer.Added(len(items))
 	}
 	return nil
 }
 
 // Test checks if an item is in the Bloom filter.
 func (bf *BloomFilter) Test(data []byte) (bool, error) {
+	probes := getProbes(bf.k)
+	defer probePool.Put(probes)
+	bf.hash(data, *probes)
+	present := bf.testHashes(*probes)
+	observeTest(bf.observer, present)
+	return present, nil
+}
+
+// testHashes checks the bits of the hashes of an item, with the locking of the lock type.
+func (bf *BloomFilter) testHashes(hashes []uint64) bool {
+	if sm, ok := bf.mutex.(*StripedMutex); ok {
+		return bf.testStriped(sm, hashes)
+	}
 	if bf.mutex != nil {
 		bf.mutex.RLock()
 		defer bf.mutex.RUnlock()
 	}
-	for _, hash := range bf.hashes {
-		hash.Reset()
-		_, err := hash.Write(data)
-		if err != nil {
-			return false, err
-		}
-		hashValue := hash.Sum64() % bf.m
+	for _, h := range hashes {
+		hashValue := h % bf.m
 		index := hashValue / 64    // Find the index in the bitSet
 		position := hashValue % 64 // Find the position in the uint64
-		if bf.bitSet[index]&(1<<position) == 0 {
-			return false, nil
+		var word uint64
+		if bf.atomic {
+			word = atomic.LoadUint64(&bf.bitSet[index])
+		} else {
+			word = bf.bitSet[index]
+		}
+		if word&(1<<position) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// addStriped sets the bits of the hashes, locking only the stripe of each bit, see LockTypeStriped.
+// Bits are only ever set, so they don't need to be set together.
+func (bf *BloomFilter) addStriped(sm *StripedMutex, hashes []uint64) {
+	for _, h := range hashes {
+		hashValue := h % bf.m
+		index := hashValue / 64
+		mu := sm.stripe(index / diffBlockWords)
+		mu.Lock()
+		bf.bitSet[index] |= 1 << (hashValue % 64)
+		if bf.stamps != nil {
+			bf.stamps[index/diffBlockWords] = bf.generation
+		}
+		mu.Unlock()
+	}
+}
+
+// testStriped checks the bits of the hashes, locking only the stripe of each bit, see LockTypeStriped.
+func (bf *BloomFilter) testStriped(sm *StripedMutex, hashes []uint64) bool {
+	for _, h := range hashes {
+		hashValue := h % bf.m
+		index := hashValue / 64
+		mu := sm.stripe(index / diffBlockWords)
+		mu.Lock()
+		word := bf.bitSet[index]
+		mu.Unlock()
+		if word&(1<<(hashValue%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// addAtomic sets the bits of the hashes with atomic operations, see LockTypeAtomic.
+func (bf *BloomFilter) addAtomic(hashes []uint64) {
+	for _, h := range hashes {
+		hashValue := h % bf.m
+		index := hashValue / 64
+		bit := uint64(1) << (hashValue % 64)
+		word := &bf.bitSet[index]
+		for {
+			old := atomic.LoadUint64(word)
+			if old&bit != 0 || atomic.CompareAndSwapUint64(word, old, old|bit) {
+				break
+			}
+		}
+		if bf.stamps != nil {
+			atomic.StoreUint64(&bf.stamps[index/diffBlockWords], bf.generation)
+		}
+	}
+}
+
+// hash sets probes to the hashes of the data, from the cache if it is enabled and holds them.
+func (bf *BloomFilter) hash(data []byte, probes []uint64) {
+	if bf.cache != nil && bf.cache.get(data, probes) {
+		return
+	}
+	bf.hasher64.HashK(data, probes)
+	if bf.cache != nil {
+		bf.cache.put(data, probes)
+	}
+}
+
+// AddCtx adds an item to the Bloom filter, unless the context is done.
+// The filter is in memory, so the context is only checked before adding.
+func (bf *BloomFilter) AddCtx(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return bf.Add(data)
+}
+
+// TestCtx checks if an item is in the Bloom filter, unless the context is done.
+// The filter is in memory, so the context is only checked before testing.
+func (bf *BloomFilter) TestCtx(ctx context.Context, data []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return bf.Test(data)
+}
+
+// AddString adds a string item to the Bloom filter, without copying it to a byte slice.
+// It is the same as Add([]byte(s)), so items added either way are found either way.
+func (bf *BloomFilter) AddString(s string) error {
+	return bf.Add(unsafeBytes(s))
+}
+
+// TestString checks if a string item is in the Bloom filter, without copying it to a byte slice,
+// so string-keyed lookups don't allocate. It is the same as Test([]byte(s)).
+func (bf *BloomFilter) TestString(s string) (bool, error) {
+	return bf.Test(unsafeBytes(s))
+}
+
+// unsafeBytes returns the bytes of the string without copying them. The bytes must not be modified,
+// and must not be retained after the call they are passed to, since the string may be reclaimed.
+// Hashers only read the data during the call, and the hash cache copies it.
+func unsafeBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+// locations returns the positions in a set of size m that the data hashes to, one per hash function.
+// Hashers are stateless, so it is safe for concurrent use.
+func locations(h Hasher64, data []byte, k, m uint64) []uint64 {
+	locs := make([]uint64, k)
+	h.HashK(data, locs)
+	for i := range locs {
+		locs[i] %= m
+	}
+	return locs
+}
+
+// pooledLocations is locations with a buffer from probePool, which the caller must return with probePool.Put,
+// so filters don't allocate for each item.
+func pooledLocations(h Hasher64, data []byte, k, m uint64) *[]uint64 {
+	locs := getProbes(k)
+	h.HashK(data, *locs)
+	for i := range *locs {
+		(*locs)[i] %= m
+	}
+	return locs
+}
+
+// M returns the number of bits in the bit set.
+func (bf *BloomFilter) M() uint64 {
+	return bf.m
+}
+
+// K returns the number of hash functions.
+func (bf *BloomFilter) K() uint64 {
+	return bf.k
+}
+
+// Capacity returns the number of elements the Bloom filter was sized for.
+// For filters restored from an encoding that doesn't hold the false positive rate, it is derived from m and k,
+// as the number of elements for which m and k are optimal, so it may differ slightly from the original one.
+func (bf *BloomFilter) Capacity() uint64 {
+	if bf.fpRate > 0 {
+		return uint64(float64(bf.m) * math.Pow(math.Log(2), 2) / -math.Log(bf.fpRate))
+	}
+	return uint64(float64(bf.m) * math.Log(2) / float64(bf.k))
+}
+
+// Bits returns a copy of the bit set. Bit i is set if Bits()[i/64]&(1<<(i%64)) != 0,
+// and the bits after m in the last word are always zero.
+func (bf *BloomFilter) Bits() []uint64 {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	return bf.copyBits()
+}
+
+// copyBits returns a copy of the bit set. The caller must hold the lock.
+func (bf *BloomFilter) copyBits() []uint64 {
+	bits := make([]uint64, len(bf.bitSet))
+	if bf.atomic {
+		for i := range bits {
+			bits[i] = atomic.LoadUint64(&bf.bitSet[i])
+		}
+		return bits
+	}
+	copy(bits, bf.bitSet)
+	return bits
+}
+
+// UnsafeBits returns the bit set itself, without copying it. Changes to it are visible to the filter
+// and the other way around, so the caller must synchronize its use with the filter's operations.
+// Changes made through it are not tracked by Diff.
+func (bf *BloomFilter) UnsafeBits() []uint64 {
+	return bf.bitSet
+}
+
+// SetBits replaces the bit set with a copy of bits, as returned by Bits on a filter with the same m.
+func (bf *BloomFilter) SetBits(bits []uint64) error {
+	if len(bits) != len(bf.bitSet) {
+		return fmt.Errorf("bit set has %d words, expected %d", len(bits), len(bf.bitSet))
+	}
+	if bf.m%64 != 0 && bits[len(bits)-1]>>(bf.m%64) != 0 {
+		return fmt.Errorf("bits after m=%d are set", bf.m)
+	}
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	copy(bf.bitSet, bits)
+	for i := range bf.stamps {
+		bf.stamps[i] = bf.generation
+	}
+	return nil
+}
+
+// setLocations sets the bits at the given positions, as returned by locations.
+func (bf *BloomFilter) setLocations(locs []uint64) {
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	for _, loc := range locs {
+		bf.bitSet[loc/64] |= 1 << (loc % 64)
+		if bf.stamps != nil {
+			bf.stamps[loc/64/diffBlockWords] = bf.generation
 		}
 	}
-	return true, nil
 }
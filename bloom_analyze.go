@@ -0,0 +1,89 @@
+package gobloom
+
+import (
+	"math"
+	"math/bits"
+)
+
+// analyzeBlockWords is the number of bit set words in a block analyzed by Analyze.
+const analyzeBlockWords = 64
+
+// Analysis is the distribution of the set bits of a Bloom filter over its words and blocks.
+// A good hasher spreads the bits uniformly, so the number of set bits of a region follows a binomial
+// distribution, and StdDev is close to ExpectedStdDev. A bad one, like a custom Hasher ignoring part
+// of the items or with a biased output, concentrates them, and StdDev grows well above it.
+type Analysis struct {
+	FillRatio float64      // The fraction of the bits of the filter that are set
+	Words     RegionsStats // The distribution over the 64-bit words
+	Blocks    RegionsStats // The distribution over the blocks of 4096 bits
+}
+
+// RegionsStats is the distribution of the set bits over regions of the same size of a bit set.
+// The last region is left out if it is not whole.
+type RegionsStats struct {
+	Size           uint64  // The number of bits of a region
+	Count          int     // The number of regions
+	Min            uint64  // The smallest number of set bits in a region
+	Max            uint64  // The largest number of set bits in a region
+	Mean           float64 // The mean number of set bits in a region
+	StdDev         float64 // The standard deviation of the number of set bits in a region
+	ExpectedStdDev float64 // The standard deviation expected from uniformly spread bits at the fill ratio
+}
+
+// Dispersion returns StdDev divided by ExpectedStdDev, close to 1 for uniformly spread bits. With many
+// regions, values above 1.5 point at pathological hashing. It returns 1 if no deviation is expected,
+// as for empty and full filters, unless the bits deviate anyway.
+func (s RegionsStats) Dispersion() float64 {
+	if s.ExpectedStdDev == 0 {
+		if s.StdDev == 0 {
+			return 1
+		}
+		return math.Inf(1)
+	}
+	return s.StdDev / s.ExpectedStdDev
+}
+
+// Analyze returns the distribution of the set bits of the Bloom filter over its words and blocks, to
+// detect pathological hashing in production. It reads the whole bit set.
+func (bf *BloomFilter) Analyze() Analysis {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	var set uint64
+	for _, w := range bf.bitSet {
+		set += uint64(bits.OnesCount64(w))
+	}
+	fill := float64(set) / float64(bf.m)
+	return Analysis{
+		FillRatio: fill,
+		Words:     bf.regionsStats(1, fill),
+		Blocks:    bf.regionsStats(analyzeBlockWords, fill),
+	}
+}
+
+// regionsStats returns the distribution of the set bits over the whole regions of the given number of
+// words. The caller must hold the lock.
+func (bf *BloomFilter) regionsStats(words int, fill float64) RegionsStats {
+	size := 64 * uint64(words)
+	s := RegionsStats{Size: size, Count: int(bf.m / size), Min: math.MaxUint64}
+	if s.Count == 0 {
+		s.Min = 0
+		return s
+	}
+	// The mean and variance are computed with Welford's algorithm, which is numerically stable.
+	var m2 float64
+	for i := 0; i < s.Count; i++ {
+		var n uint64
+		for _, w := range bf.bitSet[i*words : (i+1)*words] {
+			n += uint64(bits.OnesCount64(w))
+		}
+		s.Min, s.Max = min(s.Min, n), max(s.Max, n)
+		delta := float64(n) - s.Mean
+		s.Mean += delta / float64(i+1)
+		m2 += delta * (float64(n) - s.Mean)
+	}
+	s.StdDev = math.Sqrt(m2 / float64(s.Count))
+	s.ExpectedStdDev = math.Sqrt(float64(size) * fill * (1 - fill))
+	return s
+}
@@ -0,0 +1,67 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// skewedHasher is a bad hasher putting every position in the first tenth of a filter of m bits.
+type skewedHasher struct {
+	*MurMur3Hasher
+	m uint64
+}
+
+func (h *skewedHasher) HashK(data []byte, out []uint64) {
+	h.MurMur3Hasher.HashK(data, out)
+	for i := range out {
+		out[i] %= h.m / 10
+	}
+}
+
+func TestBloomFilter_Analyze(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 100000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	a := bf.Analyze()
+	assert.Zero(t, a.FillRatio)
+	assert.Equal(t, 1.0, a.Words.Dispersion(), "Empty filters should not look pathological")
+
+	for i := 0; i < 50000; i++ {
+		assert.NoError(t, bf.Add([]byte(strconv.Itoa(i))))
+	}
+	a = bf.Analyze()
+	assert.InDelta(t, bf.FillRatio(), a.FillRatio, 1e-12)
+	assert.Equal(t, uint64(64), a.Words.Size)
+	assert.Equal(t, int(bf.M()/64), a.Words.Count)
+	assert.Equal(t, uint64(4096), a.Blocks.Size)
+	assert.Equal(t, int(bf.M()/4096), a.Blocks.Count)
+	assert.InDelta(t, a.FillRatio*64, a.Words.Mean, 0.1)
+	assert.LessOrEqual(t, a.Words.Min, uint64(a.Words.Mean))
+	assert.GreaterOrEqual(t, a.Words.Max, uint64(a.Words.Mean))
+	assert.InDelta(t, 1, a.Words.Dispersion(), 0.1, "Uniform hashing should spread the bits binomially")
+	assert.InDelta(t, 1, a.Blocks.Dispersion(), 0.3)
+
+	m, _ := getOptimalParams(100000, 0.01)
+	skewed, err := New(Params{N: 100000, FalsePositiveRate: 0.01, Hasher: &skewedHasher{MurMur3Hasher: NewMurMur3Hasher(), m: m}})
+	assert.NoError(t, err)
+	for i := 0; i < 50000; i++ {
+		assert.NoError(t, skewed.Add([]byte(strconv.Itoa(i))))
+	}
+	a = skewed.Analyze()
+	assert.Greater(t, a.Blocks.Dispersion(), 1.5, "Skewed hashing should be detected")
+	assert.Zero(t, a.Blocks.Min)
+	assert.Greater(t, a.Blocks.Max, uint64(3500), "The first blocks should be almost full")
+}
+
+func TestBloomFilter_AnalyzeSmall(t *testing.T) {
+	t.Parallel()
+	bf, err := newBloomFilter(100, 3, Params{Hasher: NewMurMur3Hasher(), LockType: LockTypeExclusive})
+	assert.NoError(t, err)
+	assert.NoError(t, bf.Add([]byte("foo")))
+	a := bf.Analyze()
+	assert.Equal(t, 1, a.Words.Count, "The partial last word should be left out")
+	assert.Zero(t, a.Blocks.Count)
+	assert.Zero(t, a.Blocks.Min)
+}
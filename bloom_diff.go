@@ -0,0 +1,123 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// diffBlockWords is the number of bit set words tracked together for Diff.
+// Smaller blocks make diffs smaller, at the cost of more memory for the generation stamps.
+const diffBlockWords = 8
+
+// Generation is a marker of the state of a Bloom filter, used to export the changes made after it.
+type Generation uint64
+
+// Generation returns a marker of the current state of the Bloom filter, to be passed to Diff later.
+// Changes are tracked from the first call on, so a replica is typically created by calling Generation,
+// then copying the filter with MarshalBinary, then periodically sending the Diff since the last generation.
+func (bf *BloomFilter) Generation() Generation {
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	if bf.stamps == nil {
+		bf.stamps = make([]uint64, (uint64(len(bf.bitSet))+diffBlockWords-1)/diffBlockWords)
+	}
+	g := bf.generation
+	bf.generation++
+	return Generation(g)
+}
+
+// Diff returns the blocks of the bit set changed after the given generation, encoded with the wire format.
+// If Generation was never called, changes were not tracked and all non-empty blocks are returned.
+// Blocks changed at the same time as the generation was taken may be included in more than one diff,
+// which is harmless, since applying a diff is idempotent.
+//
+// The parameter block holds m and k. The payload holds, for each block, its index as a uvarint
+// followed by its words.
+func (bf *BloomFilter) Diff(since Generation) []byte {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, bf.m)
+	params = binary.AppendUvarint(params, bf.k)
+	var payload []byte
+	for block := 0; block*diffBlockWords < len(bf.bitSet); block++ {
+		words := bf.bitSet[block*diffBlockWords : min((block+1)*diffBlockWords, len(bf.bitSet))]
+		if bf.stamps != nil {
+			if bf.stamps[block] <= uint64(since) {
+				continue
+			}
+		} else if isZero(words) {
+			continue
+		}
+		payload = binary.AppendUvarint(payload, uint64(block))
+		payload = appendWords(payload, words)
+	}
+	return encodeFilter(filterTypeBloomDiff, params, payload)
+}
+
+// ApplyDiff sets the bits of a diff returned by Diff on a filter with the same m and k.
+func (bf *BloomFilter) ApplyDiff(diff []byte) error {
+	params, payload, err := decodeFilter(filterTypeBloomDiff, diff)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	m := r.uvarint()
+	k := r.uvarint()
+	if r.err != nil {
+		return r.err
+	}
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	if m != bf.m || k != bf.k {
+		return fmt.Errorf("incompatible filters, m=%d k=%d and m=%d k=%d", bf.m, bf.k, m, k)
+	}
+
+	// Validate the whole diff first, so a corrupt one is not partially applied.
+	type block struct {
+		index int
+		words []byte
+	}
+	var blocks []block
+	r = byteReader{data: payload}
+	for len(r.data) > 0 {
+		index := r.uvarint()
+		if r.err != nil || index*diffBlockWords >= uint64(len(bf.bitSet)) {
+			return fmt.Errorf("%w: invalid block index", ErrInvalidEncoding)
+		}
+		size := 8 * (min((index+1)*diffBlockWords, uint64(len(bf.bitSet))) - index*diffBlockWords)
+		if uint64(len(r.data)) < size {
+			return fmt.Errorf("%w: block %d is truncated", ErrInvalidEncoding, index)
+		}
+		blocks = append(blocks, block{index: int(index), words: r.data[:size]})
+		r.data = r.data[size:]
+	}
+	for _, b := range blocks {
+		changed := false
+		for i, w := range readWords(b.words) {
+			word := &bf.bitSet[b.index*diffBlockWords+i]
+			changed = changed || *word|w != *word
+			*word |= w
+		}
+		// Only changed blocks are stamped, so replicas applying each other's diffs don't send them back forever.
+		if changed && bf.stamps != nil {
+			bf.stamps[b.index] = bf.generation
+		}
+	}
+	return nil
+}
+
+// isZero reports whether all words are zero.
+func isZero(words []uint64) bool {
+	for _, w := range words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
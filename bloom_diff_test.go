@@ -0,0 +1,68 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter_DiffApplyDiff(t *testing.T) {
+	t.Parallel()
+	n := 10000
+	primary, err := New(Params{N: uint64(n), FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < n/2; i++ {
+		assert.NoError(t, primary.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	// Create the replica from a snapshot.
+	g := primary.Generation()
+	data, err := primary.MarshalBinary()
+	assert.NoError(t, err)
+	var replica BloomFilter
+	assert.NoError(t, replica.UnmarshalBinary(data))
+
+	// Nothing changed since the snapshot.
+	full := primary.Diff(g)
+	assert.Len(t, full, len(primary.Diff(primary.Generation())))
+
+	for i := n / 2; i < n/2+10; i++ {
+		assert.NoError(t, primary.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	diff := primary.Diff(g)
+	assert.Less(t, len(diff), len(data)/2, "Diff should be smaller than a snapshot")
+	assert.NoError(t, replica.ApplyDiff(diff))
+	assert.Equal(t, primary.bitSet, replica.bitSet)
+
+	// Applying a diff twice is harmless.
+	assert.NoError(t, replica.ApplyDiff(diff))
+	assert.Equal(t, primary.bitSet, replica.bitSet)
+}
+
+func TestBloomFilter_DiffUntracked(t *testing.T) {
+	t.Parallel()
+	primary, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	replica, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, primary.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	assert.NoError(t, replica.ApplyDiff(primary.Diff(0)))
+	assert.Equal(t, primary.bitSet, replica.bitSet)
+}
+
+func TestBloomFilter_ApplyDiffInvalid(t *testing.T) {
+	t.Parallel()
+	a, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	b, err := New(Params{N: 2000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, a.Add([]byte("foo")))
+
+	assert.Error(t, b.ApplyDiff(a.Diff(0)), "Diffs of incompatible filters should not apply")
+	diff := a.Diff(0)
+	assert.ErrorIs(t, a.ApplyDiff(diff[:len(diff)-1]), ErrCorruptFilter)
+}
@@ -0,0 +1,145 @@
+package gobloom
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*BloomFilter)(nil)
+	_ encoding.BinaryUnmarshaler = (*BloomFilter)(nil)
+	_ json.Marshaler             = (*BloomFilter)(nil)
+	_ json.Unmarshaler           = (*BloomFilter)(nil)
+)
+
+// MarshalBinary encodes the Bloom filter with the wire format, including its hasher,
+// so it can be restored identically. The hasher must implement NamedHasher.
+//
+// The parameter block holds m, k, and the hasher. The payload is the bit set.
+func (bf *BloomFilter) MarshalBinary() ([]byte, error) {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, bf.m)
+	params = binary.AppendUvarint(params, bf.k)
+	params, err := appendHasher(params, bf.hasher)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFilter(filterTypeBloom, params, appendWords(nil, bf.bitSet)), nil
+}
+
+// UnmarshalBinary restores a Bloom filter encoded with MarshalBinary.
+// The hasher must be registered with RegisterHasher, unless it is a hasher provided by this package.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value BloomFilter.
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeBloom, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	m := r.uvarint()
+	k := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	if err := checkMK(m, k); err != nil {
+		return err
+	}
+	if err := checkPayload(payload, 8, wordsFor(m)); err != nil {
+		return err
+	}
+	return bf.restore(m, k, hasher, readWords(payload))
+}
+
+// bloomFilterJSON is the JSON document of a Bloom filter.
+type bloomFilterJSON struct {
+	M      uint64     `json:"m"`
+	K      uint64     `json:"k"`
+	Hasher hasherJSON `json:"hasher"`
+	Bits   []byte     `json:"bits"` // The bit set as little-endian uint64 words, base64 encoded
+}
+
+// hasherJSON is the JSON document of a hasher.
+type hasherJSON struct {
+	Name  string `json:"name"`
+	State []byte `json:"state,omitempty"` // The hasher state, base64 encoded
+}
+
+// MarshalJSON encodes the Bloom filter as a JSON document with its parameters and its bit set in base64.
+// The hasher must implement NamedHasher.
+func (bf *BloomFilter) MarshalJSON() ([]byte, error) {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	name, state, err := marshalHasher(bf.hasher)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(bloomFilterJSON{
+		M:      bf.m,
+		K:      bf.k,
+		Hasher: hasherJSON{Name: name, State: state},
+		Bits:   appendWords(nil, bf.bitSet),
+	})
+}
+
+// UnmarshalJSON restores a Bloom filter encoded with MarshalJSON.
+// The hasher must be registered with RegisterHasher, unless it is a hasher provided by this package.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value BloomFilter.
+func (bf *BloomFilter) UnmarshalJSON(data []byte) error {
+	var doc bloomFilterJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if err := checkMK(doc.M, doc.K); err != nil {
+		return err
+	}
+	if len(doc.Bits)%8 != 0 || uint64(len(doc.Bits)/8) != wordsFor(doc.M) {
+		return fmt.Errorf("%w: bit set size does not match m", ErrInvalidEncoding)
+	}
+	hasher, err := unmarshalHasher(doc.Hasher.Name, doc.Hasher.State)
+	if err != nil {
+		return err
+	}
+	return bf.restore(doc.M, doc.K, hasher, readWords(doc.Bits))
+}
+
+// restore replaces the state of the Bloom filter with a decoded one, failing if m and k are invalid.
+// A zero value BloomFilter gets the default lock, filters created with LockTypeNone stay unlocked.
+func (bf *BloomFilter) restore(m, k uint64, hasher Hasher, bitSet []uint64) error {
+	if err := checkMK(m, k); err != nil {
+		return err
+	}
+	if bf.m == 0 && bf.mutex == nil && !bf.atomic {
+		bf.mutex = &ExclusiveMutex{}
+	}
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	bf.m = m
+	bf.k = k
+	// The false positive rate of the receiver doesn't apply to the decoded filter, Capacity derives it from m and k.
+	bf.fpRate = 0
+	bf.bitSet = bitSet
+	bf.hasher = hasher
+	bf.hasher64 = asHasher64(hasher)
+	if bf.cache != nil {
+		// The cached hashes may come from another hasher.
+		bf.cache = newProbeCache(bf.cache.size)
+	}
+	if bf.stamps != nil {
+		// Every block may have changed, so every block is part of the next diff.
+		bf.stamps = make([]uint64, (uint64(len(bitSet))+diffBlockWords-1)/diffBlockWords)
+		for i := range bf.stamps {
+			bf.stamps[i] = bf.generation
+		}
+	}
+	return nil
+}
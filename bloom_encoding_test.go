@@ -0,0 +1,128 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter_MarshalBinary(t *testing.T) {
+	t.Parallel()
+	n := uint64(1000)
+	bf, err := New(Params{N: n, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := uint64(0); i < n; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, bf.m, restored.m)
+	assert.Equal(t, bf.k, restored.k)
+	assert.Equal(t, bf.bitSet, restored.bitSet)
+	for i := uint64(0); i < n; i++ {
+		b, err := restored.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should be present after restoring", i)
+	}
+
+	again, err := restored.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, data, again)
+}
+
+func TestBloomFilter_UnmarshalBinaryInvalid(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+
+	var restored BloomFilter
+	assert.ErrorIs(t, restored.UnmarshalBinary(nil), ErrInvalidEncoding)
+	assert.ErrorIs(t, restored.UnmarshalBinary(data[:len(data)-1]), ErrInvalidEncoding)
+
+	// A huge k would allocate its probes on the first Test.
+	for _, k := range []uint64{65, 1 << 40} {
+		params := binary.AppendUvarint(nil, 64)
+		params = binary.AppendUvarint(params, k)
+		params, err = appendHasher(params, NewMurMur3Hasher())
+		assert.NoError(t, err)
+		assert.ErrorIs(t, restored.UnmarshalBinary(encodeFilter(filterTypeBloom, params, make([]byte, 8))), ErrInvalidEncoding,
+			"k=%d should be rejected", k)
+	}
+}
+
+func TestBloomFilter_UnmarshalBinaryLockType(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+
+	var zero BloomFilter
+	assert.NoError(t, zero.UnmarshalBinary(data))
+	assert.IsType(t, &ExclusiveMutex{}, zero.mutex, "A zero value filter should get the default lock")
+
+	unlocked, err := New(Params{N: 10, FalsePositiveRate: 0.1, LockType: LockTypeNone})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, unlocked.UnmarshalBinary(data))
+	assert.Nil(t, unlocked.mutex, "A filter created with LockTypeNone should stay unlocked")
+}
+
+type unnamedHasher struct{}
+
+func (unnamedHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashes := make([]hash.Hash64, n)
+	for i := range hashes {
+		hashes[i] = fnv.New64a()
+	}
+	return hashes
+}
+
+func TestBloomFilter_MarshalBinaryUnnamedHasher(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 100, FalsePositiveRate: 0.01, Hasher: unnamedHasher{}})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	_, err = bf.MarshalBinary()
+	assert.Error(t, err, "Hashers without a name should not be serializable")
+}
+
+func TestBloomFilter_MarshalJSON(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+
+	data, err := json.Marshal(bf)
+	assert.NoError(t, err)
+
+	var doc map[string]any
+	assert.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, float64(bf.m), doc["m"])
+	assert.Equal(t, float64(bf.k), doc["k"])
+	assert.Equal(t, map[string]any{"name": "murmur3"}, doc["hasher"])
+	assert.IsType(t, "", doc["bits"])
+
+	var restored BloomFilter
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, bf.bitSet, restored.bitSet)
+	b, err := restored.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b)
+
+	assert.ErrorIs(t, restored.UnmarshalJSON([]byte(`{"m":128,"k":3,"hasher":{"name":"murmur3"},"bits":""}`)), ErrInvalidEncoding)
+	assert.ErrorIs(t, restored.UnmarshalJSON([]byte(`{"m":18446744073709551615,"k":3,"hasher":{"name":"murmur3"},"bits":""}`)),
+		ErrInvalidEncoding, "Sizes wrapping around should be rejected")
+	assert.ErrorIs(t, restored.UnmarshalJSON([]byte(`{"m":64,"k":1099511627776,"hasher":{"name":"murmur3"},"bits":"AAAAAAAAAAA="}`)),
+		ErrInvalidEncoding, "A huge k should be rejected")
+	assert.Error(t, restored.UnmarshalJSON([]byte(`{"m":64,"k":3,"hasher":{"name":"unknown"},"bits":"AAAAAAAAAAA="}`)))
+}
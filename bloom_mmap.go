@@ -0,0 +1,238 @@
+//go:build linux || darwin
+
+package gobloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ErrBigEndian is returned when memory mapping a filter on a big-endian platform.
+var ErrBigEndian = errors.New("memory mapped filters are not supported on big-endian platforms")
+
+// A memory mapped filter file starts with a header, followed by the bit set as little-endian uint64 words,
+// which is mapped in memory as is:
+//
+//   - 4 magic bytes, "GBLM"
+//   - 1 byte layout version
+//   - 1 byte filter type
+//   - 2 zero bytes
+//   - 4 bytes length of the parameter block, little-endian
+//   - the parameter block, as in MarshalBinary
+//   - zero padding, so the bit set starts at a multiple of mmapAlign
+const (
+	mmapVersion = 1
+	mmapAlign   = 4096
+)
+
+// MmapBloomFilter is a Bloom filter whose bit set is a memory mapped file, so large filters don't live
+// on the Go heap, survive restarts and can be opened without loading them.
+// The filter must not be used after Close.
+type MmapBloomFilter struct {
+	*BloomFilter
+	file *os.File
+	data []byte // The whole mapped file
+}
+
+// OpenMmap opens the memory mapped Bloom filter stored in the file at path, creating it if it doesn't exist.
+// When the file exists, the parameters and hasher stored in it are used, and only the lock type is taken
+// from p. When it is created, the hasher must implement NamedHasher so it can be restored.
+// Changes are written back to the file by the operating system, Flush forces them to be written.
+func OpenMmap(path string, p Params) (*MmapBloomFilter, error) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		return nil, ErrBigEndian
+	}
+	applyDefaults(&p)
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	bf, size, err := readMmapHeader(file, p)
+	if err == nil {
+		var data []byte
+		data, err = syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+		if err == nil {
+			words := (bf.m + 63) / 64
+			bf.bitSet = unsafe.Slice((*uint64)(unsafe.Pointer(&data[uint64(size)-8*words])), words)
+			bf.hasher64 = asHasher64(bf.hasher)
+			bf.mutex = mu
+			return &MmapBloomFilter{BloomFilter: bf, file: file, data: data}, nil
+		}
+	}
+	_ = file.Close()
+	return nil, err
+}
+
+// readMmapHeader reads the header of a memory mapped filter file, or writes it if the file is empty,
+// and returns the filter, without its bit set, and the size of the file.
+func readMmapHeader(file *os.File, p Params) (*BloomFilter, int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.Size() == 0 {
+		return writeMmapHeader(file, p)
+	}
+
+	prefix := make([]byte, len(wireMagic)+8)
+	if _, err := io.ReadFull(file, prefix); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+	if string(prefix[:len(wireMagic)]) != wireMagic {
+		return nil, 0, fmt.Errorf("%w: bad magic bytes", ErrInvalidEncoding)
+	}
+	if prefix[4] != mmapVersion {
+		return nil, 0, fmt.Errorf("%w: %d", ErrUnsupportedVersion, prefix[4])
+	}
+	if filterType(prefix[5]) != filterTypeBloomMmap {
+		return nil, 0, fmt.Errorf("%w: filter type is %d, expected %d", ErrInvalidEncoding, prefix[5], filterTypeBloomMmap)
+	}
+	paramsLen := binary.LittleEndian.Uint32(prefix[8:])
+	if int64(paramsLen) > info.Size() {
+		return nil, 0, fmt.Errorf("%w: parameters are longer than the file", ErrInvalidEncoding)
+	}
+	params := make([]byte, paramsLen)
+	if _, err := io.ReadFull(file, params); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+	r := byteReader{data: params}
+	m := r.uvarint()
+	k := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := checkMK(m, k); err != nil {
+		return nil, 0, err
+	}
+	// The bit set size is checked against the file size without multiplying, so huge values of m
+	// cannot wrap around.
+	header, size := mmapHeaderSize(len(params)), info.Size()
+	if size < header || (size-header)%8 != 0 || uint64((size-header)/8) != wordsFor(m) {
+		return nil, 0, fmt.Errorf("%w: file is %d bytes, too small or large for m=%d", ErrInvalidEncoding, size, m)
+	}
+	return &BloomFilter{m: m, k: k, hasher: hasher}, size, nil
+}
+
+// writeMmapHeader writes the header of a new memory mapped filter file, and extends the file to its
+// full size, with an empty bit set.
+func writeMmapHeader(file *os.File, p Params) (*BloomFilter, int64, error) {
+	if err := validateParams(p); err != nil {
+		return nil, 0, err
+	}
+	m, k := getOptimalParams(p.N, p.FalsePositiveRate)
+	params := binary.AppendUvarint(nil, m)
+	params = binary.AppendUvarint(params, k)
+	params, err := appendHasher(params, p.Hasher)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	header := make([]byte, 0, mmapHeaderSize(len(params)))
+	header = append(header, wireMagic...)
+	header = append(header, mmapVersion, byte(filterTypeBloomMmap), 0, 0)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(params)))
+	header = append(header, params...)
+	header = header[:cap(header)]
+	size := int64(len(header)) + 8*int64((m+63)/64)
+	if _, err := file.WriteAt(header, 0); err != nil {
+		return nil, 0, err
+	}
+	if err := file.Truncate(size); err != nil {
+		return nil, 0, err
+	}
+	return &BloomFilter{m: m, k: k, fpRate: p.FalsePositiveRate, hasher: p.Hasher}, size, nil
+}
+
+// mmapHeaderSize returns the size of the header of a memory mapped filter file, padding included.
+func mmapHeaderSize(paramsLen int) int64 {
+	return (int64(len(wireMagic)+8+paramsLen) + mmapAlign - 1) / mmapAlign * mmapAlign
+}
+
+// Restore copies the bit set of the snapshot into the mapped file, see BloomFilter.Restore.
+func (f *MmapBloomFilter) Restore(snapshot *BloomFilter) error {
+	if snapshot.m != f.m || snapshot.k != f.k {
+		return fmt.Errorf("incompatible filters, m=%d k=%d and m=%d k=%d", f.m, f.k, snapshot.m, snapshot.k)
+	}
+	return f.SetBits(snapshot.Bits())
+}
+
+// UnmarshalBinary copies the bit set of a filter encoded with MarshalBinary into the mapped file.
+// The parameters and hasher are stored in the file and can't change, so the encoded filter must have the same.
+func (f *MmapBloomFilter) UnmarshalBinary(data []byte) error {
+	var decoded BloomFilter
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	return f.load(&decoded)
+}
+
+// UnmarshalJSON copies the bit set of a filter encoded with MarshalJSON into the mapped file,
+// see UnmarshalBinary.
+func (f *MmapBloomFilter) UnmarshalJSON(data []byte) error {
+	var decoded BloomFilter
+	if err := decoded.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	return f.load(&decoded)
+}
+
+// load copies the bit set of a decoded filter into the mapped file, instead of replacing the mapped bit set.
+func (f *MmapBloomFilter) load(decoded *BloomFilter) error {
+	if decoded.m != f.m || decoded.k != f.k {
+		return fmt.Errorf("incompatible filters, m=%d k=%d and m=%d k=%d", f.m, f.k, decoded.m, decoded.k)
+	}
+	name, state, err := marshalHasher(f.hasher)
+	if err != nil {
+		return err
+	}
+	decodedName, decodedState, err := marshalHasher(decoded.hasher)
+	if err != nil {
+		return err
+	}
+	if name != decodedName || !bytes.Equal(state, decodedState) {
+		return fmt.Errorf("incompatible hashers, %s and %s", name, decodedName)
+	}
+	return f.SetBits(decoded.bitSet)
+}
+
+// Flush writes the changes made to the filter to the file, and waits until they are written.
+func (f *MmapBloomFilter) Flush() error {
+	if f.mutex != nil {
+		f.mutex.RLock()
+		defer f.mutex.RUnlock()
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&f.data[0])), uintptr(len(f.data)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Close flushes the filter, then unmaps and closes the file.
+func (f *MmapBloomFilter) Close() error {
+	err := f.Flush()
+	if f.mutex != nil {
+		f.mutex.WLock()
+		defer f.mutex.WUnlock()
+	}
+	f.bitSet = nil
+	if unmapErr := syscall.Munmap(f.data); err == nil {
+		err = unmapErr
+	}
+	f.data = nil
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
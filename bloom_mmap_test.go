@@ -0,0 +1,137 @@
+//go:build linux || darwin
+
+package gobloom
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMmapBloomFilter_Reopen(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "filter.bloom")
+	bf, err := OpenMmap(path, Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create memory mapped Bloom filter")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.NoError(t, bf.Flush())
+	assert.NoError(t, bf.Close())
+
+	// The parameters stored in the file win over the given ones.
+	bf, err = OpenMmap(path, Params{N: 5, FalsePositiveRate: 0.5})
+	assert.NoError(t, err, "Failed to reopen memory mapped Bloom filter")
+	defer bf.Close()
+	assert.Equal(t, uint64(9586), bf.M())
+	assert.Equal(t, uint64(7), bf.K())
+	for i := 0; i < 1000; i++ {
+		b, err := bf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item should be present after reopening")
+	}
+
+	// The bit set is the same as a heap filter's.
+	heap, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, heap.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.Equal(t, heap.Bits(), bf.Bits())
+}
+
+func TestMmapBloomFilter_Invalid(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	_, err := OpenMmap(filepath.Join(dir, "empty"), Params{})
+	assert.Error(t, err, "Invalid parameters should be rejected")
+
+	path := filepath.Join(dir, "garbage")
+	assert.NoError(t, os.WriteFile(path, []byte("not a filter"), 0o644))
+	_, err = OpenMmap(path, Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+
+	path = filepath.Join(dir, "truncated")
+	bf, err := OpenMmap(path, Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create memory mapped Bloom filter")
+	assert.NoError(t, bf.Close())
+	assert.NoError(t, os.Truncate(path, mmapAlign))
+	_, err = OpenMmap(path, Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+
+	// header returns the header of a file of a filter with the given m and k, followed by words words.
+	header := func(m, k uint64, words int64) []byte {
+		params := binary.AppendUvarint(nil, m)
+		params = binary.AppendUvarint(params, k)
+		params, err := appendHasher(params, NewMurMur3Hasher())
+		assert.NoError(t, err)
+		header := append([]byte(wireMagic), mmapVersion, byte(filterTypeBloomMmap), 0, 0)
+		header = binary.LittleEndian.AppendUint32(header, uint32(len(params)))
+		header = append(header, params...)
+		return append(header, make([]byte, mmapHeaderSize(len(params))-int64(len(header))+8*words)...)
+	}
+	path = filepath.Join(dir, "huge")
+
+	// A huge m must not wrap the expected size around to the size of the file.
+	assert.NoError(t, os.WriteFile(path, header(math.MaxUint64, 3, 0), 0o644))
+	_, err = OpenMmap(path, Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+
+	// Nor a huge k, which would allocate its probes on the first Test.
+	assert.NoError(t, os.WriteFile(path, header(64, 1<<40, 1), 0o644))
+	_, err = OpenMmap(path, Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+
+	// Neither must a huge parameters length.
+	data := binary.LittleEndian.AppendUint32(header(64, 3, 1)[:len(wireMagic)+4], math.MaxUint32)
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+	_, err = OpenMmap(path, Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestMmapBloomFilter_Decode(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "filter.bloom")
+	bf, err := OpenMmap(path, Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create memory mapped Bloom filter")
+
+	heap, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, heap.Add([]byte("binary")))
+	data, err := heap.MarshalBinary()
+	assert.NoError(t, err)
+	assert.NoError(t, bf.UnmarshalBinary(data))
+	assert.NoError(t, heap.Add([]byte("json")))
+	data, err = json.Marshal(heap)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, bf))
+	assert.NoError(t, heap.Add([]byte("snapshot")))
+	assert.NoError(t, bf.Restore(heap.Snapshot()))
+
+	// The decoded bits, and the ones added after, are written to the file.
+	assert.NoError(t, bf.Add([]byte("added")))
+	assert.NoError(t, bf.Close())
+	bf, err = OpenMmap(path, Params{})
+	assert.NoError(t, err, "Failed to reopen memory mapped Bloom filter")
+	defer bf.Close()
+	for _, item := range []string{"binary", "json", "snapshot", "added"} {
+		b, err := bf.Test([]byte(item))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item %q should be present after reopening", item)
+	}
+
+	other, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: NewFNVHasher()})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	data, err = other.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Error(t, bf.UnmarshalBinary(data), "Filters with another hasher should not be decoded into the file")
+	small, err := New(Params{N: 10, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Error(t, bf.Restore(small), "Filters with other parameters should not be restored into the file")
+}
@@ -0,0 +1,96 @@
+package gobloom
+
+import (
+	"fmt"
+)
+
+// checkCompatible returns an error if the Bloom filters don't have the same m, k and hasher,
+// so their bit sets cannot be combined.
+func (bf *BloomFilter) checkCompatible(other *BloomFilter) error {
+	if bf.m != other.m || bf.k != other.k {
+		return fmt.Errorf("incompatible filters, m=%d k=%d and m=%d k=%d", bf.m, bf.k, other.m, other.k)
+	}
+	if !sameHasher(bf.hasher, other.hasher) {
+		return fmt.Errorf("incompatible filters, hashers %T and %T differ", bf.hasher, other.hasher)
+	}
+	return nil
+}
+
+// Union adds the items of the other Bloom filter to this one, by ORing their bit sets.
+// The filters must have the same m, k and hasher. The result is the same as adding all the items
+// to a single filter, so filters built by several workers can be combined into one.
+func (bf *BloomFilter) Union(other *BloomFilter) error {
+	if err := bf.checkCompatible(other); err != nil {
+		return err
+	}
+	bits := other.Bits()
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	for i, w := range bits {
+		if bf.bitSet[i]|w != bf.bitSet[i] {
+			bf.bitSet[i] |= w
+			if bf.stamps != nil {
+				bf.stamps[i/diffBlockWords] = bf.generation
+			}
+		}
+	}
+	return nil
+}
+
+// NewUnion creates a new Bloom filter holding the items of all the given filters, without changing them.
+// The filters must have the same m, k and hasher, the new filter uses the lock type of the first one.
+func NewUnion(filters ...*BloomFilter) (*BloomFilter, error) {
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("at least one filter is required")
+	}
+	union := filters[0].Snapshot()
+	for _, other := range filters[1:] {
+		if err := union.Union(other); err != nil {
+			return nil, err
+		}
+	}
+	return union, nil
+}
+
+// Intersect keeps only the items of this Bloom filter that are also in the other one, by ANDing their
+// bit sets. The filters must have the same m, k and hasher. The result may have a higher false positive
+// rate than a filter built from the common items only, since bits set by different items in each filter
+// remain set.
+func (bf *BloomFilter) Intersect(other *BloomFilter) error {
+	if err := bf.checkCompatible(other); err != nil {
+		return err
+	}
+	bits := other.Bits()
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	for i, w := range bits {
+		if bf.bitSet[i]&w != bf.bitSet[i] {
+			bf.bitSet[i] &= w
+			if bf.stamps != nil {
+				bf.stamps[i/diffBlockWords] = bf.generation
+			}
+		}
+	}
+	return nil
+}
+
+// Clear removes all the items from the Bloom filter, keeping its parameters.
+func (bf *BloomFilter) Clear() {
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	clear(bf.bitSet)
+	for i := range bf.stamps {
+		bf.stamps[i] = bf.generation
+	}
+}
+
+// Merge is the same as Union, so the Bloom filter implements Merger.
+func (bf *BloomFilter) Merge(other *BloomFilter) error {
+	return bf.Union(other)
+}
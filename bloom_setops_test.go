@@ -0,0 +1,80 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter_Union(t *testing.T) {
+	t.Parallel()
+	p := Params{N: 1000, FalsePositiveRate: 0.01}
+	all, err := New(p)
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	workers := make([]*BloomFilter, 4)
+	for w := range workers {
+		workers[w], err = New(p)
+		assert.NoError(t, err, "Failed to create Bloom filter")
+		for i := 0; i < 100; i++ {
+			item := []byte(fmt.Sprintf("item-%d-%d", w, i))
+			assert.NoError(t, workers[w].Add(item))
+			assert.NoError(t, all.Add(item))
+		}
+	}
+
+	union, err := NewUnion(workers...)
+	assert.NoError(t, err)
+	assert.Equal(t, all.Bits(), union.Bits(), "Union should equal adding all items to one filter")
+	assert.NotEqual(t, all.Bits(), workers[0].Bits(), "NewUnion should not change the filters")
+
+	assert.NoError(t, workers[0].Union(workers[1]))
+	b, err := workers[0].Test([]byte("item-1-42"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Items of the other filter should be present after Union")
+}
+
+func TestBloomFilter_UnionIncompatible(t *testing.T) {
+	t.Parallel()
+	a, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	b, err := New(Params{N: 2000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	c, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: NewBitsAndBloomsHasher()})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+
+	assert.Error(t, a.Union(b), "Filters of different sizes should not be combined")
+	assert.Error(t, a.Union(c), "Filters with different hashers should not be combined")
+	_, err = NewUnion()
+	assert.Error(t, err)
+}
+
+func TestBloomFilter_Intersect(t *testing.T) {
+	t.Parallel()
+	p := Params{N: 1000, FalsePositiveRate: 0.01}
+	a, err := New(p)
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	b, err := New(p)
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < 200; i++ {
+		assert.NoError(t, a.Add([]byte(fmt.Sprintf("item-%d", i))))
+		assert.NoError(t, b.Add([]byte(fmt.Sprintf("item-%d", i+100))))
+	}
+
+	assert.NoError(t, a.Intersect(b))
+	falsePositives := 0
+	for i := 0; i < 300; i++ {
+		found, err := a.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		if i >= 100 && i < 200 {
+			assert.True(t, found, "Common item %d should be present", i)
+		} else if found {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 10, "Items in only one filter should mostly be absent")
+
+	c, err := New(Params{N: 2000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Error(t, a.Intersect(c), "Filters of different sizes should not be combined")
+}
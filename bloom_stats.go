@@ -0,0 +1,97 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Stats is a snapshot of the statistics of a filter.
+type Stats struct {
+	Bits                       uint64  // The number of bits
+	SetBits                    uint64  // The number of set bits
+	K                          uint64  // The number of hash functions
+	EstimatedItems             uint64  // The estimated number of distinct items added
+	EstimatedFalsePositiveRate float64 // The estimated current false positive rate
+	MemoryBytes                uint64  // The size of the bit sets in bytes
+}
+
+// String formats the statistics on a single line, for logging.
+func (s Stats) String() string {
+	return fmt.Sprintf("bits=%d set=%d (%.1f%%) k=%d items~%d fp~%.4g memory=%dB",
+		s.Bits, s.SetBits, 100*float64(s.SetBits)/float64(s.Bits), s.K, s.EstimatedItems, s.EstimatedFalsePositiveRate, s.MemoryBytes)
+}
+
+// Stats returns a snapshot of the statistics of the Bloom filter.
+func (bf *BloomFilter) Stats() Stats {
+	x := bf.setBits()
+	return Stats{
+		Bits:                       bf.m,
+		SetBits:                    x,
+		K:                          bf.k,
+		EstimatedItems:             estimateCount(x, bf.m, bf.k),
+		EstimatedFalsePositiveRate: math.Pow(float64(x)/float64(bf.m), float64(bf.k)),
+		MemoryBytes:                8 * uint64(len(bf.bitSet)),
+	}
+}
+
+// ApproximateCount estimates the number of distinct items added to the Bloom filter from the number
+// of set bits X, with the estimator -m/k*ln(1-X/m) by Swamidass and Baldi.
+// If every bit is set, the count cannot be estimated and math.MaxUint64 is returned.
+func (bf *BloomFilter) ApproximateCount() uint64 {
+	return estimateCount(bf.setBits(), bf.m, bf.k)
+}
+
+// estimateCount estimates the number of items from the number of set bits x, see ApproximateCount.
+func estimateCount(x, m, k uint64) uint64 {
+	if x >= m {
+		return math.MaxUint64
+	}
+	return uint64(math.Round(-float64(m) / float64(k) * math.Log(1-float64(x)/float64(m))))
+}
+
+// setBits returns the number of set bits.
+func (bf *BloomFilter) setBits() uint64 {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	var n int
+	for _, w := range bf.bitSet {
+		n += bits.OnesCount64(w)
+	}
+	return uint64(n)
+}
+
+// FillRatio returns the fraction of the bits of the Bloom filter that are set.
+func (bf *BloomFilter) FillRatio() float64 {
+	return float64(bf.setBits()) / float64(bf.m)
+}
+
+// EstimatedFalsePositiveRate estimates the current false positive rate of the Bloom filter from its
+// fill ratio, as the probability that all k bits of an item not in the filter are set.
+// It exceeds the rate the filter was sized for once more than N items were added.
+func (bf *BloomFilter) EstimatedFalsePositiveRate() float64 {
+	return math.Pow(bf.FillRatio(), float64(bf.k))
+}
+
+// EstimateMemory returns the number of bits, the size of the bit set in bytes, and the number of hash
+// functions of a Bloom filter created with the given number of elements (n) and false positive rate (fp),
+// without allocating it. It returns zeros if the parameters are invalid.
+func EstimateMemory(n uint64, fp float64) (bits, bytes, k uint64) {
+	if n == 0 || fp <= 0 || fp >= 1 {
+		return 0, 0, 0
+	}
+	m, k := getOptimalParams(n, fp)
+	return m, bitSetBytes(m), k
+}
+
+// MemoryUsage returns the memory used by the Bloom filter in bytes: its bit set, plus the generation
+// stamps once Generation was called. The hash cache, if enabled, is not included.
+func (bf *BloomFilter) MemoryUsage() uint64 {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	return 8 * uint64(len(bf.bitSet)+len(bf.stamps))
+}
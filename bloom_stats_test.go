@@ -0,0 +1,89 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter_ApproximateCount(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 10000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Equal(t, uint64(0), bf.ApproximateCount())
+
+	for i := 0; i < 5000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.InDelta(t, 5000, bf.ApproximateCount(), 100, "Count should be close to the number of items")
+	// Adding the same items again does not change the count.
+	for i := 0; i < 5000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.InDelta(t, 5000, bf.ApproximateCount(), 100, "Count should not change with duplicates")
+
+	for i := range bf.bitSet {
+		bf.bitSet[i] = math.MaxUint64
+	}
+	assert.Equal(t, uint64(math.MaxUint64), bf.ApproximateCount())
+}
+
+func TestBloomFilter_FillRatio(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 10000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Equal(t, 0.0, bf.FillRatio())
+	assert.Equal(t, 0.0, bf.EstimatedFalsePositiveRate())
+
+	for i := 0; i < 10000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.InDelta(t, 0.5, bf.FillRatio(), 0.02, "An optimally sized filter should be half full at capacity")
+	assert.InDelta(t, 0.01, bf.EstimatedFalsePositiveRate(), 0.002, "Rate should be close to the design rate at capacity")
+
+	for i := 10000; i < 20000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.Greater(t, bf.EstimatedFalsePositiveRate(), 0.05, "Rate should degrade past capacity")
+}
+
+func TestBloomFilter_Stats(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	s := bf.Stats()
+	assert.Equal(t, bf.M(), s.Bits)
+	assert.Equal(t, bf.K(), s.K)
+	assert.Equal(t, uint64(1200), s.MemoryBytes)
+	assert.InDelta(t, bf.FillRatio(), float64(s.SetBits)/float64(s.Bits), 1e-9)
+	assert.Equal(t, bf.ApproximateCount(), s.EstimatedItems)
+	assert.Equal(t, bf.EstimatedFalsePositiveRate(), s.EstimatedFalsePositiveRate)
+	assert.Regexp(t, `^bits=9586 set=\d+ \(\d+\.\d%\) k=7 items~\d+ fp~0\.0\d+ memory=1200B$`, s.String())
+}
+
+func TestEstimateMemory(t *testing.T) {
+	t.Parallel()
+	for _, n := range []uint64{1, 1000, 123456} {
+		for _, fp := range []float64{0.1, 0.01, 0.0001} {
+			bits, bytes, k := EstimateMemory(n, fp)
+			bf, err := New(Params{N: n, FalsePositiveRate: fp})
+			assert.NoError(t, err)
+			assert.Equal(t, bf.M(), bits, "n=%d fp=%g", n, fp)
+			assert.Equal(t, bf.K(), k, "n=%d fp=%g", n, fp)
+			assert.Equal(t, bf.MemoryUsage(), bytes, "n=%d fp=%g", n, fp)
+		}
+	}
+	bits, bytes, k := EstimateMemory(0, 0.01)
+	assert.Zero(t, bits+bytes+k, "Invalid parameters should return zeros")
+
+	bf, _ := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	before := bf.MemoryUsage()
+	bf.Generation()
+	assert.Greater(t, bf.MemoryUsage(), before, "Generation stamps should be counted")
+}
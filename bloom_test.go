@@ -1,7 +1,9 @@
 package gobloom
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -58,3 +60,250 @@ func TestBloomFilter_AddTestNonExistentItem(t *testing.T) {
 	assert.NoError(t, err, "Failed to test non-existent item")
 	assert.False(t, b, "Non-existent item should not be present in the Bloom filter.")
 }
+
+func TestBloomFilter_BitsSetBits(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+	assert.Equal(t, uint64(9586), bf.M())
+	assert.Equal(t, uint64(7), bf.K())
+	assert.Equal(t, uint64(1000), bf.Capacity())
+
+	bits := bf.Bits()
+	assert.Len(t, bits, int((bf.M()+63)/64))
+	bits[0] = ^uint64(0)
+	assert.NotEqual(t, bits[0], bf.UnsafeBits()[0], "Bits should return a copy")
+
+	other, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, other.SetBits(bf.Bits()))
+	b, err := other.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after setting the bits")
+
+	assert.Error(t, other.SetBits(bits[1:]), "Bit sets of another size should be rejected")
+	bits = bf.Bits()
+	bits[len(bits)-1] = ^uint64(0)
+	assert.Error(t, other.SetBits(bits), "Bits after m should be rejected")
+}
+
+func TestBloomFilter_Ctx(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	ctx := context.Background()
+	assert.NoError(t, bf.AddCtx(ctx, []byte("foo")))
+	b, err := bf.TestCtx(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, bf.AddCtx(canceled, []byte("bar")), context.Canceled)
+	_, err = bf.TestCtx(canceled, []byte("foo"))
+	assert.ErrorIs(t, err, context.Canceled)
+	b, err = bf.Test([]byte("bar"))
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should not be added once the context is canceled")
+}
+
+func TestBloomFilter_Capacity(t *testing.T) {
+	t.Parallel()
+	for _, n := range []uint64{1, 10, 1000, 123456} {
+		for _, p := range []float64{0.1, 0.01, 0.0001} {
+			bf, err := New(Params{N: n, FalsePositiveRate: p})
+			assert.NoError(t, err, "Failed to create Bloom filter")
+			assert.Equal(t, n, bf.Capacity(), "Capacity of n=%d p=%f", n, p)
+		}
+	}
+
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.InDelta(t, 1000, restored.Capacity(), 100, "Capacity of a restored filter should be derived from m and k")
+
+	other, err := New(Params{N: 10, FalsePositiveRate: 0.5})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, other.UnmarshalBinary(data))
+	assert.Equal(t, restored.Capacity(), other.Capacity(), "The false positive rate of the receiver should not be kept")
+}
+
+func TestBloomFilter_AtomicLock(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 10000, FalsePositiveRate: 0.01, LockType: LockTypeAtomic})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Nil(t, bf.mutex, "Atomic filters should not use a lock")
+
+	// Concurrent writers and readers, run with -race.
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 10000; i += 4 {
+				item := []byte(fmt.Sprintf("item-%d", i))
+				assert.NoError(t, bf.Add(item))
+				b, err := bf.Test(item)
+				assert.NoError(t, err)
+				assert.True(t, b, "Item %d should be present after adding it", i)
+			}
+		}(g)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			_ = bf.Snapshot()
+		}
+	}()
+	wg.Wait()
+
+	for i := 0; i < 10000; i++ {
+		b, err := bf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item %d should be present", i)
+	}
+	assert.True(t, bf.Snapshot().atomic, "Snapshots should keep the lock type")
+
+	_, err = NewCounting(Params{N: 100, FalsePositiveRate: 0.01, LockType: LockTypeAtomic})
+	assert.ErrorIs(t, err, ErrInvalidLockType, "Other filters should not support atomic locking")
+}
+
+func TestBloomFilter_StripedLock(t *testing.T) {
+	t.Parallel()
+	f, err := NewWithOptions(10000, 0.01, WithStripes(8))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	bf := f.(*BloomFilter)
+	assert.Len(t, bf.mutex.(*StripedMutex).stripes, 8)
+	bf.Generation()
+
+	// Concurrent writers and readers, run with -race.
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 10000; i += 4 {
+				item := []byte(fmt.Sprintf("item-%d", i))
+				assert.NoError(t, bf.Add(item))
+				b, err := bf.Test(item)
+				assert.NoError(t, err)
+				assert.True(t, b, "Item %d should be present after adding it", i)
+			}
+		}(g)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			_ = bf.Bits()
+			_ = bf.Snapshot()
+		}
+	}()
+	wg.Wait()
+
+	restored, err := New(Params{N: 10000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, restored.ApplyDiff(bf.Diff(0)))
+	for i := 0; i < 10000; i++ {
+		b, err := restored.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item %d should be present in the diff", i)
+	}
+}
+
+func TestBloomFilter_ZeroAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("Pooled buffers are dropped at random with the race detector")
+	}
+	hashers := map[string]Hasher{
+		"MurMur3":       NewMurMur3Hasher(),
+		"MurMur3x128":   NewMurMur3x128Hasher(),
+		"BitsAndBlooms": NewBitsAndBloomsHasher(),
+		"XX":            NewXXHasher(),
+		"FNV":           NewFNVHasher(),
+		"Wy":            NewWyHasher(),
+		"Double":        NewDoubleHasher(nil),
+		"Stateful":      statefulOnlyHasher{NewMurMur3Hasher()},
+		"DoubleOver":    NewDoubleHasher(statefulOnlyHasher{NewMurMur3Hasher()}),
+	}
+	for name, hasher := range hashers {
+		for _, lockType := range []LockType{LockTypeExclusive, LockTypeReadWrite, LockTypeAtomic, LockTypeStriped} {
+			bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: hasher, LockType: lockType})
+			assert.NoError(t, err, "Failed to create Bloom filter")
+			data := []byte("zero-alloc-item")
+			allocs := testing.AllocsPerRun(100, func() {
+				_ = bf.Add(data)
+				_, _ = bf.Test(data)
+			})
+			assert.Zero(t, allocs, "Add and Test with %s hasher and lock type %d should not allocate", name, lockType)
+		}
+	}
+}
+
+func TestBloomFilter_AddMany(t *testing.T) {
+	t.Parallel()
+	items := make([][]byte, 1000)
+	for i := range items {
+		items[i] = []byte(fmt.Sprintf("item-%d", i))
+	}
+	for _, lockType := range []LockType{LockTypeNone, LockTypeExclusive, LockTypeAtomic, LockTypeStriped} {
+		bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, LockType: lockType})
+		assert.NoError(t, err, "Failed to create Bloom filter")
+		one, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+		assert.NoError(t, err, "Failed to create Bloom filter")
+		assert.NoError(t, bf.AddMany(items))
+		for _, item := range items {
+			assert.NoError(t, one.Add(item))
+		}
+		assert.Equal(t, one.Bits(), bf.Bits(), "AddMany should set the same bits as Add with lock type %d", lockType)
+	}
+}
+
+func BenchmarkBloomFilter_AddMany(b *testing.B) {
+	items := make([][]byte, 1000)
+	for i := range items {
+		items[i] = []byte(fmt.Sprintf("item-%d", i))
+	}
+	bf, _ := New(Params{N: 100000, FalsePositiveRate: 0.01})
+	b.Run("Add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, item := range items {
+				_ = bf.Add(item)
+			}
+		}
+	})
+	b.Run("AddMany", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = bf.AddMany(items)
+		}
+	})
+}
+
+func TestBloomFilter_String(t *testing.T) {
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.AddString("foo"))
+	assert.NoError(t, bf.Add([]byte("bar")))
+	for _, item := range []string{"foo", "bar"} {
+		b, err := bf.TestString(item)
+		assert.NoError(t, err)
+		assert.True(t, b, "Item '%s' should be present", item)
+		b, err = bf.Test([]byte(item))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item '%s' should be present", item)
+	}
+	b, err := bf.TestString("")
+	assert.NoError(t, err)
+	assert.False(t, b, "Empty string should not be present")
+
+	if !raceEnabled {
+		key := fmt.Sprintf("item-%d", 42)
+		allocs := testing.AllocsPerRun(100, func() { _, _ = bf.TestString(key) })
+		assert.Zero(t, allocs, "TestString should not allocate")
+	}
+}
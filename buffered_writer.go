@@ -0,0 +1,59 @@
+package gobloom
+
+import "time"
+
+// BufferedWriter accumulates the hashes of the items added through it, and sets their bits in
+// the Bloom filter in one locked operation when its buffer is full or its flush interval elapsed,
+// or when Flush is called. Each goroutine uses its own writer, so writers don't contend for the
+// filter lock on every item, at the cost of items only being reported by Test once flushed.
+//
+// A BufferedWriter is not safe for concurrent use.
+type BufferedWriter struct {
+	bf        *BloomFilter
+	probes    []uint64      // The hashes of the buffered items
+	size      int           // The number of items buffered before flushing
+	interval  time.Duration // The maximum age of the buffered items checked on Add, 0 for no limit
+	lastFlush time.Time
+}
+
+// NewBufferedWriter creates a writer buffering up to size items, which defaults to 256.
+// When interval is not 0, Add also flushes the buffer once interval elapsed since the last flush,
+// which bounds the staleness of Test results while items keep being added.
+func (bf *BloomFilter) NewBufferedWriter(size int, interval time.Duration) *BufferedWriter {
+	if size <= 0 {
+		size = 256
+	}
+	return &BufferedWriter{
+		bf:        bf,
+		probes:    make([]uint64, 0, uint64(size)*bf.k),
+		size:      size,
+		interval:  interval,
+		lastFlush: time.Now(),
+	}
+}
+
+// Add buffers the hashes of an item, flushing the buffer if it is full or its interval elapsed.
+func (w *BufferedWriter) Add(data []byte) error {
+	n := len(w.probes)
+	w.probes = w.probes[:n+int(w.bf.k)]
+	w.bf.hash(data, w.probes[n:])
+	if len(w.probes) == cap(w.probes) || (w.interval > 0 && time.Since(w.lastFlush) >= w.interval) {
+		w.Flush()
+	}
+	return nil
+}
+
+// Flush sets the bits of the buffered items in the Bloom filter.
+// It must be called once the writer is no longer used, for the last items to be added.
+func (w *BufferedWriter) Flush() {
+	if len(w.probes) > 0 {
+		w.bf.addHashes(w.probes)
+		w.probes = w.probes[:0]
+	}
+	w.lastFlush = time.Now()
+}
+
+// Buffered returns the number of items waiting to be flushed.
+func (w *BufferedWriter) Buffered() int {
+	return len(w.probes) / int(w.bf.k)
+}
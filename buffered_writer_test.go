@@ -0,0 +1,77 @@
+package gobloom
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferedWriter_Flush(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	w := bf.NewBufferedWriter(10, 0)
+
+	for i := 0; i < 9; i++ {
+		assert.NoError(t, w.Add([]byte(strconv.Itoa(i))))
+	}
+	assert.Equal(t, 9, w.Buffered())
+	b, _ := bf.Test([]byte("0"))
+	assert.False(t, b, "Buffered items should not be in the filter before flushing")
+
+	// Filling the buffer flushes it.
+	assert.NoError(t, w.Add([]byte("9")))
+	assert.Equal(t, 0, w.Buffered())
+	for i := 0; i < 10; i++ {
+		b, _ := bf.Test([]byte(strconv.Itoa(i)))
+		assert.True(t, b, "Item %d should be present after flushing", i)
+	}
+
+	assert.NoError(t, w.Add([]byte("last")))
+	w.Flush()
+	b, _ = bf.Test([]byte("last"))
+	assert.True(t, b, "Item should be present after Flush")
+}
+
+func TestBufferedWriter_Interval(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	w := bf.NewBufferedWriter(100, time.Millisecond)
+	assert.NoError(t, w.Add([]byte("first")))
+	time.Sleep(2 * time.Millisecond)
+	assert.NoError(t, w.Add([]byte("second")))
+	assert.Equal(t, 0, w.Buffered(), "Items older than the interval should be flushed")
+	b, _ := bf.Test([]byte("first"))
+	assert.True(t, b)
+}
+
+func TestBufferedWriter_Concurrent(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 8000, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			w := bf.NewBufferedWriter(64, 0)
+			defer w.Flush()
+			for i := 0; i < 1000; i++ {
+				assert.NoError(t, w.Add([]byte(fmt.Sprintf("%d-%d", g, i))))
+				_, _ = bf.Test([]byte(strconv.Itoa(i)))
+			}
+		}(g)
+	}
+	wg.Wait()
+	for g := 0; g < 8; g++ {
+		for i := 0; i < 1000; i++ {
+			b, _ := bf.Test([]byte(fmt.Sprintf("%d-%d", g, i)))
+			assert.True(t, b, "Item %d-%d should be present", g, i)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"math/bits"
+)
+
+const cassandraHasherName = "cassandra"
+
+// CassandraFormat is the layout of the bloom filter component (the Filter.db file) of a Cassandra SSTable,
+// which depends on the SSTable format version, the two letters after the keyspace and table in the file name.
+type CassandraFormat uint8
+
+const (
+	// CassandraFormat40 is the format of the SSTables written by Cassandra 4.0 and later, from version "na",
+	// storing the bits as raw bytes.
+	CassandraFormat40 CassandraFormat = iota
+	// CassandraFormat30 is the format of the SSTables written by Cassandra 3.x, versions "ma" to "md",
+	// storing the bits as big-endian longs.
+	CassandraFormat30
+	// CassandraFormatLegacy is the format of the SSTables written before Cassandra 3.0, up to version "la",
+	// storing the bits like CassandraFormat30, with the halves of the hash swapped when deriving the positions.
+	CassandraFormatLegacy
+)
+
+// CassandraHasher is a hasher compatible with the bloom filters of Cassandra SSTables. Cassandra hashes
+// the serialized partition key with its murmur3 x64 128-bit variant, which sign-extends the bytes of the
+// last incomplete block, and derives the positions by adding one half of the hash to the other. Legacy
+// hashers use the order of the halves of the SSTables written before Cassandra 3.0.
+type CassandraHasher struct {
+	legacy bool
+}
+
+var (
+	_ NamedHasher = (*CassandraHasher)(nil)
+	_ Hasher64    = (*CassandraHasher)(nil)
+)
+
+// NewCassandraHasher creates a CassandraHasher, for the SSTables written before Cassandra 3.0 if legacy is true.
+func NewCassandraHasher(legacy bool) *CassandraHasher {
+	return &CassandraHasher{legacy: legacy}
+}
+
+// Legacy returns whether the hasher uses the hash order of the SSTables written before Cassandra 3.0.
+func (h *CassandraHasher) Legacy() bool {
+	return h.legacy
+}
+
+func (h *CassandraHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashers := make([]hash.Hash64, n)
+	for i := range hashers {
+		hashers[i] = &cassandraHash{h: h, i: i}
+	}
+	return hashers
+}
+
+// HashK sets the positions before they are reduced to the size of the bit set. Cassandra reduces them
+// with abs(base % m) on signed longs, which is the absolute value of base reduced modulo m.
+func (h *CassandraHasher) HashK(data []byte, out []uint64) {
+	h1, h2 := cassandraMurmur3(data)
+	base, inc := h2, h1
+	if h.legacy {
+		base, inc = h1, h2
+	}
+	for i := range out {
+		v := base
+		if int64(v) < 0 {
+			v = -v // math.MinInt64 stays 1<<63, its absolute value
+		}
+		out[i] = v
+		base += inc
+	}
+}
+
+func (h *CassandraHasher) Name() string {
+	return cassandraHasherName
+}
+
+// MarshalBinary encodes the hash order of the hasher, the current order is encoded as no state.
+func (h *CassandraHasher) MarshalBinary() ([]byte, error) {
+	if !h.legacy {
+		return nil, nil
+	}
+	return []byte{1}, nil
+}
+
+// UnmarshalBinary restores the hash order of the hasher encoded with MarshalBinary.
+func (h *CassandraHasher) UnmarshalBinary(data []byte) error {
+	switch {
+	case len(data) == 0:
+		h.legacy = false
+	case len(data) == 1 && data[0] == 1:
+		h.legacy = true
+	default:
+		return fmt.Errorf("%w: invalid cassandra hasher state", ErrInvalidEncoding)
+	}
+	return nil
+}
+
+// cassandraHash is a hash.Hash64 whose sum is the i-th probe position of the written data,
+// before it is reduced to the size of the bit set.
+type cassandraHash struct {
+	h    *CassandraHasher
+	i    int
+	data []byte
+}
+
+func (h *cassandraHash) Write(p []byte) (int, error) {
+	h.data = append(h.data, p...)
+	return len(p), nil
+}
+
+func (h *cassandraHash) Sum64() uint64 {
+	out := make([]uint64, h.i+1)
+	h.h.HashK(h.data, out)
+	return out[h.i]
+}
+
+func (h *cassandraHash) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func (h *cassandraHash) Reset() {
+	h.data = h.data[:0]
+}
+
+func (h *cassandraHash) Size() int {
+	return 8
+}
+
+func (h *cassandraHash) BlockSize() int {
+	return 1
+}
+
+// cassandraMurmur3 is the MurmurHash.hash3_x64_128 function of Cassandra with a zero seed. It is murmur3
+// x64 128, except that the bytes of the last incomplete block are sign-extended before being shifted.
+func cassandraMurmur3(data []byte) (uint64, uint64) {
+	const c1, c2 = 0x87c37b91114253d5, 0x4cf5ad432745937f
+	var h1, h2 uint64
+	n := len(data) / 16 * 16
+	for i := 0; i < n; i += 16 {
+		k1, k2 := binary.LittleEndian.Uint64(data[i:]), binary.LittleEndian.Uint64(data[i+8:])
+		k1 *= c1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= c2
+		h1 ^= k1
+		h1 = bits.RotateLeft64(h1, 27)
+		h1 += h2
+		h1 = h1*5 + 0x52dce729
+		k2 *= c2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= c1
+		h2 ^= k2
+		h2 = bits.RotateLeft64(h2, 31)
+		h2 += h1
+		h2 = h2*5 + 0x38495ab5
+	}
+	tail := data[n:]
+	var k1, k2 uint64
+	for i := len(tail) - 1; i >= 8; i-- {
+		k2 ^= uint64(int64(int8(tail[i]))) << (8 * (i - 8))
+	}
+	if len(tail) > 8 {
+		k2 *= c2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= c1
+		h2 ^= k2
+	}
+	for i := min(len(tail), 8) - 1; i >= 0; i-- {
+		k1 ^= uint64(int64(int8(tail[i]))) << (8 * i)
+	}
+	if len(tail) > 0 {
+		k1 *= c1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= c2
+		h1 ^= k1
+	}
+	h1 ^= uint64(len(data))
+	h2 ^= uint64(len(data))
+	h1 += h2
+	h2 += h1
+	h1, h2 = murmur3Mix(h1), murmur3Mix(h2)
+	h1 += h2
+	h2 += h1
+	return h1, h2
+}
+
+// murmur3Mix is the fmix64 finalizer of murmur3.
+func murmur3Mix(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
+
+// ImportCassandra reads the bloom filter component of a Cassandra SSTable, its Filter.db file, in the given
+// format, so the filter of the SSTable can be queried for partition keys from Go. The items are the serialized
+// partition keys, and the filter uses a CassandraHasher and ExclusiveLock.
+func ImportCassandra(r io.Reader, format CassandraFormat) (*BloomFilter, error) {
+	if format > CassandraFormatLegacy {
+		return nil, fmt.Errorf("unknown cassandra format %d", format)
+	}
+	var header struct {
+		HashCount int32
+		Words     int32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.HashCount <= 0 || header.Words <= 0 || header.Words > math.MaxInt32/8 {
+		return nil, fmt.Errorf("%w: invalid parameters hash_count=%d, words=%d", ErrInvalidEncoding, header.HashCount, header.Words)
+	}
+	bitSet := make([]uint64, header.Words)
+	// Bit i is in byte i/8 of the bit set, so the raw bytes of the current format are little-endian words,
+	// while the older formats write each word as a big-endian long.
+	order := binary.ByteOrder(binary.LittleEndian)
+	if format != CassandraFormat40 {
+		order = binary.BigEndian
+	}
+	if err := binary.Read(r, order, bitSet); err != nil {
+		return nil, err
+	}
+	bf := &BloomFilter{}
+	if err := bf.restore(64*uint64(header.Words), uint64(header.HashCount), NewCassandraHasher(format == CassandraFormatLegacy), bitSet); err != nil {
+		return nil, err
+	}
+	return bf, nil
+}
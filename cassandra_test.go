@@ -0,0 +1,103 @@
+package gobloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/spaolacci/murmur3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassandraMurmur3(t *testing.T) {
+	t.Parallel()
+	// Cassandra's murmur3 only differs for tail bytes with the high bit set, which it sign-extends.
+	data := []byte("cassandra-partition-key-0123456789abcdef")
+	for n := 0; n <= len(data); n++ {
+		h1, h2 := cassandraMurmur3(data[:n])
+		w1, w2 := murmur3.Sum128(data[:n])
+		assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2}, "length %d", n)
+	}
+	block := bytes.Repeat([]byte{0xff}, 16)
+	h1, h2 := cassandraMurmur3(block)
+	w1, w2 := murmur3.Sum128(block)
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2}, "Whole blocks should not be sign-extended")
+	h1, h2 = cassandraMurmur3(block[:15])
+	w1, w2 = murmur3.Sum128(block[:15])
+	assert.NotEqual(t, [2]uint64{w1, w2}, [2]uint64{h1, h2}, "Tail bytes should be sign-extended")
+}
+
+func TestCassandraHasher_Positions(t *testing.T) {
+	t.Parallel()
+	const m = 64 * 1000
+	for _, legacy := range []bool{false, true} {
+		for i := 0; i < 100; i++ {
+			data := []byte("key-" + strconv.Itoa(i))
+			out := make([]uint64, 5)
+			NewCassandraHasher(legacy).HashK(data, out)
+			// Cassandra computes abs(base % max) with Java long arithmetic.
+			h1, h2 := cassandraMurmur3(data)
+			base, inc := int64(h2), int64(h1)
+			if legacy {
+				base, inc = int64(h1), int64(h2)
+			}
+			for j := range out {
+				want := base % m
+				if want < 0 {
+					want = -want
+				}
+				assert.Equal(t, uint64(want), out[j]%m)
+				base += inc
+			}
+		}
+	}
+}
+
+func TestImportCassandra(t *testing.T) {
+	t.Parallel()
+	formats := []CassandraFormat{CassandraFormat40, CassandraFormat30, CassandraFormatLegacy}
+	for _, format := range formats {
+		legacy := format == CassandraFormatLegacy
+		bf, err := newBloomFilter(64*20, 5, Params{Hasher: NewCassandraHasher(legacy), LockType: LockTypeExclusive})
+		assert.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, bf.Add([]byte("key-"+strconv.Itoa(i))))
+		}
+
+		var buf bytes.Buffer
+		order := binary.ByteOrder(binary.BigEndian)
+		if format == CassandraFormat40 {
+			order = binary.LittleEndian
+		}
+		_ = binary.Write(&buf, binary.BigEndian, [2]int32{5, 20})
+		_ = binary.Write(&buf, order, bf.Bits())
+
+		imported, err := ImportCassandra(bytes.NewReader(buf.Bytes()), format)
+		assert.NoError(t, err, "Failed to import cassandra filter")
+		assert.Equal(t, uint64(64*20), imported.M())
+		assert.Equal(t, uint64(5), imported.K())
+		assert.Equal(t, legacy, imported.hasher.(*CassandraHasher).Legacy())
+		assert.Equal(t, bf.Bits(), imported.Bits())
+		for i := 0; i < 100; i++ {
+			b, _ := imported.Test([]byte("key-" + strconv.Itoa(i)))
+			assert.True(t, b, "Imported filter should contain the added keys")
+		}
+
+		encoded, err := imported.MarshalBinary()
+		assert.NoError(t, err)
+		decoded := &BloomFilter{}
+		assert.NoError(t, decoded.UnmarshalBinary(encoded))
+		assert.True(t, sameHasher(imported.hasher, decoded.hasher), "The hash order should be serialized")
+	}
+}
+
+func TestImportCassandra_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := ImportCassandra(bytes.NewReader([]byte{0, 0, 0, 0, 0, 0, 0, 1}), CassandraFormat40)
+	assert.ErrorIs(t, err, ErrInvalidEncoding, "A zero hash count should be rejected")
+	_, err = ImportCassandra(bytes.NewReader([]byte{0, 0, 0, 1, 0, 0, 0, 1, 0}), CassandraFormat40)
+	assert.Error(t, err, "Truncated bits should be rejected")
+	_, err = ImportCassandra(bytes.NewReader(nil), CassandraFormatLegacy+1)
+	assert.Error(t, err, "Unknown formats should be rejected")
+}
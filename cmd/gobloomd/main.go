@@ -0,0 +1,29 @@
+// Command gobloomd runs the gobloom gRPC service, serving named Bloom filters created with CreateFilter.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+
+	"github.com/franciscoescher/gobloom/gobloompb"
+	"github.com/franciscoescher/gobloom/grpcserver"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	maxBits := flag.Uint64("max-bits", grpcserver.DefaultMaxBits, "maximum number of bits of a filter")
+	maxK := flag.Uint64("max-k", grpcserver.DefaultMaxHashFunctions, "maximum number of hash functions of a filter")
+	flag.Parse()
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	gobloompb.RegisterFilterServiceServer(srv, grpcserver.New(grpcserver.WithMaxBits(*maxBits), grpcserver.WithMaxHashFunctions(*maxK)))
+	log.Printf("listening on %s", *addr)
+	log.Fatal(srv.Serve(lis))
+}
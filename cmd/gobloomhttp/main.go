@@ -0,0 +1,32 @@
+// Command gobloomhttp runs the gobloom HTTP service, serving named scalable Bloom filters.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/franciscoescher/gobloom"
+	"github.com/franciscoescher/gobloom/httpserver"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	n := flag.Uint64("n", 100000, "initial number of items of each filter")
+	fpRate := flag.Float64("fp", 0.01, "false positive rate of each filter")
+	maxBits := flag.Uint64("max-bits", httpserver.DefaultMaxBits, "maximum number of bits of a restored filter")
+	maxK := flag.Uint64("max-k", httpserver.DefaultMaxHashFunctions, "maximum number of hash functions of a restored filter")
+	flag.Parse()
+
+	srv, err := httpserver.New(gobloom.ParamsScalable{
+		InitialSize:         *n,
+		FalsePositiveRate:   *fpRate,
+		FalsePositiveGrowth: 2,
+		LockType:            gobloom.LockTypeReadWrite,
+	}, httpserver.WithMaxBits(*maxBits), httpserver.WithMaxHashFunctions(*maxK))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, srv))
+}
@@ -0,0 +1,118 @@
+package gobloom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a declarative description of a filter, decoded by NewFromConfig, so filter settings can live
+// in the configuration of an application. Every field has a lower-case name, like false_positive_rate.
+type Config struct {
+	// Type is the type of the filter, "standard" for a BloomFilter, or "scalable" for a ScalableBloomFilter.
+	// Defaults to standard.
+	Type string `yaml:"type"`
+	// N is the number of elements expected to be added, the initial size of scalable filters.
+	N uint64 `yaml:"n"`
+	// FalsePositiveRate is the acceptable false positive rate.
+	FalsePositiveRate float64 `yaml:"false_positive_rate"`
+	// FalsePositiveGrowth is the growth of the false positive rate of each layer of scalable filters. Defaults to 2.
+	FalsePositiveGrowth float64 `yaml:"false_positive_growth"`
+	// MaxExpectedItems is the hint of the maximum number of items of scalable filters, see ParamsScalable.
+	MaxExpectedItems uint64 `yaml:"max_expected_items"`
+	// Hasher is the name of the hasher, like "murmur3" or "xxhash", or of a hasher registered with
+	// RegisterHasher. Defaults to murmur3.
+	Hasher string `yaml:"hasher"`
+	// LockType is the lock type, "none", "exclusive", "read_write", "atomic", "striped" or "auto". Defaults to exclusive.
+	LockType string `yaml:"lock_type"`
+	// Persistence, if set, saves standard filters to a file, see NewPersistent.
+	Persistence *PersistenceConfig `yaml:"persistence"`
+}
+
+// PersistenceConfig is the persistence of a filter described by a Config.
+type PersistenceConfig struct {
+	// Path is the file the filter is saved to.
+	Path string `yaml:"path"`
+	// FlushInterval is the interval between saves, like "30s".
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	// FlushEvery is the number of writes after which the filter is saved.
+	FlushEvery uint64 `yaml:"flush_every"`
+}
+
+// lockTypes are the lock types by their name in a Config.
+var lockTypes = map[string]LockType{
+	"":           LockTypeDefault,
+	"none":       LockTypeNone,
+	"exclusive":  LockTypeExclusive,
+	"read_write": LockTypeReadWrite,
+	"atomic":     LockTypeAtomic,
+	"striped":    LockTypeStriped,
+	"auto":       LockTypeAuto,
+}
+
+// NewFromConfig creates the filter described by a Config document, in JSON or YAML. It returns a *BloomFilter,
+// a *ScalableBloomFilter, or a *PersistentBloomFilter when persistence is set, which must be closed.
+// Unknown fields are rejected, so misspelled settings are not silently ignored.
+func NewFromConfig(r io.Reader) (Interface, error) {
+	dec := yaml.NewDecoder(r) // JSON documents are YAML documents too
+	dec.KnownFields(true)
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("empty config")
+		}
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return c.New()
+}
+
+// New creates the filter described by the configuration.
+func (c Config) New() (Interface, error) {
+	lockType, ok := lockTypes[c.LockType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidLockType, c.LockType)
+	}
+	var hasher Hasher
+	if c.Hasher != "" {
+		var err error
+		if hasher, err = unmarshalHasher(c.Hasher, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	switch c.Type {
+	case "", "standard":
+		if c.Persistence != nil {
+			return NewPersistent(ParamsPersistent{
+				Path:              c.Persistence.Path,
+				N:                 c.N,
+				FalsePositiveRate: c.FalsePositiveRate,
+				FlushInterval:     c.Persistence.FlushInterval,
+				FlushEvery:        c.Persistence.FlushEvery,
+				Hasher:            hasher,
+				LockType:          lockType,
+			})
+		}
+		return New(Params{N: c.N, FalsePositiveRate: c.FalsePositiveRate, Hasher: hasher, LockType: lockType})
+	case "scalable":
+		if c.Persistence != nil {
+			return nil, fmt.Errorf("persistence is not supported by scalable filters")
+		}
+		growth := c.FalsePositiveGrowth
+		if growth == 0 {
+			growth = 2
+		}
+		return NewScalable(ParamsScalable{
+			InitialSize:         c.N,
+			FalsePositiveRate:   c.FalsePositiveRate,
+			FalsePositiveGrowth: growth,
+			Hasher:              hasher,
+			LockType:            lockType,
+			MaxExpectedItems:    c.MaxExpectedItems,
+		})
+	}
+	return nil, fmt.Errorf("unknown filter type %q", c.Type)
+}
@@ -0,0 +1,67 @@
+package gobloom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromConfig_JSON(t *testing.T) {
+	t.Parallel()
+	f, err := NewFromConfig(strings.NewReader(`{"n": 1000, "false_positive_rate": 0.01, "hasher": "xxhash", "lock_type": "read_write"}`))
+	assert.NoError(t, err, "Failed to create filter from config")
+	bf, ok := f.(*BloomFilter)
+	assert.True(t, ok, "A standard filter should be created, got %T", f)
+	want, _ := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.Equal(t, want.M(), bf.M())
+	assert.Equal(t, NewXXHasher(), bf.hasher)
+	assert.IsType(t, &ReadWriteMutex{}, bf.mutex)
+}
+
+func TestNewFromConfig_YAML(t *testing.T) {
+	t.Parallel()
+	f, err := NewFromConfig(strings.NewReader(`
+type: scalable
+n: 100
+false_positive_rate: 0.001
+max_expected_items: 10000
+`))
+	assert.NoError(t, err, "Failed to create filter from config")
+	sbf, ok := f.(*ScalableBloomFilter)
+	assert.True(t, ok, "A scalable filter should be created, got %T", f)
+	assert.Equal(t, 2.0, sbf.fpGrowth, "The growth should default to 2")
+	assert.Equal(t, uint64(10000), sbf.maxItems)
+}
+
+func TestNewFromConfig_Persistence(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "filter.bloom")
+	config := "n: 1000\nfalse_positive_rate: 0.01\npersistence:\n  path: " + path + "\n  flush_interval: 1h\n"
+	f, err := NewFromConfig(strings.NewReader(config))
+	assert.NoError(t, err, "Failed to create filter from config")
+	pbf, ok := f.(*PersistentBloomFilter)
+	assert.True(t, ok, "A persistent filter should be created, got %T", f)
+	assert.NoError(t, pbf.Add([]byte("foo")))
+	assert.NoError(t, pbf.Close())
+	_, err = os.Stat(path)
+	assert.NoError(t, err, "The filter should be saved")
+}
+
+func TestNewFromConfig_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, config := range []string{
+		``,
+		`{"n": 1000, "false_positive_rate": 0.01, "fp": 0.1}`,
+		`{"n": 1000, "false_positive_rate": 0.01, "type": "cuckoo"}`,
+		`{"n": 1000, "false_positive_rate": 0.01, "hasher": "unknown"}`,
+		`{"n": 1000, "false_positive_rate": 0.01, "lock_type": "spin"}`,
+		`{"n": 1000, "false_positive_rate": 2}`,
+		`{"n": 1000, "false_positive_rate": 0.01, "type": "scalable", "persistence": {"path": "x"}}`,
+	} {
+		_, err := NewFromConfig(strings.NewReader(config))
+		assert.Error(t, err, "Config %q should be rejected", config)
+	}
+}
@@ -0,0 +1,199 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+var (
+	_ Clearer                 = (*CountMinSketch)(nil)
+	_ Merger[*CountMinSketch] = (*CountMinSketch)(nil)
+	_ Serializer              = (*CountMinSketch)(nil)
+)
+
+// CountMinSketch is a probabilistic data structure that estimates the frequency of items in a stream.
+// Estimates never underestimate the true frequency, and overestimate it by at most
+// Epsilon times the total count with probability 1 - Delta.
+type CountMinSketch struct {
+	width    uint64   // The number of counters per row
+	depth    uint64   // The number of rows, one per hash function
+	count    []uint64 // The counters, width consecutive entries per row
+	hasher   Hasher   // The hash provider the hash functions come from
+	hasher64 Hasher64 // The hasher computing the hashes of an item, one per row
+	mutex    Mutex    // Mutex to ensure thread safety
+}
+
+// ParamsCountMin represents the parameters for creating a new count-min sketch.
+type ParamsCountMin struct {
+	// Epsilon is the acceptable overestimation, as a fraction of the total count of all items.
+	// Smaller values increase the number of counters per row.
+	Epsilon float64
+	// Delta is the probability that an estimate exceeds the Epsilon error bound.
+	// Smaller values increase the number of rows.
+	Delta float64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewCountMinSketch creates a new count-min sketch.
+func NewCountMinSketch(p ParamsCountMin) (*CountMinSketch, error) {
+	applyDefaultsCountMin(&p)
+	if p.Epsilon <= 0 || p.Epsilon >= 1 {
+		return nil, fmt.Errorf("invalid epsilon, must be between 0 and 1, got %f", p.Epsilon)
+	}
+	if p.Delta <= 0 || p.Delta >= 1 {
+		return nil, fmt.Errorf("invalid delta, must be between 0 and 1, got %f", p.Delta)
+	}
+	width := uint64(math.Ceil(math.E / p.Epsilon))
+	depth := uint64(math.Ceil(math.Log(1 / p.Delta)))
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	return &CountMinSketch{
+		width:    width,
+		depth:    depth,
+		count:    make([]uint64, width*depth),
+		hasher:   p.Hasher,
+		hasher64: asHasher64(p.Hasher),
+		mutex:    mu,
+	}, nil
+}
+
+// applyDefaultsCountMin applies the default values to the parameters if they are not set.
+func applyDefaultsCountMin(p *ParamsCountMin) {
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	if p.LockType == LockTypeDefault {
+		p.LockType = LockTypeExclusive
+	}
+}
+
+// Add adds count occurrences of an item to the sketch. The counters saturate at math.MaxUint64.
+func (cms *CountMinSketch) Add(data []byte, count uint64) error {
+	if cms.mutex != nil {
+		cms.mutex.WLock()
+		defer cms.mutex.WUnlock()
+	}
+	probes := pooledLocations(cms.hasher64, data, cms.depth, cms.width)
+	defer probePool.Put(probes)
+	locs := *probes
+	for row, l := range locs {
+		i := uint64(row)*cms.width + l
+		cms.count[i] = satAdd(cms.count[i], count)
+	}
+	return nil
+}
+
+// Estimate returns the estimated number of occurrences of an item.
+func (cms *CountMinSketch) Estimate(data []byte) (uint64, error) {
+	if cms.mutex != nil {
+		cms.mutex.RLock()
+		defer cms.mutex.RUnlock()
+	}
+	probes := pooledLocations(cms.hasher64, data, cms.depth, cms.width)
+	defer probePool.Put(probes)
+	locs := *probes
+	estimate := uint64(math.MaxUint64)
+	for row, l := range locs {
+		if c := cms.count[uint64(row)*cms.width+l]; c < estimate {
+			estimate = c
+		}
+	}
+	return estimate, nil
+}
+
+// Merge adds the counts of other into the sketch, saturating at math.MaxUint64.
+// Both sketches must have the same dimensions and hasher. The counts of other are copied before locking
+// the sketch, so merges in both directions can run concurrently. Merging a sketch with itself doubles the counts.
+func (cms *CountMinSketch) Merge(other *CountMinSketch) error {
+	width, depth, hasher, counts := other.mergeSource()
+	if cms.mutex != nil {
+		cms.mutex.WLock()
+		defer cms.mutex.WUnlock()
+	}
+	if cms.width != width || cms.depth != depth {
+		return fmt.Errorf("incompatible sketch dimensions, %dx%d and %dx%d", cms.depth, cms.width, depth, width)
+	}
+	if !sameHasher(cms.hasher, hasher) {
+		return fmt.Errorf("incompatible sketches, hashers %T and %T differ", cms.hasher, hasher)
+	}
+	for i, c := range counts {
+		cms.count[i] = satAdd(cms.count[i], c)
+	}
+	return nil
+}
+
+// mergeSource returns the dimensions, the hasher and a copy of the counters of the sketch,
+// read under its lock, to be merged into another sketch.
+func (cms *CountMinSketch) mergeSource() (uint64, uint64, Hasher, []uint64) {
+	if cms.mutex != nil {
+		cms.mutex.RLock()
+		defer cms.mutex.RUnlock()
+	}
+	return cms.width, cms.depth, cms.hasher, append([]uint64(nil), cms.count...)
+}
+
+// MarshalBinary encodes the sketch with the wire format.
+// The parameter block holds the width, the depth, and the hasher. The payload is the counters.
+func (cms *CountMinSketch) MarshalBinary() ([]byte, error) {
+	if cms.mutex != nil {
+		cms.mutex.RLock()
+		defer cms.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, cms.width)
+	params = binary.AppendUvarint(params, cms.depth)
+	params, err := appendHasher(params, cms.hasher)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFilter(filterTypeCountMin, params, appendWords(nil, cms.count)), nil
+}
+
+// UnmarshalBinary restores a sketch encoded with MarshalBinary.
+// The lock type of the sketch is kept, it defaults to ExclusiveLock for a zero value sketch.
+func (cms *CountMinSketch) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeCountMin, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	width := r.uvarint()
+	depth := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	if width == 0 || depth == 0 {
+		return fmt.Errorf("%w: width and depth must be greater than 0", ErrInvalidEncoding)
+	}
+	if err := checkPayload(payload, 8, width, depth); err != nil {
+		return err
+	}
+	if cms.mutex == nil && cms.width == 0 {
+		cms.mutex = &ExclusiveMutex{}
+	}
+	if cms.mutex != nil {
+		cms.mutex.WLock()
+		defer cms.mutex.WUnlock()
+	}
+	cms.width = width
+	cms.depth = depth
+	cms.count = readWords(payload)
+	cms.hasher = hasher
+	cms.hasher64 = asHasher64(hasher)
+	return nil
+}
+
+// Clear removes all the items from the sketch, keeping its parameters.
+func (cms *CountMinSketch) Clear() {
+	if cms.mutex != nil {
+		cms.mutex.WLock()
+		defer cms.mutex.WUnlock()
+	}
+	clear(cms.count)
+}
@@ -0,0 +1,101 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountMinSketch_Estimate(t *testing.T) {
+	t.Parallel()
+	eps := 0.001
+	cms, err := NewCountMinSketch(ParamsCountMin{Epsilon: eps, Delta: 0.01})
+	assert.NoError(t, err, "Failed to create count-min sketch")
+
+	total := uint64(0)
+	for i := uint64(0); i < 1000; i++ {
+		assert.NoError(t, cms.Add([]byte(fmt.Sprintf("item-%d", i)), i))
+		total += i
+	}
+	for i := uint64(0); i < 1000; i++ {
+		c, err := cms.Estimate([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, c, i, "Estimate should never underestimate")
+		assert.LessOrEqual(t, float64(c), float64(i)+eps*float64(total), "Estimate exceeds the error bound")
+	}
+}
+
+func TestCountMinSketch_Merge(t *testing.T) {
+	t.Parallel()
+	a, err := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01})
+	assert.NoError(t, err)
+	b, err := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01})
+	assert.NoError(t, err)
+
+	assert.NoError(t, a.Add([]byte("foo"), 3))
+	assert.NoError(t, b.Add([]byte("foo"), 4))
+	assert.NoError(t, a.Merge(b))
+
+	c, err := a.Estimate([]byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), c)
+
+	d, err := NewCountMinSketch(ParamsCountMin{Epsilon: 0.1, Delta: 0.01})
+	assert.NoError(t, err)
+	assert.Error(t, a.Merge(d), "Sketches with different dimensions should not merge")
+	e, err := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01, Hasher: NewMurMur3HasherWithSeed(7)})
+	assert.NoError(t, err)
+	assert.Error(t, a.Merge(e), "Sketches with different hashers should not merge")
+
+	assert.NoError(t, a.Merge(a))
+	c, err = a.Estimate([]byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(14), c, "Merging a sketch with itself should double the counts")
+}
+
+func TestCountMinSketch_Saturate(t *testing.T) {
+	t.Parallel()
+	cms, err := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01})
+	assert.NoError(t, err)
+	assert.NoError(t, cms.Add([]byte("foo"), math.MaxUint64-1))
+	assert.NoError(t, cms.Add([]byte("foo"), 2))
+	c, err := cms.Estimate([]byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), c, "Counters should saturate instead of wrapping around")
+
+	assert.NoError(t, cms.Merge(cms))
+	c, err = cms.Estimate([]byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), c, "Merged counters should saturate instead of wrapping around")
+}
+
+func TestCountMinSketch_MergeCrossed(t *testing.T) {
+	t.Parallel()
+	a, _ := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01, LockType: LockTypeReadWrite})
+	b, _ := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01, LockType: LockTypeReadWrite})
+	// Merges in both directions used to hold the lock of one sketch while waiting for the other.
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, a.Merge(b))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, b.Merge(a))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewCountMinSketch_InvalidParams(t *testing.T) {
+	t.Parallel()
+	_, err := NewCountMinSketch(ParamsCountMin{Epsilon: 0, Delta: 0.01})
+	assert.Error(t, err)
+	_, err = NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,264 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var (
+	_ Interface  = (*CountingBloomFilter)(nil)
+	_ Remover    = (*CountingBloomFilter)(nil)
+	_ Clearer    = (*CountingBloomFilter)(nil)
+	_ Serializer = (*CountingBloomFilter)(nil)
+)
+
+// ErrNotFound is returned when removing an item that is not present in the filter.
+var ErrNotFound = errors.New("item not found")
+
+// CountingBloomFilter is a Bloom filter that uses small counters instead of bits,
+// which allows items to be removed.
+type CountingBloomFilter struct {
+	m        uint64   // The number of counters
+	counters []uint8  // The counters, one per position
+	k        uint64   // The number of hash functions to use
+	hasher   Hasher   // The hash provider the hash functions come from
+	hasher64 Hasher64 // The hasher computing the hashes of an item
+	mutex    Mutex    // Mutex to ensure thread safety
+
+	rand          *rand.Rand // Random source of the rounding of Decay
+	deterministic bool       // Whether rand is seeded with decayDeterministicSeed, see Params
+}
+
+// NewCounting creates a new counting Bloom filter with the given parameters.
+func NewCounting(p Params) (*CountingBloomFilter, error) {
+	applyDefaults(&p)
+	if err := validateParams(p); err != nil {
+		return nil, err
+	}
+	m, k := getOptimalParams(p.N, p.FalsePositiveRate)
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	return &CountingBloomFilter{
+		m:        m,
+		k:        k,
+		counters: make([]uint8, m),
+		hasher:   p.Hasher,
+		hasher64: asHasher64(p.Hasher),
+		mutex:    mu,
+
+		rand:          newDecayRand(p.Deterministic),
+		deterministic: p.Deterministic,
+	}, nil
+}
+
+// Add adds an item to the counting Bloom filter.
+// Counters saturate at their maximum value instead of overflowing.
+func (cbf *CountingBloomFilter) Add(data []byte) error {
+	if cbf.mutex != nil {
+		cbf.mutex.WLock()
+		defer cbf.mutex.WUnlock()
+	}
+	probes := pooledLocations(cbf.hasher64, data, cbf.k, cbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	for _, l := range locs {
+		if cbf.counters[l] < math.MaxUint8 {
+			cbf.counters[l]++
+		}
+	}
+	return nil
+}
+
+// Test checks if an item is in the counting Bloom filter.
+func (cbf *CountingBloomFilter) Test(data []byte) (bool, error) {
+	if cbf.mutex != nil {
+		cbf.mutex.RLock()
+		defer cbf.mutex.RUnlock()
+	}
+	probes := pooledLocations(cbf.hasher64, data, cbf.k, cbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	for _, l := range locs {
+		if cbf.counters[l] == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Remove removes an item from the counting Bloom filter.
+// It returns ErrNotFound if the item is definitely not in the filter, in which case
+// no counter is changed. Removing an item that was never added (but tests positive)
+// may introduce false negatives for other items.
+func (cbf *CountingBloomFilter) Remove(data []byte) error {
+	if cbf.mutex != nil {
+		cbf.mutex.WLock()
+		defer cbf.mutex.WUnlock()
+	}
+	probes := pooledLocations(cbf.hasher64, data, cbf.k, cbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	for _, l := range locs {
+		if cbf.counters[l] == 0 {
+			return ErrNotFound
+		}
+	}
+	for _, l := range locs {
+		// Saturated counters are never decremented, since the true count is unknown.
+		if cbf.counters[l] < math.MaxUint8 {
+			cbf.counters[l]--
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the counting Bloom filter with the wire format.
+// The parameter block holds m, k, and the hasher. The payload is one byte per counter.
+func (cbf *CountingBloomFilter) MarshalBinary() ([]byte, error) {
+	if cbf.mutex != nil {
+		cbf.mutex.RLock()
+		defer cbf.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, cbf.m)
+	params = binary.AppendUvarint(params, cbf.k)
+	params, err := appendHasher(params, cbf.hasher)
+	if err != nil {
+		return nil, err
+	}
+	params = appendDeterministic(params, cbf.deterministic)
+	return encodeFilter(filterTypeCounting, params, cbf.counters), nil
+}
+
+// UnmarshalBinary restores a counting Bloom filter encoded with MarshalBinary.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value filter.
+func (cbf *CountingBloomFilter) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeCounting, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	m := r.uvarint()
+	k := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	deterministic, err := r.deterministic()
+	if err != nil {
+		return err
+	}
+	if err := checkMK(m, k); err != nil {
+		return err
+	}
+	if err := checkPayload(payload, m); err != nil {
+		return err
+	}
+	if cbf.mutex == nil && cbf.m == 0 {
+		cbf.mutex = &ExclusiveMutex{}
+	}
+	if cbf.mutex != nil {
+		cbf.mutex.WLock()
+		defer cbf.mutex.WUnlock()
+	}
+	cbf.m = m
+	cbf.k = k
+	cbf.counters = append([]uint8(nil), payload...)
+	cbf.hasher = hasher
+	cbf.hasher64 = asHasher64(hasher)
+	// Deterministic filters are seeded again, so every decoded copy decays the same way.
+	if deterministic || cbf.rand == nil {
+		cbf.rand = newDecayRand(deterministic)
+	}
+	cbf.deterministic = deterministic
+	return nil
+}
+
+// Decay multiplies the counters by factor, between 0 and 1, so older additions weigh less than recent ones,
+// for exponentially decayed frequencies in streaming jobs. Counters are rounded up or down at random, in
+// proportion to their fractional part, so they decay by factor on average instead of dropping to zero,
+// and items are forgotten once all their counters reach zero. Saturated counters decay too.
+func (cbf *CountingBloomFilter) Decay(factor float64) error {
+	if err := validateDecay(factor); err != nil {
+		return err
+	}
+	if cbf.mutex != nil {
+		cbf.mutex.WLock()
+		defer cbf.mutex.WUnlock()
+	}
+	for i, c := range cbf.counters {
+		if c != 0 {
+			cbf.counters[i] = uint8(decayCounter(uint64(c), factor, cbf.rand))
+		}
+	}
+	return nil
+}
+
+// decayDeterministicSeed is the seed of the rounding of Decay in deterministic filters.
+const decayDeterministicSeed = 1
+
+// newDecayRand returns the random source of the rounding of Decay, seeded with decayDeterministicSeed
+// for deterministic filters, and with the time otherwise.
+func newDecayRand(deterministic bool) *rand.Rand {
+	if deterministic {
+		return rand.New(rand.NewSource(decayDeterministicSeed))
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// appendDeterministic appends the optional last parameter of encoded counting and spectral filters,
+// which is only written for deterministic filters, so the encodings of the others are unchanged.
+func appendDeterministic(params []byte, deterministic bool) []byte {
+	if !deterministic {
+		return params
+	}
+	return binary.AppendUvarint(params, 1)
+}
+
+// deterministic reads the parameter written by appendDeterministic.
+func (r *byteReader) deterministic() (bool, error) {
+	if len(r.data) == 0 {
+		return false, nil
+	}
+	switch v := r.uvarint(); {
+	case r.err != nil:
+		return false, r.err
+	case v > 1:
+		return false, fmt.Errorf("%w: invalid deterministic flag %d", ErrInvalidEncoding, v)
+	default:
+		return v == 1, nil
+	}
+}
+
+// validateDecay checks that a decay factor is between 0 and 1.
+func validateDecay(factor float64) error {
+	if !(factor >= 0 && factor <= 1) {
+		return fmt.Errorf("invalid decay factor, must be between 0 and 1, got %f", factor)
+	}
+	return nil
+}
+
+// decayCounter returns the counter c multiplied by factor, rounded up with a probability equal to the
+// fractional part of the product, so its expected value is c*factor.
+func decayCounter(c uint64, factor float64, r *rand.Rand) uint64 {
+	v := float64(c) * factor
+	n := math.Floor(v)
+	if r.Float64() < v-n {
+		n++
+	}
+	return uint64(n)
+}
+
+// Clear removes all the items from the counting Bloom filter, keeping its parameters.
+func (cbf *CountingBloomFilter) Clear() {
+	if cbf.mutex != nil {
+		cbf.mutex.WLock()
+		defer cbf.mutex.WUnlock()
+	}
+	clear(cbf.counters)
+}
@@ -0,0 +1,121 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountingBloomFilter_AddTestRemove(t *testing.T) {
+	t.Parallel()
+	cbf, err := NewCounting(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create counting Bloom filter")
+
+	item := []byte("test-item")
+	assert.NoError(t, cbf.Add(item))
+
+	b, err := cbf.Test(item)
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after Add")
+
+	assert.NoError(t, cbf.Remove(item))
+	b, err = cbf.Test(item)
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should not be present after Remove")
+
+	assert.ErrorIs(t, cbf.Remove(item), ErrNotFound)
+}
+
+func TestCountingBloomFilter_RemoveKeepsOthers(t *testing.T) {
+	t.Parallel()
+	n := uint64(1000)
+	cbf, err := NewCounting(Params{N: n, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create counting Bloom filter")
+
+	for i := uint64(0); i < n; i++ {
+		assert.NoError(t, cbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	for i := uint64(0); i < n; i += 2 {
+		assert.NoError(t, cbf.Remove([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	for i := uint64(1); i < n; i += 2 {
+		b, err := cbf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should still be present", i)
+	}
+}
+
+func TestCountingBloomFilter_Saturation(t *testing.T) {
+	t.Parallel()
+	cbf, err := NewCounting(Params{N: 10, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create counting Bloom filter")
+
+	item := []byte("hot-item")
+	for i := 0; i < 300; i++ {
+		assert.NoError(t, cbf.Add(item))
+	}
+	for i := 0; i < 300; i++ {
+		assert.NoError(t, cbf.Remove(item))
+	}
+	b, err := cbf.Test(item)
+	assert.NoError(t, err)
+	assert.True(t, b, "Saturated counters should never be decremented")
+}
+
+func TestCountingBloomFilter_Decay(t *testing.T) {
+	t.Parallel()
+	cbf, err := NewCounting(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, cbf.Add([]byte(fmt.Sprint(i))))
+	}
+	var before int
+	for _, c := range cbf.counters {
+		before += int(c)
+	}
+	assert.NoError(t, cbf.Decay(0.5))
+	var after int
+	for _, c := range cbf.counters {
+		after += int(c)
+	}
+	assert.InDelta(t, float64(before)/2, after, float64(before)/20, "Counters should halve on average")
+
+	// Repeated decay forgets every item.
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, cbf.Decay(0.5))
+	}
+	for i := 0; i < 1000; i++ {
+		b, _ := cbf.Test([]byte(fmt.Sprint(i)))
+		assert.False(t, b, "Item %d should have decayed", i)
+	}
+
+	assert.Error(t, cbf.Decay(1.5))
+	assert.Error(t, cbf.Decay(-0.1))
+}
+
+func TestCountingBloomFilter_DecayDeterministic(t *testing.T) {
+	t.Parallel()
+	build := func() *CountingBloomFilter {
+		cbf, err := NewCounting(Params{N: 1000, FalsePositiveRate: 0.01, Deterministic: true})
+		assert.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			assert.NoError(t, cbf.Add([]byte(fmt.Sprint(i))))
+		}
+		return cbf
+	}
+	a, b := build(), build()
+	assert.NoError(t, a.Decay(0.5))
+	assert.NoError(t, b.Decay(0.5))
+	assert.Equal(t, a.counters, b.counters, "Deterministic filters should decay identically")
+
+	// Decoded copies decay identically too.
+	data, err := a.MarshalBinary()
+	assert.NoError(t, err)
+	var c, d CountingBloomFilter
+	assert.NoError(t, c.UnmarshalBinary(data))
+	assert.NoError(t, d.UnmarshalBinary(data))
+	assert.NoError(t, c.Decay(0.5))
+	assert.NoError(t, d.Decay(0.5))
+	assert.Equal(t, c.counters, d.counters, "Decoded deterministic filters should decay identically")
+}
@@ -0,0 +1,508 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+var (
+	_ Interface                       = (*CountingQuotientFilter)(nil)
+	_ Remover                         = (*CountingQuotientFilter)(nil)
+	_ Clearer                         = (*CountingQuotientFilter)(nil)
+	_ Merger[*CountingQuotientFilter] = (*CountingQuotientFilter)(nil)
+	_ Serializer                      = (*CountingQuotientFilter)(nil)
+)
+
+// CountingQuotientFilter is a counting quotient filter (CQF), a quotient filter that counts how many times
+// each fingerprint was added. Counts are stored in the slots following the remainder of the fingerprint,
+// as digits of r bits, so items added once take a single slot, and counts only take as many slots as they
+// need, which suits skewed multisets like k-mer counts of genomes or the items of a stream.
+//
+// Each slot holds a tag bit besides the remainder, telling remainders apart from count digits, so a slot
+// holds r+1 bits. Like QuotientFilter, the table grows when it gets too full, doubling the false positive rate.
+type CountingQuotientFilter struct {
+	table    QuotientFilter // The slots, holding tagged remainders and count digits, see tagRemainder and tagDigit
+	distinct uint64         // The number of distinct fingerprints stored
+	total    uint64         // The sum of the counts, saturating at math.MaxUint64
+	mutex    Mutex          // Mutex to ensure thread safety
+}
+
+// Slot values of a counting quotient filter, the remainder or the count digit shifted left by the tag bit.
+func tagRemainder(rem uint64) uint64 { return rem << 1 << qfMetaBits }
+func tagDigit(digit uint64) uint64   { return (digit<<1 | 1) << qfMetaBits }
+func isDigit(slot uint64) bool       { return slot>>qfMetaBits&1 == 1 }
+
+// NewCountingQuotient creates a new counting quotient filter with the given number of distinct elements (n)
+// and false positive rate (p). The table grows automatically when it gets too full, see NewQuotient.
+func NewCountingQuotient(p Params) (*CountingQuotientFilter, error) {
+	applyDefaults(&p)
+	if err := validateParams(p); err != nil {
+		return nil, err
+	}
+	q := max(uint64(math.Ceil(math.Log2(float64(p.N)/qfMaxLoad))), 1)
+	r := max(uint64(math.Ceil(math.Log2(1/p.FalsePositiveRate))), 1)
+	if q+r+1 > 64-qfMetaBits {
+		return nil, fmt.Errorf("counting quotient filter too large, fingerprint needs %d bits", q+r)
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	return &CountingQuotientFilter{
+		table: QuotientFilter{
+			q:        q,
+			r:        r + 1,
+			slots:    make([]uint64, 1<<q),
+			hasher:   p.Hasher,
+			hasher64: asHasher64(p.Hasher),
+		},
+		mutex: mu,
+	}, nil
+}
+
+// remainderBits returns the number of bits of the remainders, and of the count digits.
+func (cqf *CountingQuotientFilter) remainderBits() uint64 {
+	return cqf.table.r - 1
+}
+
+// fingerprint returns the q+r bit fingerprint of the data.
+func (cqf *CountingQuotientFilter) fingerprint(data []byte) uint64 {
+	hashes := getProbes(1)
+	defer probePool.Put(hashes)
+	cqf.table.hasher64.HashK(data, *hashes)
+	return (*hashes)[0] & (uint64(1)<<(cqf.table.q+cqf.remainderBits()) - 1)
+}
+
+// split splits a fingerprint into its quotient and remainder.
+func (cqf *CountingQuotientFilter) split(fp uint64) (uint64, uint64) {
+	r := cqf.remainderBits()
+	return fp >> r, fp & (uint64(1)<<r - 1)
+}
+
+// digitsFor returns the number of count digits needed to store the count, which is stored minus one,
+// so items added once have no digits.
+func (cqf *CountingQuotientFilter) digitsFor(count uint64) uint64 {
+	r := cqf.remainderBits()
+	return (uint64(bits.Len64(count-1)) + r - 1) / r
+}
+
+// find returns the slot of the remainder in the run of the quotient, or the slot it would be inserted at
+// if it is absent. The quotient must be occupied.
+func (cqf *CountingQuotientFilter) find(fq, fr uint64) (uint64, bool) {
+	t := &cqf.table
+	s := t.findRunIndex(fq)
+	for {
+		if rem := t.slots[s] >> qfMetaBits >> 1; rem == fr {
+			return s, true
+		} else if rem > fr {
+			return s, false
+		}
+		// Skip the remainder and its count digits.
+		for {
+			s = t.incr(s)
+			if t.slots[s]&qfContinuation == 0 {
+				return s, false // The end of the run
+			}
+			if !isDigit(t.slots[s]) {
+				break
+			}
+		}
+	}
+}
+
+// counter returns the count of the remainder at slot s, and its number of count digits.
+func (cqf *CountingQuotientFilter) counter(s uint64) (uint64, uint64) {
+	t := &cqf.table
+	r := cqf.remainderBits()
+	var v, digits uint64
+	for {
+		s = t.incr(s)
+		if t.slots[s]&qfContinuation == 0 || !isDigit(t.slots[s]) {
+			return v + 1, digits
+		}
+		v |= (t.slots[s] >> qfMetaBits >> 1) << (r * digits)
+		digits++
+	}
+}
+
+// setCount sets the count of the remainder at slot s, of the run of the quotient fq, which has the given
+// number of count digits, inserting or removing digit slots as needed.
+func (cqf *CountingQuotientFilter) setCount(s, fq, digits, count uint64) {
+	t := &cqf.table
+	r := cqf.remainderBits()
+	want := cqf.digitsFor(count)
+	v := count - 1
+	pos := s
+	for i := uint64(0); i < want; i++ {
+		pos = t.incr(pos)
+		slot := tagDigit(v & (uint64(1)<<r - 1))
+		if i < digits {
+			t.slots[pos] = slot | t.slots[pos]&qfMetaMask
+		} else {
+			t.insertAt(pos, slot|qfContinuation|qfShifted)
+			t.entries++
+		}
+		v >>= r
+	}
+	// Extra digits are removed from the last, so the remaining ones don't move.
+	for i := digits; i > want; i-- {
+		t.removeAt((s+i)&(uint64(len(t.slots))-1), fq)
+		t.entries--
+	}
+}
+
+// insertNew inserts a remainder that is not stored, at the slot s returned by find if its quotient is occupied.
+func (cqf *CountingQuotientFilter) insertNew(fq, fr, s, count uint64) {
+	t := &cqf.table
+	entry := tagRemainder(fr)
+	tfq := t.slots[fq]
+	switch {
+	case qfIsEmpty(tfq):
+		t.slots[fq] = entry | qfOccupied
+		s = fq
+	case tfq&qfOccupied == 0:
+		// The run is new, it starts where the run of the next quotient would.
+		t.slots[fq] |= qfOccupied
+		s = t.findRunIndex(fq)
+		if s != fq {
+			entry |= qfShifted
+		}
+		t.insertAt(s, entry)
+	default:
+		if start := t.findRunIndex(fq); s == start {
+			// The new remainder becomes the head of the run, the old head becomes a continuation.
+			t.slots[start] |= qfContinuation
+		} else {
+			entry |= qfContinuation
+		}
+		if s != fq {
+			entry |= qfShifted
+		}
+		t.insertAt(s, entry)
+	}
+	t.entries++
+	cqf.distinct++
+	cqf.setCount(s, fq, 0, count)
+}
+
+// full reports whether using more slots would exceed the maximum load factor.
+func (cqf *CountingQuotientFilter) full(more uint64) bool {
+	return float64(cqf.table.entries+more) > qfMaxLoad*float64(len(cqf.table.slots))
+}
+
+// add adds count to the count of the fingerprint, growing the table if needed.
+func (cqf *CountingQuotientFilter) add(fp, count uint64) error {
+	for {
+		fq, fr := cqf.split(fp)
+		s, found := uint64(0), false
+		if cqf.table.slots[fq]&qfOccupied != 0 {
+			s, found = cqf.find(fq, fr)
+		}
+		var current, digits uint64
+		more := 1 + cqf.digitsFor(count)
+		if found {
+			current, digits = cqf.counter(s)
+			more = cqf.digitsFor(satAdd(current, count)) - digits
+		}
+		if more > 0 && cqf.full(more) {
+			if err := cqf.grow(); err != nil {
+				return err
+			}
+			continue
+		}
+		if found {
+			cqf.setCount(s, fq, digits, satAdd(current, count))
+			cqf.total = satAdd(cqf.total, satAdd(current, count)-current)
+		} else {
+			cqf.insertNew(fq, fr, s, count)
+			cqf.total = satAdd(cqf.total, count)
+		}
+		return nil
+	}
+}
+
+// satAdd returns a+b, saturating at math.MaxUint64.
+func satAdd(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
+
+// counts returns the stored fingerprints and their counts.
+func (cqf *CountingQuotientFilter) counts() ([]uint64, []uint64) {
+	t := &cqf.table
+	r := cqf.remainderBits()
+	fps := make([]uint64, 0, cqf.distinct)
+	counts := make([]uint64, 0, cqf.distinct)
+	var digits uint64
+	// Remainders are followed by their count digits, in slot order.
+	for _, slot := range t.fingerprints() {
+		quot, value := slot>>t.r, slot&(uint64(1)<<t.r-1)
+		if value&1 == 0 {
+			fps = append(fps, quot<<r|value>>1)
+			counts = append(counts, 1)
+			digits = 0
+			continue
+		}
+		counts[len(counts)-1] += (value >> 1) << (r * digits)
+		digits++
+	}
+	return fps, counts
+}
+
+// grow doubles the number of slots, moving one remainder bit into the quotient.
+// The caller must hold the lock.
+func (cqf *CountingQuotientFilter) grow() error {
+	if cqf.remainderBits() <= 1 {
+		return ErrFilterFull
+	}
+	fps, counts := cqf.counts()
+	t := &cqf.table
+	t.q++
+	t.r--
+	t.slots = make([]uint64, 1<<t.q)
+	t.entries = 0
+	cqf.distinct = 0
+	for i, fp := range fps {
+		fq, fr := cqf.split(fp)
+		s := uint64(0)
+		if t.slots[fq]&qfOccupied != 0 {
+			s, _ = cqf.find(fq, fr)
+		}
+		cqf.insertNew(fq, fr, s, counts[i])
+	}
+	return nil
+}
+
+// Add adds an item to the counting quotient filter, incrementing its count, and growing the table if needed.
+// It returns ErrFilterFull if the table cannot grow any further.
+func (cqf *CountingQuotientFilter) Add(data []byte) error {
+	return cqf.AddN(data, 1)
+}
+
+// AddN adds an item n times to the counting quotient filter. Counts saturate at math.MaxUint64.
+func (cqf *CountingQuotientFilter) AddN(data []byte, n uint64) error {
+	if n == 0 {
+		return nil
+	}
+	if cqf.mutex != nil {
+		cqf.mutex.WLock()
+		defer cqf.mutex.WUnlock()
+	}
+	return cqf.add(cqf.fingerprint(data), n)
+}
+
+// Test checks if an item is in the counting quotient filter.
+func (cqf *CountingQuotientFilter) Test(data []byte) (bool, error) {
+	c, err := cqf.Count(data)
+	return c > 0, err
+}
+
+// Count returns the number of times an item was added to the counting quotient filter, minus the number
+// of times it was deleted. It overestimates the count of an item whose fingerprint collides with another's.
+func (cqf *CountingQuotientFilter) Count(data []byte) (uint64, error) {
+	if cqf.mutex != nil {
+		cqf.mutex.RLock()
+		defer cqf.mutex.RUnlock()
+	}
+	fq, fr := cqf.split(cqf.fingerprint(data))
+	if cqf.table.slots[fq]&qfOccupied == 0 {
+		return 0, nil
+	}
+	s, found := cqf.find(fq, fr)
+	if !found {
+		return 0, nil
+	}
+	count, _ := cqf.counter(s)
+	return count, nil
+}
+
+// Delete decrements the count of an item in the counting quotient filter, removing it once it reaches zero.
+// It returns ErrNotFound if the item is not in the filter. Deleting an item that was never added
+// (but tests positive) decrements the count of a different item sharing the same fingerprint.
+func (cqf *CountingQuotientFilter) Delete(data []byte) error {
+	if cqf.mutex != nil {
+		cqf.mutex.WLock()
+		defer cqf.mutex.WUnlock()
+	}
+	fq, fr := cqf.split(cqf.fingerprint(data))
+	if cqf.table.slots[fq]&qfOccupied == 0 {
+		return ErrNotFound
+	}
+	s, found := cqf.find(fq, fr)
+	if !found {
+		return ErrNotFound
+	}
+	count, digits := cqf.counter(s)
+	if count > 1 {
+		cqf.setCount(s, fq, digits, count-1)
+	} else {
+		cqf.table.removeAt(s, fq)
+		cqf.table.entries--
+		cqf.distinct--
+	}
+	if cqf.total != math.MaxUint64 {
+		cqf.total--
+	}
+	return nil
+}
+
+// Remove is the same as Delete, so the counting quotient filter implements Remover.
+func (cqf *CountingQuotientFilter) Remove(data []byte) error {
+	return cqf.Delete(data)
+}
+
+// Distinct returns the number of distinct fingerprints stored in the counting quotient filter.
+func (cqf *CountingQuotientFilter) Distinct() uint64 {
+	if cqf.mutex != nil {
+		cqf.mutex.RLock()
+		defer cqf.mutex.RUnlock()
+	}
+	return cqf.distinct
+}
+
+// Total returns the sum of the counts of the items, saturating at math.MaxUint64.
+func (cqf *CountingQuotientFilter) Total() uint64 {
+	if cqf.mutex != nil {
+		cqf.mutex.RLock()
+		defer cqf.mutex.RUnlock()
+	}
+	return cqf.total
+}
+
+// Resize doubles the number of slots of the counting quotient filter, moving one remainder bit into the
+// quotient. This doubles the capacity and the false positive rate. It returns ErrFilterFull if there are
+// no remainder bits left to move.
+func (cqf *CountingQuotientFilter) Resize() error {
+	if cqf.mutex != nil {
+		cqf.mutex.WLock()
+		defer cqf.mutex.WUnlock()
+	}
+	return cqf.grow()
+}
+
+// Merge adds all items of other into the counting quotient filter, adding their counts, and growing it if
+// needed. Both filters must use the same hash functions and fingerprint size (quotient plus remainder bits).
+// The counts of other are copied before locking the filter, so merges in both directions can run
+// concurrently. Merging a filter with itself doubles the counts.
+func (cqf *CountingQuotientFilter) Merge(other *CountingQuotientFilter) error {
+	bits, hasher, fps, counts := other.mergeSource()
+	if cqf.mutex != nil {
+		cqf.mutex.WLock()
+		defer cqf.mutex.WUnlock()
+	}
+	if cqf.table.q+cqf.remainderBits() != bits {
+		return fmt.Errorf("incompatible fingerprint sizes, %d and %d bits", cqf.table.q+cqf.remainderBits(), bits)
+	}
+	if !sameHasher(cqf.table.hasher, hasher) {
+		return fmt.Errorf("incompatible filters, hashers %T and %T differ", cqf.table.hasher, hasher)
+	}
+	return cqf.addCounts(fps, counts)
+}
+
+// mergeSource returns the fingerprint size, the hasher, and the fingerprints and counts of the counting
+// quotient filter, read under its lock, to be merged into another filter.
+func (cqf *CountingQuotientFilter) mergeSource() (uint64, Hasher, []uint64, []uint64) {
+	if cqf.mutex != nil {
+		cqf.mutex.RLock()
+		defer cqf.mutex.RUnlock()
+	}
+	fps, counts := cqf.counts()
+	return cqf.table.q + cqf.remainderBits(), cqf.table.hasher, fps, counts
+}
+
+// addCounts adds the counts of the fingerprints.
+func (cqf *CountingQuotientFilter) addCounts(fps, counts []uint64) error {
+	for i, fp := range fps {
+		if err := cqf.add(fp, counts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the counting quotient filter with the wire format.
+// The parameter block holds the quotient bits, the remainder bits, the number of used slots,
+// the number of distinct fingerprints, the total count, and the hasher. The payload is the slots.
+func (cqf *CountingQuotientFilter) MarshalBinary() ([]byte, error) {
+	if cqf.mutex != nil {
+		cqf.mutex.RLock()
+		defer cqf.mutex.RUnlock()
+	}
+	t := &cqf.table
+	params := binary.AppendUvarint(nil, t.q)
+	params = binary.AppendUvarint(params, cqf.remainderBits())
+	params = binary.AppendUvarint(params, t.entries)
+	params = binary.AppendUvarint(params, cqf.distinct)
+	params = binary.AppendUvarint(params, cqf.total)
+	params, err := appendHasher(params, t.hasher)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFilter(filterTypeCountingQuotient, params, appendWords(nil, t.slots)), nil
+}
+
+// UnmarshalBinary restores a counting quotient filter encoded with MarshalBinary.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value filter.
+func (cqf *CountingQuotientFilter) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeCountingQuotient, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	q := r.uvarint()
+	rem := r.uvarint()
+	entries := r.uvarint()
+	distinct := r.uvarint()
+	total := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	if q == 0 || rem == 0 || q+rem+1 > 64-qfMetaBits || entries > uint64(1)<<q || distinct > entries {
+		return fmt.Errorf("%w: invalid parameters", ErrInvalidEncoding)
+	}
+	if err := checkPayload(payload, 8, uint64(1)<<q); err != nil {
+		return err
+	}
+	table := QuotientFilter{
+		q:        q,
+		r:        rem + 1,
+		slots:    readWords(payload),
+		entries:  entries,
+		hasher:   hasher,
+		hasher64: asHasher64(hasher),
+	}
+	if used, err := table.checkSlots(); err != nil {
+		return err
+	} else if used != entries {
+		return fmt.Errorf("%w: %d slots in use, expected %d", ErrInvalidEncoding, used, entries)
+	}
+	if cqf.mutex == nil && cqf.table.slots == nil {
+		cqf.mutex = &ExclusiveMutex{}
+	}
+	if cqf.mutex != nil {
+		cqf.mutex.WLock()
+		defer cqf.mutex.WUnlock()
+	}
+	cqf.table = table
+	cqf.distinct = distinct
+	cqf.total = total
+	return nil
+}
+
+// Clear removes all the items from the counting quotient filter, keeping its parameters.
+func (cqf *CountingQuotientFilter) Clear() {
+	if cqf.mutex != nil {
+		cqf.mutex.WLock()
+		defer cqf.mutex.WUnlock()
+	}
+	clear(cqf.table.slots)
+	cqf.table.entries = 0
+	cqf.distinct = 0
+	cqf.total = 0
+}
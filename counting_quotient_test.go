@@ -0,0 +1,196 @@
+package gobloom
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkCounts asserts the counts of the counting quotient filter match the expected counts.
+func checkCounts(t *testing.T, cqf *CountingQuotientFilter, want map[string]uint64) {
+	t.Helper()
+	var total uint64
+	for item, n := range want {
+		c, err := cqf.Count([]byte(item))
+		assert.NoError(t, err)
+		if c != n {
+			t.Fatalf("Count of '%s' is %d, expected %d", item, c, n)
+		}
+		total += n
+	}
+	assert.Equal(t, total, cqf.Total())
+}
+
+func TestCountingQuotientFilter_AddCountDelete(t *testing.T) {
+	t.Parallel()
+	cqf, err := NewCountingQuotient(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create counting quotient filter")
+
+	item := []byte("test-item")
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, cqf.Add(item))
+	}
+	assert.NoError(t, cqf.AddN(item, 1000))
+	c, err := cqf.Count(item)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1003), c)
+	assert.Equal(t, uint64(1), cqf.Distinct())
+	b, _ := cqf.Test(item)
+	assert.True(t, b, "Item should be present after Add")
+
+	for i := 0; i < 1003; i++ {
+		assert.NoError(t, cqf.Delete(item))
+	}
+	b, _ = cqf.Test(item)
+	assert.False(t, b, "Item should not be present once its count reaches zero")
+	assert.Equal(t, uint64(0), cqf.Distinct())
+	assert.Equal(t, uint64(0), cqf.table.entries, "Count digits should be removed with the item")
+	assert.ErrorIs(t, cqf.Delete(item), ErrNotFound)
+}
+
+func TestCountingQuotientFilter_Random(t *testing.T) {
+	t.Parallel()
+	// Small remainders need several digits per count, and the small filter grows as items are added.
+	for _, p := range []Params{{N: 4096, FalsePositiveRate: 0.25}, {N: 64, FalsePositiveRate: 0.0001}} {
+		cqf, err := NewCountingQuotient(p)
+		assert.NoError(t, err)
+		q := cqf.table.q
+		rnd := rand.New(rand.NewSource(1))
+		want := make(map[string]uint64)
+		for op := 0; op < 20000; op++ {
+			item := "item-" + strconv.Itoa(rnd.Intn(200))
+			switch rnd.Intn(4) {
+			case 0:
+				if want[item] > 0 {
+					assert.NoError(t, cqf.Delete([]byte(item)))
+					if want[item]--; want[item] == 0 {
+						delete(want, item)
+					}
+				}
+			case 1:
+				n := uint64(rnd.Intn(5000))
+				assert.NoError(t, cqf.AddN([]byte(item), n))
+				if n > 0 {
+					want[item] += n
+				}
+			default:
+				assert.NoError(t, cqf.Add([]byte(item)))
+				want[item]++
+			}
+		}
+		if p.FalsePositiveRate < 0.001 {
+			// Fingerprints of 200 items don't collide, so the counts are exact.
+			checkCounts(t, cqf, want)
+			assert.Equal(t, uint64(len(want)), cqf.Distinct())
+			assert.Greater(t, cqf.table.q, q, "The filter should have grown")
+		} else {
+			for item, n := range want {
+				c, _ := cqf.Count([]byte(item))
+				assert.GreaterOrEqual(t, c, n, "Counts should never be underestimated")
+			}
+		}
+	}
+}
+
+func TestCountingQuotientFilter_ResizeMerge(t *testing.T) {
+	t.Parallel()
+	a, _ := NewCountingQuotient(Params{N: 100, FalsePositiveRate: 0.0001})
+	b, _ := NewCountingQuotient(Params{N: 100, FalsePositiveRate: 0.0001})
+	want := make(map[string]uint64)
+	for i := 0; i < 50; i++ {
+		item := strconv.Itoa(i)
+		assert.NoError(t, a.AddN([]byte(item), uint64(i+1)))
+		assert.NoError(t, b.AddN([]byte(item), 100))
+		want[item] = uint64(i + 101)
+	}
+	assert.NoError(t, b.Add([]byte("only-b")))
+	want["only-b"] = 1
+
+	assert.NoError(t, a.Resize())
+	assert.NoError(t, a.Merge(b))
+	checkCounts(t, a, want)
+
+	assert.NoError(t, a.Merge(a))
+	for item := range want {
+		want[item] *= 2
+	}
+	checkCounts(t, a, want)
+
+	other, _ := NewCountingQuotient(Params{N: 100, FalsePositiveRate: 0.1})
+	assert.Error(t, a.Merge(other), "Filters with different fingerprint sizes should not merge")
+	other, _ = NewCountingQuotient(Params{N: 100, FalsePositiveRate: 0.0001, Hasher: NewMurMur3HasherWithSeed(7)})
+	assert.Error(t, a.Merge(other), "Filters with different hashers should not merge")
+}
+
+func TestCountingQuotientFilter_MergeCrossed(t *testing.T) {
+	t.Parallel()
+	a, _ := NewCountingQuotient(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	b, _ := NewCountingQuotient(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	assert.NoError(t, a.Add([]byte("a")))
+	assert.NoError(t, b.Add([]byte("b")))
+	// Merges in both directions used to hold the lock of one filter while waiting for the other.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, a.Merge(b))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, b.Merge(a))
+		}()
+	}
+	wg.Wait()
+	for _, cqf := range []*CountingQuotientFilter{a, b} {
+		for _, item := range []string{"a", "b"} {
+			n, _ := cqf.Count([]byte(item))
+			assert.Greater(t, n, uint64(0))
+		}
+	}
+}
+
+func TestCountingQuotientFilter_MarshalBinary(t *testing.T) {
+	t.Parallel()
+	cqf, _ := NewCountingQuotient(Params{N: 100, FalsePositiveRate: 0.001})
+	want := map[string]uint64{"a": 1, "b": 7, "c": 123456}
+	for item, n := range want {
+		assert.NoError(t, cqf.AddN([]byte(item), n))
+	}
+	data, err := cqf.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded CountingQuotientFilter
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	checkCounts(t, &decoded, want)
+	assert.Equal(t, uint64(3), decoded.Distinct())
+
+	var qf QuotientFilter
+	assert.ErrorIs(t, qf.UnmarshalBinary(data), ErrInvalidEncoding)
+
+	// Slots without an empty one would make the scans of the clusters loop forever.
+	full := &CountingQuotientFilter{table: cqf.table, distinct: cqf.distinct, total: cqf.total}
+	full.table.slots = make([]uint64, len(cqf.table.slots))
+	for i := range full.table.slots {
+		full.table.slots[i] = qfShifted | qfContinuation
+	}
+	data, err = full.MarshalBinary()
+	assert.NoError(t, err)
+	assert.ErrorIs(t, new(CountingQuotientFilter).UnmarshalBinary(data), ErrInvalidEncoding)
+
+	decoded.Clear()
+	b, _ := decoded.Test([]byte("b"))
+	assert.False(t, b, "Cleared filter should be empty")
+	assert.Equal(t, uint64(0), decoded.Total())
+}
+
+func TestNewCountingQuotient_InvalidParams(t *testing.T) {
+	t.Parallel()
+	_, err := NewCountingQuotient(Params{FalsePositiveRate: 0.01})
+	assert.Error(t, err)
+	_, err = NewCountingQuotient(Params{N: 10, FalsePositiveRate: 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,443 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+var (
+	_ Interface  = (*CuckooFilter)(nil)
+	_ Remover    = (*CuckooFilter)(nil)
+	_ Clearer    = (*CuckooFilter)(nil)
+	_ Counter    = (*CuckooFilter)(nil)
+	_ Serializer = (*CuckooFilter)(nil)
+)
+
+// ErrFilterFull is returned when an item cannot be added because the filter has no room left.
+var ErrFilterFull = errors.New("filter is full")
+
+const (
+	defaultCuckooBucketSize      = 4
+	defaultCuckooFingerprintBits = 16
+	defaultCuckooMaxKicks        = 500
+	cuckooLoadFactor             = 0.95
+)
+
+// CuckooFilter is a cuckoo filter, which stores a short fingerprint of each item in one
+// of two candidate buckets. Compared to a Bloom filter it supports deletion and is more
+// space efficient for low false positive rates.
+type CuckooFilter struct {
+	buckets         []uint32   // Fingerprints, bucketSize consecutive entries per bucket, 0 means empty
+	packed          []uint64   // The encoded buckets of semi-sorted filters, which have no buckets
+	semiSorted      bool       // Whether the buckets are semi-sorted, see ParamsCuckoo
+	numBuckets      uint64     // The number of buckets, always a power of two
+	bucketSize      uint64     // The number of fingerprints per bucket
+	fingerprintBits uint64     // The number of bits per fingerprint
+	maxKicks        int        // The maximum number of relocations of an Add
+	count           uint64     // The number of items stored
+	hasher          Hasher     // The hash provider the hash function comes from
+	hasher64        Hasher64   // The hasher computing the hash of an item
+	rand            *rand.Rand // Random source used to choose victims on relocation
+	deterministic   bool       // Whether rand is seeded with cuckooDeterministicSeed, see ParamsCuckoo
+	mutex           Mutex      // Mutex to ensure thread safety
+}
+
+// ParamsCuckoo represents the parameters for creating a new cuckoo filter.
+type ParamsCuckoo struct {
+	// N is the number of elements expected to be added to the cuckoo filter.
+	N uint64
+	// BucketSize is the number of fingerprints per bucket. Defaults to 4.
+	// Larger buckets allow higher load factors, but increase the false positive rate.
+	BucketSize uint64
+	// FingerprintBits is the number of bits per fingerprint, between 1 and 32. Defaults to 16.
+	// Each additional bit halves the false positive rate.
+	FingerprintBits uint64
+	// MaxKicks is the maximum number of fingerprints relocated to make room for an item before Add
+	// returns ErrFilterFull. Defaults to 500. More relocations let the filter fill up further before
+	// inserts fail, at the cost of slower inserts once it is nearly full.
+	MaxKicks int
+	// SemiSorted sorts the fingerprints of each bucket, and encodes their 4 most significant bits together,
+	// saving one bit per fingerprint for the same false positive rate, at the cost of slower operations.
+	// It needs a BucketSize of 4 and FingerprintBits of at least 4.
+	SemiSorted bool
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+	// Deterministic seeds the choice of the fingerprints relocated when buckets are full with a fixed seed,
+	// instead of the time, so adding the same items in the same order gives bit-identical filters.
+	Deterministic bool
+}
+
+// cuckooDeterministicSeed is the seed of the relocation choices of deterministic cuckoo filters.
+const cuckooDeterministicSeed = 1
+
+// The flags of the optional last parameter of encoded cuckoo filters.
+const (
+	cuckooFlagSemiSorted    = 1 << 0
+	cuckooFlagDeterministic = 1 << 1
+)
+
+// NewCuckoo creates a new cuckoo filter.
+func NewCuckoo(p ParamsCuckoo) (*CuckooFilter, error) {
+	applyDefaultsCuckoo(&p)
+	if p.N == 0 {
+		return nil, fmt.Errorf("number of elements cannot be 0")
+	}
+	if p.FingerprintBits == 0 || p.FingerprintBits > 32 {
+		return nil, fmt.Errorf("invalid fingerprint bits, must be between 1 and 32, got %d", p.FingerprintBits)
+	}
+	if p.MaxKicks < 0 {
+		return nil, fmt.Errorf("invalid max kicks, must not be negative, got %d", p.MaxKicks)
+	}
+	if p.SemiSorted && (p.BucketSize != cuckooSemiSortedBucketSize || p.FingerprintBits < cuckooSemiSortedPrefixBits) {
+		return nil, fmt.Errorf("semi-sorted buckets need a bucket size of %d and at least %d fingerprint bits",
+			cuckooSemiSortedBucketSize, cuckooSemiSortedPrefixBits)
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	numBuckets := nextPowerOfTwo(uint64(float64(p.N)/float64(p.BucketSize)/cuckooLoadFactor) + 1)
+	seed := time.Now().UnixNano()
+	if p.Deterministic {
+		seed = cuckooDeterministicSeed
+	}
+	cf := &CuckooFilter{
+		semiSorted:      p.SemiSorted,
+		numBuckets:      numBuckets,
+		bucketSize:      p.BucketSize,
+		fingerprintBits: p.FingerprintBits,
+		maxKicks:        p.MaxKicks,
+		hasher:          p.Hasher,
+		hasher64:        asHasher64(p.Hasher),
+		rand:            rand.New(rand.NewSource(seed)),
+		deterministic:   p.Deterministic,
+		mutex:           mu,
+	}
+	cf.allocate()
+	return cf, nil
+}
+
+// allocate allocates the empty buckets of the filter.
+func (cf *CuckooFilter) allocate() {
+	if cf.semiSorted {
+		cf.packed = make([]uint64, (cf.numBuckets*cf.bucketBits()+63)/64)
+	} else {
+		cf.buckets = make([]uint32, cf.numBuckets*cf.bucketSize)
+	}
+}
+
+// applyDefaultsCuckoo applies the default values to the parameters if they are not set.
+func applyDefaultsCuckoo(p *ParamsCuckoo) {
+	if p.BucketSize == 0 {
+		p.BucketSize = defaultCuckooBucketSize
+	}
+	if p.FingerprintBits == 0 {
+		p.FingerprintBits = defaultCuckooFingerprintBits
+	}
+	if p.MaxKicks == 0 {
+		p.MaxKicks = defaultCuckooMaxKicks
+	}
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	if p.LockType == LockTypeDefault {
+		p.LockType = LockTypeExclusive
+	}
+}
+
+// nextPowerOfTwo returns the smallest power of two greater than or equal to n.
+func nextPowerOfTwo(n uint64) uint64 {
+	r := uint64(1)
+	for r < n {
+		r <<= 1
+	}
+	return r
+}
+
+// indexAndFingerprint returns the primary bucket index and the fingerprint of the data.
+func (cf *CuckooFilter) indexAndFingerprint(data []byte) (uint64, uint32, error) {
+	hashes := getProbes(1)
+	defer probePool.Put(hashes)
+	cf.hasher64.HashK(data, *hashes)
+	h := (*hashes)[0]
+	// The fingerprint uses the upper bits and the index the lower bits, so they are independent.
+	fp := uint32(h>>32) & uint32((uint64(1)<<cf.fingerprintBits)-1)
+	if fp == 0 {
+		fp = 1 // 0 is reserved for empty slots
+	}
+	return h & (cf.numBuckets - 1), fp, nil
+}
+
+// altIndex returns the alternate bucket index for the given index and fingerprint.
+func (cf *CuckooFilter) altIndex(i uint64, fp uint32) uint64 {
+	return (i ^ (uint64(fp) * 0x5bd1e995)) & (cf.numBuckets - 1)
+}
+
+// insert stores the fingerprint in the given bucket, returning false if it is full.
+func (cf *CuckooFilter) insert(i uint64, fp uint32) bool {
+	return cf.replace(i, 0, fp)
+}
+
+// contains reports whether the given bucket holds the fingerprint.
+func (cf *CuckooFilter) contains(i uint64, fp uint32) bool {
+	var buf [cuckooSemiSortedBucketSize]uint32
+	for _, f := range cf.load(i, buf[:]) {
+		if f == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// delete removes one copy of the fingerprint from the given bucket, returning false if absent.
+func (cf *CuckooFilter) delete(i uint64, fp uint32) bool {
+	return cf.replace(i, fp, 0)
+}
+
+// replace replaces one copy of the fingerprint from with to in the given bucket, returning false if absent.
+func (cf *CuckooFilter) replace(i uint64, from, to uint32) bool {
+	var buf [cuckooSemiSortedBucketSize]uint32
+	bucket := cf.load(i, buf[:])
+	for j := range bucket {
+		if bucket[j] == from {
+			bucket[j] = to
+			cf.save(i, bucket)
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds an item to the cuckoo filter.
+// It returns ErrFilterFull if no room could be made for the item.
+func (cf *CuckooFilter) Add(data []byte) error {
+	if cf.mutex != nil {
+		cf.mutex.WLock()
+		defer cf.mutex.WUnlock()
+	}
+	i1, fp, err := cf.indexAndFingerprint(data)
+	if err != nil {
+		return err
+	}
+	i2 := cf.altIndex(i1, fp)
+	if cf.insert(i1, fp) || cf.insert(i2, fp) {
+		cf.count++
+		return nil
+	}
+
+	// Both buckets are full, relocate existing fingerprints to make room.
+	// Relocations are recorded so they can be undone if no room is found,
+	// otherwise an unrelated fingerprint would be lost.
+	type kick struct {
+		index   uint64
+		in, out uint32 // The fingerprint stored in the bucket, and the one it evicted
+	}
+	kicks := make([]kick, 0, cf.maxKicks)
+	i := i1
+	if cf.rand.Intn(2) == 0 {
+		i = i2
+	}
+	var buf [cuckooSemiSortedBucketSize]uint32
+	for n := 0; n < cf.maxKicks; n++ {
+		bucket := cf.load(i, buf[:])
+		slot := cf.rand.Intn(int(cf.bucketSize))
+		kicks = append(kicks, kick{index: i, in: fp, out: bucket[slot]})
+		fp, bucket[slot] = bucket[slot], fp
+		cf.save(i, bucket)
+		i = cf.altIndex(i, fp)
+		if cf.insert(i, fp) {
+			cf.count++
+			return nil
+		}
+	}
+	for n := len(kicks) - 1; n >= 0; n-- {
+		cf.replace(kicks[n].index, kicks[n].in, kicks[n].out)
+	}
+	return ErrFilterFull
+}
+
+// Test checks if an item is in the cuckoo filter.
+func (cf *CuckooFilter) Test(data []byte) (bool, error) {
+	if cf.mutex != nil {
+		cf.mutex.RLock()
+		defer cf.mutex.RUnlock()
+	}
+	i1, fp, err := cf.indexAndFingerprint(data)
+	if err != nil {
+		return false, err
+	}
+	return cf.contains(i1, fp) || cf.contains(cf.altIndex(i1, fp), fp), nil
+}
+
+// Delete removes an item from the cuckoo filter.
+// It returns ErrNotFound if the item is not in the filter. Deleting an item that was never
+// added (but tests positive) may remove a different item sharing the same fingerprint.
+func (cf *CuckooFilter) Delete(data []byte) error {
+	if cf.mutex != nil {
+		cf.mutex.WLock()
+		defer cf.mutex.WUnlock()
+	}
+	i1, fp, err := cf.indexAndFingerprint(data)
+	if err != nil {
+		return err
+	}
+	if cf.delete(i1, fp) || cf.delete(cf.altIndex(i1, fp), fp) {
+		cf.count--
+		return nil
+	}
+	return ErrNotFound
+}
+
+// Count returns the number of items stored in the cuckoo filter.
+func (cf *CuckooFilter) Count() uint64 {
+	if cf.mutex != nil {
+		cf.mutex.RLock()
+		defer cf.mutex.RUnlock()
+	}
+	return cf.count
+}
+
+// MarshalBinary encodes the cuckoo filter with the wire format.
+// The parameter block holds the number of buckets, the bucket size, the fingerprint bits,
+// the number of items, and the hasher, followed by 1 for semi-sorted filters.
+// The payload is the fingerprints.
+func (cf *CuckooFilter) MarshalBinary() ([]byte, error) {
+	if cf.mutex != nil {
+		cf.mutex.RLock()
+		defer cf.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, cf.numBuckets)
+	params = binary.AppendUvarint(params, cf.bucketSize)
+	params = binary.AppendUvarint(params, cf.fingerprintBits)
+	params = binary.AppendUvarint(params, cf.count)
+	params, err := appendHasher(params, cf.hasher)
+	if err != nil {
+		return nil, err
+	}
+	var flags uint64
+	if cf.semiSorted {
+		flags |= cuckooFlagSemiSorted
+	}
+	if cf.deterministic {
+		flags |= cuckooFlagDeterministic
+	}
+	if flags != 0 {
+		params = binary.AppendUvarint(params, flags)
+	}
+	payload := make([]byte, 0, 4*cf.numBuckets*cf.bucketSize)
+	var buf [cuckooSemiSortedBucketSize]uint32
+	for i := uint64(0); i < cf.numBuckets; i++ {
+		for _, fp := range cf.load(i, buf[:]) {
+			payload = binary.LittleEndian.AppendUint32(payload, fp)
+		}
+	}
+	return encodeFilter(filterTypeCuckoo, params, payload), nil
+}
+
+// UnmarshalBinary restores a cuckoo filter encoded with MarshalBinary.
+// The lock type and the max kicks of the filter are kept, they default to ExclusiveLock
+// and 500 for a zero value filter.
+func (cf *CuckooFilter) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeCuckoo, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	numBuckets := r.uvarint()
+	bucketSize := r.uvarint()
+	fingerprintBits := r.uvarint()
+	count := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	var flags uint64
+	if len(r.data) > 0 {
+		flags = r.uvarint()
+	}
+	semiSorted := flags&cuckooFlagSemiSorted != 0
+	deterministic := flags&cuckooFlagDeterministic != 0
+	if r.err != nil {
+		return r.err
+	}
+	if numBuckets == 0 || numBuckets&(numBuckets-1) != 0 || bucketSize == 0 || fingerprintBits == 0 || fingerprintBits > 32 ||
+		flags&^(cuckooFlagSemiSorted|cuckooFlagDeterministic) != 0 ||
+		semiSorted && (bucketSize != cuckooSemiSortedBucketSize || fingerprintBits < cuckooSemiSortedPrefixBits) {
+		return fmt.Errorf("%w: invalid parameters", ErrInvalidEncoding)
+	}
+	if err := checkPayload(payload, 4, numBuckets, bucketSize); err != nil {
+		return err
+	}
+	decoded := &CuckooFilter{semiSorted: semiSorted, numBuckets: numBuckets, bucketSize: bucketSize, fingerprintBits: fingerprintBits}
+	decoded.allocate()
+	bucket := make([]uint32, bucketSize)
+	var occupied uint64
+	for i := uint64(0); i < numBuckets; i++ {
+		for j := range bucket {
+			bucket[j] = binary.LittleEndian.Uint32(payload[4*(i*bucketSize+uint64(j)):])
+			if uint64(bucket[j]) >= 1<<fingerprintBits {
+				return fmt.Errorf("%w: fingerprint %d has more than %d bits", ErrInvalidEncoding, bucket[j], fingerprintBits)
+			}
+			if bucket[j] != 0 {
+				occupied++
+			}
+		}
+		if semiSorted {
+			decoded.save(i, bucket)
+		} else {
+			copy(decoded.load(i, nil), bucket)
+		}
+	}
+	// Each item takes a slot, so Count and Len would be wrong for a count other than the number of occupied slots.
+	if count != occupied {
+		return fmt.Errorf("%w: count is %d, but %d slots are occupied", ErrInvalidEncoding, count, occupied)
+	}
+	if cf.mutex == nil && cf.numBuckets == 0 {
+		cf.mutex = &ExclusiveMutex{}
+	}
+	if cf.mutex != nil {
+		cf.mutex.WLock()
+		defer cf.mutex.WUnlock()
+	}
+	cf.buckets = decoded.buckets
+	cf.packed = decoded.packed
+	cf.semiSorted = semiSorted
+	cf.numBuckets = numBuckets
+	cf.bucketSize = bucketSize
+	cf.fingerprintBits = fingerprintBits
+	cf.count = count
+	cf.hasher = hasher
+	cf.hasher64 = asHasher64(hasher)
+	if cf.maxKicks == 0 {
+		cf.maxKicks = defaultCuckooMaxKicks
+	}
+	// Deterministic filters are seeded again, so every decoded copy makes the same relocation choices.
+	if deterministic {
+		cf.rand = rand.New(rand.NewSource(cuckooDeterministicSeed))
+	} else if cf.rand == nil {
+		cf.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	cf.deterministic = deterministic
+	return nil
+}
+
+// Clear removes all the items from the cuckoo filter, keeping its parameters.
+func (cf *CuckooFilter) Clear() {
+	if cf.mutex != nil {
+		cf.mutex.WLock()
+		defer cf.mutex.WUnlock()
+	}
+	clear(cf.buckets)
+	clear(cf.packed)
+	cf.count = 0
+}
+
+// Remove is the same as Delete, so the cuckoo filter implements Remover.
+func (cf *CuckooFilter) Remove(data []byte) error {
+	return cf.Delete(data)
+}
@@ -0,0 +1,122 @@
+package gobloom
+
+import "sync"
+
+// Semi-sorted cuckoo filters store the fingerprints of a bucket in ascending order, so the 4 most significant
+// bits of the fingerprints, their prefixes, form a non-decreasing sequence. There are only 3876 such sequences
+// of four prefixes, so the prefixes of a bucket are stored as the 12-bit index of their sequence instead of
+// 16 bits, saving one bit per fingerprint.
+const (
+	cuckooSemiSortedBucketSize = 4  // The bucket size of semi-sorted filters
+	cuckooSemiSortedPrefixBits = 4  // The bits of a fingerprint stored in the index of the sequence
+	cuckooSemiSortedIndexBits  = 12 // The bits of the index of the sequence of prefixes of a bucket
+	cuckooSemiSortedSequences  = 3876
+)
+
+// semiSortedTables maps the sequences of prefixes, four nibbles from the most significant, to their index and back.
+type semiSortedTables struct {
+	index     [1 << 16]uint16
+	sequences [cuckooSemiSortedSequences]uint16
+}
+
+var (
+	semiSortedOnce  sync.Once
+	semiSortedTable *semiSortedTables
+)
+
+// semiSorted returns the tables of the sequences of prefixes, computing them on the first call.
+func semiSorted() *semiSortedTables {
+	semiSortedOnce.Do(func() {
+		t := &semiSortedTables{}
+		n := uint16(0)
+		for a := uint16(0); a < 16; a++ {
+			for b := a; b < 16; b++ {
+				for c := b; c < 16; c++ {
+					for d := c; d < 16; d++ {
+						seq := a<<12 | b<<8 | c<<4 | d
+						t.index[seq] = n
+						t.sequences[n] = seq
+						n++
+					}
+				}
+			}
+		}
+		semiSortedTable = t
+	})
+	return semiSortedTable
+}
+
+// bucketBits returns the size in bits of a bucket of a semi-sorted filter.
+func (cf *CuckooFilter) bucketBits() uint64 {
+	return cuckooSemiSortedIndexBits + cuckooSemiSortedBucketSize*(cf.fingerprintBits-cuckooSemiSortedPrefixBits)
+}
+
+// load returns the fingerprints of the bucket. Buckets of semi-sorted filters are decoded into buf, which must
+// hold a bucket, and must be stored back with save once modified; the others are returned in place.
+func (cf *CuckooFilter) load(i uint64, buf []uint32) []uint32 {
+	if !cf.semiSorted {
+		return cf.buckets[i*cf.bucketSize : (i+1)*cf.bucketSize]
+	}
+	low := cf.fingerprintBits - cuckooSemiSortedPrefixBits
+	off := i * cf.bucketBits()
+	seq := semiSorted().sequences[packedBits(cf.packed, off, cuckooSemiSortedIndexBits)]
+	off += cuckooSemiSortedIndexBits
+	bucket := buf[:cuckooSemiSortedBucketSize]
+	for j := range bucket {
+		prefix := uint32(seq>>(12-4*j)) & 0xf
+		bucket[j] = prefix<<low | uint32(packedBits(cf.packed, off, low))
+		off += low
+	}
+	return bucket
+}
+
+// save stores a bucket returned by load, sorting and encoding the fingerprints of semi-sorted filters.
+func (cf *CuckooFilter) save(i uint64, bucket []uint32) {
+	if !cf.semiSorted {
+		return
+	}
+	for j := 1; j < len(bucket); j++ {
+		for l := j; l > 0 && bucket[l] < bucket[l-1]; l-- {
+			bucket[l], bucket[l-1] = bucket[l-1], bucket[l]
+		}
+	}
+	low := cf.fingerprintBits - cuckooSemiSortedPrefixBits
+	var seq uint16
+	for j, fp := range bucket {
+		seq |= uint16(fp>>low) << (12 - 4*j)
+	}
+	off := i * cf.bucketBits()
+	setPackedBits(cf.packed, off, cuckooSemiSortedIndexBits, uint64(semiSorted().index[seq]))
+	off += cuckooSemiSortedIndexBits
+	for _, fp := range bucket {
+		setPackedBits(cf.packed, off, low, uint64(fp))
+		off += low
+	}
+}
+
+// packedBits returns the n bits, at most 64, at the offset of the bit array.
+func packedBits(words []uint64, off, n uint64) uint64 {
+	if n == 0 {
+		return 0
+	}
+	w, s := off/64, off%64
+	v := words[w] >> s
+	if s+n > 64 {
+		v |= words[w+1] << (64 - s)
+	}
+	return v & (1<<n - 1)
+}
+
+// setPackedBits sets the n bits, at most 64, at the offset of the bit array to the low bits of v.
+func setPackedBits(words []uint64, off, n, v uint64) {
+	if n == 0 {
+		return
+	}
+	mask := uint64(1)<<n - 1
+	v &= mask
+	w, s := off/64, off%64
+	words[w] = words[w]&^(mask<<s) | v<<s
+	if s+n > 64 {
+		words[w+1] = words[w+1]&^(mask>>(64-s)) | v>>(64-s)
+	}
+}
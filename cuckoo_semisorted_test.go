@@ -0,0 +1,97 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCuckooFilter_SemiSorted(t *testing.T) {
+	t.Parallel()
+	n := uint64(10000)
+	cf, err := NewCuckoo(ParamsCuckoo{N: n, FingerprintBits: 13, SemiSorted: true})
+	assert.NoError(t, err, "Failed to create semi-sorted cuckoo filter")
+	plain, _ := NewCuckoo(ParamsCuckoo{N: n, FingerprintBits: 13})
+	// Each bucket saves one bit per fingerprint, 4 of 4*13 bits.
+	assert.Less(t, 8*len(cf.packed), 4*len(plain.buckets))
+
+	for i := uint64(0); i < n; i++ {
+		assert.NoError(t, cf.Add([]byte("item-"+strconv.FormatUint(i, 10))))
+	}
+	assert.Equal(t, n, cf.Count())
+	for i := uint64(0); i < n; i++ {
+		b, err := cf.Test([]byte("item-" + strconv.FormatUint(i, 10)))
+		assert.NoError(t, err)
+		if !b {
+			t.Fatalf("Item 'item-%d' should be present", i)
+		}
+	}
+	var fp int
+	for i := uint64(0); i < n; i++ {
+		if b, _ := cf.Test([]byte("other-" + strconv.FormatUint(i, 10))); b {
+			fp++
+		}
+	}
+	assert.LessOrEqual(t, float64(fp)/float64(n), 2.0*4/(1<<13))
+
+	data, err := cf.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded CuckooFilter
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, decoded.semiSorted, "Decoded filter should be semi-sorted")
+	assert.Equal(t, cf.packed, decoded.packed)
+	assert.Equal(t, cf.Count(), decoded.Count())
+
+	cf.count--
+	corrupt, err := cf.MarshalBinary()
+	assert.NoError(t, err)
+	cf.count++
+	assert.ErrorIs(t, decoded.UnmarshalBinary(corrupt), ErrInvalidEncoding, "A count other than the number of occupied slots should be rejected")
+
+	for i := uint64(0); i < n; i++ {
+		assert.NoError(t, cf.Delete([]byte("item-"+strconv.FormatUint(i, 10))))
+	}
+	assert.Equal(t, uint64(0), cf.Count())
+	assert.Equal(t, make([]uint64, len(cf.packed)), cf.packed, "Deleting every item should empty the buckets")
+}
+
+func TestCuckooFilter_SemiSortedFull(t *testing.T) {
+	t.Parallel()
+	cf, err := NewCuckoo(ParamsCuckoo{N: 8, FingerprintBits: 8, SemiSorted: true})
+	assert.NoError(t, err)
+	var added []string
+	var fullErr error
+	for i := 0; i < 1000 && fullErr == nil; i++ {
+		item := strconv.Itoa(i)
+		if fullErr = cf.Add([]byte(item)); fullErr == nil {
+			added = append(added, item)
+		}
+	}
+	assert.ErrorIs(t, fullErr, ErrFilterFull)
+	for _, item := range added {
+		b, _ := cf.Test([]byte(item))
+		assert.True(t, b, "Item '%s' should still be present", item)
+	}
+}
+
+func TestSemiSortedTables(t *testing.T) {
+	t.Parallel()
+	tables := semiSorted()
+	assert.Equal(t, uint16(0), tables.sequences[0], "Empty buckets should be encoded as zeros")
+	for i, seq := range tables.sequences {
+		assert.Equal(t, uint16(i), tables.index[seq])
+	}
+}
+
+func TestPackedBits(t *testing.T) {
+	t.Parallel()
+	words := make([]uint64, 3)
+	setPackedBits(words, 60, 12, 0xabc)
+	setPackedBits(words, 72, 64, 0x0123456789abcdef)
+	assert.Equal(t, uint64(0xabc), packedBits(words, 60, 12))
+	assert.Equal(t, uint64(0x0123456789abcdef), packedBits(words, 72, 64))
+	setPackedBits(words, 60, 12, 0)
+	assert.Equal(t, uint64(0), packedBits(words, 60, 12))
+	assert.Equal(t, uint64(0x0123456789abcdef), packedBits(words, 72, 64), "Setting bits should keep the neighboring bits")
+}
@@ -0,0 +1,187 @@
+package gobloom
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCuckooFilter_AddTestDelete(t *testing.T) {
+	t.Parallel()
+	cf, err := NewCuckoo(ParamsCuckoo{N: 1000})
+	assert.NoError(t, err, "Failed to create cuckoo filter")
+
+	item := []byte("test-item")
+	assert.NoError(t, cf.Add(item))
+
+	b, err := cf.Test(item)
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after Add")
+	assert.Equal(t, uint64(1), cf.Count())
+
+	assert.NoError(t, cf.Delete(item))
+	b, err = cf.Test(item)
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should not be present after Delete")
+	assert.Equal(t, uint64(0), cf.Count())
+
+	assert.ErrorIs(t, cf.Delete(item), ErrNotFound)
+}
+
+func TestCuckooFilter_FalsePositiveRate(t *testing.T) {
+	t.Parallel()
+	n := uint64(100000)
+	cf, err := NewCuckoo(ParamsCuckoo{N: n, BucketSize: 4, FingerprintBits: 12})
+	assert.NoError(t, err, "Failed to create cuckoo filter")
+
+	for i := uint64(0); i < n; i++ {
+		assert.NoError(t, cf.Add([]byte(fmt.Sprintf("test-item-%d", i))))
+	}
+	for i := uint64(0); i < n; i++ {
+		b, err := cf.Test([]byte(fmt.Sprintf("test-item-%d", i)))
+		assert.NoError(t, err)
+		if !b {
+			t.Fatalf("Item 'test-item-%d' should be present", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := uint64(0); i < n; i++ {
+		b, _ := cf.Test([]byte(fmt.Sprintf("different-item-%d", i)))
+		if b {
+			falsePositives++
+		}
+	}
+	// The upper bound for the false positive rate is 2*b/2^f.
+	expected := 2.0 * 4 / (1 << 12)
+	assert.LessOrEqual(t, float64(falsePositives)/float64(n), expected)
+}
+
+func TestCuckooFilter_Full(t *testing.T) {
+	t.Parallel()
+	cf, err := NewCuckoo(ParamsCuckoo{N: 8, BucketSize: 2})
+	assert.NoError(t, err, "Failed to create cuckoo filter")
+
+	var added []string
+	var fullErr error
+	for i := 0; i < 1000 && fullErr == nil; i++ {
+		item := fmt.Sprintf("item-%d", i)
+		if fullErr = cf.Add([]byte(item)); fullErr == nil {
+			added = append(added, item)
+		}
+	}
+	assert.ErrorIs(t, fullErr, ErrFilterFull)
+
+	// A failed insertion must not evict previously added items.
+	for _, item := range added {
+		b, err := cf.Test([]byte(item))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item '%s' should still be present", item)
+	}
+}
+
+func TestNewCuckoo_InvalidParams(t *testing.T) {
+	t.Parallel()
+	_, err := NewCuckoo(ParamsCuckoo{})
+	assert.Error(t, err)
+	_, err = NewCuckoo(ParamsCuckoo{N: 10, FingerprintBits: 33})
+	assert.Error(t, err)
+	_, err = NewCuckoo(ParamsCuckoo{N: 10, MaxKicks: -1})
+	assert.Error(t, err)
+	_, err = NewCuckoo(ParamsCuckoo{N: 10, BucketSize: 2, SemiSorted: true})
+	assert.Error(t, err, "Semi-sorted buckets should need a bucket size of 4")
+	_, err = NewCuckoo(ParamsCuckoo{N: 10, FingerprintBits: 3, SemiSorted: true})
+	assert.Error(t, err, "Semi-sorted buckets should need at least 4 fingerprint bits")
+}
+
+func TestCuckooFilter_MaxKicks(t *testing.T) {
+	t.Parallel()
+	fill := func(maxKicks int) uint64 {
+		cf, err := NewCuckoo(ParamsCuckoo{N: 1000, MaxKicks: maxKicks, Deterministic: true})
+		assert.NoError(t, err, "Failed to create cuckoo filter")
+		for i := 0; i < 10000; i++ {
+			if cf.Add([]byte(strconv.Itoa(i))) != nil {
+				break
+			}
+		}
+		return cf.Count()
+	}
+	assert.Less(t, fill(1), fill(500), "More relocations should let the filter fill up further")
+}
+
+func TestCuckooFilter_MarshalFingerprintBits32(t *testing.T) {
+	t.Parallel()
+	cf, _ := NewCuckoo(ParamsCuckoo{N: 100, FingerprintBits: 32})
+	assert.NoError(t, cf.Add([]byte("item")))
+	data, err := cf.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded CuckooFilter
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	b, _ := decoded.Test([]byte("item"))
+	assert.True(t, b)
+}
+
+func TestCuckooFilter_Deterministic(t *testing.T) {
+	t.Parallel()
+	build := func() []byte {
+		cf, err := NewCuckoo(ParamsCuckoo{N: 1000, BucketSize: 2, Deterministic: true})
+		assert.NoError(t, err, "Failed to create cuckoo filter")
+		// The filter is filled until relocations happen, which choose random victims.
+		for i := 0; i < 1000; i++ {
+			if err := cf.Add([]byte(strconv.Itoa(i))); err != nil {
+				break
+			}
+		}
+		data, err := cf.MarshalBinary()
+		assert.NoError(t, err)
+		return data
+	}
+	assert.Equal(t, build(), build(), "Deterministic filters should be bit-identical")
+}
+
+func TestCuckooFilter_DeterministicDecoded(t *testing.T) {
+	t.Parallel()
+	cf, err := NewCuckoo(ParamsCuckoo{N: 1000, BucketSize: 2, Deterministic: true})
+	assert.NoError(t, err, "Failed to create cuckoo filter")
+	for i := 0; i < 500; i++ {
+		assert.NoError(t, cf.Add([]byte(strconv.Itoa(i))))
+	}
+	data, err := cf.MarshalBinary()
+	assert.NoError(t, err)
+
+	// Decoded copies keep relocating the same victims.
+	fill := func() []byte {
+		var decoded CuckooFilter
+		assert.NoError(t, decoded.UnmarshalBinary(data))
+		for i := 500; i < 1000; i++ {
+			if err := decoded.Add([]byte(strconv.Itoa(i))); err != nil {
+				break
+			}
+		}
+		filled, err := decoded.MarshalBinary()
+		assert.NoError(t, err)
+		return filled
+	}
+	assert.Equal(t, fill(), fill(), "Decoded deterministic filters should stay bit-identical")
+}
+
+func TestCuckooFilter_UnmarshalBinaryCount(t *testing.T) {
+	t.Parallel()
+	cf, err := NewCuckoo(ParamsCuckoo{N: 100})
+	assert.NoError(t, err, "Failed to create cuckoo filter")
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, cf.Add([]byte(strconv.Itoa(i))))
+	}
+	data, err := cf.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded CuckooFilter
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, uint64(10), decoded.Count())
+
+	cf.count++
+	data, err = cf.MarshalBinary()
+	assert.NoError(t, err)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(data), ErrInvalidEncoding, "A count other than the number of occupied slots should be rejected")
+}
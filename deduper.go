@@ -0,0 +1,67 @@
+package gobloom
+
+import "sync"
+
+// Deduper reports whether keys, like message IDs consumed from a queue, were seen before, so
+// redelivered messages can be skipped. A key is tested and added in one atomic step, so concurrent
+// consumers of the same key never both see it as new. False positives make a few new keys be
+// reported as seen, so it suits workloads tolerating the loss of a tiny fraction of messages.
+type Deduper struct {
+	filter   Interface
+	mu       sync.Mutex           // Makes testing and adding a key atomic, unless the filter does it itself
+	rotating *RotatingBloomFilter // The filter, when the keys are forgotten after a time window
+}
+
+// testAndAdder is implemented by filters that can test and add an item atomically.
+type testAndAdder interface {
+	TestAndAdd([]byte) (bool, error)
+}
+
+// NewDeduper creates a deduper remembering the keys in the filter.
+func NewDeduper(f Interface) *Deduper {
+	return &Deduper{filter: f}
+}
+
+// NewWindowedDeduper creates a deduper remembering the keys in a rotating Bloom filter, so keys are
+// forgotten between (Filters-1)*Interval and Filters*Interval after they were seen, bounding the memory
+// used by an endless stream. Close must be called to stop the rotation once the deduper is no longer used.
+func NewWindowedDeduper(p ParamsRotating) (*Deduper, error) {
+	rbf, err := NewRotating(p)
+	if err != nil {
+		return nil, err
+	}
+	return &Deduper{filter: rbf, rotating: rbf}, nil
+}
+
+// Seen reports whether the key was seen before, and records it as seen.
+// If the filter fails, the key is reported as not seen, so it is processed rather than lost.
+func (d *Deduper) Seen(key []byte) bool {
+	seen, _ := d.SeenErr(key)
+	return seen
+}
+
+// SeenErr is like Seen, but returns the error of the filter.
+func (d *Deduper) SeenErr(key []byte) (bool, error) {
+	if f, ok := d.filter.(testAndAdder); ok {
+		return f.TestAndAdd(key)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen, err := d.filter.Test(key)
+	if err != nil || seen {
+		return seen, err
+	}
+	return false, d.filter.Add(key)
+}
+
+// Filter returns the filter the keys are remembered in.
+func (d *Deduper) Filter() Interface {
+	return d.filter
+}
+
+// Close stops the rotation of a windowed deduper. It does nothing for other dedupers.
+func (d *Deduper) Close() {
+	if d.rotating != nil {
+		d.rotating.Close()
+	}
+}
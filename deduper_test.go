@@ -0,0 +1,76 @@
+package gobloom
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduper_Seen(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	d := NewDeduper(bf)
+	assert.Same(t, bf, d.Filter())
+	assert.False(t, d.Seen([]byte("msg-1")), "A new key should not be seen")
+	assert.True(t, d.Seen([]byte("msg-1")), "A key should be seen the second time")
+	assert.False(t, d.Seen([]byte("msg-2")))
+	d.Close()
+}
+
+func TestDeduper_Concurrent(t *testing.T) {
+	t.Parallel()
+	for _, f := range []Interface{mustNew(t), mustInverse(t)} {
+		d := NewDeduper(f)
+		// Every key is delivered to 4 consumers, only one of them should process it.
+		var processed atomic.Int64
+		var wg sync.WaitGroup
+		for c := 0; c < 4; c++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					if !d.Seen([]byte(strconv.Itoa(i))) {
+						processed.Add(1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, processed.Load(), int64(500), "%T: Keys should be processed at most once", f)
+		assert.Greater(t, processed.Load(), int64(490), "%T: Almost every key should be processed", f)
+	}
+}
+
+func TestDeduper_Windowed(t *testing.T) {
+	t.Parallel()
+	d, err := NewWindowedDeduper(ParamsRotating{N: 1000, FalsePositiveRate: 0.01, Filters: 2, Interval: time.Hour})
+	assert.NoError(t, err, "Failed to create windowed deduper")
+	defer d.Close()
+	assert.False(t, d.Seen([]byte("msg")))
+	assert.True(t, d.Seen([]byte("msg")))
+
+	rbf := d.Filter().(*RotatingBloomFilter)
+	assert.NoError(t, rbf.Rotate())
+	assert.NoError(t, rbf.Rotate())
+	assert.False(t, d.Seen([]byte("msg")), "Keys should be forgotten after the window")
+
+	_, err = NewWindowedDeduper(ParamsRotating{N: 1000, FalsePositiveRate: 0.01, Filters: 1, Interval: time.Hour})
+	assert.Error(t, err)
+}
+
+func mustNew(t *testing.T) *BloomFilter {
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.001})
+	assert.NoError(t, err)
+	return bf
+}
+
+func mustInverse(t *testing.T) *InverseBloomFilter {
+	ibf, err := NewInverse(ParamsInverse{Size: 1 << 20})
+	assert.NoError(t, err)
+	return ibf
+}
@@ -0,0 +1,228 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+var (
+	_ Interface  = (*DeletableBloomFilter)(nil)
+	_ Remover    = (*DeletableBloomFilter)(nil)
+	_ Clearer    = (*DeletableBloomFilter)(nil)
+	_ Serializer = (*DeletableBloomFilter)(nil)
+)
+
+// ErrNotDeletable is returned when an item cannot be removed because all of its bits are in
+// regions where collisions happened.
+var ErrNotDeletable = errors.New("item is not deletable")
+
+// DeletableBloomFilter is a Bloom filter that supports removing some items without counters.
+// The bit set is split into regions, and a collision bitmap records the regions where a bit
+// that was already set got set again. Bits in collision-free regions belong to a single item,
+// so they can be reset safely. An item is removed when at least one of its bits is reset.
+type DeletableBloomFilter struct {
+	m          uint64   // The number of bits in the bit set
+	bitSet     []uint64 // The bit array represented as a slice of uint64
+	regions    uint64   // The number of regions
+	collisions []uint64 // The collision bitmap, one bit per region
+	k          uint64   // The number of hash functions to use
+	hasher     Hasher   // The hash provider the hash functions come from
+	hasher64   Hasher64 // The hasher computing the hashes of an item
+	mutex      Mutex    // Mutex to ensure thread safety
+}
+
+// ParamsDeletable represents the parameters for creating a new deletable Bloom filter.
+type ParamsDeletable struct {
+	// N is the number of elements expected to be added to the Bloom filter.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate.
+	FalsePositiveRate float64
+	// Regions is the number of regions the bit set is split into. Defaults to one region per 16 bits.
+	// More regions increase the fraction of deletable items, at the cost of one bit of memory each.
+	Regions uint64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewDeletable creates a new deletable Bloom filter.
+func NewDeletable(p ParamsDeletable) (*DeletableBloomFilter, error) {
+	bp := Params{N: p.N, FalsePositiveRate: p.FalsePositiveRate, Hasher: p.Hasher, LockType: p.LockType}
+	applyDefaults(&bp)
+	if err := validateParams(bp); err != nil {
+		return nil, err
+	}
+	p.Hasher, p.LockType = bp.Hasher, bp.LockType
+	m, k := getOptimalParams(p.N, p.FalsePositiveRate)
+	if p.Regions == 0 {
+		p.Regions = (m + 15) / 16
+	}
+	if p.Regions > m {
+		return nil, fmt.Errorf("invalid number of regions, must be at most %d, got %d", m, p.Regions)
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	return &DeletableBloomFilter{
+		m:          m,
+		k:          k,
+		bitSet:     make([]uint64, (m+63)/64),
+		regions:    p.Regions,
+		collisions: make([]uint64, (p.Regions+63)/64),
+		hasher:     p.Hasher,
+		hasher64:   asHasher64(p.Hasher),
+		mutex:      mu,
+	}, nil
+}
+
+// region returns the region of the bit at the given position.
+// The product is computed on 128 bits, as pos * regions overflows for large filters.
+func (dbf *DeletableBloomFilter) region(pos uint64) uint64 {
+	hi, lo := bits.Mul64(pos, dbf.regions)
+	r, _ := bits.Div64(hi, lo, dbf.m)
+	return r
+}
+
+// Add adds an item to the deletable Bloom filter.
+func (dbf *DeletableBloomFilter) Add(data []byte) error {
+	if dbf.mutex != nil {
+		dbf.mutex.WLock()
+		defer dbf.mutex.WUnlock()
+	}
+	probes := pooledLocations(dbf.hasher64, data, dbf.k, dbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	for _, l := range locs {
+		if dbf.bitSet[l/64]&(1<<(l%64)) != 0 {
+			r := dbf.region(l)
+			dbf.collisions[r/64] |= 1 << (r % 64)
+			continue
+		}
+		dbf.bitSet[l/64] |= 1 << (l % 64)
+	}
+	return nil
+}
+
+// Test checks if an item is in the deletable Bloom filter.
+func (dbf *DeletableBloomFilter) Test(data []byte) (bool, error) {
+	if dbf.mutex != nil {
+		dbf.mutex.RLock()
+		defer dbf.mutex.RUnlock()
+	}
+	probes := pooledLocations(dbf.hasher64, data, dbf.k, dbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	for _, l := range locs {
+		if dbf.bitSet[l/64]&(1<<(l%64)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Remove removes an item from the deletable Bloom filter.
+// It returns ErrNotFound if the item is not in the filter, and ErrNotDeletable if all of its bits
+// are in regions with collisions, in which case the item remains in the filter.
+// Removing an item that was never added (but tests positive) may introduce false negatives.
+func (dbf *DeletableBloomFilter) Remove(data []byte) error {
+	if dbf.mutex != nil {
+		dbf.mutex.WLock()
+		defer dbf.mutex.WUnlock()
+	}
+	probes := pooledLocations(dbf.hasher64, data, dbf.k, dbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	for _, l := range locs {
+		if dbf.bitSet[l/64]&(1<<(l%64)) == 0 {
+			return ErrNotFound
+		}
+	}
+	removed := false
+	for _, l := range locs {
+		r := dbf.region(l)
+		if dbf.collisions[r/64]&(1<<(r%64)) == 0 {
+			dbf.bitSet[l/64] &^= 1 << (l % 64)
+			removed = true
+		}
+	}
+	if !removed {
+		return ErrNotDeletable
+	}
+	return nil
+}
+
+// MarshalBinary encodes the deletable Bloom filter with the wire format.
+// The parameter block holds m, k, the number of regions, and the hasher.
+// The payload is the bit set followed by the collision bitmap.
+func (dbf *DeletableBloomFilter) MarshalBinary() ([]byte, error) {
+	if dbf.mutex != nil {
+		dbf.mutex.RLock()
+		defer dbf.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, dbf.m)
+	params = binary.AppendUvarint(params, dbf.k)
+	params = binary.AppendUvarint(params, dbf.regions)
+	params, err := appendHasher(params, dbf.hasher)
+	if err != nil {
+		return nil, err
+	}
+	payload := appendWords(nil, dbf.bitSet)
+	payload = appendWords(payload, dbf.collisions)
+	return encodeFilter(filterTypeDeletable, params, payload), nil
+}
+
+// UnmarshalBinary restores a deletable Bloom filter encoded with MarshalBinary.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value filter.
+func (dbf *DeletableBloomFilter) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeDeletable, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	m := r.uvarint()
+	k := r.uvarint()
+	regions := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	if err := checkMK(m, k); err != nil {
+		return err
+	}
+	if regions == 0 || regions > m {
+		return fmt.Errorf("%w: invalid number of regions", ErrInvalidEncoding)
+	}
+	bitSetSize := wordsFor(m)
+	if err := checkPayload(payload, 8, bitSetSize+wordsFor(regions)); err != nil {
+		return err
+	}
+	if dbf.mutex == nil && dbf.m == 0 {
+		dbf.mutex = &ExclusiveMutex{}
+	}
+	if dbf.mutex != nil {
+		dbf.mutex.WLock()
+		defer dbf.mutex.WUnlock()
+	}
+	dbf.m = m
+	dbf.k = k
+	dbf.regions = regions
+	dbf.bitSet = readWords(payload[:8*bitSetSize])
+	dbf.collisions = readWords(payload[8*bitSetSize:])
+	dbf.hasher = hasher
+	dbf.hasher64 = asHasher64(hasher)
+	return nil
+}
+
+// Clear removes all the items from the deletable Bloom filter, keeping its parameters.
+func (dbf *DeletableBloomFilter) Clear() {
+	if dbf.mutex != nil {
+		dbf.mutex.WLock()
+		defer dbf.mutex.WUnlock()
+	}
+	clear(dbf.bitSet)
+	clear(dbf.collisions)
+}
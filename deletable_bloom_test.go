@@ -0,0 +1,70 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeletableBloomFilter_AddTestRemove(t *testing.T) {
+	t.Parallel()
+	dbf, err := NewDeletable(ParamsDeletable{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create deletable Bloom filter")
+
+	item := []byte("test-item")
+	assert.NoError(t, dbf.Add(item))
+
+	b, err := dbf.Test(item)
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after Add")
+
+	assert.NoError(t, dbf.Remove(item))
+	b, err = dbf.Test(item)
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should not be present after Remove")
+
+	assert.ErrorIs(t, dbf.Remove(item), ErrNotFound)
+}
+
+func TestDeletableBloomFilter_NoFalseNegatives(t *testing.T) {
+	t.Parallel()
+	n := 1000
+	dbf, err := NewDeletable(ParamsDeletable{N: uint64(n), FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create deletable Bloom filter")
+
+	for i := 0; i < n; i++ {
+		assert.NoError(t, dbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	// Remove half of the items, the other half must remain present.
+	deleted := 0
+	for i := 0; i < n; i += 2 {
+		err := dbf.Remove([]byte(fmt.Sprintf("item-%d", i)))
+		if err == nil {
+			deleted++
+		} else {
+			assert.ErrorIs(t, err, ErrNotDeletable)
+		}
+	}
+	assert.Greater(t, deleted, 0, "Expected some items to be deletable")
+	for i := 1; i < n; i += 2 {
+		b, err := dbf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should still be present", i)
+	}
+}
+
+func TestNewDeletable_InvalidRegions(t *testing.T) {
+	t.Parallel()
+	_, err := NewDeletable(ParamsDeletable{N: 10, FalsePositiveRate: 0.01, Regions: 1 << 20})
+	assert.Error(t, err)
+}
+
+func TestDeletableBloomFilter_RegionLargeFilter(t *testing.T) {
+	t.Parallel()
+	dbf := &DeletableBloomFilter{m: 1 << 62, regions: 1 << 20}
+	assert.Equal(t, uint64(0), dbf.region(0))
+	assert.Equal(t, uint64(1<<19), dbf.region(1<<61))
+	assert.Equal(t, uint64(1<<20-1), dbf.region(1<<62-1))
+}
@@ -0,0 +1,216 @@
+package gobloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"sync"
+)
+
+const doubleHasherName = "double"
+
+// ProbeStrategy is the way a DoubleHasher derives the hash functions from the base hashes.
+type ProbeStrategy uint8
+
+const (
+	// ProbeDouble is plain double hashing, the i-th hash function is h1 + i*h2.
+	ProbeDouble ProbeStrategy = iota
+	// ProbeEnhancedDouble is enhanced double hashing, as described by Dillinger and Manolios,
+	// the i-th hash function is h1 + i*h2 + (i^3-i)/6. The cubic term avoids the probes of items
+	// with close base hashes overlapping, which raises the false positive rate of plain double
+	// hashing at high fill ratios.
+	ProbeEnhancedDouble
+	// ProbeTriple is triple hashing, the i-th hash function is h1 + i*h2 + i*(i-1)/2*h3.
+	// It costs a third base hash, and is the closest to independent hash functions.
+	ProbeTriple
+)
+
+// DoubleHasher derives all the hash functions from two base hashes h1 and h2 of the data, the i-th one
+// being h1 + i*h2, as described by Kirsch and Mitzenmacher in "Less Hashing, Same Performance".
+// The base hashes are computed once per item and shared by the hash functions of a GetHashes call,
+// so adding or testing an item costs two hashes instead of k, with the same false positive rate.
+// The hash functions of a GetHashes call must be used with the same data one after the other,
+// which is how filters use them.
+type DoubleHasher struct {
+	base     Hasher        // The hasher the base hash functions come from
+	strategy ProbeStrategy // The way the hash functions are derived from the base hashes
+
+	adaptOnce sync.Once
+	adapted   Hasher64 // The base hasher adapted to Hasher64, if it doesn't implement it
+}
+
+var (
+	_ NamedHasher = (*DoubleHasher)(nil)
+	_ Hasher64    = (*DoubleHasher)(nil)
+)
+
+// NewDoubleHasher creates a DoubleHasher whose two base hash functions come from base.
+// The base hasher defaults to MurMur3Hasher, and must implement NamedHasher for filters using it
+// to be serialized.
+func NewDoubleHasher(base Hasher) *DoubleHasher {
+	if base == nil {
+		base = NewMurMur3Hasher()
+	}
+	return &DoubleHasher{base: base}
+}
+
+// NewDoubleHasherWithStrategy creates a DoubleHasher deriving the hash functions with the given strategy.
+func NewDoubleHasherWithStrategy(base Hasher, strategy ProbeStrategy) (*DoubleHasher, error) {
+	if strategy > ProbeTriple {
+		return nil, fmt.Errorf("invalid probe strategy %d", strategy)
+	}
+	h := NewDoubleHasher(base)
+	h.strategy = strategy
+	return h, nil
+}
+
+func (h *DoubleHasher) GetHashes(n uint64) []hash.Hash64 {
+	bases := uint64(2)
+	if h.strategy == ProbeTriple {
+		bases = 3
+	}
+	state := &doubleHashState{base: h.base.GetHashes(bases), strategy: h.strategy}
+	hashers := make([]hash.Hash64, n)
+	for i := range hashers {
+		hashers[i] = &doubleHash{i: uint64(i), state: state}
+	}
+	return hashers
+}
+
+func (h *DoubleHasher) HashK(data []byte, out []uint64) {
+	bases := uint64(2)
+	if h.strategy == ProbeTriple {
+		bases = 3
+	}
+	// The base hashes are computed in out when it is large enough, so hashing doesn't allocate.
+	base := out
+	if uint64(len(out)) < bases {
+		buf := getProbes(bases)
+		defer probePool.Put(buf)
+		base = *buf
+	}
+	h.base64().HashK(data, base[:bases])
+	h1, h2, h3 := base[0], base[1], uint64(0)
+	if bases == 3 {
+		h3 = base[2]
+	}
+	if h2 == 0 {
+		h2 = 1 // All the hash functions would be the same
+	}
+	for i := range out {
+		out[i] = probe(h.strategy, h1, h2, h3, uint64(i))
+	}
+}
+
+// base64 returns the base hasher as a Hasher64, adapting it once if it doesn't implement it.
+func (h *DoubleHasher) base64() Hasher64 {
+	if h64, ok := h.base.(Hasher64); ok {
+		return h64
+	}
+	h.adaptOnce.Do(func() { h.adapted = asHasher64(h.base) })
+	return h.adapted
+}
+
+func (h *DoubleHasher) Name() string {
+	return doubleHasherName
+}
+
+// MarshalBinary encodes the name and state of the base hasher, followed by the strategy
+// as a byte unless it is ProbeDouble.
+func (h *DoubleHasher) MarshalBinary() ([]byte, error) {
+	data, err := appendHasher(nil, h.base)
+	if err != nil || h.strategy == ProbeDouble {
+		return data, err
+	}
+	return append(data, byte(h.strategy)), nil
+}
+
+// UnmarshalBinary restores the base hasher and strategy encoded with MarshalBinary.
+func (h *DoubleHasher) UnmarshalBinary(data []byte) error {
+	r := byteReader{data: data}
+	base, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	strategy := ProbeDouble
+	switch {
+	case len(r.data) == 1 && ProbeStrategy(r.data[0]) <= ProbeTriple:
+		strategy = ProbeStrategy(r.data[0])
+	case len(r.data) != 0:
+		return fmt.Errorf("%w: invalid double hasher state", ErrInvalidEncoding)
+	}
+	h.base = base
+	h.strategy = strategy
+	h.adaptOnce = sync.Once{}
+	h.adapted = nil
+	return nil
+}
+
+// doubleHashState holds the base hashes of the last data, shared by the hash functions of a GetHashes call.
+type doubleHashState struct {
+	base       []hash.Hash64 // The base hash functions, two or three depending on the strategy
+	strategy   ProbeStrategy
+	data       []byte // The data the base hashes were computed for
+	valid      bool   // Whether the base hashes were computed
+	h1, h2, h3 uint64
+}
+
+// doubleHash is a hash.Hash64 whose sum is the i-th hash function of the strategy for the written data.
+type doubleHash struct {
+	i     uint64
+	state *doubleHashState
+	data  []byte
+}
+
+func (h *doubleHash) Write(p []byte) (int, error) {
+	h.data = append(h.data, p...)
+	return len(p), nil
+}
+
+func (h *doubleHash) Sum64() uint64 {
+	s := h.state
+	if !s.valid || !bytes.Equal(s.data, h.data) {
+		for _, b := range s.base {
+			b.Reset()
+			_, _ = b.Write(h.data) // hash.Hash writes never fail
+		}
+		s.h1, s.h2 = s.base[0].Sum64(), s.base[1].Sum64()
+		if s.h2 == 0 {
+			s.h2 = 1 // All the hash functions would be the same
+		}
+		if len(s.base) > 2 {
+			s.h3 = s.base[2].Sum64()
+		}
+		s.data = append(s.data[:0], h.data...)
+		s.valid = true
+	}
+	return probe(s.strategy, s.h1, s.h2, s.h3, h.i)
+}
+
+// probe returns the i-th hash function of the strategy, for the base hashes h1, h2 and h3.
+func probe(strategy ProbeStrategy, h1, h2, h3, i uint64) uint64 {
+	switch strategy {
+	case ProbeEnhancedDouble:
+		return h1 + i*h2 + (i*i*i-i)/6
+	case ProbeTriple:
+		return h1 + i*h2 + i*(i-1)/2*h3
+	}
+	return h1 + i*h2
+}
+
+func (h *doubleHash) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func (h *doubleHash) Reset() {
+	h.data = h.data[:0]
+}
+
+func (h *doubleHash) Size() int {
+	return 8
+}
+
+func (h *doubleHash) BlockSize() int {
+	return 1
+}
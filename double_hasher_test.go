@@ -0,0 +1,153 @@
+package gobloom
+
+import (
+	"fmt"
+	"hash"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingHasher counts the writes to its hash functions.
+type countingHasher struct {
+	Hasher
+	writes *int
+}
+
+func (h countingHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashes := h.Hasher.GetHashes(n)
+	for i := range hashes {
+		hashes[i] = countingHash{Hash64: hashes[i], writes: h.writes}
+	}
+	return hashes
+}
+
+type countingHash struct {
+	hash.Hash64
+	writes *int
+}
+
+func (h countingHash) Write(p []byte) (int, error) {
+	*h.writes++
+	return h.Hash64.Write(p)
+}
+
+func TestDoubleHasher_Probes(t *testing.T) {
+	t.Parallel()
+	base := NewMurMur3Hasher().GetHashes(2)
+	hashes := NewDoubleHasher(nil).GetHashes(5)
+	for _, item := range []string{"foo", "bar", "foo"} {
+		base[0].Reset()
+		base[1].Reset()
+		_, _ = base[0].Write([]byte(item))
+		_, _ = base[1].Write([]byte(item))
+		h1, h2 := base[0].Sum64(), base[1].Sum64()
+		for i, h := range hashes {
+			h.Reset()
+			_, _ = h.Write([]byte(item))
+			assert.Equal(t, h1+uint64(i)*h2, h.Sum64(), "Probe %d of %q", i, item)
+		}
+	}
+}
+
+func TestDoubleHasher_HashesOncePerItem(t *testing.T) {
+	t.Parallel()
+	writes := 0
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.001, Hasher: NewDoubleHasher(countingHasher{NewMurMur3Hasher(), &writes})})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Equal(t, uint64(10), bf.K())
+	assert.NoError(t, bf.Add([]byte("foo")))
+	assert.Equal(t, 2, writes, "Only the two base hashes should be computed")
+}
+
+func TestDoubleHasher_FalsePositiveRate(t *testing.T) {
+	t.Parallel()
+	n, fp := 10000, 0.01
+	bf, err := New(Params{N: uint64(n), FalsePositiveRate: fp, Hasher: NewDoubleHasher(nil)})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < n; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		b, err := bf.Test([]byte(fmt.Sprintf("other-%d", i)))
+		assert.NoError(t, err)
+		if b {
+			falsePositives++
+		}
+	}
+	assert.InDelta(t, fp, float64(falsePositives)/float64(n), fp/2, "False positive rate should match independent hashing")
+}
+
+func TestDoubleHasher_Encoding(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: NewDoubleHasher(NewMurMur3HasherWithSeed(42))})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, NewDoubleHasher(NewMurMur3HasherWithSeed(42)), restored.hasher)
+	b, err := restored.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after restoring")
+}
+
+func TestDoubleHasher_Strategies(t *testing.T) {
+	t.Parallel()
+	base := NewMurMur3Hasher().GetHashes(3)
+	var h [3]uint64
+	for j := range base {
+		_, _ = base[j].Write([]byte("foo"))
+		h[j] = base[j].Sum64()
+	}
+	probes := map[ProbeStrategy]func(i uint64) uint64{
+		ProbeDouble:         func(i uint64) uint64 { return h[0] + i*h[1] },
+		ProbeEnhancedDouble: func(i uint64) uint64 { return h[0] + i*h[1] + (i*i*i-i)/6 },
+		ProbeTriple:         func(i uint64) uint64 { return h[0] + i*h[1] + i*(i-1)/2*h[2] },
+	}
+	for strategy, probe := range probes {
+		dh, err := NewDoubleHasherWithStrategy(nil, strategy)
+		assert.NoError(t, err)
+		for i, hash := range dh.GetHashes(7) {
+			_, _ = hash.Write([]byte("foo"))
+			assert.Equal(t, probe(uint64(i)), hash.Sum64(), "Probe %d of strategy %d", i, strategy)
+		}
+
+		bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: dh})
+		assert.NoError(t, err, "Failed to create Bloom filter")
+		data, err := bf.MarshalBinary()
+		assert.NoError(t, err)
+		var restored BloomFilter
+		assert.NoError(t, restored.UnmarshalBinary(data))
+		assert.Equal(t, dh, restored.hasher, "Strategy should be kept when encoding")
+	}
+
+	_, err := NewDoubleHasherWithStrategy(nil, ProbeTriple+1)
+	assert.Error(t, err, "Unknown strategies should be rejected")
+}
+
+func TestDoubleHasher_EnhancedHighFill(t *testing.T) {
+	t.Parallel()
+	// At twice the capacity, enhanced double hashing should perform like plain double hashing on unrelated items.
+	n := 5000
+	rate := func(strategy ProbeStrategy) float64 {
+		dh, _ := NewDoubleHasherWithStrategy(nil, strategy)
+		bf, err := New(Params{N: uint64(n), FalsePositiveRate: 0.01, Hasher: dh})
+		assert.NoError(t, err, "Failed to create Bloom filter")
+		for i := 0; i < 2*n; i++ {
+			assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+		}
+		falsePositives := 0
+		for i := 0; i < n; i++ {
+			b, _ := bf.Test([]byte(fmt.Sprintf("other-%d", i)))
+			if b {
+				falsePositives++
+			}
+		}
+		return float64(falsePositives) / float64(n)
+	}
+	assert.InDelta(t, rate(ProbeDouble), rate(ProbeEnhancedDouble), 0.05)
+}
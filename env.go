@@ -0,0 +1,65 @@
+package gobloom
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// defaultEnvPrefix is the prefix of the environment variables read by NewFromEnv, when not set.
+const defaultEnvPrefix = "GOBLOOM"
+
+// NewFromEnv creates the filter described by environment variables named after the fields of Config,
+// prefixed with prefix, which defaults to GOBLOOM: GOBLOOM_TYPE, GOBLOOM_N, GOBLOOM_FALSE_POSITIVE_RATE,
+// GOBLOOM_FALSE_POSITIVE_GROWTH, GOBLOOM_MAX_EXPECTED_ITEMS, GOBLOOM_HASHER and GOBLOOM_LOCK_TYPE.
+// Unset variables get the defaults of Config, so filters can be tuned per deployment.
+func NewFromEnv(prefix string) (Interface, error) {
+	c, err := configFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return c.New()
+}
+
+// configFromEnv reads a Config from the environment variables with the given prefix.
+func configFromEnv(prefix string) (Config, error) {
+	if prefix == "" {
+		prefix = defaultEnvPrefix
+	}
+	var c Config
+	var errs error
+	lookup := func(name string, parse func(string) error) {
+		name = prefix + "_" + name
+		if v, ok := os.LookupEnv(name); ok && errs == nil {
+			if err := parse(v); err != nil {
+				errs = fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	parseUint := func(dst *uint64) func(string) error {
+		return func(v string) (err error) {
+			*dst, err = strconv.ParseUint(v, 10, 64)
+			return err
+		}
+	}
+	parseFloat := func(dst *float64) func(string) error {
+		return func(v string) (err error) {
+			*dst, err = strconv.ParseFloat(v, 64)
+			return err
+		}
+	}
+	parseString := func(dst *string) func(string) error {
+		return func(v string) error {
+			*dst = v
+			return nil
+		}
+	}
+	lookup("TYPE", parseString(&c.Type))
+	lookup("N", parseUint(&c.N))
+	lookup("FALSE_POSITIVE_RATE", parseFloat(&c.FalsePositiveRate))
+	lookup("FALSE_POSITIVE_GROWTH", parseFloat(&c.FalsePositiveGrowth))
+	lookup("MAX_EXPECTED_ITEMS", parseUint(&c.MaxExpectedItems))
+	lookup("HASHER", parseString(&c.Hasher))
+	lookup("LOCK_TYPE", parseString(&c.LockType))
+	return c, errs
+}
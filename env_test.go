@@ -0,0 +1,42 @@
+package gobloom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The tests set environment variables, so they can't run in parallel.
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("GOBLOOM_N", "1000")
+	t.Setenv("GOBLOOM_FALSE_POSITIVE_RATE", "0.01")
+	t.Setenv("GOBLOOM_HASHER", "wyhash")
+	t.Setenv("GOBLOOM_LOCK_TYPE", "none")
+	f, err := NewFromEnv("")
+	assert.NoError(t, err, "Failed to create filter from the environment")
+	bf, ok := f.(*BloomFilter)
+	assert.True(t, ok, "A standard filter should be created, got %T", f)
+	assert.Equal(t, NewWyHasher(), bf.hasher)
+	assert.Nil(t, bf.mutex)
+
+	t.Setenv("CACHE_TYPE", "scalable")
+	t.Setenv("CACHE_N", "100")
+	t.Setenv("CACHE_FALSE_POSITIVE_RATE", "0.001")
+	t.Setenv("CACHE_FALSE_POSITIVE_GROWTH", "1.5")
+	f, err = NewFromEnv("CACHE")
+	assert.NoError(t, err, "Failed to create filter from the environment")
+	sbf, ok := f.(*ScalableBloomFilter)
+	assert.True(t, ok, "A scalable filter should be created, got %T", f)
+	assert.Equal(t, 1.5, sbf.fpGrowth)
+}
+
+func TestNewFromEnv_Invalid(t *testing.T) {
+	t.Setenv("BAD_N", "many")
+	t.Setenv("BAD_FALSE_POSITIVE_RATE", "0.01")
+	_, err := NewFromEnv("BAD")
+	assert.ErrorContains(t, err, "BAD_N")
+
+	_, err = NewFromEnv("UNSET")
+	assert.Error(t, err, "Missing parameters should be rejected")
+}
@@ -0,0 +1,19 @@
+package gobloom
+
+import "expvar"
+
+// PublishExpvar publishes the live statistics of the filter as the expvar variable name,
+// served as JSON on /debug/vars by the expvar handler. The variable holds the Metrics of
+// instrumented filters, and the Stats of the other filters that have them.
+// Like expvar.Publish, it panics if the name is already published.
+func PublishExpvar(name string, f Interface) {
+	expvar.Publish(name, expvar.Func(func() any {
+		switch f := f.(type) {
+		case *InstrumentedFilter:
+			return f.Metrics()
+		case interface{ Stats() Stats }:
+			return f.Stats()
+		}
+		return nil
+	}))
+}
@@ -0,0 +1,30 @@
+package gobloom
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	PublishExpvar("test_bloom", bf)
+	assert.NoError(t, bf.Add([]byte("foo")))
+
+	var stats Stats
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("test_bloom").String()), &stats))
+	assert.Equal(t, bf.Stats(), stats, "The variable should hold the live statistics")
+
+	f := Instrument(bf)
+	PublishExpvar("test_instrumented", f)
+	_, _ = f.Test([]byte("foo"))
+	var m Metrics
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("test_instrumented").String()), &m))
+	assert.Equal(t, uint64(1), m.Positives)
+
+	assert.Panics(t, func() { PublishExpvar("test_bloom", bf) }, "Publishing a name twice should panic")
+}
@@ -0,0 +1,96 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+)
+
+const fnvHasherName = "fnv"
+
+// FNVHasher is a Hasher using 64-bit FNV-1a from the standard library, for users who want no third party
+// hash dependency. Its distribution is weaker than murmur3's, so false positive rates may be slightly higher.
+// The i-th hash function hashes the seed seed+i as 8 little-endian bytes, followed by the data.
+type FNVHasher struct {
+	seed uint64 // The seed of the first hash function, the following ones use the next seeds
+}
+
+var (
+	_ NamedHasher = (*FNVHasher)(nil)
+	_ Hasher64    = (*FNVHasher)(nil)
+)
+
+func NewFNVHasher() *FNVHasher {
+	return &FNVHasher{}
+}
+
+// NewFNVHasherWithSeed creates an FNVHasher whose hash functions use the seeds seed, seed+1, and so on.
+func NewFNVHasherWithSeed(seed uint64) *FNVHasher {
+	return &FNVHasher{seed: seed}
+}
+
+func (h *FNVHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashers := make([]hash.Hash64, n)
+	for i := 0; uint64(i) < n; i++ {
+		fh := &fnvHash{Hash64: fnv.New64a(), seed: h.seed + uint64(i)}
+		fh.Reset()
+		hashers[i] = fh
+	}
+	return hashers
+}
+
+func (h *FNVHasher) HashK(data []byte, out []uint64) {
+	const offset64, prime64 = 14695981039346656037, 1099511628211
+	for i := range out {
+		sum := uint64(offset64)
+		seed := h.seed + uint64(i)
+		for j := 0; j < 8; j++ {
+			sum ^= (seed >> (8 * j)) & 0xff
+			sum *= prime64
+		}
+		for _, c := range data {
+			sum ^= uint64(c)
+			sum *= prime64
+		}
+		out[i] = sum
+	}
+}
+
+func (h *FNVHasher) Name() string {
+	return fnvHasherName
+}
+
+// MarshalBinary encodes the seed of the hasher, the default seed is encoded as no state.
+func (h *FNVHasher) MarshalBinary() ([]byte, error) {
+	if h.seed == 0 {
+		return nil, nil
+	}
+	return binary.LittleEndian.AppendUint64(nil, h.seed), nil
+}
+
+// UnmarshalBinary restores the seed of the hasher encoded with MarshalBinary.
+func (h *FNVHasher) UnmarshalBinary(data []byte) error {
+	switch len(data) {
+	case 0:
+		h.seed = 0
+	case 8:
+		h.seed = binary.LittleEndian.Uint64(data)
+	default:
+		return fmt.Errorf("%w: fnv hasher state is %d bytes", ErrInvalidEncoding, len(data))
+	}
+	return nil
+}
+
+// fnvHash is a FNV-1a hash that hashes its seed first.
+type fnvHash struct {
+	hash.Hash64
+	seed uint64
+}
+
+func (h *fnvHash) Reset() {
+	h.Hash64.Reset()
+	var seed [8]byte
+	binary.LittleEndian.PutUint64(seed[:], h.seed)
+	_, _ = h.Hash64.Write(seed[:]) // hash.Hash writes never fail
+}
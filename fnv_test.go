@@ -0,0 +1,52 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFNVHasher(t *testing.T) {
+	t.Parallel()
+	hashes := NewFNVHasherWithSeed(5).GetHashes(2)
+	for i, h := range hashes {
+		// Reset must keep the seed.
+		_, _ = h.Write([]byte("garbage"))
+		h.Reset()
+		_, _ = h.Write([]byte("foo"))
+
+		expected := fnv.New64a()
+		_, _ = expected.Write(binary.LittleEndian.AppendUint64(nil, uint64(5+i)))
+		_, _ = expected.Write([]byte("foo"))
+		assert.Equal(t, expected.Sum64(), h.Sum64(), "Hash function %d should hash its seed then the data", i)
+	}
+}
+
+func TestFNVHasher_BloomFilter(t *testing.T) {
+	t.Parallel()
+	n := 10000
+	bf, err := New(Params{N: uint64(n), FalsePositiveRate: 0.01, Hasher: NewFNVHasher()})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < n; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		b, err := bf.Test([]byte(fmt.Sprintf("other-%d", i)))
+		assert.NoError(t, err)
+		if b {
+			falsePositives++
+		}
+	}
+	assert.Less(t, float64(falsePositives)/float64(n), 0.03, "False positive rate should be close to the target")
+
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, bf.Bits(), restored.Bits())
+	assert.Equal(t, NewFNVHasher(), restored.hasher)
+}
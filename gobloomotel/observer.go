@@ -0,0 +1,138 @@
+// Package gobloomotel reports the operations of gobloom filters to OpenTelemetry, so their behavior shows up
+// in existing distributed traces and metrics. It lives in its own package to keep gobloom free of the
+// OpenTelemetry dependency. The observer is enabled with the WithObserver option, or the Observer field of
+// ParamsScalable:
+//
+//	obs, err := gobloomotel.NewObserver(gobloomotel.WithAttributes(attribute.String("gobloom.filter", "sessions")))
+//	if err != nil {
+//		return err
+//	}
+//	f, err := gobloom.NewWithOptions(1000, 0.01, gobloom.WithObserver(obs))
+//
+// Batch operations, like AddManyCtx, start a span in the trace of their context, and the items added and
+// tested and the layers added by scalable filters are counted.
+package gobloomotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/franciscoescher/gobloom"
+)
+
+// scope is the name of the instrumentation scope of the tracer and meter.
+const scope = "github.com/franciscoescher/gobloom/gobloomotel"
+
+// Observer is a gobloom.ContextObserver starting a span for each batch operation, and counting the
+// operations with OpenTelemetry metrics.
+type Observer struct {
+	tracer    trace.Tracer
+	attrs     []attribute.KeyValue     // The attributes of the spans
+	set       metric.MeasurementOption // The attributes of the measurements
+	adds      metric.Int64Counter
+	tests     metric.Int64Counter
+	positives metric.Int64Counter
+	scales    metric.Int64Counter
+}
+
+var _ gobloom.ContextObserver = (*Observer)(nil)
+
+// config is the configuration of an observer.
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	attrs          []attribute.KeyValue
+}
+
+// Option configures an observer.
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer, the global one by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) { c.tracerProvider = tp }
+}
+
+// WithMeterProvider sets the provider of the meter, the global one by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) { c.meterProvider = mp }
+}
+
+// WithAttributes adds attributes to the spans and measurements, like the name of the filter.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(c *config) { c.attrs = append(c.attrs, attrs...) }
+}
+
+// NewObserver creates an observer, failing if the counters cannot be created.
+func NewObserver(opts ...Option) (*Observer, error) {
+	c := config{tracerProvider: otel.GetTracerProvider(), meterProvider: otel.GetMeterProvider()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	meter := c.meterProvider.Meter(scope)
+	o := &Observer{
+		tracer: c.tracerProvider.Tracer(scope),
+		attrs:  c.attrs,
+		set:    metric.WithAttributeSet(attribute.NewSet(c.attrs...)),
+	}
+	var err error
+	if o.adds, err = meter.Int64Counter("gobloom.adds", metric.WithDescription("The number of items added to the filter."),
+		metric.WithUnit("{item}")); err != nil {
+		return nil, err
+	}
+	if o.tests, err = meter.Int64Counter("gobloom.tests", metric.WithDescription("The number of items tested against the filter."),
+		metric.WithUnit("{item}")); err != nil {
+		return nil, err
+	}
+	if o.positives, err = meter.Int64Counter("gobloom.positives", metric.WithDescription("The number of tests reporting the item as present."),
+		metric.WithUnit("{item}")); err != nil {
+		return nil, err
+	}
+	if o.scales, err = meter.Int64Counter("gobloom.scales", metric.WithDescription("The number of layers added by scalable filters."),
+		metric.WithUnit("{layer}")); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Batch starts a span for a batch operation without a context, as the root of a new trace.
+func (o *Observer) Batch(op string, items int) func(error) {
+	return o.BatchCtx(context.Background(), op, items)
+}
+
+// BatchCtx starts a span for a batch operation in the trace of ctx, ended with the result of the operation.
+func (o *Observer) BatchCtx(ctx context.Context, op string, items int) func(error) {
+	attrs := make([]attribute.KeyValue, 0, len(o.attrs)+1)
+	attrs = append(attrs, o.attrs...)
+	attrs = append(attrs, attribute.Int("gobloom.items", items))
+	_, span := o.tracer.Start(ctx, "gobloom."+op, trace.WithAttributes(attrs...))
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// Added counts the items added.
+func (o *Observer) Added(items int) {
+	o.adds.Add(context.Background(), int64(items), o.set)
+}
+
+// Tested counts the items tested, and the positives among them.
+func (o *Observer) Tested(items, positives int) {
+	o.tests.Add(context.Background(), int64(items), o.set)
+	if positives > 0 {
+		o.positives.Add(context.Background(), int64(positives), o.set)
+	}
+}
+
+// Scaled counts the layer added by a scalable filter.
+func (o *Observer) Scaled(int) {
+	o.scales.Add(context.Background(), 1, o.set)
+}
@@ -0,0 +1,98 @@
+package gobloomotel
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/franciscoescher/gobloom"
+)
+
+// newObserver creates an observer recording to in-memory providers.
+func newObserver(t *testing.T) (*Observer, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	obs, err := NewObserver(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithAttributes(attribute.String("gobloom.filter", "test")))
+	assert.NoError(t, err, "Failed to create observer")
+	return obs, spans, reader
+}
+
+// counters returns the values of the counters recorded by the reader, by name.
+func counters(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	values := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				filter, _ := dp.Attributes.Value("gobloom.filter")
+				assert.Equal(t, "test", filter.AsString(), "Measurements should have the attributes")
+				values[m.Name] += dp.Value
+			}
+		}
+	}
+	return values
+}
+
+func TestObserver(t *testing.T) {
+	t.Parallel()
+	obs, spans, reader := newObserver(t)
+	f, err := gobloom.NewWithOptions(1000, 0.01, gobloom.WithObserver(obs))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	bf := f.(*gobloom.BloomFilter)
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	assert.NoError(t, bf.AddManyCtx(ctx, [][]byte{[]byte("foo"), []byte("bar")}))
+	parent.End()
+	assert.NoError(t, bf.Add([]byte("baz")))
+	_, _ = bf.Test([]byte("foo"))
+	_, _ = bf.Test([]byte("missing"))
+
+	ended := spans.Ended()
+	assert.Len(t, ended, 1, "Batch operations should have a span")
+	assert.Equal(t, "gobloom.AddMany", ended[0].Name())
+	assert.Equal(t, parent.SpanContext().TraceID(), ended[0].SpanContext().TraceID(), "The span should join the trace of the context")
+	assert.Equal(t, parent.SpanContext().SpanID(), ended[0].Parent().SpanID())
+	assert.Contains(t, ended[0].Attributes(), attribute.Int("gobloom.items", 2))
+	assert.Contains(t, ended[0].Attributes(), attribute.String("gobloom.filter", "test"))
+
+	assert.Equal(t, map[string]int64{"gobloom.adds": 3, "gobloom.tests": 2, "gobloom.positives": 1}, counters(t, reader))
+}
+
+func TestObserver_Scalable(t *testing.T) {
+	t.Parallel()
+	obs, _, reader := newObserver(t)
+	sbf, err := gobloom.NewScalable(gobloom.ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2, Observer: obs})
+	assert.NoError(t, err, "Error initializing scalable Bloom filter")
+	for i := 0; i < 2000; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	values := counters(t, reader)
+	assert.Equal(t, int64(2000), values["gobloom.adds"])
+	assert.Equal(t, int64(len(sbf.LayerStats())-1), values["gobloom.scales"], "Each new layer should be counted")
+}
+
+func TestObserver_BatchError(t *testing.T) {
+	t.Parallel()
+	obs, spans, _ := newObserver(t)
+	obs.Batch("AddMany", 1)(errors.New("failed"))
+	ended := spans.Ended()
+	assert.Len(t, ended, 1)
+	assert.Equal(t, codes.Error, ended[0].Status().Code, "Failed batches should have an error status")
+	assert.Equal(t, "failed", ended[0].Status().Description)
+}
@@ -0,0 +1,10 @@
+// Package gobloompb contains the protobuf messages of the gobloom filters,
+// so they can be exchanged by gRPC-based services.
+// Use the ToProto methods and the FromProto functions of the gobloom package to convert filters.
+//
+// service.proto defines FilterService, a gRPC service over named filters, served by the gobloomd command.
+// NewFilterServiceClient creates its client.
+package gobloompb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative gobloom.proto
+//go:generate protoc --go_out=. --go-grpc_out=. --go_opt=paths=source_relative --go-grpc_opt=paths=source_relative service.proto
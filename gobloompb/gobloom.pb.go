@@ -0,0 +1,354 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: gobloom.proto
+
+package gobloompb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Hasher identifies the hash provider of a filter.
+type Hasher struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name is the name of the hasher, as returned by NamedHasher.Name.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// State is the serialized state of the hasher, like its seeds, if it has any.
+	State []byte `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+}
+
+func (x *Hasher) Reset() {
+	*x = Hasher{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gobloom_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Hasher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hasher) ProtoMessage() {}
+
+func (x *Hasher) ProtoReflect() protoreflect.Message {
+	mi := &file_gobloom_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hasher.ProtoReflect.Descriptor instead.
+func (*Hasher) Descriptor() ([]byte, []int) {
+	return file_gobloom_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Hasher) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Hasher) GetState() []byte {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+// BloomFilter is a Bloom filter.
+type BloomFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// M is the number of bits in the bit set.
+	M uint64 `protobuf:"varint,1,opt,name=m,proto3" json:"m,omitempty"`
+	// K is the number of hash functions.
+	K uint64 `protobuf:"varint,2,opt,name=k,proto3" json:"k,omitempty"`
+	// Hasher is the hash provider of the filter.
+	Hasher *Hasher `protobuf:"bytes,3,opt,name=hasher,proto3" json:"hasher,omitempty"`
+	// Bits is the bit set, as little-endian uint64 words.
+	Bits []byte `protobuf:"bytes,4,opt,name=bits,proto3" json:"bits,omitempty"`
+}
+
+func (x *BloomFilter) Reset() {
+	*x = BloomFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gobloom_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BloomFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BloomFilter) ProtoMessage() {}
+
+func (x *BloomFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_gobloom_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BloomFilter.ProtoReflect.Descriptor instead.
+func (*BloomFilter) Descriptor() ([]byte, []int) {
+	return file_gobloom_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BloomFilter) GetM() uint64 {
+	if x != nil {
+		return x.M
+	}
+	return 0
+}
+
+func (x *BloomFilter) GetK() uint64 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+func (x *BloomFilter) GetHasher() *Hasher {
+	if x != nil {
+		return x.Hasher
+	}
+	return nil
+}
+
+func (x *BloomFilter) GetBits() []byte {
+	if x != nil {
+		return x.Bits
+	}
+	return nil
+}
+
+// ScalableBloomFilter is a scalable Bloom filter.
+type ScalableBloomFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Layers are the Bloom filters of each layer, from the oldest to the newest.
+	Layers []*BloomFilter `protobuf:"bytes,1,rep,name=layers,proto3" json:"layers,omitempty"`
+	// N is the number of items added.
+	N uint64 `protobuf:"varint,2,opt,name=n,proto3" json:"n,omitempty"`
+	// FalsePositiveRate is the false positive rate of the first layer.
+	FalsePositiveRate float64 `protobuf:"fixed64,3,opt,name=false_positive_rate,json=falsePositiveRate,proto3" json:"false_positive_rate,omitempty"`
+	// FalsePositiveGrowth is the growth rate of the false positive rate of each layer.
+	FalsePositiveGrowth float64 `protobuf:"fixed64,4,opt,name=false_positive_growth,json=falsePositiveGrowth,proto3" json:"false_positive_growth,omitempty"`
+}
+
+func (x *ScalableBloomFilter) Reset() {
+	*x = ScalableBloomFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gobloom_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScalableBloomFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScalableBloomFilter) ProtoMessage() {}
+
+func (x *ScalableBloomFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_gobloom_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScalableBloomFilter.ProtoReflect.Descriptor instead.
+func (*ScalableBloomFilter) Descriptor() ([]byte, []int) {
+	return file_gobloom_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScalableBloomFilter) GetLayers() []*BloomFilter {
+	if x != nil {
+		return x.Layers
+	}
+	return nil
+}
+
+func (x *ScalableBloomFilter) GetN() uint64 {
+	if x != nil {
+		return x.N
+	}
+	return 0
+}
+
+func (x *ScalableBloomFilter) GetFalsePositiveRate() float64 {
+	if x != nil {
+		return x.FalsePositiveRate
+	}
+	return 0
+}
+
+func (x *ScalableBloomFilter) GetFalsePositiveGrowth() float64 {
+	if x != nil {
+		return x.FalsePositiveGrowth
+	}
+	return 0
+}
+
+var File_gobloom_proto protoreflect.FileDescriptor
+
+var file_gobloom_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x67, 0x6f, 0x62, 0x6c, 0x6f, 0x6f, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0a, 0x67, 0x6f, 0x62, 0x6c, 0x6f, 0x6f, 0x6d, 0x2e, 0x76, 0x31, 0x22, 0x32, 0x0a, 0x06, 0x48,
+	0x61, 0x73, 0x68, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22,
+	0x69, 0x0a, 0x0b, 0x42, 0x6c, 0x6f, 0x6f, 0x6d, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x0c,
+	0x0a, 0x01, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x01, 0x6d, 0x12, 0x0c, 0x0a, 0x01,
+	0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x01, 0x6b, 0x12, 0x2a, 0x0a, 0x06, 0x68, 0x61,
+	0x73, 0x68, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x62,
+	0x6c, 0x6f, 0x6f, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x65, 0x72, 0x52, 0x06,
+	0x68, 0x61, 0x73, 0x68, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x69, 0x74, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x62, 0x69, 0x74, 0x73, 0x22, 0xb8, 0x01, 0x0a, 0x13, 0x53,
+	0x63, 0x61, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x6f, 0x6d, 0x46, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x12, 0x2f, 0x0a, 0x06, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x62, 0x6c, 0x6f, 0x6f, 0x6d, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x6c, 0x6f, 0x6f, 0x6d, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x73, 0x12, 0x0c, 0x0a, 0x01, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x01,
+	0x6e, 0x12, 0x2e, 0x0a, 0x13, 0x66, 0x61, 0x6c, 0x73, 0x65, 0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x76, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x11,
+	0x66, 0x61, 0x6c, 0x73, 0x65, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x52, 0x61, 0x74,
+	0x65, 0x12, 0x32, 0x0a, 0x15, 0x66, 0x61, 0x6c, 0x73, 0x65, 0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x76, 0x65, 0x5f, 0x67, 0x72, 0x6f, 0x77, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x13, 0x66, 0x61, 0x6c, 0x73, 0x65, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x47,
+	0x72, 0x6f, 0x77, 0x74, 0x68, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x66, 0x72, 0x61, 0x6e, 0x63, 0x69, 0x73, 0x63, 0x6f, 0x65, 0x73, 0x63,
+	0x68, 0x65, 0x72, 0x2f, 0x67, 0x6f, 0x62, 0x6c, 0x6f, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x62, 0x6c,
+	0x6f, 0x6f, 0x6d, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gobloom_proto_rawDescOnce sync.Once
+	file_gobloom_proto_rawDescData = file_gobloom_proto_rawDesc
+)
+
+func file_gobloom_proto_rawDescGZIP() []byte {
+	file_gobloom_proto_rawDescOnce.Do(func() {
+		file_gobloom_proto_rawDescData = protoimpl.X.CompressGZIP(file_gobloom_proto_rawDescData)
+	})
+	return file_gobloom_proto_rawDescData
+}
+
+var file_gobloom_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_gobloom_proto_goTypes = []any{
+	(*Hasher)(nil),              // 0: gobloom.v1.Hasher
+	(*BloomFilter)(nil),         // 1: gobloom.v1.BloomFilter
+	(*ScalableBloomFilter)(nil), // 2: gobloom.v1.ScalableBloomFilter
+}
+var file_gobloom_proto_depIdxs = []int32{
+	0, // 0: gobloom.v1.BloomFilter.hasher:type_name -> gobloom.v1.Hasher
+	1, // 1: gobloom.v1.ScalableBloomFilter.layers:type_name -> gobloom.v1.BloomFilter
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_gobloom_proto_init() }
+func file_gobloom_proto_init() {
+	if File_gobloom_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gobloom_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Hasher); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gobloom_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*BloomFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gobloom_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ScalableBloomFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gobloom_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_gobloom_proto_goTypes,
+		DependencyIndexes: file_gobloom_proto_depIdxs,
+		MessageInfos:      file_gobloom_proto_msgTypes,
+	}.Build()
+	File_gobloom_proto = out.File
+	file_gobloom_proto_rawDesc = nil
+	file_gobloom_proto_goTypes = nil
+	file_gobloom_proto_depIdxs = nil
+}
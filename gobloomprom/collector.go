@@ -0,0 +1,109 @@
+// Package gobloomprom exports the metrics of instrumented gobloom filters to Prometheus, so their saturation
+// can be alerted on in production. It lives in its own package to keep gobloom free of the client_golang
+// dependency.
+//
+//	f := gobloom.Instrument(filter)
+//	c := gobloomprom.NewCollector("myapp")
+//	c.Add("sessions", f)
+//	prometheus.MustRegister(c)
+package gobloomprom
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/franciscoescher/gobloom"
+)
+
+// Collector is a prometheus.Collector exporting the metrics of named instrumented filters,
+// with the name of each filter in the filter label.
+type Collector struct {
+	mu      sync.RWMutex                           // Guards filters
+	filters map[string]*gobloom.InstrumentedFilter // The filters by name
+
+	adds         *prometheus.Desc
+	tests        *prometheus.Desc
+	positives    *prometheus.Desc
+	positiveRate *prometheus.Desc
+	fillRatio    *prometheus.Desc
+	layers       *prometheus.Desc
+	memoryBytes  *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector creates a collector without filters, prefixing the names of the metrics with namespace,
+// if not empty, and gobloom.
+func NewCollector(namespace string) *Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "gobloom", name), help, []string{"filter"}, nil)
+	}
+	return &Collector{
+		filters:      make(map[string]*gobloom.InstrumentedFilter),
+		adds:         desc("adds_total", "The number of items added to the filter."),
+		tests:        desc("tests_total", "The number of items tested against the filter."),
+		positives:    desc("positives_total", "The number of tests reporting the item as present."),
+		positiveRate: desc("positive_rate", "The fraction of tests reporting the item as present."),
+		fillRatio:    desc("fill_ratio", "The fraction of the bits of the filter that are set."),
+		layers:       desc("layers", "The number of layers of the filter."),
+		memoryBytes:  desc("memory_bytes", "The size of the bit sets of the filter in bytes."),
+	}
+}
+
+// Add exports the metrics of the filter under the given name, failing if the name is taken.
+func (c *Collector) Add(name string, f *gobloom.InstrumentedFilter) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.filters[name] != nil {
+		return fmt.Errorf("filter %q already exists", name)
+	}
+	c.filters[name] = f
+	return nil
+}
+
+// Remove stops exporting the metrics of the filter with the given name.
+func (c *Collector) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.filters, name)
+}
+
+// Describe sends the descriptors of the metrics to ch.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.adds
+	ch <- c.tests
+	ch <- c.positives
+	ch <- c.positiveRate
+	ch <- c.fillRatio
+	ch <- c.layers
+	ch <- c.memoryBytes
+}
+
+// Collect sends the metrics of every filter to ch, sorted by name.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.filters))
+	for name := range c.filters {
+		names = append(names, name)
+	}
+	filters := make([]*gobloom.InstrumentedFilter, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		filters[i] = c.filters[name]
+	}
+	c.mu.RUnlock()
+
+	for i, f := range filters {
+		m := f.Metrics()
+		ch <- prometheus.MustNewConstMetric(c.adds, prometheus.CounterValue, float64(m.Adds), names[i])
+		ch <- prometheus.MustNewConstMetric(c.tests, prometheus.CounterValue, float64(m.Tests), names[i])
+		ch <- prometheus.MustNewConstMetric(c.positives, prometheus.CounterValue, float64(m.Positives), names[i])
+		ch <- prometheus.MustNewConstMetric(c.positiveRate, prometheus.GaugeValue, m.PositiveRate, names[i])
+		ch <- prometheus.MustNewConstMetric(c.fillRatio, prometheus.GaugeValue, m.FillRatio, names[i])
+		ch <- prometheus.MustNewConstMetric(c.layers, prometheus.GaugeValue, float64(m.Layers), names[i])
+		ch <- prometheus.MustNewConstMetric(c.memoryBytes, prometheus.GaugeValue, float64(m.MemoryBytes), names[i])
+	}
+}
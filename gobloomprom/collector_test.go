@@ -0,0 +1,67 @@
+package gobloomprom
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/franciscoescher/gobloom"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+	bf, err := gobloom.New(gobloom.Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Error initializing Bloom filter")
+	f := gobloom.Instrument(bf)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, f.Add([]byte(strconv.Itoa(i))))
+	}
+	for i := 0; i < 4; i++ {
+		_, err := f.Test([]byte(strconv.Itoa(i * 5)))
+		assert.NoError(t, err)
+	}
+
+	c := NewCollector("test")
+	assert.NoError(t, c.Add("seen", f))
+	assert.Error(t, c.Add("seen", f), "Names should be unique")
+	reg := prometheus.NewPedanticRegistry()
+	assert.NoError(t, reg.Register(c))
+
+	m := f.Metrics()
+	expected := `
+# HELP test_gobloom_adds_total The number of items added to the filter.
+# TYPE test_gobloom_adds_total counter
+test_gobloom_adds_total{filter="seen"} 10
+# HELP test_gobloom_layers The number of layers of the filter.
+# TYPE test_gobloom_layers gauge
+test_gobloom_layers{filter="seen"} 1
+# HELP test_gobloom_memory_bytes The size of the bit sets of the filter in bytes.
+# TYPE test_gobloom_memory_bytes gauge
+test_gobloom_memory_bytes{filter="seen"} ` + strconv.FormatUint(m.MemoryBytes, 10) + `
+# HELP test_gobloom_positive_rate The fraction of tests reporting the item as present.
+# TYPE test_gobloom_positive_rate gauge
+test_gobloom_positive_rate{filter="seen"} 0.5
+# HELP test_gobloom_positives_total The number of tests reporting the item as present.
+# TYPE test_gobloom_positives_total counter
+test_gobloom_positives_total{filter="seen"} 2
+# HELP test_gobloom_tests_total The number of items tested against the filter.
+# TYPE test_gobloom_tests_total counter
+test_gobloom_tests_total{filter="seen"} 4
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"test_gobloom_adds_total", "test_gobloom_layers", "test_gobloom_memory_bytes",
+		"test_gobloom_positive_rate", "test_gobloom_positives_total", "test_gobloom_tests_total"))
+	fill, err := testutil.GatherAndCount(reg, "test_gobloom_fill_ratio")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fill)
+	assert.Greater(t, m.FillRatio, 0.0)
+
+	c.Remove("seen")
+	count, err := testutil.GatherAndCount(reg)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count, "Removed filters should not be exported")
+}
@@ -0,0 +1,260 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// ErrInvalidEncoding is returned when decoding data that is not a valid encoding of a filter.
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+// GolombCodedSet is a compressed, static set for membership tests.
+// Items are hashed to the range [0, N*2^P), sorted, and the differences between consecutive
+// hashes are Golomb-Rice coded with parameter P. This takes about P+2 bits per item, less
+// than a Bloom filter with the same false positive rate of 2^-P, but testing an item
+// requires decoding the set, which is linear in its size.
+type GolombCodedSet struct {
+	n      uint64    // The number of items in the set
+	p      uint8     // The Golomb-Rice parameter, the false positive rate is 2^-p
+	data   []byte    // The Golomb-Rice coded deltas
+	hasher Hasher    // The hash provider the hash functions come from
+	pool   sync.Pool // Pool of hash functions, so concurrent calls don't share state
+}
+
+// ParamsGolomb represents the parameters for creating a new Golomb-coded set.
+type ParamsGolomb struct {
+	// FalsePositiveRate is the acceptable false positive rate, it is rounded down to a power of 2.
+	FalsePositiveRate float64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+}
+
+// NewGolombCodedSet creates a Golomb-coded set holding the given items.
+func NewGolombCodedSet(items [][]byte, p ParamsGolomb) (*GolombCodedSet, error) {
+	if p.FalsePositiveRate <= 0 || p.FalsePositiveRate >= 1 {
+		return nil, fmt.Errorf("false positive rate must be between 0 and 1")
+	}
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	h := p.Hasher.GetHashes(1)[0]
+	hashes := make([]uint64, len(items))
+	for i, item := range items {
+		h.Reset()
+		_, err := h.Write(item)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = h.Sum64()
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	return BuildGolombCodedSet(hashes, uint8(math.Ceil(math.Log2(1/p.FalsePositiveRate))), p.Hasher)
+}
+
+// BuildGolombCodedSet creates a Golomb-coded set from the sorted 64-bit hashes of its items,
+// computed with the first hash function of hasher, and the Golomb-Rice parameter p.
+// Hashers default to MurMur3Hasher if nil.
+func BuildGolombCodedSet(sortedHashes []uint64, p uint8, hasher Hasher) (*GolombCodedSet, error) {
+	if p == 0 || p > 32 {
+		return nil, fmt.Errorf("invalid parameter, must be between 1 and 32, got %d", p)
+	}
+	if !sort.SliceIsSorted(sortedHashes, func(i, j int) bool { return sortedHashes[i] < sortedHashes[j] }) {
+		return nil, fmt.Errorf("hashes must be sorted")
+	}
+	gcs := newGolombCodedSet(uint64(len(sortedHashes)), p, hasher)
+	w := bitWriter{}
+	prev := uint64(0)
+	for _, h := range sortedHashes {
+		// Reducing the hashes to the set range is monotonic, so they stay sorted.
+		v := gcs.reduce(h)
+		delta := v - prev
+		prev = v
+		w.writeUnary(delta >> p)
+		w.writeBits(delta&(uint64(1)<<p-1), p)
+	}
+	gcs.data = w.data
+	return gcs, nil
+}
+
+// DecodeGolombCodedSet decodes a Golomb-coded set encoded with MarshalBinary.
+// The hasher must be the same used to build the set. If nil, the encoded hasher is used,
+// which must be registered with RegisterHasher, unless it is a hasher provided by this package.
+func DecodeGolombCodedSet(data []byte, hasher Hasher) (*GolombCodedSet, error) {
+	params, payload, err := decodeFilter(filterTypeGolomb, data)
+	if err != nil {
+		return nil, err
+	}
+	r := byteReader{data: params}
+	n := r.uvarint()
+	p := r.uvarint()
+	name := r.bytes()
+	state := r.bytes()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if p == 0 || p > 32 {
+		return nil, fmt.Errorf("%w: invalid parameter %d", ErrInvalidEncoding, p)
+	}
+	if hasher == nil {
+		hasher, err = unmarshalHasher(string(name), state)
+		if err != nil {
+			return nil, err
+		}
+	}
+	gcs := newGolombCodedSet(n, uint8(p), hasher)
+	gcs.data = append([]byte(nil), payload...)
+	return gcs, nil
+}
+
+func newGolombCodedSet(n uint64, p uint8, hasher Hasher) *GolombCodedSet {
+	if hasher == nil {
+		hasher = NewMurMur3Hasher()
+	}
+	return &GolombCodedSet{
+		n:      n,
+		p:      p,
+		hasher: hasher,
+		pool: sync.Pool{
+			New: func() any { return hasher.GetHashes(1)[0] },
+		},
+	}
+}
+
+// reduce maps a 64-bit hash to the range [0, n*2^p).
+func (gcs *GolombCodedSet) reduce(h uint64) uint64 {
+	hi, _ := bits.Mul64(h, gcs.n<<gcs.p)
+	return hi
+}
+
+// MarshalBinary encodes the set with the wire format.
+// The parameter block holds the number of items, the Golomb-Rice parameter, and the hasher.
+// The payload is the coded deltas. The hasher must implement NamedHasher.
+func (gcs *GolombCodedSet) MarshalBinary() ([]byte, error) {
+	params := binary.AppendUvarint(nil, gcs.n)
+	params = binary.AppendUvarint(params, uint64(gcs.p))
+	params, err := appendHasher(params, gcs.hasher)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFilter(filterTypeGolomb, params, gcs.data), nil
+}
+
+// Test checks if an item is in the set.
+func (gcs *GolombCodedSet) Test(data []byte) (bool, error) {
+	if gcs.n == 0 {
+		return false, nil
+	}
+	h := gcs.pool.Get().(hash.Hash64)
+	defer gcs.pool.Put(h)
+	h.Reset()
+	_, err := h.Write(data)
+	if err != nil {
+		return false, err
+	}
+	target := gcs.reduce(h.Sum64())
+
+	r := bitReader{data: gcs.data}
+	v := uint64(0)
+	for i := uint64(0); i < gcs.n; i++ {
+		q, err := r.readUnary()
+		if err != nil {
+			return false, err
+		}
+		rem, err := r.readBits(gcs.p)
+		if err != nil {
+			return false, err
+		}
+		v += q<<gcs.p | rem
+		if v == target {
+			return true, nil
+		}
+		if v > target {
+			return false, nil
+		}
+	}
+	return false, nil
+}
+
+// Len returns the number of items in the set.
+func (gcs *GolombCodedSet) Len() uint64 {
+	return gcs.n
+}
+
+// bitWriter writes bits MSB first.
+type bitWriter struct {
+	data []byte
+	n    uint64 // The number of bits written
+}
+
+func (w *bitWriter) writeBit(b bool) {
+	if w.n%8 == 0 {
+		w.data = append(w.data, 0)
+	}
+	if b {
+		w.data[len(w.data)-1] |= 1 << (7 - w.n%8)
+	}
+	w.n++
+}
+
+func (w *bitWriter) writeUnary(q uint64) {
+	for i := uint64(0); i < q; i++ {
+		w.writeBit(true)
+	}
+	w.writeBit(false)
+}
+
+func (w *bitWriter) writeBits(v uint64, n uint8) {
+	for i := int(n) - 1; i >= 0; i-- {
+		w.writeBit(v&(1<<i) != 0)
+	}
+}
+
+// bitReader reads bits MSB first.
+type bitReader struct {
+	data []byte
+	n    uint64 // The number of bits read
+}
+
+func (r *bitReader) readBit() (bool, error) {
+	if r.n/8 >= uint64(len(r.data)) {
+		return false, ErrInvalidEncoding
+	}
+	b := r.data[r.n/8]&(1<<(7-r.n%8)) != 0
+	r.n++
+	return b, nil
+}
+
+func (r *bitReader) readUnary() (uint64, error) {
+	q := uint64(0)
+	for {
+		b, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		if !b {
+			return q, nil
+		}
+		q++
+	}
+}
+
+func (r *bitReader) readBits(n uint8) (uint64, error) {
+	v := uint64(0)
+	for i := uint8(0); i < n; i++ {
+		b, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		v <<= 1
+		if b {
+			v |= 1
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,70 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGolombCodedSet_Test(t *testing.T) {
+	t.Parallel()
+	n := 10000
+	p := 1.0 / 1024
+	items := make([][]byte, n)
+	for i := range items {
+		items[i] = []byte(fmt.Sprintf("item-%d", i))
+	}
+	gcs, err := NewGolombCodedSet(items, ParamsGolomb{FalsePositiveRate: p})
+	assert.NoError(t, err, "Failed to create Golomb-coded set")
+	assert.Equal(t, uint64(n), gcs.Len())
+	assert.Less(t, len(gcs.data)*8, n*(10+3), "Expected about P+2 bits per item")
+
+	for _, item := range items {
+		b, err := gcs.Test(item)
+		assert.NoError(t, err)
+		assert.True(t, b, "Item '%s' should be in the set", item)
+	}
+
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		b, err := gcs.Test([]byte(fmt.Sprintf("different-item-%d", i)))
+		assert.NoError(t, err)
+		if b {
+			falsePositives++
+		}
+	}
+	assert.LessOrEqual(t, float64(falsePositives)/float64(n), p*1.5)
+}
+
+func TestGolombCodedSet_Serialization(t *testing.T) {
+	t.Parallel()
+	items := [][]byte{[]byte("foo"), []byte("bar"), []byte("baz")}
+	gcs, err := NewGolombCodedSet(items, ParamsGolomb{FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Golomb-coded set")
+
+	data, err := gcs.MarshalBinary()
+	assert.NoError(t, err)
+	decoded, err := DecodeGolombCodedSet(data, nil)
+	assert.NoError(t, err)
+
+	for _, item := range items {
+		b, err := decoded.Test(item)
+		assert.NoError(t, err)
+		assert.True(t, b, "Item '%s' should be in the decoded set", item)
+	}
+	b, err := decoded.Test([]byte("qux"))
+	assert.NoError(t, err)
+	assert.False(t, b)
+
+	_, err = DecodeGolombCodedSet([]byte{}, nil)
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestBuildGolombCodedSet_Unsorted(t *testing.T) {
+	t.Parallel()
+	_, err := BuildGolombCodedSet([]uint64{2, 1}, 8, nil)
+	assert.Error(t, err)
+	_, err = BuildGolombCodedSet([]uint64{1, 2}, 0, nil)
+	assert.Error(t, err)
+}
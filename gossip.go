@@ -0,0 +1,210 @@
+package gobloom
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Gossiper keeps a Bloom filter in sync with the filters of other processes, its peers, without a
+// central server. On every interval, it pushes to each peer the changes of the filter since the last
+// successful push, which the peer ORs into its filter. Changes received from a peer are pushed to the
+// other peers in turn, so the filters of a cluster whose peers are connected converge to the union
+// of the items added on every node.
+//
+// The first push to a peer, and the push after a failed one, send the whole filter, since the peer
+// may have been restarted. The filters must have the same m, k and hasher.
+//
+// The protocol is a TCP connection per push, carrying a message kind byte, followed by the length
+// of the message as a uvarint and the message, which is the filter encoded with MarshalBinary for a
+// full push, or a diff returned by Diff.
+type Gossiper struct {
+	bf       *BloomFilter
+	listener net.Listener
+	interval time.Duration
+	maxSize  uint64 // The maximum size of a received message
+
+	mu    sync.Mutex            // Serializes pushes and guards sent
+	peers []string              // The addresses of the peers
+	sent  map[string]Generation // The generation of the last successful push to each peer
+
+	stop chan struct{}
+	done sync.WaitGroup
+	once sync.Once
+}
+
+// ParamsGossip represents the parameters for synchronizing a Bloom filter with its peers.
+type ParamsGossip struct {
+	// Addr is the TCP address to listen on for pushes from the peers, like ":7946".
+	Addr string
+	// Peers are the TCP addresses of the peers to push the changes to.
+	Peers []string
+	// Interval is the time between pushes. Defaults to 1 second.
+	Interval time.Duration
+}
+
+// Message kinds of the gossip protocol.
+const (
+	gossipFull byte = 1 // The whole filter
+	gossipDiff byte = 2 // The changes since the last push
+)
+
+// NewGossiper starts listening for pushes from the peers and pushing the changes of the filter to them.
+// Close must be called to stop synchronizing once the filter is no longer used.
+func NewGossiper(bf *BloomFilter, p ParamsGossip) (*Gossiper, error) {
+	if p.Interval == 0 {
+		p.Interval = time.Second
+	}
+	if p.Interval < 0 {
+		return nil, fmt.Errorf("invalid interval, must be greater than 0, got %s", p.Interval)
+	}
+	l, err := net.Listen("tcp", p.Addr)
+	if err != nil {
+		return nil, err
+	}
+	bf.Generation() // Start tracking the changes
+	g := &Gossiper{
+		bf:       bf,
+		listener: l,
+		interval: p.Interval,
+		// An encoded filter is its words plus a small header, and a diff also has the block indexes.
+		maxSize: 1024 + 16*uint64(len(bf.bitSet)),
+		peers:   append([]string(nil), p.Peers...),
+		sent:    make(map[string]Generation),
+		stop:    make(chan struct{}),
+	}
+	g.done.Add(2)
+	go g.serve()
+	go g.run()
+	return g, nil
+}
+
+// Addr returns the address the gossiper listens on.
+func (g *Gossiper) Addr() net.Addr {
+	return g.listener.Addr()
+}
+
+// AddPeer adds a peer to push the changes to, starting with the whole filter.
+func (g *Gossiper) AddPeer(addr string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.peers = append(g.peers, addr)
+}
+
+// Close stops synchronizing the filter. The filter can still be used.
+func (g *Gossiper) Close() error {
+	var err error
+	g.once.Do(func() {
+		close(g.stop)
+		err = g.listener.Close()
+		g.done.Wait()
+	})
+	return err
+}
+
+// run pushes the changes to the peers on every interval, until Close is called.
+func (g *Gossiper) run() {
+	defer g.done.Done()
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// Failed pushes are retried with the whole filter on the next interval.
+			_ = g.Push()
+		case <-g.stop:
+			return
+		}
+	}
+}
+
+// Push pushes the changes of the filter to every peer now, instead of waiting for the next interval.
+// It returns the errors of the pushes that failed.
+func (g *Gossiper) Push() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var errs []error
+	for _, peer := range g.peers {
+		// Changes made from now on are stamped with a later generation, so none are missed.
+		next := g.bf.Generation()
+		kind, msg, err := gossipFull, []byte(nil), error(nil)
+		if since, ok := g.sent[peer]; ok {
+			kind, msg = gossipDiff, g.bf.Diff(since)
+		} else if msg, err = g.bf.MarshalBinary(); err != nil {
+			return err
+		}
+		if err := g.send(peer, kind, msg); err != nil {
+			delete(g.sent, peer) // The peer may have been restarted, so it gets the whole filter next time
+			errs = append(errs, fmt.Errorf("peer %s: %w", peer, err))
+			continue
+		}
+		g.sent[peer] = next
+	}
+	return errors.Join(errs...)
+}
+
+// send sends a message to the peer.
+func (g *Gossiper) send(peer string, kind byte, msg []byte) error {
+	conn, err := net.DialTimeout("tcp", peer, g.interval)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(g.interval))
+	header := binary.AppendUvarint([]byte{kind}, uint64(len(msg)))
+	if _, err := conn.Write(append(header, msg...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// serve accepts the pushes of the peers, until Close is called.
+func (g *Gossiper) serve() {
+	defer g.done.Done()
+	for {
+		conn, err := g.listener.Accept()
+		if err != nil {
+			return // The listener was closed
+		}
+		// Pushes are small and applied under the filter lock, so they are handled one at a time.
+		_ = g.receive(conn)
+	}
+}
+
+// receive applies a message pushed by a peer.
+func (g *Gossiper) receive(conn net.Conn) error {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(g.interval))
+	r := bufio.NewReader(conn)
+	kind, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+	if size > g.maxSize {
+		return fmt.Errorf("%w: message of %d bytes is too large", ErrInvalidEncoding, size)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return err
+	}
+	switch kind {
+	case gossipFull:
+		var other BloomFilter
+		if err := other.UnmarshalBinary(msg); err != nil {
+			return err
+		}
+		return g.bf.Union(&other)
+	case gossipDiff:
+		return g.bf.ApplyDiff(msg)
+	}
+	return fmt.Errorf("%w: unknown message kind %d", ErrInvalidEncoding, kind)
+}
@@ -0,0 +1,69 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newGossipNode(t *testing.T) (*BloomFilter, *Gossiper) {
+	t.Helper()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	g, err := NewGossiper(bf, ParamsGossip{Addr: "127.0.0.1:0", Interval: time.Hour})
+	assert.NoError(t, err, "Failed to start gossiper")
+	t.Cleanup(func() { _ = g.Close() })
+	return bf, g
+}
+
+func TestGossiper_Converges(t *testing.T) {
+	t.Parallel()
+	// The nodes form a ring, so changes reach the last node through the others.
+	a, ga := newGossipNode(t)
+	b, gb := newGossipNode(t)
+	c, gc := newGossipNode(t)
+	ga.AddPeer(gb.Addr().String())
+	gb.AddPeer(gc.Addr().String())
+	gc.AddPeer(ga.Addr().String())
+
+	assert.NoError(t, a.Add([]byte("before-sync"))) // Sent in the first, whole filter push
+	push := func() {
+		for _, g := range []*Gossiper{ga, gb, gc} {
+			assert.NoError(t, g.Push())
+		}
+	}
+	push()
+	for i := 0; i < 30; i++ {
+		assert.NoError(t, []*BloomFilter{a, b, c}[i%3].Add([]byte(strconv.Itoa(i))))
+	}
+	assert.Eventually(t, func() bool {
+		push()
+		for _, bf := range []*BloomFilter{a, b, c} {
+			for _, item := range []string{"before-sync", "0", "1", "2", "29"} {
+				if present, _ := bf.Test([]byte(item)); !present {
+					return false
+				}
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond, "Every node should have every item")
+	assert.Equal(t, a.Bits(), b.Bits())
+	assert.Equal(t, a.Bits(), c.Bits())
+}
+
+func TestGossiper_UnreachablePeer(t *testing.T) {
+	t.Parallel()
+	_, g := newGossipNode(t)
+	_, other := newGossipNode(t)
+	addr := other.Addr().String()
+	assert.NoError(t, other.Close())
+	g.AddPeer(addr)
+	assert.Error(t, g.Push(), "Pushing to a stopped peer should fail")
+	_, ok := g.sent[addr]
+	assert.False(t, ok, "The peer should get the whole filter on the next push")
+
+	_, err := NewGossiper(&BloomFilter{}, ParamsGossip{Addr: "127.0.0.1:0", Interval: -time.Second})
+	assert.Error(t, err)
+}
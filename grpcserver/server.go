@@ -0,0 +1,222 @@
+// Package grpcserver serves named gobloom Bloom filters over gRPC, implementing the FilterService of
+// gobloompb, so services in any language can share one centralized filter. The gobloomd command runs it,
+// and gobloompb.NewFilterServiceClient creates its client.
+package grpcserver
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/franciscoescher/gobloom"
+	"github.com/franciscoescher/gobloom/gobloompb"
+)
+
+// DefaultMaxBits is the default maximum number of bits of a filter, so a filter takes at most 512 MiB.
+const DefaultMaxBits = 1 << 32
+
+// DefaultMaxHashFunctions is the default maximum number of hash functions of a filter, the number of a filter
+// created for a false positive rate of about 5e-20.
+const DefaultMaxHashFunctions = 64
+
+// Server is a gobloompb.FilterServiceServer serving named Bloom filters.
+type Server struct {
+	gobloompb.UnimplementedFilterServiceServer
+
+	maxBits uint64 // The maximum number of bits of a filter
+	maxK    uint64 // The maximum number of hash functions of a filter
+
+	mu      sync.RWMutex            // Guards filters
+	filters map[string]*namedFilter // The filters by name
+}
+
+var _ gobloompb.FilterServiceServer = (*Server)(nil)
+
+// namedFilter is a filter served by the server.
+type namedFilter struct {
+	bf *gobloom.BloomFilter
+	// testAndAdd serializes the TestAndAdd calls, so concurrent calls with the same item don't both
+	// report it as absent. Add and Test don't need it.
+	testAndAdd sync.Mutex
+}
+
+// Option configures a server.
+type Option func(*Server)
+
+// WithMaxBits sets the maximum number of bits of a filter, DefaultMaxBits by default.
+// Larger filters are rejected with InvalidArgument, so a request cannot exhaust the memory of the server.
+func WithMaxBits(m uint64) Option {
+	return func(s *Server) { s.maxBits = m }
+}
+
+// WithMaxHashFunctions sets the maximum number of hash functions of a filter, DefaultMaxHashFunctions by default.
+// Filters with more are rejected with InvalidArgument.
+func WithMaxHashFunctions(k uint64) Option {
+	return func(s *Server) { s.maxK = k }
+}
+
+// New creates a server without filters.
+func New(opts ...Option) *Server {
+	s := &Server{
+		maxBits: DefaultMaxBits,
+		maxK:    DefaultMaxHashFunctions,
+		filters: make(map[string]*namedFilter),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// newFilter creates a Bloom filter with the lock type of the served filters.
+func newFilter(n uint64, fp float64, hasher string) (*gobloom.BloomFilter, error) {
+	f, err := gobloom.Config{N: n, FalsePositiveRate: fp, Hasher: hasher, LockType: "read_write"}.New()
+	if err != nil {
+		return nil, err
+	}
+	return f.(*gobloom.BloomFilter), nil
+}
+
+// checkLimits returns InvalidArgument if a filter with m bits and k hash functions exceeds the limits of the server.
+func (s *Server) checkLimits(m, k uint64) error {
+	if m > s.maxBits {
+		return status.Errorf(codes.InvalidArgument, "filter of %d bits exceeds the maximum of %d", m, s.maxBits)
+	}
+	if k > s.maxK {
+		return status.Errorf(codes.InvalidArgument, "filter with %d hash functions exceeds the maximum of %d", k, s.maxK)
+	}
+	return nil
+}
+
+// filter returns the filter with the given name.
+func (s *Server) filter(name string) (*namedFilter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f := s.filters[name]
+	if f == nil {
+		return nil, status.Errorf(codes.NotFound, "filter %q not found", name)
+	}
+	return f, nil
+}
+
+// CreateFilter creates a named filter, failing with AlreadyExists if the name is taken, and with
+// InvalidArgument if the filter exceeds the limits of the server.
+func (s *Server) CreateFilter(_ context.Context, req *gobloompb.CreateFilterRequest) (*gobloompb.CreateFilterResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "filter name cannot be empty")
+	}
+	// The size is checked before the filter is allocated. Invalid parameters have no size, and fail below.
+	if m, _, k := gobloom.EstimateMemory(req.GetN(), req.GetFalsePositiveRate()); m > 0 {
+		if err := s.checkLimits(m, k); err != nil {
+			return nil, err
+		}
+	}
+	bf, err := newFilter(req.GetN(), req.GetFalsePositiveRate(), req.GetHasher())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.filters[req.GetName()] != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "filter %q already exists", req.GetName())
+	}
+	s.filters[req.GetName()] = &namedFilter{bf: bf}
+	return &gobloompb.CreateFilterResponse{}, nil
+}
+
+// Add adds an item to a filter.
+func (s *Server) Add(_ context.Context, req *gobloompb.AddRequest) (*gobloompb.AddResponse, error) {
+	f, err := s.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if err := f.bf.Add(req.GetItem()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &gobloompb.AddResponse{}, nil
+}
+
+// Test checks if an item is in a filter.
+func (s *Server) Test(_ context.Context, req *gobloompb.TestRequest) (*gobloompb.TestResponse, error) {
+	f, err := s.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	present, err := f.bf.Test(req.GetItem())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &gobloompb.TestResponse{Present: present}, nil
+}
+
+// TestAndAdd checks if an item is in a filter, and adds it.
+func (s *Server) TestAndAdd(_ context.Context, req *gobloompb.TestAndAddRequest) (*gobloompb.TestAndAddResponse, error) {
+	f, err := s.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	f.testAndAdd.Lock()
+	defer f.testAndAdd.Unlock()
+	present, err := f.bf.Test(req.GetItem())
+	if err == nil && !present {
+		err = f.bf.Add(req.GetItem())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &gobloompb.TestAndAddResponse{Present: present}, nil
+}
+
+// Info returns the parameters and statistics of a filter.
+func (s *Server) Info(_ context.Context, req *gobloompb.InfoRequest) (*gobloompb.InfoResponse, error) {
+	f, err := s.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	stats := f.bf.Stats()
+	return &gobloompb.InfoResponse{
+		M:                          stats.Bits,
+		K:                          stats.K,
+		SetBits:                    stats.SetBits,
+		EstimatedItems:             stats.EstimatedItems,
+		EstimatedFalsePositiveRate: stats.EstimatedFalsePositiveRate,
+	}, nil
+}
+
+// Dump returns a filter, to back it up or move it to another server.
+func (s *Server) Dump(_ context.Context, req *gobloompb.DumpRequest) (*gobloompb.DumpResponse, error) {
+	f, err := s.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	p, err := f.bf.ToProto()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &gobloompb.DumpResponse{Filter: p}, nil
+}
+
+// Restore replaces or creates a named filter with a dumped one, failing with InvalidArgument if it exceeds
+// the limits of the server.
+func (s *Server) Restore(_ context.Context, req *gobloompb.RestoreRequest) (*gobloompb.RestoreResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "filter name cannot be empty")
+	}
+	if err := s.checkLimits(req.GetFilter().GetM(), req.GetFilter().GetK()); err != nil {
+		return nil, err
+	}
+	// The dumped filter replaces a small one, so it gets the lock type of the served filters.
+	bf, err := newFilter(1, 0.5, "")
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := bf.UnmarshalProto(req.GetFilter()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	s.mu.Lock()
+	s.filters[req.GetName()] = &namedFilter{bf: bf}
+	s.mu.Unlock()
+	return &gobloompb.RestoreResponse{}, nil
+}
@@ -0,0 +1,120 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/franciscoescher/gobloom/gobloompb"
+)
+
+// dial serves a new server over an in-memory connection, and returns a client connected to it.
+func dial(t *testing.T, opts ...Option) gobloompb.FilterServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	gobloompb.RegisterFilterServiceServer(srv, New(opts...))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial server")
+	t.Cleanup(func() { _ = conn.Close() })
+	return gobloompb.NewFilterServiceClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := dial(t)
+
+	_, err := client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{Name: "seen", N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	_, err = client.Add(ctx, &gobloompb.AddRequest{Name: "seen", Item: []byte("a")})
+	assert.NoError(t, err)
+
+	test, err := client.Test(ctx, &gobloompb.TestRequest{Name: "seen", Item: []byte("a")})
+	assert.NoError(t, err)
+	assert.True(t, test.GetPresent())
+	test, err = client.Test(ctx, &gobloompb.TestRequest{Name: "seen", Item: []byte("b")})
+	assert.NoError(t, err)
+	assert.False(t, test.GetPresent())
+
+	testAndAdd, err := client.TestAndAdd(ctx, &gobloompb.TestAndAddRequest{Name: "seen", Item: []byte("b")})
+	assert.NoError(t, err)
+	assert.False(t, testAndAdd.GetPresent(), "The item should be absent the first time")
+	testAndAdd, err = client.TestAndAdd(ctx, &gobloompb.TestAndAddRequest{Name: "seen", Item: []byte("b")})
+	assert.NoError(t, err)
+	assert.True(t, testAndAdd.GetPresent(), "The item should be present the second time")
+
+	info, err := client.Info(ctx, &gobloompb.InfoRequest{Name: "seen"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9586), info.GetM())
+	assert.Equal(t, uint64(7), info.GetK())
+	assert.Equal(t, uint64(2), info.GetEstimatedItems())
+
+	// A dump restores the filter under another name.
+	dump, err := client.Dump(ctx, &gobloompb.DumpRequest{Name: "seen"})
+	assert.NoError(t, err)
+	_, err = client.Restore(ctx, &gobloompb.RestoreRequest{Name: "copy", Filter: dump.GetFilter()})
+	assert.NoError(t, err)
+	test, err = client.Test(ctx, &gobloompb.TestRequest{Name: "copy", Item: []byte("b")})
+	assert.NoError(t, err)
+	assert.True(t, test.GetPresent(), "Restored filters should keep their items")
+}
+
+func TestServer_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := dial(t)
+
+	_, err := client.Test(ctx, &gobloompb.TestRequest{Name: "unknown", Item: []byte("a")})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{Name: "f", N: 1000, FalsePositiveRate: 2})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{Name: "f", N: 1000, FalsePositiveRate: 0.01, Hasher: "unknown"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{N: 1000, FalsePositiveRate: 0.01})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{Name: "f", N: 1000, FalsePositiveRate: 0.01, Hasher: "xxhash"})
+	assert.NoError(t, err)
+	_, err = client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{Name: "f", N: 1000, FalsePositiveRate: 0.01})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = client.Restore(ctx, &gobloompb.RestoreRequest{Name: "f", Filter: &gobloompb.BloomFilter{M: 128, K: 3}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_Limits(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := dial(t, WithMaxBits(1<<20), WithMaxHashFunctions(10))
+
+	_, err := client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{Name: "large", N: 1 << 30, FalsePositiveRate: 0.01})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "Filters with too many bits should be rejected")
+	_, err = client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{Name: "precise", N: 1000, FalsePositiveRate: 1e-6})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "Filters with too many hash functions should be rejected")
+	_, err = client.CreateFilter(ctx, &gobloompb.CreateFilterRequest{Name: "f", N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+
+	_, err = client.Restore(ctx, &gobloompb.RestoreRequest{Name: "f", Filter: &gobloompb.BloomFilter{M: 64, K: 1 << 40,
+		Hasher: &gobloompb.Hasher{Name: "murmur3"}, Bits: make([]byte, 8)}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "Filters with a huge k should be rejected")
+	_, err = client.Restore(ctx, &gobloompb.RestoreRequest{Name: "f", Filter: &gobloompb.BloomFilter{M: 1 << 21, K: 3,
+		Hasher: &gobloompb.Hasher{Name: "murmur3"}, Bits: make([]byte, 1<<18)}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "Filters with too many bits should be rejected")
+
+	info, err := client.Info(ctx, &gobloompb.InfoRequest{Name: "f"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9586), info.GetM(), "Rejected filters should not replace the existing one")
+}
@@ -0,0 +1,212 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+
+	"github.com/spaolacci/murmur3"
+)
+
+const guavaHasherName = "guava"
+
+// GuavaStrategy is the strategy of a Guava BloomFilter, which derives the probe positions of an item
+// from its 128-bit murmur3 hash. Its values are the ordinals of the strategies in Guava.
+type GuavaStrategy uint8
+
+const (
+	// GuavaMurmur128Mitz32 is the MURMUR128_MITZ_32 strategy, used by Guava before version 13.
+	GuavaMurmur128Mitz32 GuavaStrategy = 0
+	// GuavaMurmur128Mitz64 is the MURMUR128_MITZ_64 strategy, the default since Guava 13.
+	GuavaMurmur128Mitz64 GuavaStrategy = 1
+)
+
+// GuavaHasher is a hasher compatible with the BloomFilter of the Guava Java library, with the given strategy.
+// Guava hashes items through a funnel: the items of a filter of byte arrays, or of strings funneled with
+// Funnels.stringFunnel(UTF_8), are hashed as their bytes, while Funnels.integerFunnel and Funnels.longFunnel
+// hash the little-endian bytes of the number.
+type GuavaHasher struct {
+	strategy GuavaStrategy
+}
+
+var (
+	_ NamedHasher = (*GuavaHasher)(nil)
+	_ Hasher64    = (*GuavaHasher)(nil)
+)
+
+// NewGuavaHasher creates a GuavaHasher with the given strategy.
+func NewGuavaHasher(strategy GuavaStrategy) *GuavaHasher {
+	return &GuavaHasher{strategy: strategy}
+}
+
+// Strategy returns the strategy of the hasher.
+func (h *GuavaHasher) Strategy() GuavaStrategy {
+	return h.strategy
+}
+
+func (h *GuavaHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashers := make([]hash.Hash64, n)
+	for i := range hashers {
+		hashers[i] = &guavaHash{h: h, i: i}
+	}
+	return hashers
+}
+
+func (h *GuavaHasher) HashK(data []byte, out []uint64) {
+	h1, h2 := murmur3.Sum128(data)
+	if h.strategy == GuavaMurmur128Mitz32 {
+		// The 32-bit halves of the first 64 bits of the hash are combined with Java int arithmetic.
+		hash1, hash2 := int32(h1), int32(h1>>32)
+		for i := range out {
+			combined := hash1 + int32(i+1)*hash2
+			if combined < 0 {
+				combined = ^combined
+			}
+			out[i] = uint64(combined)
+		}
+		return
+	}
+	combined := h1
+	for i := range out {
+		out[i] = combined & math.MaxInt64
+		combined += h2
+	}
+}
+
+func (h *GuavaHasher) Name() string {
+	return guavaHasherName
+}
+
+// MarshalBinary encodes the strategy of the hasher, the default strategy is encoded as no state.
+func (h *GuavaHasher) MarshalBinary() ([]byte, error) {
+	if h.strategy == GuavaMurmur128Mitz64 {
+		return nil, nil
+	}
+	return []byte{byte(h.strategy)}, nil
+}
+
+// UnmarshalBinary restores the strategy of the hasher encoded with MarshalBinary.
+func (h *GuavaHasher) UnmarshalBinary(data []byte) error {
+	switch {
+	case len(data) == 0:
+		h.strategy = GuavaMurmur128Mitz64
+	case len(data) == 1 && GuavaStrategy(data[0]) <= GuavaMurmur128Mitz64:
+		h.strategy = GuavaStrategy(data[0])
+	default:
+		return fmt.Errorf("%w: invalid guava hasher state", ErrInvalidEncoding)
+	}
+	return nil
+}
+
+// guavaHash is a hash.Hash64 whose sum is the i-th probe position of the written data,
+// before it is reduced to the size of the bit set.
+type guavaHash struct {
+	h    *GuavaHasher
+	i    int
+	data []byte
+}
+
+func (h *guavaHash) Write(p []byte) (int, error) {
+	h.data = append(h.data, p...)
+	return len(p), nil
+}
+
+func (h *guavaHash) Sum64() uint64 {
+	out := make([]uint64, h.i+1)
+	h.h.HashK(h.data, out)
+	return out[h.i]
+}
+
+func (h *guavaHash) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func (h *guavaHash) Reset() {
+	h.data = h.data[:0]
+}
+
+func (h *guavaHash) Size() int {
+	return 8
+}
+
+func (h *guavaHash) BlockSize() int {
+	return 1
+}
+
+// NewGuava creates a new Bloom filter sized like a Guava BloomFilter created with BloomFilter.create(funnel,
+// n, p, strategy), using a GuavaHasher, so it can be exported with ExportGuava. Guava rounds the number of
+// bits up to whole 64-bit words, and uses them all.
+func NewGuava(p Params, strategy GuavaStrategy) (*BloomFilter, error) {
+	if strategy > GuavaMurmur128Mitz64 {
+		return nil, fmt.Errorf("unknown guava strategy %d", strategy)
+	}
+	if p.Hasher != nil {
+		return nil, fmt.Errorf("guava filters use a GuavaHasher, the hasher must not be set")
+	}
+	p.Hasher = NewGuavaHasher(strategy)
+	applyDefaults(&p)
+	if err := validateParams(p); err != nil {
+		return nil, err
+	}
+	bits := uint64(-float64(p.N) * math.Log(p.FalsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := max(uint64(math.Round(float64(bits)/float64(p.N)*math.Ln2)), 1)
+	if k > math.MaxUint8 {
+		return nil, fmt.Errorf("guava filters have at most %d hash functions, got %d", math.MaxUint8, k)
+	}
+	return newBloomFilter(64*((max(bits, 1)+63)/64), k, p)
+}
+
+// ImportGuava reads a Bloom filter written by the writeTo method of the BloomFilter of the Guava Java library,
+// so filters built on the JVM can be queried in Go. The filter uses a GuavaHasher with the strategy of the
+// Guava filter, and ExclusiveLock.
+func ImportGuava(r io.Reader) (*BloomFilter, error) {
+	var header struct {
+		Strategy uint8
+		K        uint8
+		Words    int32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, err
+	}
+	if GuavaStrategy(header.Strategy) > GuavaMurmur128Mitz64 || header.K == 0 || header.Words <= 0 {
+		return nil, fmt.Errorf("%w: invalid parameters strategy=%d, k=%d, words=%d",
+			ErrInvalidEncoding, header.Strategy, header.K, header.Words)
+	}
+	bitSet, err := readWordsFrom(r, binary.BigEndian, uint64(header.Words))
+	if err != nil {
+		return nil, err
+	}
+	bf := &BloomFilter{}
+	if err := bf.restore(64*uint64(header.Words), uint64(header.K), NewGuavaHasher(GuavaStrategy(header.Strategy)), bitSet); err != nil {
+		return nil, err
+	}
+	return bf, nil
+}
+
+// ExportGuava writes the Bloom filter in the format read by the readFrom method of the BloomFilter of the
+// Guava Java library. The filter must use a GuavaHasher, and have a whole number of 64-bit words of bits,
+// like the filters created with NewGuava or imported with ImportGuava.
+func (bf *BloomFilter) ExportGuava(w io.Writer) error {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	h, ok := bf.hasher.(*GuavaHasher)
+	if !ok {
+		return fmt.Errorf("hasher %T is not compatible, the filter must use GuavaHasher", bf.hasher)
+	}
+	if bf.m != 64*uint64(len(bf.bitSet)) || len(bf.bitSet) > math.MaxInt32 {
+		return fmt.Errorf("guava filters have a whole number of 64-bit words, the filter has %d bits", bf.m)
+	}
+	if bf.k > math.MaxUint8 {
+		return fmt.Errorf("guava filters have at most %d hash functions, the filter has %d", math.MaxUint8, bf.k)
+	}
+	header := []byte{byte(h.strategy), byte(bf.k)}
+	header = binary.BigEndian.AppendUint32(header, uint32(len(bf.bitSet)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, bf.bitSet)
+}
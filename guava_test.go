@@ -0,0 +1,93 @@
+package gobloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/spaolacci/murmur3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuava_ExportImport(t *testing.T) {
+	t.Parallel()
+	for _, strategy := range []GuavaStrategy{GuavaMurmur128Mitz32, GuavaMurmur128Mitz64} {
+		bf, err := NewGuava(Params{N: 1000, FalsePositiveRate: 0.01}, strategy)
+		assert.NoError(t, err, "Failed to create guava filter")
+		assert.Zero(t, bf.M()%64, "Guava filters should use whole words")
+		for i := 0; i < 1000; i++ {
+			assert.NoError(t, bf.Add([]byte("item-"+strconv.Itoa(i))))
+		}
+
+		var buf bytes.Buffer
+		assert.NoError(t, bf.ExportGuava(&buf))
+		data := buf.Bytes()
+		assert.Equal(t, byte(strategy), data[0], "The strategy should be its ordinal")
+		assert.Equal(t, byte(bf.K()), data[1])
+		assert.Equal(t, uint32(bf.M()/64), binary.BigEndian.Uint32(data[2:6]))
+		assert.Equal(t, bf.Bits()[0], binary.BigEndian.Uint64(data[6:14]), "Words should be big-endian longs")
+
+		imported, err := ImportGuava(bytes.NewReader(data))
+		assert.NoError(t, err, "Failed to import guava filter")
+		assert.Equal(t, bf.M(), imported.M())
+		assert.Equal(t, bf.K(), imported.K())
+		for i := 0; i < 1000; i++ {
+			b, _ := imported.Test([]byte("item-" + strconv.Itoa(i)))
+			assert.True(t, b, "Imported filter should contain the added items")
+		}
+	}
+}
+
+func TestGuavaHasher_Mitz32(t *testing.T) {
+	t.Parallel()
+	// MURMUR128_MITZ_32 combines the 32-bit halves of the hash with wrapping int arithmetic,
+	// and flips the bits of negative results.
+	data := []byte("guava")
+	h1, _ := murmur3.Sum128(data)
+	hash1, hash2 := int32(h1), int32(h1>>32)
+	out := make([]uint64, 5)
+	NewGuavaHasher(GuavaMurmur128Mitz32).HashK(data, out)
+	for i, got := range out {
+		combined := int64(hash1) + int64(i+1)*int64(hash2)
+		want := int32(combined) // Java int overflow
+		if want < 0 {
+			want = ^want
+		}
+		assert.Equal(t, uint64(want), got)
+		assert.Less(t, got, uint64(1)<<31, "Positions should be non-negative ints")
+	}
+}
+
+func TestGuavaHasher_Serialization(t *testing.T) {
+	t.Parallel()
+	bf, _ := NewGuava(Params{N: 100, FalsePositiveRate: 0.01}, GuavaMurmur128Mitz32)
+	assert.NoError(t, bf.Add([]byte("item")))
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded BloomFilter
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	h, ok := decoded.hasher.(*GuavaHasher)
+	assert.True(t, ok, "Decoded filter should use a GuavaHasher")
+	assert.Equal(t, GuavaMurmur128Mitz32, h.Strategy())
+	b, _ := decoded.Test([]byte("item"))
+	assert.True(t, b)
+}
+
+func TestGuava_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := NewGuava(Params{N: 100, FalsePositiveRate: 0.01}, 2)
+	assert.Error(t, err, "Unknown strategies should be rejected")
+	_, err = NewGuava(Params{N: 100, FalsePositiveRate: 0.01, Hasher: NewXXHasher()}, GuavaMurmur128Mitz64)
+	assert.Error(t, err)
+
+	bf, _ := New(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.Error(t, bf.ExportGuava(&bytes.Buffer{}), "Filters not using a GuavaHasher should not be exported")
+
+	_, err = ImportGuava(bytes.NewReader([]byte{5, 3, 0, 0, 0, 1}))
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+	_, err = ImportGuava(bytes.NewReader([]byte{1, 3, 0, 0, 0, 2, 0, 0}))
+	assert.Error(t, err, "Truncated filters should be rejected")
+	_, err = ImportGuava(bytes.NewReader([]byte{1, 3, 0x7f, 0xff, 0xff, 0xff}))
+	assert.Error(t, err, "Headers claiming more words than the stream holds should be rejected")
+}
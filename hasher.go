@@ -0,0 +1,81 @@
+package gobloom
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	hashersMu sync.RWMutex
+	hashers   = map[string]func() Hasher{
+		murmur3HasherName:       func() Hasher { return NewMurMur3Hasher() },
+		bitsAndBloomsHasherName: func() Hasher { return NewBitsAndBloomsHasher() },
+		doubleHasherName:        func() Hasher { return NewDoubleHasher(nil) },
+		xxHasherName:            func() Hasher { return NewXXHasher() },
+		fnvHasherName:           func() Hasher { return NewFNVHasher() },
+		wyHasherName:            func() Hasher { return NewWyHasher() },
+		murmur3x128HasherName:   func() Hasher { return NewMurMur3x128Hasher() },
+		guavaHasherName:         func() Hasher { return NewGuavaHasher(GuavaMurmur128Mitz64) },
+		pybloomHasherName:       func() Hasher { return NewPybloomHasher(1, 1) },
+		cassandraHasherName:     func() Hasher { return NewCassandraHasher(false) },
+	}
+)
+
+// RegisterHasher registers a constructor for the hasher with the given name,
+// so filters using it can be deserialized.
+// The constructor must return a Hasher whose Name method returns name.
+func RegisterHasher(name string, newHasher func() Hasher) {
+	hashersMu.Lock()
+	defer hashersMu.Unlock()
+	hashers[name] = newHasher
+}
+
+// marshalHasher returns the name and the serialized state of the hasher.
+func marshalHasher(h Hasher) (string, []byte, error) {
+	nh, ok := h.(NamedHasher)
+	if !ok {
+		return "", nil, fmt.Errorf("hasher %T cannot be serialized, it must implement NamedHasher", h)
+	}
+	m, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nh.Name(), nil, nil
+	}
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return "", nil, err
+	}
+	return nh.Name(), state, nil
+}
+
+// unmarshalHasher returns the registered hasher with the given name, restored to the serialized state.
+func unmarshalHasher(name string, state []byte) (Hasher, error) {
+	hashersMu.RLock()
+	newHasher, ok := hashers[name]
+	hashersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown hasher %q, it must be registered with RegisterHasher", name)
+	}
+	h := newHasher()
+	if u, ok := h.(encoding.BinaryUnmarshaler); ok {
+		if err := u.UnmarshalBinary(state); err != nil {
+			return nil, err
+		}
+	} else if len(state) > 0 {
+		return nil, fmt.Errorf("hasher %q has state, but cannot unmarshal it", name)
+	}
+	return h, nil
+}
+
+// sameHasher reports whether two hashers produce the same hash functions, that is whether they are
+// the same value, or named hashers with the same name and state.
+func sameHasher(a, b Hasher) bool {
+	if reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b {
+		return true
+	}
+	nameA, stateA, errA := marshalHasher(a)
+	nameB, stateB, errB := marshalHasher(b)
+	return errA == nil && errB == nil && nameA == nameB && bytes.Equal(stateA, stateB)
+}
@@ -0,0 +1,67 @@
+package gobloom
+
+import (
+	"hash"
+	"reflect"
+	"sync"
+)
+
+// Hasher64 is a stateless hasher computing all the hashes of an item in a single call, without the
+// allocations and locking the stateful hash functions of Hasher need. Filters use it instead of GetHashes
+// when their hasher implements it. All the hashers provided by this package implement it.
+type Hasher64 interface {
+	// HashK sets out[i] to the i-th hash of data, the sum of the i-th hash function of GetHashes,
+	// for every i < len(out). It must be safe for concurrent use.
+	HashK(data []byte, out []uint64)
+}
+
+// asHasher64 returns the hasher as a Hasher64, adapting hashers that don't implement it.
+func asHasher64(h Hasher) Hasher64 {
+	if h64, ok := h.(Hasher64); ok {
+		return h64
+	}
+	return &statefulHasher64{hasher: h}
+}
+
+// statefulHasher64 adapts a Hasher to Hasher64, with a pool of hash functions
+// so concurrent calls don't share state.
+type statefulHasher64 struct {
+	hasher Hasher
+	pool   sync.Pool
+}
+
+func (h *statefulHasher64) HashK(data []byte, out []uint64) {
+	hashes, _ := h.pool.Get().(*[]hash.Hash64)
+	if hashes == nil || uint64(len(*hashes)) < uint64(len(out)) {
+		hs := h.hasher.GetHashes(uint64(len(out)))
+		hashes = &hs
+	}
+	for i := range out {
+		hs := (*hashes)[i]
+		hs.Reset()
+		_, _ = hs.Write(data) // hash.Hash writes never fail
+		out[i] = hs.Sum64()
+	}
+	h.pool.Put(hashes)
+}
+
+// sameHasher64 returns whether a and b are the same hasher instance, so the hashes of one can be used for the other.
+// Only pointers are compared, since comparing other types may panic.
+func sameHasher64(a, b Hasher64) bool {
+	return a != nil && b != nil && reflect.TypeOf(a).Kind() == reflect.Pointer && a == b
+}
+
+// probePool holds buffers for the hashes of an item, so adding and testing items doesn't allocate.
+var probePool = sync.Pool{
+	New: func() any { return new([]uint64) },
+}
+
+// getProbes returns a buffer of k hashes from the pool, to be returned with probePool.Put.
+func getProbes(k uint64) *[]uint64 {
+	probes := probePool.Get().(*[]uint64)
+	if uint64(cap(*probes)) < k {
+		*probes = make([]uint64, k)
+	}
+	*probes = (*probes)[:k]
+	return probes
+}
@@ -0,0 +1,86 @@
+package gobloom
+
+import (
+	"fmt"
+	"hash"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// statefulOnlyHasher hides the Hasher64 implementation of a hasher, to test the adapter.
+type statefulOnlyHasher struct {
+	hasher Hasher
+}
+
+func (h statefulOnlyHasher) GetHashes(n uint64) []hash.Hash64 {
+	return h.hasher.GetHashes(n)
+}
+
+func TestHasher64_MatchesGetHashes(t *testing.T) {
+	t.Parallel()
+	triple, err := NewDoubleHasherWithStrategy(NewMurMur3Hasher(), ProbeTriple)
+	assert.NoError(t, err)
+	enhanced, err := NewDoubleHasherWithStrategy(NewFNVHasher(), ProbeEnhancedDouble)
+	assert.NoError(t, err)
+	hashers := map[string]Hasher{
+		"MurMur3":           NewMurMur3HasherWithSeed(3),
+		"MurMur3x128":       NewMurMur3x128HasherWithSeed(3),
+		"BitsAndBlooms":     NewBitsAndBloomsHasher(),
+		"XX":                NewXXHasherWithSeed(3),
+		"FNV":               NewFNVHasherWithSeed(3),
+		"Wy":                NewWyHasherWithSeed(3),
+		"Double":            NewDoubleHasher(NewXXHasher()),
+		"DoubleTriple":      triple,
+		"DoubleEnhanced":    enhanced,
+		"StatefulAdapter":   statefulOnlyHasher{NewMurMur3Hasher()},
+		"DoubleOverAdapter": NewDoubleHasher(statefulOnlyHasher{NewWyHasher()}),
+	}
+	for name, hasher := range hashers {
+		hasher := hasher
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			for _, data := range [][]byte{nil, []byte("foo"), make([]byte, 100)} {
+				out := make([]uint64, 7)
+				asHasher64(hasher).HashK(data, out)
+				for i, h := range hasher.GetHashes(7) {
+					_, _ = h.Write(data)
+					assert.Equal(t, h.Sum64(), out[i], "Hash %d of %q should match GetHashes", i, data)
+				}
+			}
+		})
+	}
+}
+
+func TestBloomFilter_ConcurrentTest(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite, Hasher: statefulOnlyHasher{NewMurMur3Hasher()}})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				b, err := bf.Test([]byte(fmt.Sprintf("item-%d", i)))
+				assert.NoError(t, err)
+				assert.True(t, b, "Item %d should be present", i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkBloomFilter_Test(b *testing.B) {
+	bf, _ := New(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeNone})
+	data := []byte("benchmark-item")
+	_ = bf.Add(data)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = bf.Test(data)
+	}
+}
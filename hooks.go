@@ -0,0 +1,163 @@
+package gobloom
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// HookEvent is the information passed to the hooks of a filter. The fields not relevant to a hook are zero.
+type HookEvent struct {
+	Items     int     // The number of items added or tested by the operation
+	Positives int     // The number of the tested items reported as present, for OnTest
+	Added     uint64  // The number of items added to the filter so far, including duplicates
+	Capacity  uint64  // The number of items the filter was sized for, for OnSaturation
+	FillRatio float64 // The fraction of the bits of the filter that are set, for OnSaturation
+	Layers    int     // The new number of layers of a scalable filter, for OnScale
+}
+
+// Hooks are callbacks invoked with the lifecycle events of a filter, so applications can log, meter, or
+// audit them without wrapping every call site. Like the methods of an Observer, they are called synchronously,
+// possibly concurrently, after the operation, and must be fast. Nil hooks are skipped.
+type Hooks struct {
+	// OnAdd is called after items were added, by Add or AddMany.
+	OnAdd func(HookEvent)
+	// OnTest is called after items were tested.
+	OnTest func(HookEvent)
+	// OnScale is called after a scalable filter added a layer.
+	OnScale func(HookEvent)
+	// OnSaturation is called once, when more items were added to a Bloom filter than it was sized for,
+	// so its false positive rate exceeds the one it was created with, or when its fill ratio reaches
+	// SaturationThreshold if set. Scalable filters add a layer instead.
+	OnSaturation func(HookEvent)
+	// SaturationThreshold, if set, is the fill ratio of the Bloom filter at which OnSaturation is called,
+	// between 0 and 1. A filter sized with the optimal number of hash functions is half full at capacity,
+	// so a threshold below 0.5 gives the application a chance to rotate or resize it before its false
+	// positive rate degrades.
+	SaturationThreshold float64
+}
+
+// OnAdd calls fn after items were added, see Hooks. WithBitSetBackend is not supported.
+func OnAdd(fn func(HookEvent)) Option {
+	return func(o *options) { o.hooks.OnAdd = fn }
+}
+
+// OnTest calls fn after items were tested, see Hooks. WithBitSetBackend is not supported.
+func OnTest(fn func(HookEvent)) Option {
+	return func(o *options) { o.hooks.OnTest = fn }
+}
+
+// OnScale calls fn after a scalable filter added a layer, see Hooks. Bloom filters never scale,
+// set ParamsScalable.Hooks for scalable filters.
+func OnScale(fn func(HookEvent)) Option {
+	return func(o *options) { o.hooks.OnScale = fn }
+}
+
+// OnSaturation calls fn once more items were added than the filter was sized for, or once its fill ratio
+// reaches the threshold set with WithSaturationThreshold, see Hooks. WithBitSetBackend is not supported.
+func OnSaturation(fn func(HookEvent)) Option {
+	return func(o *options) { o.hooks.OnSaturation = fn }
+}
+
+// WithSaturationThreshold calls the OnSaturation hook when the fill ratio of the filter reaches the given
+// threshold, rather than when more items were added than it was sized for, see Hooks.
+func WithSaturationThreshold(fillRatio float64) Option {
+	return func(o *options) { o.hooks.SaturationThreshold = fillRatio }
+}
+
+// validate checks the settings of the hooks.
+func (h Hooks) validate() error {
+	if h.SaturationThreshold < 0 || h.SaturationThreshold >= 1 {
+		return fmt.Errorf("invalid saturation threshold, must be between 0 and 1, got %f", h.SaturationThreshold)
+	}
+	return nil
+}
+
+// empty returns whether no hook is set.
+func (h Hooks) empty() bool {
+	return h.OnAdd == nil && h.OnTest == nil && h.OnScale == nil && h.OnSaturation == nil
+}
+
+// observer returns the observer calling the hooks and then next, if not nil, for the Bloom filter bf,
+// nil for a scalable filter. It returns next if no hook is set.
+func (h Hooks) observer(next Observer, bf *BloomFilter) Observer {
+	if h.empty() {
+		return next
+	}
+	return &hookObserver{hooks: h, next: next, bf: bf}
+}
+
+// hookObserver is an Observer calling hooks.
+type hookObserver struct {
+	hooks     Hooks
+	next      Observer     // The observer set along the hooks, nil if none
+	bf        *BloomFilter // The Bloom filter checked for saturation, nil for scalable filters
+	added     atomic.Uint64
+	saturated atomic.Bool
+	nextCheck atomic.Uint64 // The number of added items at which the fill ratio is checked against the threshold
+}
+
+func (o *hookObserver) Batch(op string, items int) func(error) {
+	return o.BatchCtx(context.Background(), op, items)
+}
+
+func (o *hookObserver) BatchCtx(ctx context.Context, op string, items int) func(error) {
+	return observeBatch(ctx, o.next, op, items)
+}
+
+func (o *hookObserver) Added(items int) {
+	if o.next != nil {
+		o.next.Added(items)
+	}
+	added := o.added.Add(uint64(items))
+	if o.hooks.OnAdd != nil {
+		o.hooks.OnAdd(HookEvent{Items: items, Added: added})
+	}
+	if o.hooks.OnSaturation == nil || o.bf == nil {
+		return
+	}
+	if o.saturated.Load() {
+		return
+	}
+	capacity := o.bf.Capacity()
+	if o.hooks.SaturationThreshold == 0 {
+		if added > capacity && o.saturated.CompareAndSwap(false, true) {
+			o.hooks.OnSaturation(HookEvent{Items: items, Added: added, Capacity: capacity, FillRatio: o.bf.FillRatio()})
+		}
+		return
+	}
+	next := o.nextCheck.Load()
+	if added < next {
+		return
+	}
+	// Counting the set bits reads the whole filter, so it is done once enough items were added to reach the
+	// threshold, each item setting at most k bits.
+	fill := o.bf.FillRatio()
+	if fill < o.hooks.SaturationThreshold {
+		missing := (o.hooks.SaturationThreshold - fill) * float64(o.bf.m) / float64(o.bf.k)
+		o.nextCheck.CompareAndSwap(next, added+max(uint64(math.Ceil(missing)), 1))
+		return
+	}
+	if o.saturated.CompareAndSwap(false, true) {
+		o.hooks.OnSaturation(HookEvent{Items: items, Added: added, Capacity: capacity, FillRatio: fill})
+	}
+}
+
+func (o *hookObserver) Tested(items, positives int) {
+	if o.next != nil {
+		o.next.Tested(items, positives)
+	}
+	if o.hooks.OnTest != nil {
+		o.hooks.OnTest(HookEvent{Items: items, Positives: positives, Added: o.added.Load()})
+	}
+}
+
+func (o *hookObserver) Scaled(layers int) {
+	if o.next != nil {
+		o.next.Scaled(layers)
+	}
+	if o.hooks.OnScale != nil {
+		o.hooks.OnScale(HookEvent{Added: o.added.Load(), Layers: layers})
+	}
+}
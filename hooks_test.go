@@ -0,0 +1,93 @@
+package gobloom
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks_BloomFilter(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var adds, tests, saturations []HookEvent
+	obs := &recordingObserver{}
+	f, err := NewWithOptions(100, 0.01, WithObserver(obs),
+		OnAdd(func(e HookEvent) { mu.Lock(); adds = append(adds, e); mu.Unlock() }),
+		OnTest(func(e HookEvent) { mu.Lock(); tests = append(tests, e); mu.Unlock() }),
+		OnSaturation(func(e HookEvent) { mu.Lock(); saturations = append(saturations, e); mu.Unlock() }),
+	)
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	bf := f.(*BloomFilter)
+
+	assert.NoError(t, bf.Add([]byte("foo")))
+	assert.NoError(t, bf.AddMany([][]byte{[]byte("bar"), []byte("baz")}))
+	_, _ = bf.Test([]byte("foo"))
+	assert.Equal(t, []HookEvent{{Items: 1, Added: 1}, {Items: 2, Added: 3}}, adds)
+	assert.Equal(t, []HookEvent{{Items: 1, Positives: 1, Added: 3}}, tests)
+	assert.Equal(t, 3, obs.added, "The observer should still be called")
+	assert.Equal(t, []string{"AddMany:2"}, obs.batches)
+	assert.Empty(t, saturations)
+
+	for i := 0; uint64(i) < 2*bf.Capacity(); i++ {
+		assert.NoError(t, bf.Add([]byte("item-"+strconv.Itoa(i))))
+	}
+	assert.Len(t, saturations, 1, "Saturation should be reported once")
+	assert.Equal(t, bf.Capacity(), saturations[0].Capacity)
+	assert.Equal(t, bf.Capacity()+1, saturations[0].Added)
+	assert.Greater(t, saturations[0].FillRatio, 0.4)
+}
+
+func TestHooks_SaturationThreshold(t *testing.T) {
+	t.Parallel()
+	var saturations []HookEvent
+	f, err := NewWithOptions(1000, 0.01, WithSaturationThreshold(0.25),
+		OnSaturation(func(e HookEvent) { saturations = append(saturations, e) }))
+	assert.NoError(t, err)
+	bf := f.(*BloomFilter)
+	for i := 0; i < 1000; i++ {
+		before := bf.FillRatio()
+		assert.NoError(t, bf.Add([]byte(strconv.Itoa(i))))
+		if len(saturations) > 0 {
+			assert.Less(t, before, 0.25, "Saturation should be reported as soon as the threshold is reached")
+			break
+		}
+	}
+	assert.Len(t, saturations, 1)
+	assert.GreaterOrEqual(t, saturations[0].FillRatio, 0.25)
+	assert.Less(t, saturations[0].Added, bf.Capacity(), "The threshold should be reached before the capacity")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, bf.Add([]byte("more-"+strconv.Itoa(i))))
+	}
+	assert.Len(t, saturations, 1, "Saturation should be reported once")
+
+	_, err = NewWithOptions(1000, 0.01, WithSaturationThreshold(1))
+	assert.Error(t, err, "The threshold should be a fill ratio")
+}
+
+func TestHooks_Scalable(t *testing.T) {
+	t.Parallel()
+	var adds, layers int
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 10, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2,
+		Hooks: Hooks{
+			OnAdd:        func(e HookEvent) { adds += e.Items },
+			OnScale:      func(e HookEvent) { layers = e.Layers },
+			OnSaturation: func(HookEvent) { t.Error("Scalable filters should not saturate") },
+		}})
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	assert.Equal(t, 1000, adds)
+	assert.Equal(t, len(sbf.filters()), layers)
+	assert.Greater(t, layers, 1)
+}
+
+func TestHooks_BitSetBackend(t *testing.T) {
+	t.Parallel()
+	_, err := NewWithOptions(100, 0.01, WithBitSetBackend(NewRoaringBitSet()), OnAdd(func(HookEvent) {}))
+	assert.Error(t, err, "Hooks are not supported with bit set backends")
+	_, err = NewWithOptions(100, 0.01, OnScale(func(HookEvent) {}))
+	assert.NoError(t, err)
+}
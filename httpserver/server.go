@@ -0,0 +1,276 @@
+// Package httpserver serves named gobloom filters over HTTP with JSON bodies,
+// so a filter can run as a small deduplication sidecar.
+//
+// The endpoints are:
+//
+//	POST /filters/{name}/add       {"items": ["a", "b"]} -> {"added": 2}, creating the filter if needed
+//	POST /filters/{name}/test      {"items": ["a", "c"]} -> {"results": [true, false]}
+//	GET  /filters/{name}/stats     -> the gobloom.Stats of the filter
+//	GET  /filters/{name}/snapshot  -> the filter encoded with MarshalBinary
+//	PUT  /filters/{name}/snapshot  <- a filter encoded with MarshalBinary, replacing or creating the filter
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/franciscoescher/gobloom"
+)
+
+// maxBodyBytes is the maximum size of a request body.
+const maxBodyBytes = 64 << 20
+
+// DefaultMaxBits is the default maximum number of bits of a restored filter, across its layers.
+const DefaultMaxBits = 1 << 32
+
+// DefaultMaxHashFunctions is the default maximum number of hash functions of a layer of a restored filter.
+const DefaultMaxHashFunctions = 64
+
+// Server is an http.Handler serving named scalable Bloom filters.
+type Server struct {
+	params  gobloom.ParamsScalable                  // The parameters of the filters created on the first add
+	maxBits uint64                                  // The maximum number of bits of a restored filter
+	maxK    uint64                                  // The maximum number of hash functions of a restored layer
+	mu      sync.RWMutex                            // Guards filters
+	filters map[string]*gobloom.ScalableBloomFilter // The filters by name
+}
+
+// Option configures a server.
+type Option func(*Server)
+
+// WithMaxBits sets the maximum number of bits of a restored filter, across its layers, DefaultMaxBits by default.
+func WithMaxBits(m uint64) Option {
+	return func(s *Server) { s.maxBits = m }
+}
+
+// WithMaxHashFunctions sets the maximum number of hash functions of a layer of a restored filter,
+// DefaultMaxHashFunctions by default.
+func WithMaxHashFunctions(k uint64) Option {
+	return func(s *Server) { s.maxK = k }
+}
+
+var _ http.Handler = (*Server)(nil)
+
+// New creates a server whose filters are created with the given parameters.
+// The parameters are validated by creating a filter.
+func New(p gobloom.ParamsScalable, opts ...Option) (*Server, error) {
+	if _, err := gobloom.NewScalable(p); err != nil {
+		return nil, err
+	}
+	s := &Server{
+		params:  p,
+		maxBits: DefaultMaxBits,
+		maxK:    DefaultMaxHashFunctions,
+		filters: make(map[string]*gobloom.ScalableBloomFilter),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// itemsRequest is the body of the add and test requests.
+type itemsRequest struct {
+	Items []string `json:"items"`
+}
+
+type addResponse struct {
+	Added int `json:"added"`
+}
+
+type testResponse struct {
+	Results []bool `json:"results"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// errNotFound is returned for requests on filters that don't exist.
+var errNotFound = errors.New("filter not found")
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, action, ok := parsePath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path %q", r.URL.Path))
+		return
+	}
+	switch {
+	case action == "add" && r.Method == http.MethodPost:
+		s.add(w, r, name)
+	case action == "test" && r.Method == http.MethodPost:
+		s.test(w, r, name)
+	case action == "stats" && r.Method == http.MethodGet:
+		s.stats(w, name)
+	case action == "snapshot" && r.Method == http.MethodGet:
+		s.snapshot(w, name)
+	case action == "snapshot" && r.Method == http.MethodPut:
+		s.restore(w, r, name)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed on %s", r.Method, action))
+	}
+}
+
+// parsePath splits a path of the form /filters/{name}/{action}.
+func parsePath(path string) (name, action string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "filters" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// filter returns the filter with the given name, creating it if create is true.
+func (s *Server) filter(name string, create bool) (*gobloom.ScalableBloomFilter, error) {
+	s.mu.RLock()
+	sbf := s.filters[name]
+	s.mu.RUnlock()
+	if sbf != nil {
+		return sbf, nil
+	}
+	if !create {
+		return nil, errNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sbf = s.filters[name]; sbf != nil {
+		return sbf, nil // Created concurrently
+	}
+	sbf, err := gobloom.NewScalable(s.params)
+	if err != nil {
+		return nil, err
+	}
+	s.filters[name] = sbf
+	return sbf, nil
+}
+
+func (s *Server) add(w http.ResponseWriter, r *http.Request, name string) {
+	var req itemsRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sbf, err := s.filter(name, true)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for _, item := range req.Items {
+		if err := sbf.Add([]byte(item)); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, addResponse{Added: len(req.Items)})
+}
+
+func (s *Server) test(w http.ResponseWriter, r *http.Request, name string) {
+	var req itemsRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sbf, err := s.filter(name, false)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	results := make([]bool, len(req.Items))
+	for i, item := range req.Items {
+		if results[i], err = sbf.Test([]byte(item)); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, testResponse{Results: results})
+}
+
+func (s *Server) stats(w http.ResponseWriter, name string) {
+	sbf, err := s.filter(name, false)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sbf.Stats())
+}
+
+func (s *Server) snapshot(w http.ResponseWriter, name string) {
+	sbf, err := s.filter(name, false)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	data, err := sbf.MarshalBinary()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(data)
+}
+
+func (s *Server) restore(w http.ResponseWriter, r *http.Request, name string) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// The snapshot is decoded into a filter created with the parameters of the server, so it keeps their lock type.
+	sbf, err := gobloom.NewScalable(s.params)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := sbf.UnmarshalBinary(data); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.checkLimits(sbf); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	s.filters[name] = sbf
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkLimits returns an error if a restored filter exceeds the limits of the server.
+func (s *Server) checkLimits(sbf *gobloom.ScalableBloomFilter) error {
+	var m uint64
+	for i, layer := range sbf.LayerStats() {
+		if layer.K > s.maxK {
+			return fmt.Errorf("layer %d has %d hash functions, more than the maximum of %d", i, layer.K, s.maxK)
+		}
+		m += layer.M
+	}
+	if m > s.maxBits {
+		return fmt.Errorf("filter of %d bits exceeds the maximum of %d", m, s.maxBits)
+	}
+	return nil
+}
+
+// readJSON decodes the JSON body of the request into v.
+func readJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
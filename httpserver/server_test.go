@@ -0,0 +1,87 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/franciscoescher/gobloom"
+	"github.com/stretchr/testify/assert"
+)
+
+func do(t *testing.T, h http.Handler, method, path string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewReader(body)))
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	srv, err := New(gobloom.ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Failed to create server")
+
+	rec := do(t, srv, http.MethodPost, "/filters/seen/test", []byte(`{"items": ["a"]}`))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "Testing an unknown filter should fail")
+
+	rec = do(t, srv, http.MethodPost, "/filters/seen/add", []byte(`{"items": ["a", "b"]}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"added": 2}`, rec.Body.String())
+
+	rec = do(t, srv, http.MethodPost, "/filters/seen/test", []byte(`{"items": ["a", "b", "c"]}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"results": [true, true, false]}`, rec.Body.String())
+
+	rec = do(t, srv, http.MethodGet, "/filters/seen/stats", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var stats gobloom.Stats
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, uint64(2), stats.EstimatedItems)
+
+	// A snapshot restores the filter under another name.
+	rec = do(t, srv, http.MethodGet, "/filters/seen/snapshot", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	snapshot, _ := io.ReadAll(rec.Body)
+	rec = do(t, srv, http.MethodPut, "/filters/copy/snapshot", snapshot)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = do(t, srv, http.MethodPost, "/filters/copy/test", []byte(`{"items": ["a", "c"]}`))
+	assert.JSONEq(t, `{"results": [true, false]}`, rec.Body.String())
+}
+
+func TestServer_Errors(t *testing.T) {
+	t.Parallel()
+	srv, err := New(gobloom.ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Failed to create server")
+
+	assert.Equal(t, http.StatusNotFound, do(t, srv, http.MethodGet, "/other", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do(t, srv, http.MethodGet, "/filters//stats", nil).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, do(t, srv, http.MethodGet, "/filters/f/add", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, http.MethodPost, "/filters/f/add", []byte(`{"item": "a"}`)).Code)
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, http.MethodPut, "/filters/f/snapshot", []byte("garbage")).Code)
+
+	_, err = New(gobloom.ParamsScalable{InitialSize: 1000, FalsePositiveRate: 2, FalsePositiveGrowth: 2})
+	assert.Error(t, err, "Invalid parameters should be rejected")
+}
+
+func TestServer_Limits(t *testing.T) {
+	t.Parallel()
+	sbf, err := gobloom.NewScalable(gobloom.ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Failed to create scalable Bloom filter")
+	snapshot, err := sbf.MarshalBinary()
+	assert.NoError(t, err)
+
+	params := gobloom.ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2}
+	srv, err := New(params, WithMaxHashFunctions(3))
+	assert.NoError(t, err, "Failed to create server")
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, http.MethodPut, "/filters/f/snapshot", snapshot).Code,
+		"Filters with too many hash functions should be rejected")
+	srv, err = New(params, WithMaxBits(1000))
+	assert.NoError(t, err, "Failed to create server")
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, http.MethodPut, "/filters/f/snapshot", snapshot).Code,
+		"Filters with too many bits should be rejected")
+	assert.Equal(t, http.StatusNotFound, do(t, srv, http.MethodGet, "/filters/f/stats", nil).Code,
+		"Rejected filters should not be stored")
+}
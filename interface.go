@@ -1,13 +1,62 @@
 package gobloom
 
-import "hash"
+import (
+	"context"
+	"encoding"
+	"hash"
+)
 
 type Interface interface {
 	Add([]byte) error
 	Test([]byte) (bool, error)
 }
 
+// The following interfaces are implemented by filters with optional capabilities,
+// so code working with any Interface can detect them with a type assertion.
+
+// Remover is implemented by filters that can remove items.
+type Remover interface {
+	Remove([]byte) error
+}
+
+// Merger is implemented by filters that can add the items of another filter of type T to themselves.
+type Merger[T any] interface {
+	Merge(other T) error
+}
+
+// Clearer is implemented by filters that can remove all their items, keeping their parameters.
+type Clearer interface {
+	Clear()
+}
+
+// Counter is implemented by filters that know the number of items they hold.
+type Counter interface {
+	Count() uint64
+}
+
+// Serializer is implemented by filters that can be encoded and restored.
+type Serializer interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// ContextInterface is implemented by filters whose operations can be canceled or given a deadline,
+// like filters backed by a remote store.
+type ContextInterface interface {
+	Interface
+	AddCtx(ctx context.Context, data []byte) error
+	TestCtx(ctx context.Context, data []byte) (bool, error)
+}
+
 // Hasher is an interface for a hash function that returns a slice of hash.Hash64.
 type Hasher interface {
 	GetHashes(n uint64) []hash.Hash64
 }
+
+// NamedHasher is a Hasher with a name, which identifies it when a filter is serialized.
+// Hashers that also implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler
+// get their state, like seeds, serialized along with the name.
+type NamedHasher interface {
+	Hasher
+	Name() string
+}
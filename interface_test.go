@@ -0,0 +1,168 @@
+package gobloom
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterface_Capabilities(t *testing.T) {
+	t.Parallel()
+	bf, _ := New(Params{N: 100, FalsePositiveRate: 0.01})
+	cbf, _ := NewCounting(Params{N: 100, FalsePositiveRate: 0.01})
+	spbf, _ := NewSpectral(Params{N: 100, FalsePositiveRate: 0.01})
+	dbf, _ := NewDeletable(ParamsDeletable{N: 100, FalsePositiveRate: 0.01})
+	cf, _ := NewCuckoo(ParamsCuckoo{N: 100})
+	qf, _ := NewQuotient(Params{N: 100, FalsePositiveRate: 0.01})
+	sbf, _ := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+
+	tests := []struct {
+		name    string
+		filter  Interface
+		remover bool
+		counter bool
+	}{
+		{"BloomFilter", bf, false, false},
+		{"CountingBloomFilter", cbf, true, false},
+		{"SpectralBloomFilter", spbf, false, false},
+		{"DeletableBloomFilter", dbf, true, false},
+		{"CuckooFilter", cf, true, true},
+		{"QuotientFilter", qf, true, true},
+		{"ScalableBloomFilter", sbf, false, false},
+	}
+	for _, tc := range tests {
+		_, ok := tc.filter.(Remover)
+		assert.Equal(t, tc.remover, ok, "%s should implement Remover: %v", tc.name, tc.remover)
+		_, ok = tc.filter.(Counter)
+		assert.Equal(t, tc.counter, ok, "%s should implement Counter: %v", tc.name, tc.counter)
+		_, ok = tc.filter.(Serializer)
+		assert.True(t, ok, "%s should implement Serializer", tc.name)
+	}
+}
+
+func TestInterface_Clear(t *testing.T) {
+	t.Parallel()
+	bf, _ := New(Params{N: 100, FalsePositiveRate: 0.01})
+	cbf, _ := NewCounting(Params{N: 100, FalsePositiveRate: 0.01})
+	spbf, _ := NewSpectral(Params{N: 100, FalsePositiveRate: 0.01})
+	dbf, _ := NewDeletable(ParamsDeletable{N: 100, FalsePositiveRate: 0.01})
+	cf, _ := NewCuckoo(ParamsCuckoo{N: 100})
+	qf, _ := NewQuotient(Params{N: 100, FalsePositiveRate: 0.01})
+
+	for _, f := range []Interface{bf, cbf, spbf, dbf, cf, qf} {
+		for i := 0; i < 50; i++ {
+			assert.NoError(t, f.Add([]byte(fmt.Sprintf("item-%d", i))))
+		}
+		f.(Clearer).Clear()
+		for i := 0; i < 50; i++ {
+			b, err := f.Test([]byte(fmt.Sprintf("item-%d", i)))
+			assert.NoError(t, err)
+			assert.False(t, b, "%T should be empty after Clear", f)
+		}
+		if c, ok := f.(Counter); ok {
+			assert.Equal(t, uint64(0), c.Count(), "%T should have no items after Clear", f)
+		}
+		assert.NoError(t, f.Add([]byte("foo")), "%T should be usable after Clear", f)
+	}
+
+	cms, _ := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01})
+	assert.NoError(t, cms.Add([]byte("foo"), 3))
+	cms.Clear()
+	estimate, err := cms.Estimate([]byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), estimate)
+}
+
+func TestInterface_Interchangeable(t *testing.T) {
+	t.Parallel()
+	bf, _ := New(Params{N: 100, FalsePositiveRate: 0.01})
+	cbf, _ := NewCounting(Params{N: 100, FalsePositiveRate: 0.01})
+	spbf, _ := NewSpectral(Params{N: 100, FalsePositiveRate: 0.01})
+	dbf, _ := NewDeletable(ParamsDeletable{N: 100, FalsePositiveRate: 0.01})
+	cf, _ := NewCuckoo(ParamsCuckoo{N: 100})
+	qf, _ := NewQuotient(Params{N: 100, FalsePositiveRate: 0.01})
+	sbf, _ := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	apbf, _ := NewAgePartitioned(ParamsAgePartitioned{N: 100, FalsePositiveRate: 0.01, Window: time.Hour})
+	rbf, _ := NewRotating(ParamsRotating{N: 100, FalsePositiveRate: 0.01, Filters: 2, Interval: time.Hour})
+	defer rbf.Close()
+	redis, _ := NewRedisBloom(ParamsRedisBloom{Capacity: 100, ErrorRate: 0.01})
+	shbf, _ := NewSharded(ParamsSharded{N: 100, FalsePositiveRate: 0.01, Shards: 4})
+
+	// Every filter is used through the same code, so implementations can be swapped.
+	for _, f := range []Interface{bf, cbf, spbf, dbf, cf, qf, sbf, apbf, rbf, redis, shbf} {
+		for i := 0; i < 50; i++ {
+			assert.NoError(t, f.Add([]byte(fmt.Sprintf("item-%d", i))), "%T", f)
+		}
+		for i := 0; i < 50; i++ {
+			b, err := f.Test([]byte(fmt.Sprintf("item-%d", i)))
+			assert.NoError(t, err, "%T", f)
+			assert.True(t, b, "Item should be present in %T", f)
+		}
+	}
+}
+
+func TestInterface_ConcurrentReaders(t *testing.T) {
+	t.Parallel()
+	bf, _ := New(Params{N: 100, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	cbf, _ := NewCounting(Params{N: 100, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	spbf, _ := NewSpectral(Params{N: 100, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	dbf, _ := NewDeletable(ParamsDeletable{N: 100, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	cf, _ := NewCuckoo(ParamsCuckoo{N: 100, LockType: LockTypeReadWrite})
+	qf, _ := NewQuotient(Params{N: 100, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+
+	// Readers share a read lock, so they must not share any hashing state. Run with -race.
+	for _, f := range []Interface{bf, cbf, spbf, dbf, cf, qf} {
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, f.Add([]byte(fmt.Sprintf("item-%d", i))))
+		}
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(f Interface) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					b, err := f.Test([]byte(fmt.Sprintf("item-%d", i)))
+					assert.NoError(t, err)
+					assert.True(t, b, "Item %d should be present in %T", i, f)
+				}
+			}(f)
+		}
+		wg.Wait()
+	}
+}
+
+func TestInterface_ZeroAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("Pooled buffers are dropped at random with the race detector")
+	}
+	cbf, _ := NewCounting(Params{N: 100, FalsePositiveRate: 0.01})
+	spbf, _ := NewSpectral(Params{N: 100, FalsePositiveRate: 0.01})
+	dbf, _ := NewDeletable(ParamsDeletable{N: 100, FalsePositiveRate: 0.01})
+	apbf, _ := NewAgePartitioned(ParamsAgePartitioned{N: 100, FalsePositiveRate: 0.01, Window: time.Hour})
+	cf, _ := NewCuckoo(ParamsCuckoo{N: 100})
+	qf, _ := NewQuotient(Params{N: 100, FalsePositiveRate: 0.01})
+
+	data := []byte("zero-alloc-item")
+	for _, f := range []Interface{cbf, spbf, dbf, apbf, cf, qf} {
+		assert.NoError(t, f.Add(data))
+		allocs := testing.AllocsPerRun(100, func() { _, _ = f.Test(data) })
+		assert.Zero(t, allocs, "Test should not allocate for %T", f)
+	}
+
+	cms, _ := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01})
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = cms.Add(data, 1)
+		_, _ = cms.Estimate(data)
+	})
+	assert.Zero(t, allocs, "Count-min sketch should not allocate")
+
+	bf, _ := New(Params{N: 100, FalsePositiveRate: 0.01})
+	w, _ := NewWAL(bf, io.Discard)
+	assert.NoError(t, w.Add(data))
+	allocs = testing.AllocsPerRun(100, func() { _ = w.Add(data) })
+	assert.Zero(t, allocs, "WAL writes should not allocate")
+}
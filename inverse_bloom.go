@@ -0,0 +1,87 @@
+package gobloom
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"sync"
+	"sync/atomic"
+)
+
+var _ Interface = (*InverseBloomFilter)(nil)
+
+// InverseBloomFilter is a fixed-size, lock-free filter with the opposite error semantics of a
+// Bloom filter: it may report false negatives, but never false positives.
+// Each item hashes to a single slot holding the last item stored there, so an item is forgotten
+// as soon as another item hashing to the same slot is added. This makes it suitable for
+// "have I probably just seen this?" checks, like deduplicating recent log lines.
+type InverseBloomFilter struct {
+	slots []atomic.Pointer[[]byte] // The last item added to each slot
+	pool  sync.Pool                // Pool of hash functions, so concurrent calls don't share state
+}
+
+// ParamsInverse represents the parameters for creating a new inverse Bloom filter.
+type ParamsInverse struct {
+	// Size is the number of slots. Larger sizes reduce the false negative rate.
+	Size uint64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+}
+
+// NewInverse creates a new inverse Bloom filter.
+func NewInverse(p ParamsInverse) (*InverseBloomFilter, error) {
+	if p.Size == 0 {
+		return nil, fmt.Errorf("size cannot be 0")
+	}
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	return &InverseBloomFilter{
+		slots: make([]atomic.Pointer[[]byte], p.Size),
+		pool: sync.Pool{
+			New: func() any { return p.Hasher.GetHashes(1)[0] },
+		},
+	}, nil
+}
+
+// slot returns the slot the data hashes to.
+func (ibf *InverseBloomFilter) slot(data []byte) (*atomic.Pointer[[]byte], error) {
+	h := ibf.pool.Get().(hash.Hash64)
+	defer ibf.pool.Put(h)
+	h.Reset()
+	_, err := h.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	return &ibf.slots[h.Sum64()%uint64(len(ibf.slots))], nil
+}
+
+// Add adds an item to the inverse Bloom filter, evicting the item previously stored in its slot.
+func (ibf *InverseBloomFilter) Add(data []byte) error {
+	_, err := ibf.TestAndAdd(data)
+	return err
+}
+
+// Test checks if an item is in the inverse Bloom filter.
+// A true result is always correct, a false result may be a false negative.
+func (ibf *InverseBloomFilter) Test(data []byte) (bool, error) {
+	s, err := ibf.slot(data)
+	if err != nil {
+		return false, err
+	}
+	old := s.Load()
+	return old != nil && bytes.Equal(*old, data), nil
+}
+
+// TestAndAdd atomically adds an item to the inverse Bloom filter and reports whether
+// it was present before.
+func (ibf *InverseBloomFilter) TestAndAdd(data []byte) (bool, error) {
+	s, err := ibf.slot(data)
+	if err != nil {
+		return false, err
+	}
+	// Store a copy, since the caller may reuse the data slice.
+	item := append([]byte(nil), data...)
+	old := s.Swap(&item)
+	return old != nil && bytes.Equal(*old, data), nil
+}
@@ -0,0 +1,58 @@
+package gobloom
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInverseBloomFilter_AddAndTest(t *testing.T) {
+	t.Parallel()
+	ibf, err := NewInverse(ParamsInverse{Size: 1024})
+	assert.NoError(t, err, "Failed to create inverse Bloom filter")
+
+	item := []byte("test-item")
+	b, err := ibf.Test(item)
+	assert.NoError(t, err)
+	assert.False(t, b)
+
+	b, err = ibf.TestAndAdd(item)
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should not be seen before being added")
+
+	b, err = ibf.TestAndAdd(item)
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be seen after being added")
+
+	// Modifying the caller's slice must not affect the stored item.
+	item[0] = 'x'
+	b, err = ibf.Test([]byte("test-item"))
+	assert.NoError(t, err)
+	assert.True(t, b)
+}
+
+func TestInverseBloomFilter_NoFalsePositives(t *testing.T) {
+	t.Parallel()
+	ibf, err := NewInverse(ParamsInverse{Size: 16})
+	assert.NoError(t, err, "Failed to create inverse Bloom filter")
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				assert.NoError(t, ibf.Add([]byte(fmt.Sprintf("item-%d-%d", g, i))))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for i := 0; i < 1000; i++ {
+		b, err := ibf.Test([]byte(fmt.Sprintf("other-%d", i)))
+		assert.NoError(t, err)
+		assert.False(t, b, "Inverse Bloom filter should never report false positives")
+	}
+}
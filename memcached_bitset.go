@@ -0,0 +1,166 @@
+package gobloom
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+var _ BitSet = (*MemcachedBitSet)(nil)
+
+// defaultMemcachedPageBytes is the default size of the pages of a MemcachedBitSet.
+const defaultMemcachedPageBytes = 4096
+
+// memcachedMaxRetries is the number of times a page update is retried when other processes update it concurrently.
+const memcachedMaxRetries = 16
+
+// MemcachedClient is the part of a memcached client used by MemcachedBitSet, so any client library can be
+// used through a small adapter, and this package doesn't depend on one.
+type MemcachedClient interface {
+	// Get returns the value of the key and its CAS token, as with the gets command,
+	// or found false if the key doesn't exist.
+	Get(ctx context.Context, key string) (value []byte, cas uint64, found bool, err error)
+	// Add stores the value if the key doesn't exist, returning stored false if it does.
+	Add(ctx context.Context, key string, value []byte) (stored bool, err error)
+	// CompareAndSwap stores the value if the CAS token of the key is still cas,
+	// returning stored false if the key was changed or removed.
+	CompareAndSwap(ctx context.Context, key string, value []byte, cas uint64) (stored bool, err error)
+}
+
+// MemcachedBitSet is a BitSet stored in memcached as fixed-size pages, each in its own key, so several
+// processes whose only shared infrastructure is memcached can share one Bloom filter. Pages are updated
+// with compare-and-swap, so concurrent writers don't lose each other's bits.
+//
+// The pages are cached locally and written through. Bits are never cleared, so a bit set in the cached
+// page is set in memcached too, and only tests hitting an unset cached bit fetch the page again.
+// Memcached may evict pages under memory pressure, which loses their bits and causes false negatives,
+// so the servers must have enough memory for the whole filter, and the keys must have no expiration.
+type MemcachedBitSet struct {
+	client    MemcachedClient
+	prefix    string // The prefix of the page keys, followed by the page number
+	pageBytes uint64 // The size of a page in bytes
+
+	mu    sync.RWMutex
+	pages map[uint64][]byte // The locally cached pages by number, never modified once stored
+}
+
+// NewMemcachedBitSet creates a new BitSet stored in memcached, in pages of pageBytes bytes stored at the keys
+// prefix0, prefix1, and so on. The page size defaults to 4096 bytes, and must not exceed the maximum item size
+// of the servers, which is 1MB by default. Smaller pages make updates cheaper, but a filter needs more of them.
+func NewMemcachedBitSet(client MemcachedClient, prefix string, pageBytes uint64) (*MemcachedBitSet, error) {
+	if client == nil {
+		return nil, fmt.Errorf("memcached client cannot be nil")
+	}
+	if prefix == "" {
+		return nil, fmt.Errorf("prefix cannot be empty")
+	}
+	if pageBytes == 0 {
+		pageBytes = defaultMemcachedPageBytes
+	}
+	return &MemcachedBitSet{client: client, prefix: prefix, pageBytes: pageBytes, pages: make(map[uint64][]byte)}, nil
+}
+
+// Set sets the bits at the given positions, updating each page they are in with compare-and-swap.
+func (mbs *MemcachedBitSet) Set(ctx context.Context, positions []uint64) error {
+	for page, offsets := range mbs.byPage(positions) {
+		if err := mbs.setPage(ctx, page, offsets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setPage sets the bits at the given offsets of a page, retrying when the page is updated concurrently.
+func (mbs *MemcachedBitSet) setPage(ctx context.Context, page uint64, offsets []uint64) error {
+	key := mbs.key(page)
+	for i := 0; i < memcachedMaxRetries; i++ {
+		value, cas, found, err := mbs.client.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if found && uint64(len(value)) != mbs.pageBytes {
+			return fmt.Errorf("page %s is %d bytes, expected %d", key, len(value), mbs.pageBytes)
+		}
+		updated := make([]byte, mbs.pageBytes)
+		copy(updated, value)
+		for _, off := range offsets {
+			updated[off/8] |= 1 << (off % 8)
+		}
+
+		var stored bool
+		switch {
+		case !found:
+			stored, err = mbs.client.Add(ctx, key, updated)
+		case string(updated) == string(value):
+			stored = true // The bits were already set
+		default:
+			stored, err = mbs.client.CompareAndSwap(ctx, key, updated, cas)
+		}
+		if err != nil {
+			return err
+		}
+		if stored {
+			mbs.cache(page, updated)
+			return nil
+		}
+	}
+	return fmt.Errorf("page %s is updated too often concurrently, gave up after %d attempts", key, memcachedMaxRetries)
+}
+
+// Test reports whether all the bits at the given positions are set, fetching the pages whose cached copy
+// has an unset bit.
+func (mbs *MemcachedBitSet) Test(ctx context.Context, positions []uint64) (bool, error) {
+	for page, offsets := range mbs.byPage(positions) {
+		mbs.mu.RLock()
+		cached := mbs.pages[page]
+		mbs.mu.RUnlock()
+		if cached != nil && allSet(cached, offsets) {
+			continue
+		}
+		value, _, found, err := mbs.client.Get(ctx, mbs.key(page))
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			return false, nil
+		}
+		if uint64(len(value)) != mbs.pageBytes {
+			return false, fmt.Errorf("page %s is %d bytes, expected %d", mbs.key(page), len(value), mbs.pageBytes)
+		}
+		mbs.cache(page, value)
+		if !allSet(value, offsets) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// byPage groups the positions by page, as offsets in the page.
+func (mbs *MemcachedBitSet) byPage(positions []uint64) map[uint64][]uint64 {
+	pageBits := 8 * mbs.pageBytes
+	pages := make(map[uint64][]uint64)
+	for _, pos := range positions {
+		pages[pos/pageBits] = append(pages[pos/pageBits], pos%pageBits)
+	}
+	return pages
+}
+
+func (mbs *MemcachedBitSet) key(page uint64) string {
+	return fmt.Sprintf("%s%d", mbs.prefix, page)
+}
+
+func (mbs *MemcachedBitSet) cache(page uint64, value []byte) {
+	mbs.mu.Lock()
+	defer mbs.mu.Unlock()
+	mbs.pages[page] = value
+}
+
+// allSet reports whether all the bits at the given offsets of the page are set.
+func allSet(page []byte, offsets []uint64) bool {
+	for _, off := range offsets {
+		if page[off/8]&(1<<(off%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
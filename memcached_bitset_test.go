@@ -0,0 +1,127 @@
+package gobloom
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMemcached is an in-memory MemcachedClient.
+type fakeMemcached struct {
+	mu    sync.Mutex
+	items map[string]fakeMemcachedItem
+	cas   uint64
+	gets  int
+}
+
+type fakeMemcachedItem struct {
+	value []byte
+	cas   uint64
+}
+
+func newFakeMemcached() *fakeMemcached {
+	return &fakeMemcached{items: make(map[string]fakeMemcachedItem)}
+}
+
+func (m *fakeMemcached) Get(_ context.Context, key string) ([]byte, uint64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	item, ok := m.items[key]
+	return append([]byte(nil), item.value...), item.cas, ok, nil
+}
+
+func (m *fakeMemcached) Add(_ context.Context, key string, value []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.items[key]; ok {
+		return false, nil
+	}
+	m.cas++
+	m.items[key] = fakeMemcachedItem{value: append([]byte(nil), value...), cas: m.cas}
+	return true, nil
+}
+
+func (m *fakeMemcached) CompareAndSwap(_ context.Context, key string, value []byte, cas uint64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if item, ok := m.items[key]; !ok || item.cas != cas {
+		return false, nil
+	}
+	m.cas++
+	m.items[key] = fakeMemcachedItem{value: append([]byte(nil), value...), cas: m.cas}
+	return true, nil
+}
+
+func TestMemcachedBitSet(t *testing.T) {
+	t.Parallel()
+	client := newFakeMemcached()
+	bits, err := NewMemcachedBitSet(client, "bloom:", 64)
+	assert.NoError(t, err, "Failed to create memcached bit set")
+	bf, err := NewWithBitSet(Params{N: 1000, FalsePositiveRate: 0.01}, bits)
+	assert.NoError(t, err, "Failed to create Bloom filter")
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.Greater(t, len(client.items), 1, "The bits should be spread over pages")
+
+	// Present items are answered from the local cache.
+	gets := client.gets
+	for i := 0; i < 100; i++ {
+		b, err := bf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item %d should be present", i)
+	}
+	assert.Equal(t, gets, client.gets, "Set bits should be read from the local cache")
+
+	// Another process sharing the pages sees the items, and its additions are seen here.
+	other, _ := NewMemcachedBitSet(client, "bloom:", 64)
+	obf, _ := NewWithBitSet(Params{N: 1000, FalsePositiveRate: 0.01}, other)
+	b, err := obf.Test([]byte("item-1"))
+	assert.NoError(t, err)
+	assert.True(t, b)
+	assert.NoError(t, obf.Add([]byte("from-other")))
+	b, err = bf.Test([]byte("from-other"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Unset cached bits should be fetched again")
+	b, err = bf.Test([]byte("missing"))
+	assert.NoError(t, err)
+	assert.False(t, b)
+}
+
+func TestMemcachedBitSet_ConcurrentWriters(t *testing.T) {
+	t.Parallel()
+	client := newFakeMemcached()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		bits, _ := NewMemcachedBitSet(client, "bloom:", 0)
+		wg.Add(1)
+		go func(w int, bits *MemcachedBitSet) {
+			defer wg.Done()
+			for i := 0; i < 64; i++ {
+				assert.NoError(t, bits.Set(context.Background(), []uint64{uint64(w*64 + i)}))
+			}
+		}(w, bits)
+	}
+	wg.Wait()
+	bits, _ := NewMemcachedBitSet(client, "bloom:", 0)
+	positions := make([]uint64, 256)
+	for i := range positions {
+		positions[i] = uint64(i)
+	}
+	b, err := bits.Test(context.Background(), positions)
+	assert.NoError(t, err)
+	assert.True(t, b, "No concurrent write should be lost")
+}
+
+func TestNewMemcachedBitSet_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := NewMemcachedBitSet(nil, "bloom:", 0)
+	assert.Error(t, err)
+	_, err = NewMemcachedBitSet(newFakeMemcached(), "", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package gobloom
+
+import "sync/atomic"
+
+var _ Interface = (*InstrumentedFilter)(nil)
+
+// InstrumentedFilter wraps a filter to count its operations, so metrics systems can export them
+// along with the statistics of the filter, and alert when it saturates. The gobloomprom package exports
+// them to Prometheus.
+type InstrumentedFilter struct {
+	filter    Interface
+	adds      atomic.Uint64
+	tests     atomic.Uint64
+	positives atomic.Uint64
+}
+
+// Metrics is a snapshot of the metrics of an instrumented filter.
+type Metrics struct {
+	Adds         uint64  // The number of items added
+	Tests        uint64  // The number of items tested
+	Positives    uint64  // The number of tests reporting the item as present
+	PositiveRate float64 // The fraction of tests reporting the item as present
+	FillRatio    float64 // The fraction of set bits, 0 if the filter has no Stats
+	Layers       int     // The number of layers of scalable filters, 1 for the other filters
+	MemoryBytes  uint64  // The size of the bit sets in bytes, 0 if the filter has no Stats
+}
+
+// Instrument wraps the filter to count its operations.
+func Instrument(f Interface) *InstrumentedFilter {
+	return &InstrumentedFilter{filter: f}
+}
+
+// Filter returns the wrapped filter.
+func (f *InstrumentedFilter) Filter() Interface {
+	return f.filter
+}
+
+func (f *InstrumentedFilter) Add(data []byte) error {
+	if err := f.filter.Add(data); err != nil {
+		return err
+	}
+	f.adds.Add(1)
+	return nil
+}
+
+func (f *InstrumentedFilter) Test(data []byte) (bool, error) {
+	b, err := f.filter.Test(data)
+	if err != nil {
+		return false, err
+	}
+	f.tests.Add(1)
+	if b {
+		f.positives.Add(1)
+	}
+	return b, nil
+}
+
+// Metrics returns a snapshot of the metrics of the filter.
+// The fill ratio and memory come from the Stats method of the filter, if it has one.
+func (f *InstrumentedFilter) Metrics() Metrics {
+	m := Metrics{
+		Adds:      f.adds.Load(),
+		Tests:     f.tests.Load(),
+		Positives: f.positives.Load(),
+		Layers:    1,
+	}
+	if m.Tests > 0 {
+		m.PositiveRate = float64(m.Positives) / float64(m.Tests)
+	}
+	if s, ok := f.filter.(interface{ Stats() Stats }); ok {
+		stats := s.Stats()
+		if stats.Bits > 0 {
+			m.FillRatio = float64(stats.SetBits) / float64(stats.Bits)
+		}
+		m.MemoryBytes = stats.MemoryBytes
+	}
+	if l, ok := f.filter.(interface{ LayerStats() []LayerStat }); ok {
+		m.Layers = len(l.LayerStats())
+	}
+	return m
+}
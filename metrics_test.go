@@ -0,0 +1,38 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumentedFilter_Metrics(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Error initializing scalable Bloom filter")
+	f := Instrument(sbf)
+	assert.Same(t, sbf, f.Filter())
+
+	for i := 0; i < 2000; i++ {
+		assert.NoError(t, f.Add([]byte(strconv.Itoa(i))))
+	}
+	for i := 0; i < 100; i++ {
+		b, err := f.Test([]byte(strconv.Itoa(i)))
+		assert.NoError(t, err)
+		assert.True(t, b)
+	}
+	m := f.Metrics()
+	assert.Equal(t, uint64(2000), m.Adds)
+	assert.Equal(t, uint64(100), m.Tests)
+	assert.Equal(t, uint64(100), m.Positives)
+	assert.Equal(t, 1.0, m.PositiveRate)
+	assert.Greater(t, m.Layers, 1, "Filter should have grown")
+	assert.Equal(t, sbf.Stats().MemoryBytes, m.MemoryBytes)
+	assert.Greater(t, m.FillRatio, 0.0)
+
+	// Filters without statistics only have the counters.
+	cf, _ := NewCuckoo(ParamsCuckoo{N: 100})
+	m = Instrument(cf).Metrics()
+	assert.Equal(t, Metrics{Layers: 1}, m)
+}
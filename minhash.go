@@ -0,0 +1,153 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// MinHash estimates the Jaccard similarity between sets.
+// Each hash function acts as a random permutation of the items, and the signature keeps
+// the minimum hash value seen for each of them. The fraction of equal signature values
+// between two sets estimates their Jaccard similarity.
+type MinHash struct {
+	mins     []uint64 // The minimum hash value seen for each hash function
+	hasher   Hasher   // The hash provider the hash functions come from
+	hasher64 Hasher64 // The hasher computing the hashes of an item
+	mutex    Mutex    // Mutex to ensure thread safety
+}
+
+// ParamsMinHash represents the parameters for creating a new MinHash.
+type ParamsMinHash struct {
+	// NumHashes is the number of hash functions, which is the size of the signature.
+	// The expected error of the similarity estimate is 1/sqrt(NumHashes).
+	NumHashes uint64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	// MinHashes can only be compared if they use the same hash functions.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewMinHash creates a new, empty MinHash.
+func NewMinHash(p ParamsMinHash) (*MinHash, error) {
+	applyDefaultsMinHash(&p)
+	if p.NumHashes == 0 {
+		return nil, fmt.Errorf("number of hashes cannot be 0")
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	mins := make([]uint64, p.NumHashes)
+	for i := range mins {
+		mins[i] = math.MaxUint64
+	}
+	return &MinHash{
+		mins:     mins,
+		hasher:   p.Hasher,
+		hasher64: asHasher64(p.Hasher),
+		mutex:    mu,
+	}, nil
+}
+
+// applyDefaultsMinHash applies the default values to the parameters if they are not set.
+func applyDefaultsMinHash(p *ParamsMinHash) {
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	if p.LockType == LockTypeDefault {
+		p.LockType = LockTypeExclusive
+	}
+}
+
+// Add adds an item to the set.
+func (mh *MinHash) Add(data []byte) error {
+	if mh.mutex != nil {
+		mh.mutex.WLock()
+		defer mh.mutex.WUnlock()
+	}
+	hashes := getProbes(uint64(len(mh.mins)))
+	defer probePool.Put(hashes)
+	mh.hasher64.HashK(data, *hashes)
+	for i, v := range *hashes {
+		if v < mh.mins[i] {
+			mh.mins[i] = v
+		}
+	}
+	return nil
+}
+
+// Signature returns a copy of the MinHash signature.
+func (mh *MinHash) Signature() []uint64 {
+	if mh.mutex != nil {
+		mh.mutex.RLock()
+		defer mh.mutex.RUnlock()
+	}
+	sig := make([]uint64, len(mh.mins))
+	copy(sig, mh.mins)
+	return sig
+}
+
+// Similarity returns the estimated Jaccard similarity between the set and other.
+// Both MinHashes must have the same number of hashes and use the same hash functions.
+func (mh *MinHash) Similarity(other *MinHash) (float64, error) {
+	a, b := mh.Signature(), other.Signature()
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("incompatible signature sizes, %d and %d", len(a), len(b))
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a)), nil
+}
+
+// MarshalBinary encodes the MinHash with the wire format.
+// The parameter block holds the number of hashes and the hasher. The payload is the signature.
+func (mh *MinHash) MarshalBinary() ([]byte, error) {
+	if mh.mutex != nil {
+		mh.mutex.RLock()
+		defer mh.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, uint64(len(mh.mins)))
+	params, err := appendHasher(params, mh.hasher)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFilter(filterTypeMinHash, params, appendWords(nil, mh.mins)), nil
+}
+
+// UnmarshalBinary restores a MinHash encoded with MarshalBinary.
+// The lock type of the MinHash is kept, it defaults to ExclusiveLock for a zero value MinHash.
+func (mh *MinHash) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeMinHash, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	numHashes := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	if numHashes == 0 {
+		return fmt.Errorf("%w: number of hashes must be greater than 0", ErrInvalidEncoding)
+	}
+	if err := checkPayload(payload, 8, numHashes); err != nil {
+		return err
+	}
+	if mh.mutex == nil && mh.mins == nil {
+		mh.mutex = &ExclusiveMutex{}
+	}
+	if mh.mutex != nil {
+		mh.mutex.WLock()
+		defer mh.mutex.WUnlock()
+	}
+	mh.mins = readWords(payload)
+	mh.hasher = hasher
+	mh.hasher64 = asHasher64(hasher)
+	return nil
+}
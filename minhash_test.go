@@ -0,0 +1,47 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinHash_Similarity(t *testing.T) {
+	t.Parallel()
+	a, err := NewMinHash(ParamsMinHash{NumHashes: 256})
+	assert.NoError(t, err, "Failed to create MinHash")
+	b, err := NewMinHash(ParamsMinHash{NumHashes: 256})
+	assert.NoError(t, err, "Failed to create MinHash")
+
+	// a holds 0..999 and b holds 500..1499, so the Jaccard similarity is 500/1500.
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, a.Add([]byte(fmt.Sprintf("item-%d", i))))
+		assert.NoError(t, b.Add([]byte(fmt.Sprintf("item-%d", i+500))))
+	}
+
+	s, err := a.Similarity(b)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.0/3, s, 0.1)
+
+	s, err = a.Similarity(a)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, s)
+}
+
+func TestMinHash_Signature(t *testing.T) {
+	t.Parallel()
+	mh, err := NewMinHash(ParamsMinHash{NumHashes: 16})
+	assert.NoError(t, err, "Failed to create MinHash")
+	assert.NoError(t, mh.Add([]byte("foo")))
+
+	sig := mh.Signature()
+	assert.Len(t, sig, 16)
+	sig[0] = 0
+	assert.NotEqual(t, sig, mh.Signature(), "Signature should return a copy")
+
+	c, err := NewMinHash(ParamsMinHash{NumHashes: 8})
+	assert.NoError(t, err)
+	_, err = mh.Similarity(c)
+	assert.Error(t, err, "MinHashes with different sizes should not be comparable")
+}
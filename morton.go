@@ -0,0 +1,419 @@
+package gobloom
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+var (
+	_ Interface = (*MortonFilter)(nil)
+	_ Remover   = (*MortonFilter)(nil)
+	_ Clearer   = (*MortonFilter)(nil)
+	_ Counter   = (*MortonFilter)(nil)
+)
+
+// Layout of the blocks of a Morton filter, which fill a 64-byte cache line, as in the paper:
+// 46 fingerprints of 8 bits, 64 fullness counters of 2 bits, and 16 overflow tracking bits.
+const (
+	mortonSlots          = 46 // The number of fingerprints of a block
+	mortonBuckets        = 64 // The number of logical buckets of a block
+	mortonBucketCapacity = 3  // The maximum number of fingerprints of a bucket, the largest 2-bit counter
+	mortonOverflowBits   = 16 // The number of overflow tracking bits of a block
+	mortonLoadFactor     = 0.95
+	// mortonWindow is the number of buckets the alternate bucket of an item is chosen within,
+	// so both candidate buckets are in nearby blocks.
+	mortonWindow = 8 * mortonBuckets
+)
+
+// mortonBlock is a block of a Morton filter. The fingerprints of its buckets are stored one after the other,
+// the fingerprints of a bucket starting after those of the buckets before it, so a bucket only takes as many
+// slots as it holds fingerprints.
+type mortonBlock struct {
+	slots    [mortonSlots]uint8 // The fingerprint storage array, the fingerprints of the buckets in order
+	overflow uint16             // The overflow tracking array, set when an item of a bucket is stored in its alternate bucket
+	counts   [2]uint64          // The fullness counter array, the number of fingerprints of each bucket in 2 bits
+}
+
+// MortonFilter is a Morton filter, a compressed cuckoo filter. Its logical buckets are variable-sized, and packed
+// in blocks of one cache line, so sparse buckets don't waste space and the filter reaches higher load factors
+// than a cuckoo filter. Insertions are biased towards the primary bucket of an item, and items stored in their
+// alternate bucket are tracked by an overflow bit of their primary block, so most lookups, and in particular
+// most negative lookups, read a single block.
+//
+// Fingerprints are 8 bits, and the false positive rate is about 1% at full load.
+// AddMany and TestMany process batches of items block by block, for better cache behavior.
+type MortonFilter struct {
+	blocks     []mortonBlock
+	numBuckets uint64     // The number of logical buckets, always a power of two
+	count      uint64     // The number of items stored
+	maxKicks   int        // The maximum number of relocations of an Add
+	hasher     Hasher     // The hash provider the hash function comes from
+	hasher64   Hasher64   // The hasher computing the hash of an item
+	rand       *rand.Rand // Random source used to choose victims on relocation
+	mutex      Mutex      // Mutex to ensure thread safety
+}
+
+// ParamsMorton represents the parameters for creating a new Morton filter.
+type ParamsMorton struct {
+	// N is the number of elements expected to be added to the Morton filter.
+	N uint64
+	// MaxKicks is the maximum number of fingerprints relocated to make room for an item before Add
+	// returns ErrFilterFull. Defaults to 500.
+	MaxKicks int
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+	// Deterministic seeds the choice of the fingerprints relocated when buckets are full with a fixed seed,
+	// instead of the time, so adding the same items in the same order gives identical filters.
+	Deterministic bool
+}
+
+// NewMorton creates a new Morton filter.
+func NewMorton(p ParamsMorton) (*MortonFilter, error) {
+	if p.N == 0 {
+		return nil, fmt.Errorf("number of elements cannot be 0")
+	}
+	if p.MaxKicks < 0 {
+		return nil, fmt.Errorf("invalid max kicks, must not be negative, got %d", p.MaxKicks)
+	}
+	if p.MaxKicks == 0 {
+		p.MaxKicks = defaultCuckooMaxKicks
+	}
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	if p.LockType == LockTypeDefault {
+		p.LockType = LockTypeExclusive
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	numBlocks := nextPowerOfTwo(uint64(float64(p.N)/mortonSlots/mortonLoadFactor) + 1)
+	seed := time.Now().UnixNano()
+	if p.Deterministic {
+		seed = cuckooDeterministicSeed
+	}
+	return &MortonFilter{
+		blocks:     make([]mortonBlock, numBlocks),
+		numBuckets: numBlocks * mortonBuckets,
+		maxKicks:   p.MaxKicks,
+		hasher:     p.Hasher,
+		hasher64:   asHasher64(p.Hasher),
+		rand:       rand.New(rand.NewSource(seed)),
+		mutex:      mu,
+	}, nil
+}
+
+// count returns the number of fingerprints of the bucket.
+func (b *mortonBlock) count(bucket uint64) uint64 {
+	return b.counts[bucket/32] >> (2 * (bucket % 32)) & 3
+}
+
+// setCount sets the number of fingerprints of the bucket.
+func (b *mortonBlock) setCount(bucket, n uint64) {
+	shift := 2 * (bucket % 32)
+	b.counts[bucket/32] = b.counts[bucket/32]&^(3<<shift) | n<<shift
+}
+
+// offset returns the slot of the first fingerprint of the bucket, the number of fingerprints of the buckets before it.
+func (b *mortonBlock) offset(bucket uint64) uint64 {
+	var n uint64
+	for w := uint64(0); w < 2; w++ {
+		fields := min(32, bucket-min(bucket, 32*w)) // The fields of the word before the bucket
+		word := b.counts[w]
+		if fields < 32 {
+			word &= 1<<(2*fields) - 1
+		}
+		n += uint64(bits.OnesCount64(word&0x5555555555555555) + 2*bits.OnesCount64(word&0xaaaaaaaaaaaaaaaa))
+	}
+	return n
+}
+
+// used returns the number of slots holding fingerprints.
+func (b *mortonBlock) used() uint64 {
+	return b.offset(mortonBuckets)
+}
+
+// contains reports whether the bucket holds the fingerprint.
+func (b *mortonBlock) contains(bucket uint64, fp uint8) bool {
+	start := b.offset(bucket)
+	for _, f := range b.slots[start : start+b.count(bucket)] {
+		if f == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// insert stores the fingerprint in the bucket, returning false if the bucket or the block is full.
+func (b *mortonBlock) insert(bucket uint64, fp uint8) bool {
+	n, used := b.count(bucket), b.used()
+	if n == mortonBucketCapacity || used == mortonSlots {
+		return false
+	}
+	end := b.offset(bucket) + n
+	copy(b.slots[end+1:used+1], b.slots[end:used])
+	b.slots[end] = fp
+	b.setCount(bucket, n+1)
+	return true
+}
+
+// delete removes one copy of the fingerprint from the bucket, returning false if absent.
+func (b *mortonBlock) delete(bucket uint64, fp uint8) bool {
+	start, n := b.offset(bucket), b.count(bucket)
+	for s := start; s < start+n; s++ {
+		if b.slots[s] == fp {
+			b.removeSlot(bucket, s)
+			return true
+		}
+	}
+	return false
+}
+
+// removeSlot removes the fingerprint at the slot, which belongs to the bucket.
+func (b *mortonBlock) removeSlot(bucket, slot uint64) {
+	used := b.used()
+	copy(b.slots[slot:used-1], b.slots[slot+1:used])
+	b.slots[used-1] = 0
+	b.setCount(bucket, b.count(bucket)-1)
+}
+
+// bucketOf returns the bucket the fingerprint at the slot belongs to.
+func (b *mortonBlock) bucketOf(slot uint64) uint64 {
+	var end uint64
+	for bucket := uint64(0); ; bucket++ {
+		if end += b.count(bucket); slot < end {
+			return bucket
+		}
+	}
+}
+
+// overflowBit returns the overflow tracking bit of the bucket.
+func overflowBit(bucket uint64) uint16 {
+	return 1 << (bucket % mortonOverflowBits)
+}
+
+// indexAndFingerprint returns the primary bucket and the fingerprint of the data.
+func (mf *MortonFilter) indexAndFingerprint(data []byte) (uint64, uint8) {
+	hashes := getProbes(1)
+	defer probePool.Put(hashes)
+	mf.hasher64.HashK(data, *hashes)
+	h := (*hashes)[0]
+	// The fingerprint uses the upper bits and the bucket the lower bits, so they are independent.
+	return h & (mf.numBuckets - 1), uint8(h >> 56)
+}
+
+// altIndex returns the alternate bucket for the given bucket and fingerprint, at most a window of buckets away.
+// The offset is odd, and added to even buckets and subtracted from odd ones, so the alternate bucket of the
+// alternate bucket is the given bucket, while the windows of the buckets overlap.
+func (mf *MortonFilter) altIndex(i uint64, fp uint8) uint64 {
+	offset := 2*(uint64(fp)*0x5bd1e995%(mortonWindow/2)) + 1
+	if i&1 == 0 {
+		return (i + offset) & (mf.numBuckets - 1)
+	}
+	return (i - offset) & (mf.numBuckets - 1)
+}
+
+func (mf *MortonFilter) block(i uint64) *mortonBlock {
+	return &mf.blocks[i/mortonBuckets]
+}
+
+// Add adds an item to the Morton filter.
+// It returns ErrFilterFull if no room could be made for the item.
+func (mf *MortonFilter) Add(data []byte) error {
+	if mf.mutex != nil {
+		mf.mutex.WLock()
+		defer mf.mutex.WUnlock()
+	}
+	i, fp := mf.indexAndFingerprint(data)
+	return mf.add(i, fp)
+}
+
+func (mf *MortonFilter) add(i1 uint64, fp uint8) error {
+	// Items are stored in their primary bucket whenever possible, so lookups rarely read a second block.
+	if mf.block(i1).insert(i1%mortonBuckets, fp) {
+		mf.count++
+		return nil
+	}
+	i2 := mf.altIndex(i1, fp)
+	if mf.block(i2).insert(i2%mortonBuckets, fp) {
+		mf.block(i1).overflow |= overflowBit(i1)
+		mf.count++
+		return nil
+	}
+
+	// Both buckets are full, relocate existing fingerprints to make room. A full bucket evicts one of
+	// its fingerprints, a bucket of a full block one of the fingerprints of the block. Relocations are
+	// recorded so they can be undone if no room is found, otherwise an unrelated fingerprint would be lost.
+	type kick struct {
+		index, from uint64 // The bucket the fingerprint was stored in, and the bucket of the evicted one
+		in, out     uint8  // The fingerprint stored, and the one evicted
+	}
+	kicks := make([]kick, 0, mf.maxKicks)
+	i := i1
+	if mf.rand.Intn(2) == 0 {
+		i = i2
+	}
+	if i == i2 {
+		mf.block(i1).overflow |= overflowBit(i1)
+	}
+	for n := 0; n < mf.maxKicks; n++ {
+		b, bucket := mf.block(i), i%mortonBuckets
+		var slot uint64
+		if c := b.count(bucket); c == mortonBucketCapacity {
+			slot = b.offset(bucket) + uint64(mf.rand.Intn(int(c)))
+		} else {
+			slot = uint64(mf.rand.Intn(mortonSlots))
+		}
+		from := b.bucketOf(slot)
+		out := b.slots[slot]
+		b.removeSlot(from, slot)
+		b.insert(bucket, fp)
+		from += i / mortonBuckets * mortonBuckets
+		kicks = append(kicks, kick{index: i, from: from, in: fp, out: out})
+
+		// The evicted fingerprint may be in its primary bucket, so its overflow bit is set.
+		mf.block(from).overflow |= overflowBit(from)
+		fp, i = out, mf.altIndex(from, out)
+		if mf.block(i).insert(i%mortonBuckets, fp) {
+			mf.count++
+			return nil
+		}
+	}
+	for n := len(kicks) - 1; n >= 0; n-- {
+		k := kicks[n]
+		mf.block(k.index).delete(k.index%mortonBuckets, k.in)
+		mf.block(k.from).insert(k.from%mortonBuckets, k.out)
+	}
+	return ErrFilterFull
+}
+
+// Test checks if an item is in the Morton filter.
+func (mf *MortonFilter) Test(data []byte) (bool, error) {
+	if mf.mutex != nil {
+		mf.mutex.RLock()
+		defer mf.mutex.RUnlock()
+	}
+	i, fp := mf.indexAndFingerprint(data)
+	return mf.test(i, fp), nil
+}
+
+func (mf *MortonFilter) test(i1 uint64, fp uint8) bool {
+	b := mf.block(i1)
+	if b.contains(i1%mortonBuckets, fp) {
+		return true
+	}
+	if b.overflow&overflowBit(i1) == 0 {
+		return false // No item of the bucket overflowed, the alternate bucket is not read
+	}
+	i2 := mf.altIndex(i1, fp)
+	return mf.block(i2).contains(i2%mortonBuckets, fp)
+}
+
+// Delete removes an item from the Morton filter.
+// It returns ErrNotFound if the item is not in the filter. Deleting an item that was never
+// added (but tests positive) may remove a different item sharing the same fingerprint.
+func (mf *MortonFilter) Delete(data []byte) error {
+	if mf.mutex != nil {
+		mf.mutex.WLock()
+		defer mf.mutex.WUnlock()
+	}
+	i1, fp := mf.indexAndFingerprint(data)
+	if mf.block(i1).delete(i1%mortonBuckets, fp) {
+		mf.count--
+		return nil
+	}
+	// Overflow bits are not cleared, since other items of the bucket may have overflowed too.
+	if i2 := mf.altIndex(i1, fp); mf.block(i1).overflow&overflowBit(i1) != 0 && mf.block(i2).delete(i2%mortonBuckets, fp) {
+		mf.count--
+		return nil
+	}
+	return ErrNotFound
+}
+
+// Remove is the same as Delete, so the Morton filter implements Remover.
+func (mf *MortonFilter) Remove(data []byte) error {
+	return mf.Delete(data)
+}
+
+// mortonItem is an item of a batch, with its position in the batch.
+type mortonItem struct {
+	pos    int
+	bucket uint64
+	fp     uint8
+}
+
+// batch hashes the items, and returns them ordered by primary bucket, so they are processed block by block.
+func (mf *MortonFilter) batch(items [][]byte) []mortonItem {
+	batch := make([]mortonItem, len(items))
+	for pos, data := range items {
+		i, fp := mf.indexAndFingerprint(data)
+		batch[pos] = mortonItem{pos: pos, bucket: i, fp: fp}
+	}
+	sort.Slice(batch, func(a, b int) bool { return batch[a].bucket < batch[b].bucket })
+	return batch
+}
+
+// AddMany adds the items to the Morton filter, hashing them all first, and storing them block by block.
+// It returns ErrFilterFull if an item could not be added, in which case the items stored before it,
+// in block order, are kept.
+func (mf *MortonFilter) AddMany(items [][]byte) error {
+	batch := mf.batch(items)
+	if mf.mutex != nil {
+		mf.mutex.WLock()
+		defer mf.mutex.WUnlock()
+	}
+	for _, it := range batch {
+		if err := mf.add(it.bucket, it.fp); err != nil {
+			return fmt.Errorf("item %d: %w", it.pos, err)
+		}
+	}
+	return nil
+}
+
+// TestMany checks if each item is in the Morton filter, hashing them all first, and testing them block by block.
+func (mf *MortonFilter) TestMany(items [][]byte) ([]bool, error) {
+	batch := mf.batch(items)
+	if mf.mutex != nil {
+		mf.mutex.RLock()
+		defer mf.mutex.RUnlock()
+	}
+	found := make([]bool, len(items))
+	for _, it := range batch {
+		found[it.pos] = mf.test(it.bucket, it.fp)
+	}
+	return found, nil
+}
+
+// Count returns the number of items stored in the Morton filter.
+func (mf *MortonFilter) Count() uint64 {
+	if mf.mutex != nil {
+		mf.mutex.RLock()
+		defer mf.mutex.RUnlock()
+	}
+	return mf.count
+}
+
+// LoadFactor returns the fraction of the fingerprint slots that are used.
+func (mf *MortonFilter) LoadFactor() float64 {
+	if mf.mutex != nil {
+		mf.mutex.RLock()
+		defer mf.mutex.RUnlock()
+	}
+	return float64(mf.count) / float64(len(mf.blocks)*mortonSlots)
+}
+
+// Clear removes all the items from the Morton filter, keeping its parameters.
+func (mf *MortonFilter) Clear() {
+	if mf.mutex != nil {
+		mf.mutex.WLock()
+		defer mf.mutex.WUnlock()
+	}
+	clear(mf.blocks)
+	mf.count = 0
+}
@@ -0,0 +1,133 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMortonFilter_AddTestDelete(t *testing.T) {
+	t.Parallel()
+	mf, err := NewMorton(ParamsMorton{N: 1000})
+	assert.NoError(t, err, "Failed to create Morton filter")
+
+	item := []byte("test-item")
+	assert.NoError(t, mf.Add(item))
+	b, err := mf.Test(item)
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after Add")
+	assert.Equal(t, uint64(1), mf.Count())
+
+	assert.NoError(t, mf.Delete(item))
+	b, _ = mf.Test(item)
+	assert.False(t, b, "Item should not be present after Delete")
+	assert.Equal(t, uint64(0), mf.Count())
+	assert.ErrorIs(t, mf.Delete(item), ErrNotFound)
+}
+
+func TestMortonFilter_LoadFactorAndFalsePositiveRate(t *testing.T) {
+	t.Parallel()
+	n := 100000
+	mf, err := NewMorton(ParamsMorton{N: uint64(n), Deterministic: true})
+	assert.NoError(t, err, "Failed to create Morton filter")
+	var added int
+	for ; added < 2*n; added++ {
+		if err := mf.Add([]byte("item-" + strconv.Itoa(added))); err != nil {
+			assert.ErrorIs(t, err, ErrFilterFull)
+			break
+		}
+	}
+	assert.Greater(t, mf.LoadFactor(), 0.9, "Morton filters should reach high load factors")
+	for i := 0; i < added; i++ {
+		b, _ := mf.Test([]byte("item-" + strconv.Itoa(i)))
+		if !b {
+			t.Fatalf("Item 'item-%d' should be present", i)
+		}
+	}
+
+	var fp int
+	for i := 0; i < n; i++ {
+		if b, _ := mf.Test([]byte("other-" + strconv.Itoa(i))); b {
+			fp++
+		}
+	}
+	assert.Less(t, float64(fp)/float64(n), 0.03)
+
+	for i := 0; i < added; i++ {
+		assert.NoError(t, mf.Delete([]byte("item-"+strconv.Itoa(i))))
+	}
+	assert.Equal(t, uint64(0), mf.Count())
+}
+
+func TestMortonFilter_Full(t *testing.T) {
+	t.Parallel()
+	mf, err := NewMorton(ParamsMorton{N: 8, MaxKicks: 20})
+	assert.NoError(t, err)
+	var added []string
+	var fullErr error
+	for i := 0; i < 1000 && fullErr == nil; i++ {
+		item := strconv.Itoa(i)
+		if fullErr = mf.Add([]byte(item)); fullErr == nil {
+			added = append(added, item)
+		}
+	}
+	assert.ErrorIs(t, fullErr, ErrFilterFull)
+	// A failed insertion must not evict previously added items.
+	for _, item := range added {
+		b, _ := mf.Test([]byte(item))
+		assert.True(t, b, "Item '%s' should still be present", item)
+	}
+	assert.Equal(t, uint64(len(added)), mf.Count())
+
+	mf.Clear()
+	assert.Equal(t, uint64(0), mf.Count())
+	b, _ := mf.Test([]byte(added[0]))
+	assert.False(t, b, "Cleared filter should be empty")
+}
+
+func TestMortonFilter_Batch(t *testing.T) {
+	t.Parallel()
+	mf, _ := NewMorton(ParamsMorton{N: 1000})
+	items := make([][]byte, 500)
+	for i := range items {
+		items[i] = []byte("item-" + strconv.Itoa(i))
+	}
+	assert.NoError(t, mf.AddMany(items))
+	assert.Equal(t, uint64(500), mf.Count())
+
+	queries := append([][]byte{[]byte("missing")}, items[:10]...)
+	found, err := mf.TestMany(queries)
+	assert.NoError(t, err)
+	assert.Len(t, found, len(queries))
+	for i, q := range queries {
+		b, _ := mf.Test(q)
+		assert.Equal(t, b, found[i], "TestMany should match Test in the order of the items")
+	}
+}
+
+func TestMortonBlock(t *testing.T) {
+	t.Parallel()
+	var b mortonBlock
+	assert.True(t, b.insert(5, 1))
+	assert.True(t, b.insert(2, 2))
+	assert.True(t, b.insert(5, 3))
+	assert.Equal(t, uint64(1), b.offset(5), "Buckets should start after the fingerprints of the buckets before them")
+	assert.Equal(t, uint64(3), b.used())
+	assert.True(t, b.contains(5, 3))
+	assert.False(t, b.contains(2, 3))
+	assert.True(t, b.insert(5, 4))
+	assert.False(t, b.insert(5, 5), "Buckets should hold at most 3 fingerprints")
+	assert.Equal(t, uint64(5), b.bucketOf(3))
+	assert.True(t, b.delete(2, 2))
+	assert.Equal(t, uint64(0), b.offset(5))
+	assert.True(t, b.contains(5, 1))
+}
+
+func TestNewMorton_InvalidParams(t *testing.T) {
+	t.Parallel()
+	_, err := NewMorton(ParamsMorton{})
+	assert.Error(t, err)
+	_, err = NewMorton(ParamsMorton{N: 10, MaxKicks: -1})
+	assert.Error(t, err)
+}
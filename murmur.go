@@ -1,23 +1,94 @@
 package gobloom
 
 import (
+	"encoding/binary"
+	"fmt"
 	"hash"
 
 	"github.com/spaolacci/murmur3"
 )
 
-type MurMur3Hasher struct{}
+const murmur3HasherName = "murmur3"
 
-var _ Hasher = (*MurMur3Hasher)(nil)
+type MurMur3Hasher struct {
+	// The seeds of the first hash functions, the following ones use the seeds after the last one.
+	// Nil means the seeds 0, 1, and so on.
+	seeds []uint32
+}
+
+var (
+	_ NamedHasher = (*MurMur3Hasher)(nil)
+	_ Hasher64    = (*MurMur3Hasher)(nil)
+)
 
 func NewMurMur3Hasher() *MurMur3Hasher {
 	return &MurMur3Hasher{}
 }
 
+// NewMurMur3HasherWithSeed creates a MurMur3Hasher whose hash functions use the seeds seed, seed+1, and so on,
+// so filters with different seeds hash the same items to unrelated positions.
+func NewMurMur3HasherWithSeed(seed uint32) *MurMur3Hasher {
+	return NewMurMur3HasherWithSeeds(seed)
+}
+
+// NewMurMur3HasherWithSeeds creates a MurMur3Hasher whose i-th hash function uses seeds[i], so processes
+// merging filters can agree on the exact hash functions. Hash functions after the last seed use the
+// seeds following it, so a single seed is the same as NewMurMur3HasherWithSeed.
+func NewMurMur3HasherWithSeeds(seeds ...uint32) *MurMur3Hasher {
+	if len(seeds) == 0 || len(seeds) == 1 && seeds[0] == 0 {
+		return &MurMur3Hasher{}
+	}
+	return &MurMur3Hasher{seeds: append([]uint32(nil), seeds...)}
+}
+
+// seed returns the seed of the i-th hash function.
+func (h *MurMur3Hasher) seed(i int) uint32 {
+	if i < len(h.seeds) {
+		return h.seeds[i]
+	}
+	if len(h.seeds) == 0 {
+		return uint32(i)
+	}
+	return h.seeds[len(h.seeds)-1] + uint32(i-len(h.seeds)+1)
+}
+
 func (h *MurMur3Hasher) GetHashes(n uint64) []hash.Hash64 {
 	hashers := make([]hash.Hash64, n)
 	for i := 0; uint64(i) < n; i++ {
-		hashers[i] = murmur3.New64WithSeed(uint32(i))
+		hashers[i] = murmur3.New64WithSeed(h.seed(i))
 	}
 	return hashers
 }
+
+func (h *MurMur3Hasher) HashK(data []byte, out []uint64) {
+	for i := range out {
+		out[i] = murmur3.Sum64WithSeed(data, h.seed(i))
+	}
+}
+
+func (h *MurMur3Hasher) Name() string {
+	return murmur3HasherName
+}
+
+// MarshalBinary encodes the seeds of the hasher as little-endian uint32s. The default seeds are encoded
+// as no state, so filters using them encode the same as before seeds were supported.
+func (h *MurMur3Hasher) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 4*len(h.seeds))
+	for _, seed := range h.seeds {
+		buf = binary.LittleEndian.AppendUint32(buf, seed)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary restores the seeds of the hasher encoded with MarshalBinary.
+func (h *MurMur3Hasher) UnmarshalBinary(data []byte) error {
+	if len(data)%4 != 0 {
+		return fmt.Errorf("%w: murmur3 hasher state is %d bytes", ErrInvalidEncoding, len(data))
+	}
+	seeds := make([]uint32, len(data)/4)
+	for i := range seeds {
+		seeds[i] = binary.LittleEndian.Uint32(data[4*i:])
+	}
+	*h = *NewMurMur3HasherWithSeeds(seeds...)
+	return nil
+}
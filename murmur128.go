@@ -0,0 +1,112 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/spaolacci/murmur3"
+)
+
+const murmur3x128HasherName = "murmur3-128"
+
+// MurMur3x128Hasher derives all the hash functions from a single 128-bit murmur3 hash of the data,
+// the i-th one being h1 + i*h2 for its two 64-bit halves. Adding or testing an item hashes it once
+// instead of k times, which is several times faster than MurMur3Hasher for the usual k of 7 to 10.
+type MurMur3x128Hasher struct {
+	seed uint32
+}
+
+var (
+	_ NamedHasher = (*MurMur3x128Hasher)(nil)
+	_ Hasher64    = (*MurMur3x128Hasher)(nil)
+)
+
+func NewMurMur3x128Hasher() *MurMur3x128Hasher {
+	return &MurMur3x128Hasher{}
+}
+
+// NewMurMur3x128HasherWithSeed creates a MurMur3x128Hasher whose 128-bit hash uses the given seed.
+func NewMurMur3x128HasherWithSeed(seed uint32) *MurMur3x128Hasher {
+	return &MurMur3x128Hasher{seed: seed}
+}
+
+func (h *MurMur3x128Hasher) GetHashes(n uint64) []hash.Hash64 {
+	hashers := make([]hash.Hash64, n)
+	for i := range hashers {
+		hashers[i] = &murmur3x128Hash{i: uint64(i), seed: h.seed}
+	}
+	return hashers
+}
+
+func (h *MurMur3x128Hasher) HashK(data []byte, out []uint64) {
+	h1, h2 := murmur3.Sum128WithSeed(data, h.seed)
+	if h2 == 0 {
+		h2 = 1 // All the hash functions would be the same
+	}
+	for i := range out {
+		out[i] = h1 + uint64(i)*h2
+	}
+}
+
+func (h *MurMur3x128Hasher) Name() string {
+	return murmur3x128HasherName
+}
+
+// MarshalBinary encodes the seed of the hasher, the default seed is encoded as no state.
+func (h *MurMur3x128Hasher) MarshalBinary() ([]byte, error) {
+	if h.seed == 0 {
+		return nil, nil
+	}
+	return binary.LittleEndian.AppendUint32(nil, h.seed), nil
+}
+
+// UnmarshalBinary restores the seed of the hasher encoded with MarshalBinary.
+func (h *MurMur3x128Hasher) UnmarshalBinary(data []byte) error {
+	switch len(data) {
+	case 0:
+		h.seed = 0
+	case 4:
+		h.seed = binary.LittleEndian.Uint32(data)
+	default:
+		return fmt.Errorf("%w: murmur3-128 hasher state is %d bytes", ErrInvalidEncoding, len(data))
+	}
+	return nil
+}
+
+// murmur3x128Hash is a hash.Hash64 whose sum is the i-th hash function of the written data.
+// Filters use HashK instead, which hashes the data once for all the hash functions.
+type murmur3x128Hash struct {
+	i    uint64
+	seed uint32
+	data []byte
+}
+
+func (h *murmur3x128Hash) Write(p []byte) (int, error) {
+	h.data = append(h.data, p...)
+	return len(p), nil
+}
+
+func (h *murmur3x128Hash) Sum64() uint64 {
+	h1, h2 := murmur3.Sum128WithSeed(h.data, h.seed)
+	if h2 == 0 {
+		h2 = 1
+	}
+	return h1 + h.i*h2
+}
+
+func (h *murmur3x128Hash) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func (h *murmur3x128Hash) Reset() {
+	h.data = h.data[:0]
+}
+
+func (h *murmur3x128Hash) Size() int {
+	return 8
+}
+
+func (h *murmur3x128Hash) BlockSize() int {
+	return 1
+}
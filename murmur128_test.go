@@ -0,0 +1,43 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spaolacci/murmur3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMurMur3x128Hasher(t *testing.T) {
+	t.Parallel()
+	h1, h2 := murmur3.Sum128WithSeed([]byte("foo"), 3)
+	for i, h := range NewMurMur3x128HasherWithSeed(3).GetHashes(4) {
+		_, _ = h.Write([]byte("garbage"))
+		h.Reset()
+		_, _ = h.Write([]byte("foo"))
+		assert.Equal(t, h1+uint64(i)*h2, h.Sum64(), "Hash function %d should be derived from the 128-bit hash", i)
+	}
+
+	n := 10000
+	bf, err := New(Params{N: uint64(n), FalsePositiveRate: 0.01, Hasher: NewMurMur3x128HasherWithSeed(3)})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < n; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		b, err := bf.Test([]byte(fmt.Sprintf("other-%d", i)))
+		assert.NoError(t, err)
+		if b {
+			falsePositives++
+		}
+	}
+	assert.Less(t, float64(falsePositives)/float64(n), 0.03, "False positive rate should be close to the target")
+
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, NewMurMur3x128HasherWithSeed(3), restored.hasher)
+	assert.Equal(t, bf.Bits(), restored.Bits())
+}
@@ -3,6 +3,7 @@ package gobloom
 import (
 	"testing"
 
+	"github.com/spaolacci/murmur3"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,3 +17,35 @@ func TestMurmur(t *testing.T) {
 		assert.NotNil(t, hasher, "Expected hasher at index %d to not be nil", i)
 	}
 }
+
+func TestMurmur_Seeds(t *testing.T) {
+	t.Parallel()
+	h := NewMurMur3HasherWithSeeds(7, 3)
+	out := make([]uint64, 4)
+	h.HashK([]byte("foo"), out)
+	for i, seed := range []uint32{7, 3, 4, 5} {
+		assert.Equal(t, murmur3.Sum64WithSeed([]byte("foo"), seed), out[i], "Hash function %d should use seed %d", i, seed)
+	}
+	assert.Equal(t, NewMurMur3HasherWithSeed(9), NewMurMur3HasherWithSeeds(9), "A single seed should be a base seed")
+	assert.Equal(t, NewMurMur3Hasher(), NewMurMur3HasherWithSeed(0), "Seed 0 should be the default")
+
+	// Processes building filters with the same seeds get identical filters, which can be merged.
+	a, err := New(Params{N: 100, FalsePositiveRate: 0.01, Hasher: NewMurMur3HasherWithSeeds(7, 3)})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	b, err := New(Params{N: 100, FalsePositiveRate: 0.01, Hasher: NewMurMur3HasherWithSeeds(7, 3)})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, a.Add([]byte("foo")))
+	assert.NoError(t, b.Add([]byte("bar")))
+	assert.NoError(t, a.Union(b))
+
+	data, err := a.MarshalBinary()
+	assert.NoError(t, err)
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, NewMurMur3HasherWithSeeds(7, 3), restored.hasher)
+	for _, item := range []string{"foo", "bar"} {
+		found, err := restored.Test([]byte(item))
+		assert.NoError(t, err)
+		assert.True(t, found, "Item '%s' should be present after merging and restoring", item)
+	}
+}
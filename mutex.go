@@ -2,6 +2,7 @@ package gobloom
 
 import (
 	"errors"
+	"runtime"
 	"sync"
 )
 
@@ -22,10 +23,34 @@ func NewMutex(l LockType) (Mutex, error) {
 		return &ExclusiveMutex{}, nil
 	case LockTypeReadWrite:
 		return &ReadWriteMutex{}, nil
+	case LockTypeStriped:
+		return NewStripedMutex(defaultStripes), nil
+	case LockTypeAuto:
+		return NewMutex(autoLockType(0, false))
 	}
 	return nil, ErrInvalidLockType
 }
 
+// autoLockType returns the lock type picked by LockTypeAuto, for the expected number of reads per write,
+// 0 if unknown, and whether the filter supports LockTypeAtomic.
+//
+// With a single processor, writers rarely contend, so ExclusiveLock is cheapest. LockTypeNone is never
+// picked, since goroutines are preempted even with a single processor, so only users knowing that a filter
+// is used by one goroutine can choose it. With several processors, LockTypeAtomic is picked for write-heavy
+// workloads on filters supporting it, so writers never wait for each other, and ReadWriteLock for workloads
+// with at least 10 reads per write, so readers don't wait for each other.
+func autoLockType(readWriteRatio float64, atomicSupported bool) LockType {
+	switch {
+	case runtime.GOMAXPROCS(0) == 1:
+		return LockTypeExclusive
+	case atomicSupported && readWriteRatio > 0 && readWriteRatio <= 1:
+		return LockTypeAtomic
+	case readWriteRatio >= 10:
+		return LockTypeReadWrite
+	}
+	return LockTypeExclusive
+}
+
 type ExclusiveMutex struct {
 	m sync.Mutex
 }
@@ -65,3 +90,52 @@ func (l *ReadWriteMutex) RLock() {
 func (l *ReadWriteMutex) RUnlock() {
 	l.m.RUnlock()
 }
+
+// defaultStripes is the number of stripes of LockTypeStriped.
+const defaultStripes = 64
+
+// StripedMutex partitions the bit set of a BloomFilter into stripes, each guarded by its own mutex,
+// so Add and Test calls touching different stripes don't wait for each other. Locking the whole
+// filter locks every stripe. Other filters lock the whole filter for every operation.
+type StripedMutex struct {
+	stripes []stripe
+}
+
+// stripe is a mutex padded to a cache line, so stripes locked by different cores don't contend.
+type stripe struct {
+	sync.Mutex
+	_ [56]byte
+}
+
+// NewStripedMutex creates a StripedMutex with the given number of stripes, at least 1.
+func NewStripedMutex(stripes int) *StripedMutex {
+	if stripes < 1 {
+		stripes = 1
+	}
+	return &StripedMutex{stripes: make([]stripe, stripes)}
+}
+
+func (l *StripedMutex) WLock() {
+	for i := range l.stripes {
+		l.stripes[i].Lock()
+	}
+}
+
+func (l *StripedMutex) WUnlock() {
+	for i := range l.stripes {
+		l.stripes[i].Unlock()
+	}
+}
+
+func (l *StripedMutex) RLock() {
+	l.WLock()
+}
+
+func (l *StripedMutex) RUnlock() {
+	l.WUnlock()
+}
+
+// stripe returns the mutex guarding the given block of the bit set.
+func (l *StripedMutex) stripe(block uint64) *sync.Mutex {
+	return &l.stripes[block%uint64(len(l.stripes))].Mutex
+}
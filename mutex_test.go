@@ -1,6 +1,7 @@
 package gobloom
 
 import (
+	"runtime"
 	"testing"
 	"time"
 
@@ -18,10 +19,41 @@ func TestNewMutex(t *testing.T) {
 	assert.NoError(t, err)
 	assert.IsType(t, (*ReadWriteMutex)(nil), readWriteMutex)
 
+	stripedMutex, err := NewMutex(LockTypeStriped)
+	assert.NoError(t, err)
+	assert.IsType(t, (*StripedMutex)(nil), stripedMutex)
+	assert.Len(t, stripedMutex.(*StripedMutex).stripes, defaultStripes)
+
 	_, err = NewMutex(0)
 	assert.Error(t, err)
 }
 
+func TestAutoLockType(t *testing.T) {
+	t.Parallel()
+	if runtime.GOMAXPROCS(0) == 1 {
+		assert.Equal(t, LockTypeExclusive, autoLockType(100, true), "A single processor should use ExclusiveLock")
+		return
+	}
+	assert.Equal(t, LockTypeAtomic, autoLockType(0.5, true), "Write-heavy filters should be atomic")
+	assert.Equal(t, LockTypeExclusive, autoLockType(0.5, false), "Filters without atomic support should lock")
+	assert.Equal(t, LockTypeReadWrite, autoLockType(50, true), "Read-heavy filters should use ReadWriteLock")
+	assert.Equal(t, LockTypeExclusive, autoLockType(0, true), "Unknown workloads should use ExclusiveLock")
+
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeAuto, ReadWriteRatio: 0.5})
+	assert.NoError(t, err)
+	assert.True(t, bf.atomic, "The filter should use atomic operations")
+	bf, err = New(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeAuto, ReadWriteRatio: 50})
+	assert.NoError(t, err)
+	assert.IsType(t, (*ReadWriteMutex)(nil), bf.mutex)
+
+	mu, err := NewMutex(LockTypeAuto)
+	assert.NoError(t, err)
+	assert.IsType(t, (*ExclusiveMutex)(nil), mu)
+	cf, err := NewCuckoo(ParamsCuckoo{N: 100, LockType: LockTypeAuto})
+	assert.NoError(t, err, "Every filter should support LockTypeAuto")
+	assert.NotNil(t, cf.mutex)
+}
+
 func TestMutexLocks(t *testing.T) {
 	type testCase struct {
 		name         string
@@ -95,6 +127,31 @@ func TestMutexLocks(t *testing.T) {
 			},
 			lock: true,
 		},
+		{
+			name:  "StripedMutex_Write_Read",
+			mutex: NewStripedMutex(4),
+			getLockFuncs: func(mutex Mutex) (func(), func(), func()) {
+				return mutex.WLock, mutex.RLock, mutex.RUnlock
+			},
+			lock: true,
+		},
+		{
+			name:  "StripedMutex_Stripe_Write",
+			mutex: NewStripedMutex(4),
+			getLockFuncs: func(mutex Mutex) (func(), func(), func()) {
+				return mutex.(*StripedMutex).stripe(5).Lock, mutex.WLock, mutex.WUnlock
+			},
+			lock: true,
+		},
+		{
+			name:  "StripedMutex_Stripe_OtherStripe",
+			mutex: NewStripedMutex(4),
+			getLockFuncs: func(mutex Mutex) (func(), func(), func()) {
+				sm := mutex.(*StripedMutex)
+				return sm.stripe(1).Lock, sm.stripe(2).Lock, sm.stripe(2).Unlock
+			},
+			lock: false,
+		},
 	}
 
 	for _, tc := range tests {
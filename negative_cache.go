@@ -0,0 +1,54 @@
+package gobloom
+
+import (
+	"context"
+	"fmt"
+)
+
+// NegativeCache fronts lookups of values by key, like database queries, with a filter holding the
+// keys that exist, so lookups of keys that don't exist return ErrNotFound without calling the loader.
+// Keys the filter reports as present are loaded, which happens for a few missing keys too,
+// because of false positives.
+//
+// The filter must be populated with every existing key, and Inserted must be called whenever a key
+// is created, or its lookups would wrongly be answered with ErrNotFound. Keys can't be removed from
+// Bloom filters, so deleted keys keep being loaded until the filter is rebuilt.
+type NegativeCache[K, V any] struct {
+	filter Interface
+	key    func(K) []byte
+	load   func(context.Context, K) (V, error)
+}
+
+// NewNegativeCache creates a negative cache calling load for the keys in the filter,
+// encoded with key like for Typed.
+func NewNegativeCache[K, V any](filter Interface, key func(K) []byte, load func(context.Context, K) (V, error)) (*NegativeCache[K, V], error) {
+	if filter == nil || key == nil || load == nil {
+		return nil, fmt.Errorf("filter, key and load cannot be nil")
+	}
+	return &NegativeCache[K, V]{filter: filter, key: key, load: load}, nil
+}
+
+// Get returns the value of the key from the loader, or ErrNotFound without calling the loader
+// if the key is not in the filter.
+func (c *NegativeCache[K, V]) Get(ctx context.Context, k K) (V, error) {
+	var zero V
+	present, err := c.filter.Test(c.key(k))
+	if err != nil {
+		return zero, err
+	}
+	if !present {
+		return zero, ErrNotFound
+	}
+	return c.load(ctx, k)
+}
+
+// Inserted records that the key now exists, so its lookups call the loader.
+// It must be called when a key is created, before it can be looked up.
+func (c *NegativeCache[K, V]) Inserted(k K) error {
+	return c.filter.Add(c.key(k))
+}
+
+// Filter returns the filter holding the existing keys.
+func (c *NegativeCache[K, V]) Filter() Interface {
+	return c.filter
+}
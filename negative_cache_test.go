@@ -0,0 +1,48 @@
+package gobloom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegativeCache(t *testing.T) {
+	t.Parallel()
+	users := map[string]int{"alice": 1, "bob": 2}
+	loads := 0
+	load := func(_ context.Context, name string) (int, error) {
+		loads++
+		id, ok := users[name]
+		if !ok {
+			return 0, ErrNotFound
+		}
+		return id, nil
+	}
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.001})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	c, err := NewNegativeCache(bf, StringKey, load)
+	assert.NoError(t, err)
+	for name := range users {
+		assert.NoError(t, c.Inserted(name))
+	}
+
+	ctx := context.Background()
+	id, err := c.Get(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+	_, err = c.Get(ctx, "carol")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 1, loads, "Missing keys should not be loaded")
+
+	// Inserted keys are loaded.
+	users["carol"] = 3
+	assert.NoError(t, c.Inserted("carol"))
+	id, err = c.Get(ctx, "carol")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+	assert.Equal(t, 2, loads)
+
+	_, err = NewNegativeCache[string, int](bf, StringKey, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package gobloom
+
+import "context"
+
+// Observer receives the operations of a filter, so they can be reported to a tracing or metrics
+// system. The OpenTelemetry observer of the gobloomotel package, for example, starts a span in Batch
+// and increments counters in the other methods. The methods are called synchronously, possibly
+// concurrently, and must be fast.
+type Observer interface {
+	// Batch is called when a batch operation, like AddMany, starts on the given number of items.
+	// The returned function, if not nil, is called with the result of the operation when it ends.
+	Batch(op string, items int) (end func(error))
+	// Added is called after items were added.
+	Added(items int)
+	// Tested is called after items were tested, positives of them being reported as present.
+	Tested(items, positives int)
+	// Scaled is called after a scalable filter added a layer, with the new number of layers.
+	Scaled(layers int)
+}
+
+// ContextObserver is an Observer receiving the context of batch operations, like AddManyCtx, so the spans
+// it starts join the trace of the caller. The gobloomotel package implements it with OpenTelemetry.
+type ContextObserver interface {
+	Observer
+	// BatchCtx is called instead of Batch when a batch operation starts, with its context.
+	BatchCtx(ctx context.Context, op string, items int) (end func(error))
+}
+
+// WithObserver reports the operations of the filter to the observer. WithBitSetBackend is not supported.
+func WithObserver(obs Observer) Option {
+	return func(o *options) { o.observer = obs }
+}
+
+// observeTest reports a test of one item to the observer, if any.
+func observeTest(obs Observer, present bool) {
+	if obs == nil {
+		return
+	}
+	if present {
+		obs.Tested(1, 1)
+	} else {
+		obs.Tested(1, 0)
+	}
+}
+
+// observeBatch reports the start of a batch operation to the observer, if any, and returns the function
+// to call when it ends, nil if none.
+func observeBatch(ctx context.Context, obs Observer, op string, items int) func(error) {
+	switch o := obs.(type) {
+	case nil:
+		return nil
+	case ContextObserver:
+		return o.BatchCtx(ctx, op, items)
+	default:
+		return o.Batch(op, items)
+	}
+}
@@ -0,0 +1,120 @@
+package gobloom
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingObserver records the operations it observes.
+type recordingObserver struct {
+	mu                            sync.Mutex
+	batches                       []string
+	ended                         int
+	added, tested, positives, max int
+}
+
+func (o *recordingObserver) Batch(op string, items int) func(error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.batches = append(o.batches, op+":"+strconv.Itoa(items))
+	return func(error) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.ended++
+	}
+}
+
+func (o *recordingObserver) Added(items int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.added += items
+}
+
+func (o *recordingObserver) Tested(items, positives int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tested += items
+	o.positives += positives
+}
+
+func (o *recordingObserver) Scaled(layers int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.max = layers
+}
+
+func TestWithObserver(t *testing.T) {
+	t.Parallel()
+	obs := &recordingObserver{}
+	f, err := NewWithOptions(1000, 0.01, WithObserver(obs))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	bf := f.(*BloomFilter)
+
+	assert.NoError(t, bf.Add([]byte("foo")))
+	assert.NoError(t, bf.AddMany([][]byte{[]byte("bar"), []byte("baz")}))
+	_, _ = bf.Test([]byte("foo"))
+	_, _ = bf.Test([]byte("missing"))
+	assert.Equal(t, []string{"AddMany:2"}, obs.batches)
+	assert.Equal(t, 1, obs.ended, "The batch should have ended")
+	assert.Equal(t, 3, obs.added)
+	assert.Equal(t, 2, obs.tested)
+	assert.Equal(t, 1, obs.positives)
+
+	_, err = NewWithOptions(1000, 0.01, WithObserver(obs), WithBitSetBackend(mustRedisBitSet(t)))
+	assert.Error(t, err, "Observers should not be supported with bit set backends")
+}
+
+// contextObserver records the context values of the batches it observes.
+type contextObserver struct {
+	recordingObserver
+	values []any
+}
+
+type contextKey struct{}
+
+func (o *contextObserver) BatchCtx(ctx context.Context, op string, items int) func(error) {
+	o.mu.Lock()
+	o.values = append(o.values, ctx.Value(contextKey{}))
+	o.mu.Unlock()
+	return o.Batch(op, items)
+}
+
+func TestWithObserver_Context(t *testing.T) {
+	t.Parallel()
+	obs := &contextObserver{}
+	f, err := NewWithOptions(1000, 0.01, WithObserver(obs), OnAdd(func(HookEvent) {}))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	bf := f.(*BloomFilter)
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "trace")
+	assert.NoError(t, bf.AddManyCtx(ctx, [][]byte{[]byte("foo"), []byte("bar")}))
+	assert.NoError(t, bf.AddMany([][]byte{[]byte("baz")}))
+	assert.Equal(t, []any{"trace", nil}, obs.values, "The context should reach the observer through the hooks")
+	assert.Equal(t, []string{"AddMany:2", "AddMany:1"}, obs.batches)
+	assert.Equal(t, 2, obs.ended)
+	assert.Equal(t, 3, obs.added)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, bf.AddManyCtx(ctx, [][]byte{[]byte("qux")}), context.Canceled)
+	assert.Equal(t, 3, obs.added, "Nothing should be added once the context is done")
+}
+
+func TestScalableBloomFilter_Observer(t *testing.T) {
+	t.Parallel()
+	obs := &recordingObserver{}
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2, Observer: obs})
+	assert.NoError(t, err, "Error initializing scalable Bloom filter")
+	for i := 0; i < 2000; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	_, _ = sbf.Test([]byte("1"))
+	assert.Equal(t, 2000, obs.added, "Items should be reported once, not by each layer")
+	assert.Equal(t, len(sbf.filters()), obs.max, "Each new layer should be reported")
+	assert.Equal(t, 1, obs.tested)
+	assert.Equal(t, 1, obs.positives)
+}
@@ -0,0 +1,194 @@
+package gobloom
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Option configures a filter created with NewWithOptions.
+type Option func(*options)
+
+// options holds the configuration built by the options.
+type options struct {
+	params     Params
+	bits       BitSet
+	randomSeed bool
+	cacheSize  int
+	stripes    int
+	observer   Observer
+	hooks      Hooks
+
+	deterministic bool
+}
+
+// WithHasher sets the hash provider. Defaults to MurMur3Hasher.
+func WithHasher(h Hasher) Option {
+	return func(o *options) { o.params.Hasher = h }
+}
+
+// WithLockType sets the lock type. Defaults to ExclusiveLock.
+func WithLockType(l LockType) Option {
+	return func(o *options) { o.params.LockType = l }
+}
+
+// WithSeed uses a MurMur3Hasher with the given seed, replacing any hasher set before.
+func WithSeed(seed uint32) Option {
+	return func(o *options) { o.params.Hasher = NewMurMur3HasherWithSeed(seed) }
+}
+
+// WithRandomSeed seeds the hasher with a random seed, so independent filters hash the same items to unrelated
+// positions, and the positions of an item can't be known in advance to craft items colliding with it.
+// The seed is part of the hasher state, so it is kept when the filter is serialized. It is applied after
+// the other options, to a MurMur3Hasher, MurMur3x128Hasher, XXHasher, FNVHasher, WyHasher, or a DoubleHasher over one of them.
+func WithRandomSeed() Option {
+	return func(o *options) { o.randomSeed = true }
+}
+
+// WithDeterministic pins the hash seeds, so two runs, or two machines, adding the same items give
+// bit-identical filters, as needed by golden-file tests and cross-node verification. The hasher seeds are
+// the ones set explicitly, or the defaults, and WithRandomSeed is rejected. Bloom filters have no other
+// randomized behavior. See ParamsCuckoo.Deterministic for cuckoo filters, and Params.Deterministic for the
+// Decay of counting and spectral filters.
+func WithDeterministic() Option {
+	return func(o *options) { o.deterministic = true }
+}
+
+// WithStripes uses LockTypeStriped with the given number of stripes. More stripes make concurrent writers
+// less likely to wait for each other, but operations locking the whole filter slower. Defaults to 64.
+func WithStripes(stripes int) Option {
+	return func(o *options) {
+		o.params.LockType = LockTypeStriped
+		o.stripes = stripes
+	}
+}
+
+// WithHashCache caches the hashes of the given number of most recently used items, so workloads testing
+// the same hot items over and over skip hashing them. It is disabled by default, since looking up the cache
+// costs about as much as hashing short items. WithBitSetBackend is not supported.
+func WithHashCache(size int) Option {
+	return func(o *options) { o.cacheSize = size }
+}
+
+// WithBitSetBackend stores the bits in the given BitSet, see NewWithBitSet.
+func WithBitSetBackend(bits BitSet) Option {
+	return func(o *options) { o.bits = bits }
+}
+
+// NewWithOptions creates a new Bloom filter with the given number of elements (n) and false positive rate (fp),
+// configured by the options, so new settings can be added without changing the Params structs.
+// It returns a *BloomFilter, or a *BitSetBloomFilter when WithBitSetBackend is used.
+func NewWithOptions(n uint64, fp float64, opts ...Option) (Interface, error) {
+	o, err := newOptions(Params{N: n, FalsePositiveRate: fp}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.bits != nil {
+		if o.cacheSize > 0 {
+			return nil, fmt.Errorf("hash caches are not supported with bit set backends")
+		}
+		if o.observer != nil || !o.hooks.empty() {
+			return nil, fmt.Errorf("observers and hooks are not supported with bit set backends")
+		}
+		return NewWithBitSet(o.params, o.bits)
+	}
+	bf, err := New(o.params)
+	if err != nil {
+		return nil, err
+	}
+	o.apply(bf)
+	return bf, nil
+}
+
+// NewFromMemory creates the Bloom filter with the largest capacity for the false positive rate (fp)
+// whose bit set fits in the given number of bytes, rounded down to a multiple of 8.
+// The number of hash functions is the optimal one for fp. WithBitSetBackend is not supported.
+func NewFromMemory(bytes uint64, fp float64, opts ...Option) (*BloomFilter, error) {
+	o, err := newOptions(Params{FalsePositiveRate: fp}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.bits != nil {
+		return nil, fmt.Errorf("bit set backends are not supported")
+	}
+	if bytes < 8 {
+		return nil, fmt.Errorf("memory budget must be at least 8 bytes")
+	}
+	m := bytes / 8 * 64
+	o.params.N = uint64(float64(m) * math.Pow(math.Log(2), 2) / -math.Log(fp))
+	applyDefaults(&o.params)
+	if err := validateParams(o.params); err != nil {
+		return nil, err
+	}
+	k := uint64(math.Ceil(-math.Log2(fp)))
+	bf, err := newBloomFilter(m, k, o.params)
+	if err != nil {
+		return nil, err
+	}
+	o.apply(bf)
+	return bf, nil
+}
+
+// newOptions applies the options to the parameters.
+func newOptions(p Params, opts []Option) (options, error) {
+	o := options{params: p}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.hooks.validate(); err != nil {
+		return o, err
+	}
+	if o.deterministic && o.randomSeed {
+		return o, fmt.Errorf("random seeds cannot be used in deterministic mode")
+	}
+	if o.randomSeed {
+		if o.params.Hasher == nil {
+			o.params.Hasher = NewMurMur3Hasher()
+		}
+		var seed [8]byte
+		if _, err := rand.Read(seed[:]); err != nil {
+			return o, err
+		}
+		h, err := reseed(o.params.Hasher, binary.LittleEndian.Uint64(seed[:]))
+		if err != nil {
+			return o, err
+		}
+		o.params.Hasher = h
+	}
+	return o, nil
+}
+
+// apply applies the options that are not parameters to the Bloom filter.
+func (o *options) apply(bf *BloomFilter) {
+	if o.cacheSize > 0 {
+		bf.cache = newProbeCache(o.cacheSize)
+	}
+	if o.stripes > 0 && o.params.LockType == LockTypeStriped {
+		bf.mutex = NewStripedMutex(o.stripes)
+	}
+	bf.observer = o.hooks.observer(o.observer, bf)
+}
+
+// reseed returns a copy of the hasher using the given seed.
+func reseed(h Hasher, seed uint64) (Hasher, error) {
+	switch h := h.(type) {
+	case *MurMur3Hasher:
+		return NewMurMur3HasherWithSeed(uint32(seed)), nil
+	case *MurMur3x128Hasher:
+		return NewMurMur3x128HasherWithSeed(uint32(seed)), nil
+	case *XXHasher:
+		return NewXXHasherWithSeed(seed), nil
+	case *FNVHasher:
+		return NewFNVHasherWithSeed(seed), nil
+	case *WyHasher:
+		return NewWyHasherWithSeed(seed), nil
+	case *DoubleHasher:
+		base, err := reseed(h.base, seed)
+		if err != nil {
+			return nil, err
+		}
+		return &DoubleHasher{base: base, strategy: h.strategy}, nil
+	}
+	return nil, fmt.Errorf("hasher %T does not support seeds", h)
+}
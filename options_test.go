@@ -0,0 +1,127 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	t.Parallel()
+	f, err := NewWithOptions(1000, 0.01)
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	bf := f.(*BloomFilter)
+	assert.Equal(t, uint64(1000), bf.Capacity())
+	assert.IsType(t, &ExclusiveMutex{}, bf.mutex)
+
+	f, err = NewWithOptions(1000, 0.01, WithLockType(LockTypeReadWrite), WithHasher(NewBitsAndBloomsHasher()))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	bf = f.(*BloomFilter)
+	assert.IsType(t, &ReadWriteMutex{}, bf.mutex)
+	assert.IsType(t, &BitsAndBloomsHasher{}, bf.hasher)
+
+	f, err = NewWithOptions(1000, 0.01, WithBitSetBackend(mustRedisBitSet(t)))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.IsType(t, &BitSetBloomFilter{}, f)
+
+	_, err = NewWithOptions(0, 0.01)
+	assert.Error(t, err, "Invalid parameters should be rejected")
+}
+
+func TestNewWithOptions_Seed(t *testing.T) {
+	t.Parallel()
+	a, err := NewWithOptions(1000, 0.01, WithSeed(1))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	b, err := NewWithOptions(1000, 0.01, WithSeed(2))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, a.Add([]byte("foo")))
+	assert.NoError(t, b.Add([]byte("foo")))
+	assert.NotEqual(t, a.(*BloomFilter).Bits(), b.(*BloomFilter).Bits(), "Seeds should change the positions")
+
+	// The seed is kept when encoding.
+	data, err := a.(*BloomFilter).MarshalBinary()
+	assert.NoError(t, err)
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, a.(*BloomFilter).Bits(), restored.Bits())
+	found, err := restored.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, found, "Item should be present after restoring a seeded filter")
+	assert.Error(t, a.(*BloomFilter).Union(b.(*BloomFilter)), "Filters with different seeds should not be combined")
+}
+
+func TestNewWithOptions_RandomSeed(t *testing.T) {
+	t.Parallel()
+	a, err := NewWithOptions(1000, 0.01, WithRandomSeed())
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	b, err := NewWithOptions(1000, 0.01, WithRandomSeed())
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NotEqual(t, a.(*BloomFilter).hasher, b.(*BloomFilter).hasher, "Filters should get different seeds")
+
+	assert.NoError(t, a.Add([]byte("foo")))
+	data, err := a.(*BloomFilter).MarshalBinary()
+	assert.NoError(t, err)
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, a.(*BloomFilter).hasher, restored.hasher, "The seed should be kept when encoding")
+	found, err := restored.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, found, "Item should be present after restoring a randomly seeded filter")
+
+	c, err := NewWithOptions(1000, 0.01, WithHasher(NewDoubleHasher(NewXXHasher())), WithRandomSeed())
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NotEqual(t, NewXXHasher(), c.(*BloomFilter).hasher.(*DoubleHasher).base, "The base hasher should be seeded")
+
+	_, err = NewWithOptions(1000, 0.01, WithHasher(NewBitsAndBloomsHasher()), WithRandomSeed())
+	assert.Error(t, err, "Hashers without seeds should be rejected")
+}
+
+func mustRedisBitSet(t *testing.T) *RedisBitSet {
+	bits, err := NewRedisBitSet(newFakeRedis(), "filter")
+	assert.NoError(t, err)
+	return bits
+}
+
+func TestNewFromMemory(t *testing.T) {
+	t.Parallel()
+	bf, err := NewFromMemory(1<<20, 0.01)
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Equal(t, uint64(1<<23), bf.M())
+	assert.Len(t, bf.UnsafeBits(), 1<<17)
+	assert.Equal(t, uint64(7), bf.K())
+	assert.Equal(t, uint64(875175), bf.Capacity())
+
+	// A filter sized by element count for the same capacity fits in the budget.
+	sized, err := New(Params{N: bf.Capacity(), FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.LessOrEqual(t, sized.M(), bf.M())
+
+	bf, err = NewFromMemory(100, 0.01, WithLockType(LockTypeNone))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Equal(t, uint64(768), bf.M())
+	assert.Nil(t, bf.mutex)
+
+	_, err = NewFromMemory(7, 0.01)
+	assert.Error(t, err, "Budgets under a word should be rejected")
+	_, err = NewFromMemory(1024, 1.5)
+	assert.Error(t, err, "Invalid rates should be rejected")
+	_, err = NewFromMemory(1024, 0.01, WithBitSetBackend(mustRedisBitSet(t)))
+	assert.Error(t, err, "Bit set backends should be rejected")
+}
+
+func TestNewWithOptions_Deterministic(t *testing.T) {
+	t.Parallel()
+	build := func() []uint64 {
+		f, err := NewWithOptions(1000, 0.01, WithDeterministic(), WithHasher(NewXXHasher()))
+		assert.NoError(t, err, "Failed to create Bloom filter")
+		for i := 0; i < 500; i++ {
+			assert.NoError(t, f.Add([]byte(strconv.Itoa(i))))
+		}
+		return f.(*BloomFilter).Bits()
+	}
+	assert.Equal(t, build(), build(), "Deterministic filters should be bit-identical")
+
+	_, err := NewWithOptions(1000, 0.01, WithDeterministic(), WithRandomSeed())
+	assert.Error(t, err, "Random seeds should be rejected in deterministic mode")
+}
@@ -0,0 +1,146 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// pageWords is the number of bit set words stored in each page, 4 KiB.
+const pageWords = 512
+
+// PageStore is an embedded key-value store holding the pages of a filter, like bbolt or Badger,
+// used through a small adapter so this package doesn't depend on one.
+type PageStore interface {
+	// Get returns the value of the key, or nil if the key doesn't exist.
+	Get(key []byte) ([]byte, error)
+	// Update sets the values of the keys in a single atomic transaction.
+	Update(values map[string][]byte) error
+}
+
+// PagedBloomFilter is a Bloom filter persisted as fixed size pages in a PageStore.
+// Save only writes the pages changed since the previous save, so very large filters can be persisted
+// often and incrementally. Each save is a single transaction, so a crash leaves the previous save intact.
+type PagedBloomFilter struct {
+	*BloomFilter
+	store  PageStore
+	prefix string
+	saved  Generation // The generation of the last save
+	save   sync.Mutex // Serializes saves
+}
+
+// OpenPaged restores the Bloom filter stored in the store under the key prefix, or creates a new one
+// if there is none. When the filter exists, the parameters and hasher stored with it are used, and only
+// the lock type is taken from p. When it is created, the hasher must implement NamedHasher.
+//
+// The filter is stored under the key prefix+"meta", holding m, k and the hasher encoded with the
+// wire format, and the keys prefix+"page" followed by the page index as a big-endian uint64.
+func OpenPaged(store PageStore, prefix string, p Params) (*PagedBloomFilter, error) {
+	if store == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+	bf, err := New(p)
+	if err != nil {
+		return nil, err
+	}
+	pbf := &PagedBloomFilter{BloomFilter: bf, store: store, prefix: prefix}
+	meta, err := store.Get(pbf.metaKey())
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		params := binary.AppendUvarint(nil, bf.m)
+		params = binary.AppendUvarint(params, bf.k)
+		params, err = appendHasher(params, bf.hasher)
+		if err != nil {
+			return nil, err
+		}
+		meta := encodeFilter(filterTypeBloomPages, params, nil)
+		if err := store.Update(map[string][]byte{string(pbf.metaKey()): meta}); err != nil {
+			return nil, err
+		}
+	} else if err := pbf.load(meta); err != nil {
+		return nil, err
+	}
+	pbf.saved = bf.Generation()
+	return pbf, nil
+}
+
+// load restores the filter from its metadata and pages.
+func (pbf *PagedBloomFilter) load(meta []byte) error {
+	params, _, err := decodeFilter(filterTypeBloomPages, meta)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	m := r.uvarint()
+	k := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	if err := checkMK(m, k); err != nil {
+		return err
+	}
+	bitSet := make([]uint64, wordsFor(m))
+	for page := 0; page*pageWords < len(bitSet); page++ {
+		words := bitSet[page*pageWords : min((page+1)*pageWords, len(bitSet))]
+		data, err := pbf.store.Get(pbf.pageKey(page))
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			continue // Pages are only written once they have bits set
+		}
+		if err := checkPayload(data, 8, uint64(len(words))); err != nil {
+			return fmt.Errorf("page %d: %w", page, err)
+		}
+		copy(words, readWords(data))
+	}
+	return pbf.restore(m, k, hasher, bitSet)
+}
+
+// Save writes the pages changed since the previous save to the store, in a single transaction.
+func (pbf *PagedBloomFilter) Save() error {
+	pbf.save.Lock()
+	defer pbf.save.Unlock()
+	g := pbf.Generation()
+	pages := pbf.changedPages()
+	if len(pages) > 0 {
+		if err := pbf.store.Update(pages); err != nil {
+			return err
+		}
+	}
+	pbf.saved = g
+	return nil
+}
+
+// changedPages returns the content of the pages with a block changed since the previous save, by key.
+func (pbf *PagedBloomFilter) changedPages() map[string][]byte {
+	if pbf.mutex != nil {
+		pbf.mutex.RLock()
+		defer pbf.mutex.RUnlock()
+	}
+	pages := make(map[string][]byte)
+	for page := 0; page*pageWords < len(pbf.bitSet); page++ {
+		first := page * pageWords / diffBlockWords
+		last := min((page+1)*pageWords/diffBlockWords, len(pbf.stamps))
+		for _, stamp := range pbf.stamps[first:last] {
+			if stamp > uint64(pbf.saved) {
+				words := pbf.bitSet[page*pageWords : min((page+1)*pageWords, len(pbf.bitSet))]
+				pages[string(pbf.pageKey(page))] = appendWords(nil, words)
+				break
+			}
+		}
+	}
+	return pages
+}
+
+func (pbf *PagedBloomFilter) metaKey() []byte {
+	return append([]byte(pbf.prefix), "meta"...)
+}
+
+func (pbf *PagedBloomFilter) pageKey(page int) []byte {
+	key := append([]byte(pbf.prefix), "page"...)
+	return binary.BigEndian.AppendUint64(key, uint64(page))
+}
@@ -0,0 +1,68 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePageStore is an in-memory PageStore.
+type fakePageStore struct {
+	values  map[string][]byte
+	updates []map[string][]byte
+}
+
+func (s *fakePageStore) Get(key []byte) ([]byte, error) {
+	return s.values[string(key)], nil
+}
+
+func (s *fakePageStore) Update(values map[string][]byte) error {
+	s.updates = append(s.updates, values)
+	for k, v := range values {
+		s.values[k] = v
+	}
+	return nil
+}
+
+func TestPagedBloomFilter_SaveAndReopen(t *testing.T) {
+	t.Parallel()
+	store := &fakePageStore{values: make(map[string][]byte)}
+	p := Params{N: 100000, FalsePositiveRate: 0.01}
+	pbf, err := OpenPaged(store, "filter/", p)
+	assert.NoError(t, err, "Failed to create paged Bloom filter")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, pbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.NoError(t, pbf.Save())
+	pages := (int(pbf.M()) + 64*pageWords - 1) / (64 * pageWords)
+	assert.Len(t, store.updates[len(store.updates)-1], pages, "All pages should be saved")
+
+	assert.NoError(t, pbf.Add([]byte("foo")))
+	assert.NoError(t, pbf.Save())
+	assert.LessOrEqual(t, len(store.updates[len(store.updates)-1]), int(pbf.K()), "Only changed pages should be saved")
+	updates := len(store.updates)
+	assert.NoError(t, pbf.Save())
+	assert.Len(t, store.updates, updates, "Nothing should be saved without changes")
+
+	// The parameters stored win over the given ones.
+	reopened, err := OpenPaged(store, "filter/", Params{N: 10, FalsePositiveRate: 0.1})
+	assert.NoError(t, err, "Failed to reopen paged Bloom filter")
+	assert.Equal(t, pbf.Bits(), reopened.Bits())
+	b, err := reopened.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after reopening")
+
+	other, err := OpenPaged(store, "other/", Params{N: 10, FalsePositiveRate: 0.1})
+	assert.NoError(t, err, "Failed to create paged Bloom filter")
+	assert.NotEqual(t, pbf.M(), other.M(), "Filters with different prefixes should be independent")
+}
+
+func TestPagedBloomFilter_Invalid(t *testing.T) {
+	t.Parallel()
+	store := &fakePageStore{values: map[string][]byte{"filter/meta": []byte("garbage")}}
+	_, err := OpenPaged(store, "filter/", Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+	_, err = OpenPaged(nil, "filter/", Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.Error(t, err)
+}
@@ -0,0 +1,250 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Thrift compact protocol field types used by the Parquet bloom filter header.
+const (
+	thriftStop   = 0
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// parquetHeader is the BloomFilterHeader of a Parquet bloom filter page, as encoded with the Thrift compact
+// protocol: the size of the bit set, and the algorithm, hash and compression unions, set to BLOCK, XXHASH and
+// UNCOMPRESSED, the only variants the specification defines, which have no fields.
+var parquetHeader = []byte{
+	0x1c, 0x1c, 0x00, 0x00, // 2: algorithm, 1: BLOCK
+	0x1c, 0x1c, 0x00, 0x00, // 3: hash, 1: XXHASH
+	0x1c, 0x1c, 0x00, 0x00, // 4: compression, 1: UNCOMPRESSED
+	0x00, // Stop
+}
+
+// ImportParquet reads a bloom filter page of a Parquet column chunk, the Thrift-encoded BloomFilterHeader
+// followed by the bit set, stored at the bloom_filter_offset of the column metadata. Only the header and
+// the bit set are read from r, so the page can be read from a reader positioned at the offset of the file.
+// The filter uses ExclusiveLock.
+func ImportParquet(r io.Reader) (*SplitBlockBloomFilter, error) {
+	tr := &thriftReader{r: r}
+	numBytes, err := tr.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	if numBytes < sbbfMinBytes || numBytes > sbbfMaxBytes || numBytes%sbbfBlockBytes != 0 {
+		return nil, fmt.Errorf("%w: invalid bit set size of %d bytes", ErrInvalidEncoding, numBytes)
+	}
+	sbf, err := newSplitBlock(uint64(numBytes), LockTypeExclusive)
+	if err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, sbf.blocks); err != nil {
+		return nil, err
+	}
+	return sbf, nil
+}
+
+// ExportParquet writes the split block Bloom filter as the bloom filter page of a Parquet column chunk,
+// the Thrift-encoded BloomFilterHeader followed by the bit set, which Parquet writers store before the
+// column index and reference with the bloom_filter_offset and bloom_filter_length of the column metadata.
+func (sbf *SplitBlockBloomFilter) ExportParquet(w io.Writer) error {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	numBytes := 4 * uint64(len(sbf.blocks))
+	header := binary.AppendUvarint([]byte{0x15}, numBytes<<1) // 1: numBytes, a zigzag encoded i32
+	if _, err := w.Write(append(header, parquetHeader...)); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, sbf.blocks)
+}
+
+// thriftReader decodes the Thrift compact protocol one byte at a time, so it doesn't read past the
+// encoded struct.
+type thriftReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (tr *thriftReader) ReadByte() (byte, error) {
+	if _, err := io.ReadFull(tr.r, tr.buf[:]); err != nil {
+		return 0, err
+	}
+	return tr.buf[0], nil
+}
+
+// readHeader reads a BloomFilterHeader, returning its numBytes, and an error if it uses an algorithm,
+// hash or compression that is not supported.
+func (tr *thriftReader) readHeader() (int64, error) {
+	numBytes := int64(-1)
+	var id int16
+	for {
+		fieldID, typ, err := tr.readField(&id)
+		if err != nil {
+			return 0, err
+		}
+		if typ == thriftStop {
+			break
+		}
+		switch {
+		case fieldID == 1 && typ == thriftI32:
+			if numBytes, err = binary.ReadVarint(tr); err != nil {
+				return 0, err
+			}
+		case fieldID >= 2 && fieldID <= 4 && typ == thriftStruct:
+			variant, err := tr.readUnion()
+			if err != nil {
+				return 0, err
+			}
+			if variant != 1 {
+				names := map[int16]string{2: "algorithm", 3: "hash", 4: "compression"}
+				return 0, fmt.Errorf("%w: unsupported %s %d", ErrInvalidEncoding, names[fieldID], variant)
+			}
+		default:
+			if err := tr.skip(typ); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if numBytes < 0 {
+		return 0, fmt.Errorf("%w: missing bit set size", ErrInvalidEncoding)
+	}
+	return numBytes, nil
+}
+
+// readField reads the header of a field of a struct, returning its id and type. last is the id of the
+// previous field of the struct, which short field headers are relative to.
+func (tr *thriftReader) readField(last *int16) (int16, byte, error) {
+	b, err := tr.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	typ := b & 0x0f
+	if typ == thriftStop {
+		return 0, thriftStop, nil
+	}
+	if delta := int16(b >> 4); delta != 0 {
+		*last += delta
+	} else {
+		id, err := binary.ReadVarint(tr)
+		if err != nil {
+			return 0, 0, err
+		}
+		*last = int16(id)
+	}
+	return *last, typ, nil
+}
+
+// readUnion reads a union whose variants are empty structs, returning the id of the variant that is set.
+func (tr *thriftReader) readUnion() (int16, error) {
+	var id, variant int16
+	for {
+		fieldID, typ, err := tr.readField(&id)
+		if err != nil {
+			return 0, err
+		}
+		if typ == thriftStop {
+			return variant, nil
+		}
+		if err := tr.skip(typ); err != nil {
+			return 0, err
+		}
+		variant = fieldID
+	}
+}
+
+// skip skips a value of the type.
+func (tr *thriftReader) skip(typ byte) error {
+	switch typ {
+	case thriftTrue, thriftFalse:
+		return nil // Booleans are encoded in the field type
+	case thriftByte:
+		_, err := tr.ReadByte()
+		return err
+	case thriftI16, thriftI32, thriftI64:
+		_, err := binary.ReadVarint(tr)
+		return err
+	case thriftDouble:
+		_, err := io.CopyN(io.Discard, tr.r, 8)
+		return err
+	case thriftBinary:
+		n, err := binary.ReadUvarint(tr)
+		if err != nil {
+			return err
+		}
+		_, err = io.CopyN(io.Discard, tr.r, int64(n))
+		return err
+	case thriftList, thriftSet:
+		b, err := tr.ReadByte()
+		if err != nil {
+			return err
+		}
+		n := uint64(b >> 4)
+		if n == 15 {
+			if n, err = binary.ReadUvarint(tr); err != nil {
+				return err
+			}
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := tr.skipElement(b & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftMap:
+		n, err := binary.ReadUvarint(tr)
+		if err != nil || n == 0 {
+			return err
+		}
+		types, err := tr.ReadByte()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := tr.skipElement(types >> 4); err != nil {
+				return err
+			}
+			if err := tr.skipElement(types & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftStruct:
+		var id int16
+		for {
+			_, typ, err := tr.readField(&id)
+			if err != nil {
+				return err
+			}
+			if typ == thriftStop {
+				return nil
+			}
+			if err := tr.skip(typ); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("%w: unknown thrift type %d", ErrInvalidEncoding, typ)
+}
+
+// skipElement skips an element of a list, set or map, whose booleans are encoded as a byte.
+func (tr *thriftReader) skipElement(typ byte) error {
+	if typ == thriftTrue || typ == thriftFalse {
+		_, err := tr.ReadByte()
+		return err
+	}
+	return tr.skip(typ)
+}
@@ -0,0 +1,65 @@
+package gobloom
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitBlockBloomFilter_ExportImportParquet(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewSplitBlock(ParamsSplitBlock{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, sbf.Add([]byte("item-"+strconv.Itoa(i))))
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, sbf.ExportParquet(&buf))
+	buf.WriteString("next page") // Data following the page must not be read
+	imported, err := ImportParquet(&buf)
+	assert.NoError(t, err, "Failed to import Parquet bloom filter")
+	assert.Equal(t, "next page", buf.String())
+	assert.Equal(t, sbf.NumBytes(), imported.NumBytes())
+	for i := 0; i < 1000; i++ {
+		b, _ := imported.Test([]byte("item-" + strconv.Itoa(i)))
+		assert.True(t, b, "Imported filter should contain the added items")
+	}
+}
+
+func TestExportParquet_Header(t *testing.T) {
+	t.Parallel()
+	sbf, _ := NewSplitBlock(ParamsSplitBlock{N: 1, FalsePositiveRate: 0.5})
+	var buf bytes.Buffer
+	assert.NoError(t, sbf.ExportParquet(&buf))
+	header := []byte{0x15, 0x40, 0x1c, 0x1c, 0x00, 0x00, 0x1c, 0x1c, 0x00, 0x00, 0x1c, 0x1c, 0x00, 0x00, 0x00}
+	assert.Equal(t, header, buf.Bytes()[:len(header)], "Header should be the Thrift compact encoding of BloomFilterHeader")
+	assert.Len(t, buf.Bytes(), len(header)+32)
+}
+
+func TestImportParquet_Invalid(t *testing.T) {
+	t.Parallel()
+	bitSet := make([]byte, 32)
+	tests := []struct {
+		name   string
+		header []byte
+		valid  bool
+	}{
+		// An unknown field 5 of type binary is skipped.
+		{"unknown field", []byte{0x15, 0x40, 0x1c, 0x1c, 0x00, 0x00, 0x1c, 0x1c, 0x00, 0x00, 0x1c, 0x1c, 0x00, 0x00, 0x18, 0x02, 'h', 'i', 0x00}, true},
+		{"unsupported hash", []byte{0x15, 0x40, 0x1c, 0x1c, 0x00, 0x00, 0x1c, 0x2c, 0x00, 0x00, 0x1c, 0x1c, 0x00, 0x00, 0x00}, false},
+		{"missing size", []byte{0x1c, 0x1c, 0x00, 0x00, 0x00}, false},
+		{"invalid size", []byte{0x15, 0x30, 0x00}, false},
+		{"truncated", []byte{0x15}, false},
+	}
+	for _, tt := range tests {
+		_, err := ImportParquet(bytes.NewReader(append(tt.header, bitSet...)))
+		if tt.valid {
+			assert.NoError(t, err, tt.name)
+		} else {
+			assert.Error(t, err, tt.name)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package gobloom
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ Interface = (*PersistentBloomFilter)(nil)
+
+// PersistentBloomFilter is a Bloom filter that is saved to a file periodically, or after a number of writes,
+// and restored from it when created, so its state survives restarts.
+// The file is written atomically, so a crash while saving leaves the previous version of the file intact.
+type PersistentBloomFilter struct {
+	bf           *BloomFilter
+	path         string
+	flushEvery   uint64
+	onFlushError func(error)
+	writes       atomic.Uint64 // The number of writes since the last flush
+	flush        sync.Mutex    // Serializes flushes
+	stop         chan struct{} // Closed to stop the flush goroutine
+	done         chan struct{} // Closed once the flush goroutine returned
+	once         sync.Once     // Ensures stop is closed only once
+}
+
+// ParamsPersistent represents the parameters for creating a new persistent Bloom filter.
+type ParamsPersistent struct {
+	// Path is the file the filter is saved to.
+	Path string
+	// N is the number of elements expected to be added to the Bloom filter.
+	// It is ignored when the filter is restored from the file.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate.
+	// It is ignored when the filter is restored from the file.
+	FalsePositiveRate float64
+	// FlushInterval is the interval between saves. Zero disables periodic saves.
+	FlushInterval time.Duration
+	// FlushEvery is the number of writes after which the filter is saved. Zero disables it.
+	FlushEvery uint64
+	// OnFlushError is called with the errors of periodic saves, which are otherwise ignored.
+	OnFlushError func(error)
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	// It must implement NamedHasher, and be registered with RegisterHasher unless it is provided by this package.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewPersistent restores the persistent Bloom filter from its file, or creates a new one if the file doesn't exist,
+// and starts saving it on every interval if FlushInterval is set.
+// Close must be called once the filter is no longer used, to save it a last time.
+func NewPersistent(p ParamsPersistent) (*PersistentBloomFilter, error) {
+	if p.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if p.FlushInterval < 0 {
+		return nil, fmt.Errorf("invalid flush interval, must not be negative, got %s", p.FlushInterval)
+	}
+	params := Params{N: p.N, FalsePositiveRate: p.FalsePositiveRate, Hasher: p.Hasher, LockType: p.LockType}
+	bf, err := New(params)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p.Path)
+	if err == nil {
+		if err := bf.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("restoring %s: %w", p.Path, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	pbf := &PersistentBloomFilter{
+		bf:           bf,
+		path:         p.Path,
+		flushEvery:   p.FlushEvery,
+		onFlushError: p.OnFlushError,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	if p.FlushInterval > 0 {
+		go pbf.run(p.FlushInterval)
+	} else {
+		close(pbf.done)
+	}
+	return pbf, nil
+}
+
+// run saves the filter on every interval, until Close is called.
+func (pbf *PersistentBloomFilter) run(interval time.Duration) {
+	defer close(pbf.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := pbf.Flush(); err != nil && pbf.onFlushError != nil {
+				pbf.onFlushError(err)
+			}
+		case <-pbf.stop:
+			return
+		}
+	}
+}
+
+// Add adds an item to the filter, and saves it if FlushEvery writes were made since the last save.
+func (pbf *PersistentBloomFilter) Add(data []byte) error {
+	if err := pbf.bf.Add(data); err != nil {
+		return err
+	}
+	if pbf.flushEvery > 0 && pbf.writes.Add(1) >= pbf.flushEvery {
+		return pbf.Flush()
+	}
+	return nil
+}
+
+// Test checks if an item is in the filter.
+func (pbf *PersistentBloomFilter) Test(data []byte) (bool, error) {
+	return pbf.bf.Test(data)
+}
+
+// Flush saves the filter to its file atomically, by writing a temporary file next to it and renaming it.
+func (pbf *PersistentBloomFilter) Flush() error {
+	pbf.flush.Lock()
+	defer pbf.flush.Unlock()
+	pbf.writes.Store(0)
+	data, err := pbf.bf.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(pbf.path, data)
+}
+
+// Close stops the periodic saves and saves the filter a last time.
+// The filter can still be used, but it is no longer saved automatically.
+func (pbf *PersistentBloomFilter) Close() error {
+	pbf.once.Do(func() { close(pbf.stop) })
+	<-pbf.done
+	return pbf.Flush()
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of path, syncs it, and renames it to path,
+// so path always holds either its previous content or data.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
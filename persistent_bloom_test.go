@@ -0,0 +1,76 @@
+package gobloom
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentBloomFilter_Reopen(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "filter.bloom")
+	pbf, err := NewPersistent(ParamsPersistent{Path: path, N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create persistent Bloom filter")
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, pbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Filter should not be saved before Close")
+	assert.NoError(t, pbf.Close())
+
+	pbf, err = NewPersistent(ParamsPersistent{Path: path, N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to reopen persistent Bloom filter")
+	for i := 0; i < 100; i++ {
+		b, err := pbf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item should be present after reopening")
+	}
+	assert.NoError(t, pbf.Close())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "Temporary files should not be left behind")
+}
+
+func TestPersistentBloomFilter_FlushEvery(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "filter.bloom")
+	pbf, err := NewPersistent(ParamsPersistent{Path: path, N: 1000, FalsePositiveRate: 0.01, FlushEvery: 10})
+	assert.NoError(t, err, "Failed to create persistent Bloom filter")
+	defer pbf.Close()
+	for i := 0; i < 9; i++ {
+		assert.NoError(t, pbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Filter should not be saved before FlushEvery writes")
+	assert.NoError(t, pbf.Add([]byte("item-9")))
+	_, err = os.Stat(path)
+	assert.NoError(t, err, "Filter should be saved after FlushEvery writes")
+}
+
+func TestPersistentBloomFilter_FlushInterval(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "filter.bloom")
+	pbf, err := NewPersistent(ParamsPersistent{Path: path, N: 1000, FalsePositiveRate: 0.01, FlushInterval: 10 * time.Millisecond})
+	assert.NoError(t, err, "Failed to create persistent Bloom filter")
+	defer pbf.Close()
+	assert.NoError(t, pbf.Add([]byte("foo")))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond, "Filter should be saved periodically")
+}
+
+func TestPersistentBloomFilter_Corrupt(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "filter.bloom")
+	assert.NoError(t, os.WriteFile(path, []byte("not a filter"), 0o644))
+	_, err := NewPersistent(ParamsPersistent{Path: path, N: 1000, FalsePositiveRate: 0.01})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+	_, err = NewPersistent(ParamsPersistent{N: 1000, FalsePositiveRate: 0.01})
+	assert.Error(t, err, "Empty path should be rejected")
+}
@@ -0,0 +1,56 @@
+package gobloom
+
+import (
+	"container/list"
+	"sync"
+)
+
+// probeCache is a least recently used cache of the hashes of items, so workloads testing the same
+// hot items over and over don't hash them every time. It is safe for concurrent use.
+type probeCache struct {
+	size  int
+	mu    sync.Mutex
+	order *list.List               // The cached entries, from the most to the least recently used
+	items map[string]*list.Element // The entries by item
+}
+
+// probeCacheEntry is an item and its hashes.
+type probeCacheEntry struct {
+	key    string
+	hashes []uint64
+}
+
+func newProbeCache(size int) *probeCache {
+	return &probeCache{size: size, order: list.New(), items: make(map[string]*list.Element, size)}
+}
+
+// get copies the cached hashes of the data to out, and returns whether they were cached.
+func (c *probeCache) get(data []byte, out []uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[string(data)]
+	if !ok || len(e.Value.(*probeCacheEntry).hashes) != len(out) {
+		return false
+	}
+	c.order.MoveToFront(e)
+	copy(out, e.Value.(*probeCacheEntry).hashes)
+	return true
+}
+
+// put caches the hashes of the data, evicting the least recently used entry when the cache is full.
+func (c *probeCache) put(data []byte, hashes []uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[string(data)]; ok {
+		c.order.MoveToFront(e)
+		e.Value.(*probeCacheEntry).hashes = append(e.Value.(*probeCacheEntry).hashes[:0], hashes...)
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*probeCacheEntry).key)
+	}
+	entry := &probeCacheEntry{key: string(data), hashes: append([]uint64(nil), hashes...)}
+	c.items[entry.key] = c.order.PushFront(entry)
+}
@@ -0,0 +1,71 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeCache(t *testing.T) {
+	t.Parallel()
+	c := newProbeCache(2)
+	out := make([]uint64, 2)
+	assert.False(t, c.get([]byte("a"), out), "Empty cache should miss")
+
+	c.put([]byte("a"), []uint64{1, 2})
+	c.put([]byte("b"), []uint64{3, 4})
+	assert.True(t, c.get([]byte("a"), out))
+	assert.Equal(t, []uint64{1, 2}, out)
+
+	c.put([]byte("c"), []uint64{5, 6}) // Evicts b, the least recently used
+	assert.False(t, c.get([]byte("b"), out), "Least recently used entry should be evicted")
+	assert.True(t, c.get([]byte("a"), out))
+	assert.True(t, c.get([]byte("c"), out))
+	assert.Equal(t, []uint64{5, 6}, out)
+	assert.False(t, c.get([]byte("c"), make([]uint64, 3)), "Entries with another number of hashes should miss")
+}
+
+func TestNewWithOptions_HashCache(t *testing.T) {
+	t.Parallel()
+	f, err := NewWithOptions(1000, 0.01, WithHashCache(16))
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	bf := f.(*BloomFilter)
+	assert.NotNil(t, bf.cache)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	for i := 0; i < 100; i++ {
+		b, err := bf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item %d should be present", i)
+	}
+	assert.Equal(t, 16, bf.cache.order.Len(), "Cache should be bounded")
+
+	// Restoring a filter using another hasher must not use the cached hashes.
+	other, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: NewXXHasher()})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, other.Add([]byte("item-99")))
+	data, err := other.MarshalBinary()
+	assert.NoError(t, err)
+	assert.NoError(t, bf.UnmarshalBinary(data))
+	b, err := bf.Test([]byte("item-99"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after restoring")
+
+	_, err = NewWithOptions(1000, 0.01, WithHashCache(16), WithBitSetBackend(mustRedisBitSet(t)))
+	assert.Error(t, err, "Hash caches should be rejected with bit set backends")
+}
+
+func BenchmarkBloomFilter_TestHashCache(b *testing.B) {
+	data := make([]byte, 256)
+	for _, size := range []int{0, 64} {
+		b.Run(fmt.Sprintf("cache=%d", size), func(b *testing.B) {
+			f, _ := NewWithOptions(100000, 0.01, WithHashCache(size), WithLockType(LockTypeNone))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = f.Test(data)
+			}
+		})
+	}
+}
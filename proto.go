@@ -0,0 +1,116 @@
+package gobloom
+
+import (
+	"fmt"
+
+	"github.com/franciscoescher/gobloom/gobloompb"
+)
+
+// ToProto converts the Bloom filter to its protobuf message.
+// The hasher must implement NamedHasher.
+func (bf *BloomFilter) ToProto() (*gobloompb.BloomFilter, error) {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	name, state, err := marshalHasher(bf.hasher)
+	if err != nil {
+		return nil, err
+	}
+	return &gobloompb.BloomFilter{
+		M:      bf.m,
+		K:      bf.k,
+		Hasher: &gobloompb.Hasher{Name: name, State: state},
+		Bits:   appendWords(nil, bf.bitSet),
+	}, nil
+}
+
+// BloomFilterFromProto creates a Bloom filter from its protobuf message.
+// The hasher must be registered with RegisterHasher, unless it is a hasher provided by this package.
+// The filter uses ExclusiveLock.
+func BloomFilterFromProto(p *gobloompb.BloomFilter) (*BloomFilter, error) {
+	bf := &BloomFilter{}
+	if err := bf.UnmarshalProto(p); err != nil {
+		return nil, err
+	}
+	return bf, nil
+}
+
+// UnmarshalProto restores a Bloom filter from its protobuf message, like BloomFilterFromProto.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value BloomFilter.
+func (bf *BloomFilter) UnmarshalProto(p *gobloompb.BloomFilter) error {
+	if err := checkMK(p.GetM(), p.GetK()); err != nil {
+		return err
+	}
+	if len(p.GetBits())%8 != 0 || uint64(len(p.GetBits())/8) != wordsFor(p.GetM()) {
+		return fmt.Errorf("%w: bit set size does not match m", ErrInvalidEncoding)
+	}
+	hasher, err := unmarshalHasher(p.GetHasher().GetName(), p.GetHasher().GetState())
+	if err != nil {
+		return err
+	}
+	return bf.restore(p.GetM(), p.GetK(), hasher, readWords(p.GetBits()))
+}
+
+// ToProto converts the scalable Bloom filter to its protobuf message, including every layer.
+// The layers must share one hasher, implementing NamedHasher. The message has no MaxExpectedItems hint,
+// so filters created from it size new layers off the number of items added.
+func (sbf *ScalableBloomFilter) ToProto() (*gobloompb.ScalableBloomFilter, error) {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	filters := sbf.filters()
+	if err := checkHashers(filters); err != nil {
+		return nil, err
+	}
+	layers := make([]*gobloompb.BloomFilter, len(filters))
+	for i, bf := range filters {
+		layer, err := bf.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		layers[i] = layer
+	}
+	return &gobloompb.ScalableBloomFilter{
+		Layers:              layers,
+		N:                   sbf.n,
+		FalsePositiveRate:   sbf.fpRate,
+		FalsePositiveGrowth: sbf.fpGrowth,
+	}, nil
+}
+
+// ScalableBloomFilterFromProto creates a scalable Bloom filter from its protobuf message, whose layers share one hasher.
+// The hasher must be registered with RegisterHasher, unless it is a hasher provided by this package.
+// The filter and its layers use ExclusiveLock.
+func ScalableBloomFilterFromProto(p *gobloompb.ScalableBloomFilter) (*ScalableBloomFilter, error) {
+	if len(p.GetLayers()) == 0 {
+		return nil, fmt.Errorf("%w: scalable Bloom filter has no layers", ErrInvalidEncoding)
+	}
+	if p.GetFalsePositiveRate() <= 0 || p.GetFalsePositiveRate() >= 1 || p.GetFalsePositiveGrowth() <= 0 {
+		return nil, fmt.Errorf("%w: invalid false positive rate or growth", ErrInvalidEncoding)
+	}
+	filters := make([]*BloomFilter, len(p.GetLayers()))
+	for i, layer := range p.GetLayers() {
+		bf, err := BloomFilterFromProto(layer)
+		if err != nil {
+			return nil, err
+		}
+		filters[i] = bf
+	}
+	if err := shareHasher(filters); err != nil {
+		return nil, err
+	}
+	sbf := &ScalableBloomFilter{
+		n:        p.GetN(),
+		fpRate:   p.GetFalsePositiveRate(),
+		fpGrowth: p.GetFalsePositiveGrowth(),
+
+		layerGrowth: defaultLayerGrowth,
+
+		lockType: LockTypeExclusive,
+		mutex:    &ExclusiveMutex{},
+	}
+	sbf.layers.Store(&filters)
+	return sbf, nil
+}
@@ -0,0 +1,92 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/franciscoescher/gobloom/gobloompb"
+)
+
+func TestBloomFilter_Proto(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+
+	msg, err := bf.ToProto()
+	assert.NoError(t, err)
+	data, err := proto.Marshal(msg)
+	assert.NoError(t, err)
+
+	var decoded gobloompb.BloomFilter
+	assert.NoError(t, proto.Unmarshal(data, &decoded))
+	restored, err := BloomFilterFromProto(&decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, bf.bitSet, restored.bitSet)
+
+	b, err := restored.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b)
+
+	decoded.Bits = decoded.Bits[:8]
+	_, err = BloomFilterFromProto(&decoded)
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+	// A huge m must not wrap the expected size around.
+	_, err = BloomFilterFromProto(&gobloompb.BloomFilter{M: math.MaxUint64, K: 3, Hasher: decoded.Hasher})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+	// Neither must a huge k, allocated on the first Test.
+	_, err = BloomFilterFromProto(&gobloompb.BloomFilter{M: 64, K: 1 << 40, Hasher: decoded.Hasher, Bits: make([]byte, 8)})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestBloomFilter_UnmarshalProto(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+	msg, err := bf.ToProto()
+	assert.NoError(t, err)
+
+	restored, err := New(Params{N: 10, FalsePositiveRate: 0.1, LockType: LockTypeReadWrite})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, restored.UnmarshalProto(msg))
+	assert.IsType(t, &ReadWriteMutex{}, restored.mutex, "The lock type of the filter should be kept")
+	assert.Equal(t, bf.bitSet, restored.bitSet)
+}
+
+func TestScalableBloomFilter_Proto(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Failed to create scalable Bloom filter")
+	for i := 0; i < 2000; i++ {
+		assert.NoError(t, sbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.Greater(t, len(sbf.filters()), 1, "Expected multiple layers")
+
+	msg, err := sbf.ToProto()
+	assert.NoError(t, err)
+	assert.Len(t, msg.GetLayers(), len(sbf.filters()))
+
+	restored, err := ScalableBloomFilterFromProto(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, sbf.n, restored.n)
+	for i := 0; i < 2000; i++ {
+		b, err := restored.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should be present after restoring", i)
+	}
+
+	_, err = ScalableBloomFilterFromProto(&gobloompb.ScalableBloomFilter{})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+
+	other, err := New(Params{N: 100, FalsePositiveRate: 0.01, Hasher: NewFNVHasher()})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	msg.Layers[1], err = other.ToProto()
+	assert.NoError(t, err)
+	_, err = ScalableBloomFilterFromProto(msg)
+	assert.ErrorIs(t, err, ErrInvalidEncoding, "Layers with different hashers should be rejected")
+}
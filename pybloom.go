@@ -0,0 +1,304 @@
+package gobloom
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+)
+
+const pybloomHasherName = "pybloom"
+
+// PybloomHasher is a hasher compatible with the BloomFilter of the pybloom and pybloom_live Python packages,
+// for a filter with the given number of slices and bits per slice. Those filters split their bits in one slice
+// per hash function, and derive the position in each slice from salted md5 or sha digests of the item, so
+// HashK returns positions in the whole bit set, which are smaller than numSlices*bitsPerSlice.
+//
+// Python 3 hashes str items as their UTF-8 bytes, and other items as the UTF-8 bytes of str(item), so a
+// bytes item b"x" is hashed as the string "b'x'". Items are found in Go by passing the bytes that Python hashed.
+type PybloomHasher struct {
+	numSlices    uint64
+	bitsPerSlice uint64
+
+	newHash   func() hash.Hash
+	chunkSize int      // The size in bytes of the unsigned integers the digests are split in
+	salts     [][]byte // The salt digests, each hashed before the item
+}
+
+var (
+	_ NamedHasher = (*PybloomHasher)(nil)
+	_ Hasher64    = (*PybloomHasher)(nil)
+)
+
+// NewPybloomHasher creates a PybloomHasher for a filter with the given number of slices and bits per slice.
+func NewPybloomHasher(numSlices, bitsPerSlice uint64) *PybloomHasher {
+	h := &PybloomHasher{}
+	h.setup(max(numSlices, 1), max(bitsPerSlice, 1))
+	return h
+}
+
+// setup chooses the digest and the size of its chunks, and computes the salts, like make_hashfuncs in pybloom.
+func (h *PybloomHasher) setup(numSlices, bitsPerSlice uint64) {
+	h.numSlices, h.bitsPerSlice = numSlices, bitsPerSlice
+	switch {
+	case bitsPerSlice >= 1<<31:
+		h.chunkSize = 8
+	case bitsPerSlice >= 1<<15:
+		h.chunkSize = 4
+	default:
+		h.chunkSize = 2
+	}
+	switch totalBits := 8 * numSlices * uint64(h.chunkSize); {
+	case totalBits > 384:
+		h.newHash = sha512.New
+	case totalBits > 256:
+		h.newHash = sha512.New384
+	case totalBits > 160:
+		h.newHash = sha256.New
+	case totalBits > 128:
+		h.newHash = sha1.New
+	default:
+		h.newHash = md5.New
+	}
+	perDigest := uint64(h.newHash().Size() / h.chunkSize)
+	h.salts = make([][]byte, (numSlices+perDigest-1)/perDigest)
+	for i := range h.salts {
+		d := h.newHash()
+		_, _ = d.Write(binary.LittleEndian.AppendUint32(nil, uint32(i)))
+		h.salts[i] = d.Sum(nil)
+	}
+}
+
+// NumSlices returns the number of slices of the filters of the hasher, which is their number of hash functions.
+func (h *PybloomHasher) NumSlices() uint64 {
+	return h.numSlices
+}
+
+// BitsPerSlice returns the number of bits of each slice of the filters of the hasher.
+func (h *PybloomHasher) BitsPerSlice() uint64 {
+	return h.bitsPerSlice
+}
+
+func (h *PybloomHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashers := make([]hash.Hash64, n)
+	for i := range hashers {
+		hashers[i] = &pybloomHash{h: h, i: i}
+	}
+	return hashers
+}
+
+// HashK sets out[i] to the position of the item in slice i, offset by the bits of the previous slices.
+// The positions repeat past numSlices, the number of hash functions of the filters of the hasher.
+func (h *PybloomHasher) HashK(data []byte, out []uint64) {
+	var buf [sha512.Size]byte
+	n := min(uint64(len(out)), h.numSlices)
+	var i uint64
+	for _, salt := range h.salts {
+		d := h.newHash()
+		_, _ = d.Write(salt)
+		_, _ = d.Write(data)
+		digest := d.Sum(buf[:0])
+		// The digest is split in little-endian unsigned integers, one per slice.
+		for ; len(digest) >= h.chunkSize && i < n; digest = digest[h.chunkSize:] {
+			var v uint64
+			switch h.chunkSize {
+			case 2:
+				v = uint64(binary.LittleEndian.Uint16(digest))
+			case 4:
+				v = uint64(binary.LittleEndian.Uint32(digest))
+			default:
+				v = binary.LittleEndian.Uint64(digest)
+			}
+			out[i] = i*h.bitsPerSlice + v%h.bitsPerSlice
+			i++
+		}
+	}
+	for j := n; j < uint64(len(out)); j++ {
+		out[j] = out[j-n]
+	}
+}
+
+func (h *PybloomHasher) Name() string {
+	return pybloomHasherName
+}
+
+// MarshalBinary encodes the number of slices and bits per slice of the hasher.
+func (h *PybloomHasher) MarshalBinary() ([]byte, error) {
+	return binary.AppendUvarint(binary.AppendUvarint(nil, h.numSlices), h.bitsPerSlice), nil
+}
+
+// UnmarshalBinary restores the number of slices and bits per slice encoded with MarshalBinary.
+func (h *PybloomHasher) UnmarshalBinary(data []byte) error {
+	r := &byteReader{data: data}
+	numSlices, bitsPerSlice := r.uvarint(), r.uvarint()
+	if r.err != nil || len(r.data) > 0 || numSlices == 0 || numSlices > maxPybloomSlices ||
+		bitsPerSlice == 0 || numSlices > math.MaxUint64/bitsPerSlice {
+		return fmt.Errorf("%w: invalid pybloom hasher state", ErrInvalidEncoding)
+	}
+	h.setup(numSlices, bitsPerSlice)
+	return nil
+}
+
+// pybloomHash is a hash.Hash64 whose sum is the i-th probe position of the written data.
+type pybloomHash struct {
+	h    *PybloomHasher
+	i    int
+	data []byte
+}
+
+func (h *pybloomHash) Write(p []byte) (int, error) {
+	h.data = append(h.data, p...)
+	return len(p), nil
+}
+
+func (h *pybloomHash) Sum64() uint64 {
+	out := make([]uint64, h.i+1)
+	h.h.HashK(h.data, out)
+	return out[h.i]
+}
+
+func (h *pybloomHash) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func (h *pybloomHash) Reset() {
+	h.data = h.data[:0]
+}
+
+func (h *pybloomHash) Size() int {
+	return 8
+}
+
+func (h *pybloomHash) BlockSize() int {
+	return 1
+}
+
+// maxPybloomSlices bounds the number of slices of the imported filters, which pybloom sets to log2(1/p).
+const maxPybloomSlices = 1024
+
+// pybloomHeader is the header of a pybloom BloomFilter file.
+type pybloomHeader struct {
+	ErrorRate    float64
+	NumSlices    uint64
+	BitsPerSlice uint64
+	Capacity     uint64
+	Count        uint64
+}
+
+// pybloomHeaderSize is the size of pybloomHeader, written without padding.
+const pybloomHeaderSize = 40
+
+// ImportPybloom reads a Bloom filter written by the tofile method of the BloomFilter of the pybloom or
+// pybloom_live Python packages, so filters built in Python can be queried in Go. The filter uses a
+// PybloomHasher, see it for how the items are hashed, and ExclusiveLock. The filters of the
+// pybloomfiltermmap package are not supported, they are a memory dump of a C structure using other hashes.
+func ImportPybloom(r io.Reader) (*BloomFilter, error) {
+	bf, _, err := importPybloom(r, 0)
+	return bf, err
+}
+
+// importPybloom reads a pybloom BloomFilter file, checking its size unless it is 0, and returns it with its header.
+func importPybloom(r io.Reader, size uint64) (*BloomFilter, pybloomHeader, error) {
+	var header pybloomHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, header, err
+	}
+	numSlices, bitsPerSlice := header.NumSlices, header.BitsPerSlice
+	if numSlices == 0 || numSlices > maxPybloomSlices || bitsPerSlice == 0 || bitsPerSlice > math.MaxUint64/64/numSlices ||
+		!(header.ErrorRate > 0 && header.ErrorRate < 1) {
+		return nil, header, fmt.Errorf("%w: invalid parameters error_rate=%g, num_slices=%d, bits_per_slice=%d",
+			ErrInvalidEncoding, header.ErrorRate, numSlices, bitsPerSlice)
+	}
+	m := numSlices * bitsPerSlice
+	// The bits are stored least significant first, so the bytes are the little-endian words of the bit set.
+	length := (m + 7) / 8
+	if size != 0 && size != pybloomHeaderSize+length {
+		return nil, header, fmt.Errorf("%w: filter of %d bits stored in %d bytes", ErrInvalidEncoding, m, size)
+	}
+	data := make([]byte, (m+63)/64*8)
+	if _, err := io.ReadFull(r, data[:length]); err != nil {
+		return nil, header, err
+	}
+	bitSet := make([]uint64, len(data)/8)
+	for i := range bitSet {
+		bitSet[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	if m%64 != 0 {
+		bitSet[len(bitSet)-1] &= 1<<(m%64) - 1
+	}
+	bf := &BloomFilter{}
+	if err := bf.restore(m, numSlices, NewPybloomHasher(numSlices, bitsPerSlice), bitSet); err != nil {
+		return nil, header, err
+	}
+	bf.fpRate = header.ErrorRate
+	return bf, header, nil
+}
+
+// ImportPybloomScalable reads a scalable Bloom filter written by the tofile method of the ScalableBloomFilter of
+// the pybloom or pybloom_live Python packages. Its layers are imported with ImportPybloom, and a layer sized and
+// grown like the next pybloom layer is added, using the default hasher, where the items added in Go are stored.
+// As its layers use different hashers, the filter cannot be encoded with MarshalBinary or ToProto.
+// The filter uses ExclusiveLock.
+func ImportPybloomScalable(r io.Reader) (*ScalableBloomFilter, error) {
+	var header struct {
+		Scale           int32
+		Ratio           float64
+		InitialCapacity uint64
+		ErrorRate       float64
+		Filters         int32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Scale < 1 || !(header.Ratio > 0 && header.Ratio < 1) || header.InitialCapacity == 0 ||
+		!(header.ErrorRate > 0 && header.ErrorRate < 1) || header.Filters < 0 || header.Filters > maxPybloomSlices {
+		return nil, fmt.Errorf("%w: invalid parameters scale=%d, ratio=%g, initial_capacity=%d, error_rate=%g, filters=%d",
+			ErrInvalidEncoding, header.Scale, header.Ratio, header.InitialCapacity, header.ErrorRate, header.Filters)
+	}
+	sizes := make([]uint64, header.Filters)
+	if err := binary.Read(r, binary.LittleEndian, sizes); err != nil {
+		return nil, err
+	}
+	layers := make([]*BloomFilter, 0, len(sizes)+1)
+	// The first pybloom layer has the error rate times 1-ratio, so the rates of all the layers sum to the error rate.
+	capacity, fpRate := header.InitialCapacity, header.ErrorRate*(1-header.Ratio)
+	var n uint64
+	for _, size := range sizes {
+		bf, h, err := importPybloom(r, size)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, bf)
+		n += h.Count
+		capacity, fpRate = h.Capacity*uint64(header.Scale), h.ErrorRate*header.Ratio
+	}
+	next, err := New(Params{N: capacity, FalsePositiveRate: fpRate})
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, next)
+
+	mu, err := NewMutex(LockTypeExclusive)
+	if err != nil {
+		return nil, err
+	}
+	sbf := &ScalableBloomFilter{
+		fpRate:      header.ErrorRate * (1 - header.Ratio),
+		fpGrowth:    header.Ratio,
+		n:           n,
+		layerGrowth: float64(header.Scale),
+		lockType:    LockTypeExclusive,
+		mutex:       mu,
+	}
+	// The expected maximum is the current capacity, so each new layer is scale times larger than the last one.
+	for _, bf := range layers {
+		sbf.maxItems += bf.Capacity()
+	}
+	sbf.layers.Store(&layers)
+	return sbf, nil
+}
@@ -0,0 +1,140 @@
+package gobloom
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The files were written like pybloom_live: a BloomFilter(capacity=100, error_rate=0.01) with the items
+// "apple", "banana" and "cherry", and a ScalableBloomFilter(initial_capacity=2, error_rate=0.01, mode=2)
+// with the items "apple", "banana", "cherry", "date" and "elderberry".
+const (
+	pybloomFile         = "7b14ae47e17a843f0700000000000000890000000000000064000000000000000300000000000000000000400090000000000000000000000000010002000000000000020000000000000000080000080000010000000000000000000004800000000000000000000000000004810000000000000000000000000000800000000100001000000000000000000010000400000000000004000000004000000000"
+	pybloomScalableFile = "02000000cdccccccccccec3f02000000000000007b14ae47e17a843f020000002c000000000000003100000000000000fba9f1d24d62503f0a000000000000000300000000000000020000000000000002000000000000005252d71d91cb7f48bf7d4d3f0b00000000000000060000000000000004000000000000000300000000000000649c2929eb58218c01"
+)
+
+var pybloomItems = []string{"apple", "banana", "cherry", "date", "elderberry"}
+
+func TestPybloomHasher_Vectors(t *testing.T) {
+	t.Parallel()
+	// The positions of "apple" in each slice, computed by pybloom_live's make_hashfuncs,
+	// covering md5, sha1, sha256, sha384 and sha512 digests, split in 2, 4 and 8 bytes chunks.
+	tests := []struct {
+		numSlices, bitsPerSlice uint64
+		want                    []uint64
+	}{
+		{7, 137, []uint64{44, 24, 62, 135, 11, 127, 60}},
+		{9, 100, []uint64{30, 35, 82, 93, 92, 22, 73, 62, 9}},
+		{12, 100, []uint64{35, 96, 53, 97, 2, 71, 95, 39, 14, 0, 5, 12}},
+		{20, 5000, []uint64{448, 3846, 3391, 4189, 3835, 348, 2907, 1463, 4679, 4931, 954, 3931, 490, 4277, 4044, 3924, 4126, 555, 2492, 2155}},
+		{40, 100, []uint64{80, 12, 20, 83, 84, 83, 63, 83, 90, 48, 91, 61, 39, 71, 96, 81, 42, 31, 10, 0,
+			81, 76, 26, 31, 37, 70, 86, 51, 54, 9, 80, 54, 57, 23, 34, 37, 92, 93, 40, 23}},
+		{10, 143776, []uint64{62576, 6711, 34651, 81467, 134711, 29866, 80226, 25028, 70270, 2740}},
+		{3, 1 << 31, []uint64{373316991, 1251982610, 52470314}},
+	}
+	for _, tt := range tests {
+		out := make([]uint64, tt.numSlices)
+		NewPybloomHasher(tt.numSlices, tt.bitsPerSlice).HashK([]byte("apple"), out)
+		for i, want := range tt.want {
+			assert.Equal(t, uint64(i)*tt.bitsPerSlice+want, out[i], "slices=%d bits=%d slice=%d", tt.numSlices, tt.bitsPerSlice, i)
+		}
+	}
+}
+
+func TestPybloomHasher_Marshal(t *testing.T) {
+	t.Parallel()
+	h := NewPybloomHasher(7, 137)
+	name, state, err := marshalHasher(h)
+	assert.NoError(t, err)
+	restored, err := unmarshalHasher(name, state)
+	assert.NoError(t, err)
+	assert.True(t, sameHasher(h, restored), "The hasher should be restored")
+	assert.Equal(t, uint64(137), restored.(*PybloomHasher).BitsPerSlice())
+
+	_, err = unmarshalHasher(name, nil)
+	assert.ErrorIs(t, err, ErrInvalidEncoding, "The hasher state is required")
+}
+
+func TestImportPybloom(t *testing.T) {
+	t.Parallel()
+	data, _ := hex.DecodeString(pybloomFile)
+	bf, err := ImportPybloom(bytes.NewReader(data))
+	assert.NoError(t, err, "Failed to import pybloom filter")
+	assert.Equal(t, uint64(7*137), bf.M())
+	assert.Equal(t, uint64(7), bf.K())
+	for _, item := range pybloomItems[:3] {
+		b, _ := bf.Test([]byte(item))
+		assert.True(t, b, "Imported filter should contain %q", item)
+	}
+
+	// Adding the items in Go sets the same bits.
+	bits := bf.Bits()
+	bf.Clear()
+	for _, item := range pybloomItems[:3] {
+		assert.NoError(t, bf.Add([]byte(item)))
+	}
+	assert.Equal(t, bits, bf.Bits())
+
+	// The filter survives a round trip through the wire format.
+	encoded, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := &BloomFilter{}
+	assert.NoError(t, decoded.UnmarshalBinary(encoded))
+	b, _ := decoded.Test([]byte("banana"))
+	assert.True(t, b)
+}
+
+func TestImportPybloom_Invalid(t *testing.T) {
+	t.Parallel()
+	data, _ := hex.DecodeString(pybloomFile)
+	_, err := ImportPybloom(bytes.NewReader(data[:len(data)-1]))
+	assert.Error(t, err, "Truncated bits should be rejected")
+
+	invalid := bytes.Clone(data)
+	clear(invalid[8:16]) // num_slices
+	_, err = ImportPybloom(bytes.NewReader(invalid))
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestImportPybloomScalable(t *testing.T) {
+	t.Parallel()
+	data, _ := hex.DecodeString(pybloomScalableFile)
+	sbf, err := ImportPybloomScalable(bytes.NewReader(data))
+	assert.NoError(t, err, "Failed to import pybloom scalable filter")
+	assert.Len(t, sbf.filters(), 3, "The two pybloom layers and a new one should be kept")
+	for _, item := range pybloomItems {
+		b, _ := sbf.Test([]byte(item))
+		assert.True(t, b, "Imported filter should contain %q", item)
+	}
+
+	// Items added in Go go to new layers, which grow like pybloom's.
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, sbf.Add([]byte("item-"+strconv.Itoa(i))))
+	}
+	filters := sbf.filters()
+	assert.Greater(t, len(filters), 3)
+	assert.IsType(t, &MurMur3Hasher{}, filters[2].hasher, "New layers should use the default hasher")
+	assert.Greater(t, filters[3].Capacity(), filters[2].Capacity())
+	for i := 0; i < 100; i++ {
+		b, _ := sbf.Test([]byte("item-" + strconv.Itoa(i)))
+		assert.True(t, b)
+	}
+	for _, item := range pybloomItems {
+		b, _ := sbf.Test([]byte(item))
+		assert.True(t, b)
+	}
+
+	_, err = sbf.MarshalBinary()
+	assert.Error(t, err, "Layers with different hashers cannot be encoded")
+
+	_, err = ImportPybloomScalable(bytes.NewReader(data[:len(data)-1]))
+	assert.Error(t, err, "Truncated filters should be rejected")
+	invalid := bytes.Clone(data)
+	invalid[32] ^= 1 // The size of the first filter, after the 32 bytes of the header and number of filters
+	_, err = ImportPybloomScalable(bytes.NewReader(invalid))
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
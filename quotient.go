@@ -0,0 +1,581 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+var (
+	_ Interface               = (*QuotientFilter)(nil)
+	_ Remover                 = (*QuotientFilter)(nil)
+	_ Clearer                 = (*QuotientFilter)(nil)
+	_ Counter                 = (*QuotientFilter)(nil)
+	_ Merger[*QuotientFilter] = (*QuotientFilter)(nil)
+	_ Serializer              = (*QuotientFilter)(nil)
+)
+
+const (
+	qfOccupied     = 1 << 0 // The slot is the canonical slot of some stored fingerprint
+	qfContinuation = 1 << 1 // The slot holds a remainder that is not the first of its run
+	qfShifted      = 1 << 2 // The slot holds a remainder that is not in its canonical slot
+	qfMetaBits     = 3
+	qfMetaMask     = qfOccupied | qfContinuation | qfShifted
+	qfMaxLoad      = 0.75
+)
+
+// QuotientFilter is a quotient filter, which stores the fingerprint of each item split into
+// a quotient (the slot index) and a remainder (the slot content). It supports deletion,
+// and can be resized and merged without access to the original items.
+type QuotientFilter struct {
+	q        uint64   // The number of quotient bits, the table has 2^q slots
+	r        uint64   // The number of remainder bits
+	slots    []uint64 // Each slot holds a remainder shifted left by the 3 metadata bits
+	entries  uint64   // The number of fingerprints stored
+	hasher   Hasher   // The hash provider the hash function comes from
+	hasher64 Hasher64 // The hasher computing the hash of an item
+	mutex    Mutex    // Mutex to ensure thread safety
+}
+
+// NewQuotient creates a new quotient filter with the given number of elements (n) and false positive rate (p).
+// The table grows automatically when it gets too full. Each time it grows, one remainder bit becomes a
+// quotient bit, which doubles the false positive rate, so N should be set as accurately as possible.
+func NewQuotient(p Params) (*QuotientFilter, error) {
+	applyDefaults(&p)
+	if err := validateParams(p); err != nil {
+		return nil, err
+	}
+	q := uint64(math.Ceil(math.Log2(float64(p.N) / qfMaxLoad)))
+	if q == 0 {
+		q = 1
+	}
+	r := uint64(math.Ceil(math.Log2(1 / p.FalsePositiveRate)))
+	if r == 0 {
+		r = 1
+	}
+	if q+r > 64-qfMetaBits {
+		return nil, fmt.Errorf("quotient filter too large, fingerprint needs %d bits", q+r)
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	return &QuotientFilter{
+		q:        q,
+		r:        r,
+		slots:    make([]uint64, 1<<q),
+		hasher:   p.Hasher,
+		hasher64: asHasher64(p.Hasher),
+		mutex:    mu,
+	}, nil
+}
+
+func (qf *QuotientFilter) incr(i uint64) uint64 { return (i + 1) & (uint64(len(qf.slots)) - 1) }
+func (qf *QuotientFilter) decr(i uint64) uint64 { return (i - 1) & (uint64(len(qf.slots)) - 1) }
+
+func qfIsEmpty(s uint64) bool        { return s&qfMetaMask == 0 }
+func qfIsClusterStart(s uint64) bool { return s&qfMetaMask == qfOccupied }
+func qfIsRunStart(s uint64) bool {
+	return s&qfContinuation == 0 && s&(qfOccupied|qfShifted) != 0
+}
+
+// fingerprint returns the q+r bit fingerprint of the data.
+func (qf *QuotientFilter) fingerprint(data []byte) (uint64, error) {
+	hashes := getProbes(1)
+	defer probePool.Put(hashes)
+	qf.hasher64.HashK(data, *hashes)
+	return (*hashes)[0] & (uint64(1)<<(qf.q+qf.r) - 1), nil
+}
+
+// split splits a fingerprint into its quotient and remainder.
+func (qf *QuotientFilter) split(fp uint64) (uint64, uint64) {
+	return fp >> qf.r, fp & (uint64(1)<<qf.r - 1)
+}
+
+// findRunIndex returns the slot where the run of the given quotient starts.
+func (qf *QuotientFilter) findRunIndex(fq uint64) uint64 {
+	// Walk back to the start of the cluster.
+	b := fq
+	for qf.slots[b]&qfShifted != 0 {
+		b = qf.decr(b)
+	}
+	// Walk forward, skipping one run for every occupied canonical slot until fq is reached.
+	s := b
+	for b != fq {
+		for {
+			s = qf.incr(s)
+			if qf.slots[s]&qfContinuation == 0 {
+				break
+			}
+		}
+		for {
+			b = qf.incr(b)
+			if qf.slots[b]&qfOccupied != 0 {
+				break
+			}
+		}
+	}
+	return s
+}
+
+// insertAt inserts the slot value at s, shifting the following slots of the cluster to the right.
+func (qf *QuotientFilter) insertAt(s uint64, slot uint64) {
+	curr := slot
+	for {
+		prev := qf.slots[s]
+		empty := qfIsEmpty(prev)
+		if !empty {
+			prev |= qfShifted
+			// The occupied bit belongs to the slot position, not to the remainder moving out of it.
+			if prev&qfOccupied != 0 {
+				curr |= qfOccupied
+				prev &^= qfOccupied
+			}
+		}
+		qf.slots[s] = curr
+		curr = prev
+		s = qf.incr(s)
+		if empty {
+			return
+		}
+	}
+}
+
+// insert inserts a fingerprint, returning false if it was already present.
+func (qf *QuotientFilter) insert(fp uint64) bool {
+	fq, fr := qf.split(fp)
+	tfq := qf.slots[fq]
+	entry := fr << qfMetaBits
+	if qfIsEmpty(tfq) {
+		qf.slots[fq] = entry | qfOccupied
+		qf.entries++
+		return true
+	}
+	if tfq&qfOccupied == 0 {
+		qf.slots[fq] |= qfOccupied
+	}
+	start := qf.findRunIndex(fq)
+	s := start
+	if tfq&qfOccupied != 0 {
+		// The run already exists, find the sorted position of the remainder within it.
+		for {
+			rem := qf.slots[s] >> qfMetaBits
+			if rem == fr {
+				return false
+			} else if rem > fr {
+				break
+			}
+			s = qf.incr(s)
+			if qf.slots[s]&qfContinuation == 0 {
+				break
+			}
+		}
+		if s == start {
+			// The new remainder becomes the head of the run, the old head becomes a continuation.
+			qf.slots[start] |= qfContinuation
+		} else {
+			entry |= qfContinuation
+		}
+	}
+	if s != fq {
+		entry |= qfShifted
+	}
+	qf.insertAt(s, entry)
+	qf.entries++
+	return true
+}
+
+// contains reports whether the fingerprint is stored in the filter.
+func (qf *QuotientFilter) contains(fp uint64) bool {
+	fq, fr := qf.split(fp)
+	if qf.slots[fq]&qfOccupied == 0 {
+		return false
+	}
+	s := qf.findRunIndex(fq)
+	for {
+		rem := qf.slots[s] >> qfMetaBits
+		if rem == fr {
+			return true
+		} else if rem > fr {
+			return false
+		}
+		s = qf.incr(s)
+		if qf.slots[s]&qfContinuation == 0 {
+			return false
+		}
+	}
+}
+
+// deleteAt removes the slot at s, shifting the following slots of the cluster to the left.
+func (qf *QuotientFilter) deleteAt(s uint64, quot uint64) {
+	orig := s
+	curr := qf.slots[s]
+	for {
+		next := qf.slots[qf.incr(s)]
+		currOccupied := curr&qfOccupied != 0
+		if qfIsEmpty(next) || qfIsClusterStart(next) || qf.incr(s) == orig {
+			qf.slots[s] = 0
+			return
+		}
+		updated := next
+		if qfIsRunStart(next) {
+			// Find the quotient of the run being shifted back, it may land in its canonical slot.
+			for {
+				quot = qf.incr(quot)
+				if qf.slots[quot]&qfOccupied != 0 {
+					break
+				}
+			}
+			if currOccupied && quot == s {
+				updated &^= qfShifted
+			}
+		}
+		if currOccupied {
+			updated |= qfOccupied
+		} else {
+			updated &^= qfOccupied
+		}
+		qf.slots[s] = updated
+		s = qf.incr(s)
+		curr = next
+	}
+}
+
+// remove removes a fingerprint, returning false if it was not present.
+func (qf *QuotientFilter) remove(fp uint64) bool {
+	fq, fr := qf.split(fp)
+	tfq := qf.slots[fq]
+	if tfq&qfOccupied == 0 || qf.entries == 0 {
+		return false
+	}
+	s := qf.findRunIndex(fq)
+	for {
+		rem := qf.slots[s] >> qfMetaBits
+		if rem == fr {
+			break
+		} else if rem > fr {
+			return false
+		}
+		s = qf.incr(s)
+		if qf.slots[s]&qfContinuation == 0 {
+			return false
+		}
+	}
+	qf.removeAt(s, fq)
+	qf.entries--
+	return true
+}
+
+// removeAt removes the remainder at slot s, which is in the run of the quotient fq.
+func (qf *QuotientFilter) removeAt(s, fq uint64) {
+	kill := qf.slots[s]
+	replaceRunStart := qfIsRunStart(kill)
+	if replaceRunStart && qf.slots[qf.incr(s)]&qfContinuation == 0 {
+		// The run only had this remainder, so the quotient is no longer occupied.
+		qf.slots[fq] &^= qfOccupied
+	}
+	qf.deleteAt(s, fq)
+	if replaceRunStart {
+		next := qf.slots[s]
+		updated := next
+		if next&qfContinuation != 0 {
+			// The new head of the run is no longer a continuation.
+			updated &^= qfContinuation
+		}
+		if s == fq && qfIsRunStart(updated) {
+			// The new head of the run is in its canonical slot.
+			updated &^= qfShifted
+		}
+		qf.slots[s] = updated
+	}
+}
+
+// fingerprints returns all stored fingerprints.
+func (qf *QuotientFilter) fingerprints() []uint64 {
+	fps := make([]uint64, 0, qf.entries)
+	if qf.entries == 0 {
+		return fps
+	}
+	// Start iterating at a cluster start, so the quotient of every run can be tracked.
+	i := uint64(0)
+	for !qfIsClusterStart(qf.slots[i]) {
+		i++
+	}
+	quot := i
+	for uint64(len(fps)) < qf.entries {
+		slot := qf.slots[i]
+		if qfIsClusterStart(slot) {
+			quot = i
+		} else if qfIsRunStart(slot) {
+			for {
+				quot = qf.incr(quot)
+				if qf.slots[quot]&qfOccupied != 0 {
+					break
+				}
+			}
+		}
+		if !qfIsEmpty(slot) {
+			fps = append(fps, quot<<qf.r|slot>>qfMetaBits)
+		}
+		i = qf.incr(i)
+	}
+	return fps
+}
+
+// grow doubles the number of slots, moving one remainder bit into the quotient.
+// The caller must hold the lock.
+func (qf *QuotientFilter) grow() error {
+	if qf.r <= 1 {
+		return ErrFilterFull
+	}
+	fps := qf.fingerprints()
+	qf.q++
+	qf.r--
+	qf.slots = make([]uint64, 1<<qf.q)
+	qf.entries = 0
+	for _, fp := range fps {
+		qf.insert(fp)
+	}
+	return nil
+}
+
+// full reports whether adding one more fingerprint would exceed the maximum load factor.
+func (qf *QuotientFilter) full() bool {
+	return float64(qf.entries+1) > qfMaxLoad*float64(len(qf.slots))
+}
+
+// Add adds an item to the quotient filter, growing the table if needed.
+// It returns ErrFilterFull if the table cannot grow any further.
+func (qf *QuotientFilter) Add(data []byte) error {
+	if qf.mutex != nil {
+		qf.mutex.WLock()
+		defer qf.mutex.WUnlock()
+	}
+	fp, err := qf.fingerprint(data)
+	if err != nil {
+		return err
+	}
+	if qf.full() && !qf.contains(fp) {
+		if err := qf.grow(); err != nil {
+			return err
+		}
+	}
+	qf.insert(fp)
+	return nil
+}
+
+// Test checks if an item is in the quotient filter.
+func (qf *QuotientFilter) Test(data []byte) (bool, error) {
+	if qf.mutex != nil {
+		qf.mutex.RLock()
+		defer qf.mutex.RUnlock()
+	}
+	fp, err := qf.fingerprint(data)
+	if err != nil {
+		return false, err
+	}
+	return qf.contains(fp), nil
+}
+
+// Delete removes an item from the quotient filter.
+// It returns ErrNotFound if the item is not in the filter. Items are stored as a set of
+// fingerprints, so two items with the same fingerprint are removed together.
+func (qf *QuotientFilter) Delete(data []byte) error {
+	if qf.mutex != nil {
+		qf.mutex.WLock()
+		defer qf.mutex.WUnlock()
+	}
+	fp, err := qf.fingerprint(data)
+	if err != nil {
+		return err
+	}
+	if !qf.remove(fp) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Resize doubles the number of slots of the quotient filter, moving one remainder bit into the quotient.
+// This doubles the capacity and the false positive rate. It returns ErrFilterFull if there are no
+// remainder bits left to move.
+func (qf *QuotientFilter) Resize() error {
+	if qf.mutex != nil {
+		qf.mutex.WLock()
+		defer qf.mutex.WUnlock()
+	}
+	return qf.grow()
+}
+
+// Merge adds all items of other into the quotient filter, growing it if needed.
+// Both filters must use the same hasher and fingerprint size (quotient plus remainder bits).
+// The fingerprints of other are copied before locking the filter, so merges in both directions
+// can run concurrently. Merging a filter with itself leaves it unchanged.
+func (qf *QuotientFilter) Merge(other *QuotientFilter) error {
+	if qf == other {
+		return nil
+	}
+	bits, hasher, fps := other.mergeSource()
+	if qf.mutex != nil {
+		qf.mutex.WLock()
+		defer qf.mutex.WUnlock()
+	}
+	if qf.q+qf.r != bits {
+		return fmt.Errorf("incompatible fingerprint sizes, %d and %d bits", qf.q+qf.r, bits)
+	}
+	if !sameHasher(qf.hasher, hasher) {
+		return fmt.Errorf("incompatible filters, hashers %T and %T differ", qf.hasher, hasher)
+	}
+	for _, fp := range fps {
+		if qf.full() && !qf.contains(fp) {
+			if err := qf.grow(); err != nil {
+				return err
+			}
+		}
+		qf.insert(fp)
+	}
+	return nil
+}
+
+// mergeSource returns the fingerprint size, the hasher and the fingerprints of the quotient filter,
+// read under its lock, to be merged into another filter.
+func (qf *QuotientFilter) mergeSource() (uint64, Hasher, []uint64) {
+	if qf.mutex != nil {
+		qf.mutex.RLock()
+		defer qf.mutex.RUnlock()
+	}
+	return qf.q + qf.r, qf.hasher, qf.fingerprints()
+}
+
+// checkSlots checks the metadata bits of decoded slots, which the scans of the clusters and runs rely on
+// to terminate, and returns the number of slots in use.
+func (qf *QuotientFilter) checkSlots() (uint64, error) {
+	// Clusters end before an empty slot, so the scan starts after one, and ends with it.
+	end := 0
+	for end < len(qf.slots) && !qfIsEmpty(qf.slots[end]) {
+		end++
+	}
+	if end == len(qf.slots) {
+		return 0, fmt.Errorf("%w: no empty slot", ErrInvalidEncoding)
+	}
+	var used uint64
+	var pending []uint64 // The occupied quotients whose runs have not started yet, in order
+	for i := qf.incr(uint64(end)); ; i = qf.incr(i) {
+		slot := qf.slots[i]
+		if slot>>qfMetaBits >= uint64(1)<<qf.r {
+			return 0, fmt.Errorf("%w: remainder of slot %d out of range", ErrInvalidEncoding, i)
+		}
+		if slot&qfOccupied != 0 {
+			pending = append(pending, i)
+		}
+		switch {
+		case qfIsEmpty(slot):
+			if len(pending) > 0 {
+				return 0, fmt.Errorf("%w: slot %d is occupied but has no run", ErrInvalidEncoding, pending[0])
+			}
+		case slot&qfContinuation != 0:
+			// Continuations follow the start of their run, so they are never empty and always shifted.
+			if qfIsEmpty(qf.slots[qf.decr(i)]) || slot&qfShifted == 0 {
+				return 0, fmt.Errorf("%w: slot %d is a continuation out of a run", ErrInvalidEncoding, i)
+			}
+		default:
+			if len(pending) == 0 {
+				return 0, fmt.Errorf("%w: slot %d starts a run of no occupied slot", ErrInvalidEncoding, i)
+			}
+			if (slot&qfShifted != 0) != (pending[0] != i) {
+				return 0, fmt.Errorf("%w: slot %d has an invalid shifted bit", ErrInvalidEncoding, i)
+			}
+			pending = pending[1:]
+		}
+		if !qfIsEmpty(slot) {
+			used++
+		}
+		if i == uint64(end) {
+			return used, nil
+		}
+	}
+}
+
+// Count returns the number of distinct fingerprints stored in the quotient filter.
+func (qf *QuotientFilter) Count() uint64 {
+	if qf.mutex != nil {
+		qf.mutex.RLock()
+		defer qf.mutex.RUnlock()
+	}
+	return qf.entries
+}
+
+// MarshalBinary encodes the quotient filter with the wire format.
+// The parameter block holds the quotient bits, the remainder bits, the number of entries,
+// and the hasher. The payload is the slots.
+func (qf *QuotientFilter) MarshalBinary() ([]byte, error) {
+	if qf.mutex != nil {
+		qf.mutex.RLock()
+		defer qf.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, qf.q)
+	params = binary.AppendUvarint(params, qf.r)
+	params = binary.AppendUvarint(params, qf.entries)
+	params, err := appendHasher(params, qf.hasher)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFilter(filterTypeQuotient, params, appendWords(nil, qf.slots)), nil
+}
+
+// UnmarshalBinary restores a quotient filter encoded with MarshalBinary.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value filter.
+func (qf *QuotientFilter) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeQuotient, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	q := r.uvarint()
+	rem := r.uvarint()
+	entries := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	if q == 0 || rem == 0 || q+rem > 64-qfMetaBits || entries > uint64(1)<<q {
+		return fmt.Errorf("%w: invalid parameters", ErrInvalidEncoding)
+	}
+	if err := checkPayload(payload, 8, uint64(1)<<q); err != nil {
+		return err
+	}
+	decoded := QuotientFilter{q: q, r: rem, slots: readWords(payload), entries: entries}
+	if used, err := decoded.checkSlots(); err != nil {
+		return err
+	} else if used != entries {
+		return fmt.Errorf("%w: %d slots in use, expected %d", ErrInvalidEncoding, used, entries)
+	}
+	if qf.mutex == nil && qf.slots == nil {
+		qf.mutex = &ExclusiveMutex{}
+	}
+	if qf.mutex != nil {
+		qf.mutex.WLock()
+		defer qf.mutex.WUnlock()
+	}
+	qf.q = q
+	qf.r = rem
+	qf.entries = entries
+	qf.slots = decoded.slots
+	qf.hasher = hasher
+	qf.hasher64 = asHasher64(hasher)
+	return nil
+}
+
+// Clear removes all the items from the quotient filter, keeping its parameters.
+func (qf *QuotientFilter) Clear() {
+	if qf.mutex != nil {
+		qf.mutex.WLock()
+		defer qf.mutex.WUnlock()
+	}
+	clear(qf.slots)
+	qf.entries = 0
+}
+
+// Remove is the same as Delete, so the quotient filter implements Remover.
+func (qf *QuotientFilter) Remove(data []byte) error {
+	return qf.Delete(data)
+}
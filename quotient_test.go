@@ -0,0 +1,190 @@
+package gobloom
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotientFilter_AddTestDelete(t *testing.T) {
+	t.Parallel()
+	qf, err := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create quotient filter")
+
+	item := []byte("test-item")
+	assert.NoError(t, qf.Add(item))
+
+	b, err := qf.Test(item)
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after Add")
+
+	assert.NoError(t, qf.Delete(item))
+	b, err = qf.Test(item)
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should not be present after Delete")
+
+	assert.ErrorIs(t, qf.Delete(item), ErrNotFound)
+}
+
+func TestQuotientFilter_Fingerprints(t *testing.T) {
+	t.Parallel()
+	qf, err := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create quotient filter")
+
+	// Use a small fingerprint space, so runs and clusters are long and wrap around.
+	rnd := rand.New(rand.NewSource(1))
+	space := uint64(1) << (qf.q + 2)
+	model := map[uint64]bool{}
+	for i := 0; i < 20000; i++ {
+		fp := rnd.Uint64() % space
+		if rnd.Intn(3) == 0 {
+			assert.Equal(t, model[fp], qf.remove(fp), "Unexpected remove result for %d", fp)
+			delete(model, fp)
+		} else if len(model) < len(qf.slots)-1 {
+			assert.Equal(t, !model[fp], qf.insert(fp), "Unexpected insert result for %d", fp)
+			model[fp] = true
+		}
+		if i%500 == 0 {
+			for fp := uint64(0); fp < space; fp++ {
+				assert.Equal(t, model[fp], qf.contains(fp), "Unexpected contains result for %d", fp)
+			}
+		}
+	}
+
+	expected := make([]uint64, 0, len(model))
+	for fp := range model {
+		expected = append(expected, fp)
+	}
+	actual := qf.fingerprints()
+	sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+	sort.Slice(actual, func(i, j int) bool { return actual[i] < actual[j] })
+	assert.Equal(t, expected, actual)
+	used, err := qf.checkSlots()
+	assert.NoError(t, err, "Valid slots should pass the checks of decoded slots")
+	assert.Equal(t, uint64(len(model)), used)
+}
+
+func TestQuotientFilter_Grow(t *testing.T) {
+	t.Parallel()
+	n := uint64(1000)
+	qf, err := NewQuotient(Params{N: 100, FalsePositiveRate: 0.001})
+	assert.NoError(t, err, "Failed to create quotient filter")
+	initialSlots := len(qf.slots)
+
+	for i := uint64(0); i < n; i++ {
+		assert.NoError(t, qf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.Greater(t, len(qf.slots), initialSlots, "Expected quotient filter to grow, but it didn't")
+	for i := uint64(0); i < n; i++ {
+		b, err := qf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should be present after growing", i)
+	}
+}
+
+func TestQuotientFilter_Merge(t *testing.T) {
+	t.Parallel()
+	a, err := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	b, err := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+
+	for i := 0; i < 500; i++ {
+		assert.NoError(t, a.Add([]byte(fmt.Sprintf("a-%d", i))))
+		assert.NoError(t, b.Add([]byte(fmt.Sprintf("b-%d", i))))
+	}
+	assert.NoError(t, a.Merge(b))
+	for i := 0; i < 500; i++ {
+		ok, err := a.Test([]byte(fmt.Sprintf("a-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = a.Test([]byte(fmt.Sprintf("b-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	c, err := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.1})
+	assert.NoError(t, err)
+	assert.Error(t, a.Merge(c), "Filters with different fingerprint sizes should not merge")
+	d, err := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: NewMurMur3HasherWithSeed(7)})
+	assert.NoError(t, err)
+	assert.Error(t, a.Merge(d), "Filters with different hashers should not merge")
+	assert.NoError(t, a.Merge(a), "Merging a filter with itself should leave it unchanged")
+}
+
+func TestQuotientFilter_MergeCrossed(t *testing.T) {
+	t.Parallel()
+	a, _ := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	b, _ := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	assert.NoError(t, a.Add([]byte("a")))
+	assert.NoError(t, b.Add([]byte("b")))
+	// Merges in both directions used to hold the lock of one filter while waiting for the other.
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, a.Merge(b))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, b.Merge(a))
+		}()
+	}
+	wg.Wait()
+	for _, qf := range []*QuotientFilter{a, b} {
+		for _, item := range []string{"a", "b"} {
+			ok, _ := qf.Test([]byte(item))
+			assert.True(t, ok)
+		}
+	}
+}
+
+func TestQuotientFilter_MarshalBinary(t *testing.T) {
+	t.Parallel()
+	qf, _ := NewQuotient(Params{N: 1000, FalsePositiveRate: 0.01})
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		item := []byte(fmt.Sprintf("item-%d", rnd.Intn(1000)))
+		if rnd.Intn(3) == 0 {
+			_ = qf.Delete(item)
+		} else {
+			assert.NoError(t, qf.Add(item))
+		}
+	}
+	data, err := qf.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded QuotientFilter
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, qf.slots, decoded.slots)
+	assert.Equal(t, qf.Count(), decoded.Count())
+
+	// Corrupt the metadata of the slots, which the scans of the runs rely on to terminate.
+	corrupt := func(update func(slots []uint64)) error {
+		slots := append([]uint64(nil), qf.slots...)
+		update(slots)
+		c := &QuotientFilter{q: qf.q, r: qf.r, slots: slots, entries: qf.entries, hasher: qf.hasher}
+		data, err := c.MarshalBinary()
+		assert.NoError(t, err)
+		return decoded.UnmarshalBinary(data)
+	}
+	// The first slot of a cluster following an empty slot.
+	first := uint64(1)
+	for !qfIsEmpty(qf.slots[first-1]) || qfIsEmpty(qf.slots[first]) {
+		first++
+	}
+	assert.ErrorIs(t, corrupt(func(slots []uint64) {
+		for i := range slots {
+			slots[i] |= qfShifted
+		}
+	}), ErrInvalidEncoding, "Slots without an empty one should be rejected")
+	assert.ErrorIs(t, corrupt(func(slots []uint64) { slots[first] |= qfContinuation | qfShifted }), ErrInvalidEncoding)
+	assert.ErrorIs(t, corrupt(func(slots []uint64) { slots[first] &^= qfOccupied }), ErrInvalidEncoding)
+	assert.ErrorIs(t, corrupt(func(slots []uint64) { slots[first] = 0 }), ErrInvalidEncoding)
+	assert.ErrorIs(t, corrupt(func(slots []uint64) { slots[first] |= ^uint64(0) << (qf.r + qfMetaBits) }), ErrInvalidEncoding)
+	assert.Equal(t, qf.slots, decoded.slots, "Invalid encodings should leave the filter unchanged")
+}
@@ -0,0 +1,133 @@
+package gobloom
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var _ Interface = (*RebuildableBloomFilter)(nil)
+
+// KeyIterator yields the keys of a data source, like a database cursor, passing the context to its I/O.
+type KeyIterator interface {
+	// Next returns the next key, and false once there are no more keys or the iteration failed.
+	Next(ctx context.Context) ([]byte, bool)
+	// Err returns the error that stopped the iteration, nil if all the keys were returned.
+	Err() error
+}
+
+// IteratorKeys returns a KeyIterator over the items of an Iterator, which ignores the context.
+func IteratorKeys(it Iterator) KeyIterator {
+	return iteratorKeys{it}
+}
+
+// iteratorKeys is a KeyIterator over the items of an Iterator.
+type iteratorKeys struct {
+	it Iterator
+}
+
+func (k iteratorKeys) Next(context.Context) ([]byte, bool) {
+	return k.it.Next()
+}
+
+func (k iteratorKeys) Err() error {
+	return k.it.Err()
+}
+
+// RebuildableBloomFilter is a Bloom filter that can be rebuilt from its keys into a new one, sized for
+// their true cardinality, while it is in use. Items added during the rebuild are written to both the
+// current and the new filter, so none is lost, and the new filter atomically replaces the current one
+// once all the keys were added to it.
+type RebuildableBloomFilter struct {
+	state   atomic.Pointer[rebuildState]
+	writers sync.RWMutex // Held by Add, and exclusively to change the state, so no add misses the new filter
+	rebuild sync.Mutex   // Serializes the rebuilds
+}
+
+// rebuildState is the current filter, and the filter being rebuilt, nil if none.
+type rebuildState struct {
+	current *BloomFilter
+	next    *BloomFilter
+}
+
+// NewRebuildable creates a new rebuildable Bloom filter with the given parameters.
+func NewRebuildable(p Params) (*RebuildableBloomFilter, error) {
+	bf, err := New(p)
+	if err != nil {
+		return nil, err
+	}
+	rbf := &RebuildableBloomFilter{}
+	rbf.state.Store(&rebuildState{current: bf})
+	return rbf, nil
+}
+
+// Add adds an item to the Bloom filter, and to the filter being rebuilt, if any.
+func (rbf *RebuildableBloomFilter) Add(data []byte) error {
+	rbf.writers.RLock()
+	defer rbf.writers.RUnlock()
+	s := rbf.state.Load()
+	if err := s.current.Add(data); err != nil {
+		return err
+	}
+	if s.next != nil {
+		return s.next.Add(data)
+	}
+	return nil
+}
+
+// Test checks if an item is in the Bloom filter. During a rebuild, the current filter is tested,
+// since the new one doesn't have all the keys yet.
+func (rbf *RebuildableBloomFilter) Test(data []byte) (bool, error) {
+	return rbf.state.Load().current.Test(data)
+}
+
+// Filter returns the current Bloom filter, which is replaced by the next rebuild.
+func (rbf *RebuildableBloomFilter) Filter() *BloomFilter {
+	return rbf.state.Load().current
+}
+
+// Rebuild creates a new Bloom filter with the parameters, adds every key to it, and replaces the current
+// filter with it, so a filter that outgrew its parameters can be rightsized. N should be the true number of
+// keys, like the row count of the table the keys come from. The filter stays in use during the rebuild:
+// Test checks the current filter, and Add writes to both filters, so the items added meanwhile are kept even
+// if the keys miss them. If the context is done or the keys fail, the new filter is dropped and the error
+// is returned. Rebuilds run one at a time.
+func (rbf *RebuildableBloomFilter) Rebuild(ctx context.Context, keys KeyIterator, newParams Params) error {
+	rbf.rebuild.Lock()
+	defer rbf.rebuild.Unlock()
+	next, err := New(newParams)
+	if err != nil {
+		return err
+	}
+	rbf.setState(func(s *rebuildState) *rebuildState { return &rebuildState{current: s.current, next: next} })
+	if err := addKeys(ctx, next, keys); err != nil {
+		rbf.setState(func(s *rebuildState) *rebuildState { return &rebuildState{current: s.current} })
+		return fmt.Errorf("rebuild failed: %w", err)
+	}
+	rbf.setState(func(s *rebuildState) *rebuildState { return &rebuildState{current: next} })
+	return nil
+}
+
+// setState replaces the state once no Add is running, so every Add writes to the filters of a single state.
+func (rbf *RebuildableBloomFilter) setState(update func(*rebuildState) *rebuildState) {
+	rbf.writers.Lock()
+	defer rbf.writers.Unlock()
+	rbf.state.Store(update(rbf.state.Load()))
+}
+
+// addKeys adds the keys to the Bloom filter, until the context is done.
+func addKeys(ctx context.Context, bf *BloomFilter, keys KeyIterator) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		key, ok := keys.Next(ctx)
+		if !ok {
+			return keys.Err()
+		}
+		if err := bf.Add(key); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package gobloom
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingKeys yields keys, pausing after the first one until release is closed.
+type blockingKeys struct {
+	KeyIterator
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (k *blockingKeys) Next(ctx context.Context) ([]byte, bool) {
+	key, ok := k.KeyIterator.Next(ctx)
+	k.once.Do(func() {
+		close(k.started)
+		<-k.release
+	})
+	return key, ok
+}
+
+func TestRebuildableBloomFilter_Rebuild(t *testing.T) {
+	t.Parallel()
+	rbf, err := NewRebuildable(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	keys := make([][]byte, 10000)
+	for i := range keys {
+		keys[i] = []byte(strconv.Itoa(i))
+		assert.NoError(t, rbf.Add(keys[i]))
+	}
+	old := rbf.Filter()
+	assert.Greater(t, old.EstimatedFalsePositiveRate(), 0.5, "The filter should have outgrown its parameters")
+
+	source := &blockingKeys{KeyIterator: IteratorKeys(SliceIterator(keys)), started: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		done <- rbf.Rebuild(context.Background(), source, Params{N: 20000, FalsePositiveRate: 0.01})
+	}()
+	<-source.started
+	// Items added during the rebuild go to both filters.
+	assert.NoError(t, rbf.Add([]byte("during")))
+	b, _ := rbf.Test([]byte("during"))
+	assert.True(t, b)
+	assert.Equal(t, old, rbf.Filter(), "The current filter should be used until the rebuild completes")
+	close(source.release)
+	assert.NoError(t, <-done)
+
+	assert.NotEqual(t, old, rbf.Filter())
+	assert.Less(t, rbf.Filter().EstimatedFalsePositiveRate(), 0.01)
+	for _, key := range append(keys, []byte("during")) {
+		b, _ := rbf.Test(key)
+		assert.True(t, b, "Key %q should be in the rebuilt filter", key)
+	}
+}
+
+func TestRebuildableBloomFilter_ConcurrentAdds(t *testing.T) {
+	t.Parallel()
+	rbf, err := NewRebuildable(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				assert.NoError(t, rbf.Add([]byte(strconv.Itoa(w)+"-"+strconv.Itoa(i))))
+			}
+		}(w)
+	}
+	// The rebuilds race with the adds, and get no keys, so only dual writes keep the items.
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, rbf.Rebuild(context.Background(), IteratorKeys(SliceIterator(nil)), Params{N: 10000, FalsePositiveRate: 0.01}))
+	}
+	wg.Wait()
+	assert.NoError(t, rbf.Rebuild(context.Background(), IteratorKeys(SliceIterator(nil)), Params{N: 10000, FalsePositiveRate: 0.01}))
+	// The last rebuild dropped every item, since it got no keys and no items were added meanwhile.
+	b, _ := rbf.Test([]byte("0-0"))
+	assert.False(t, b)
+}
+
+func TestRebuildableBloomFilter_RebuildCanceled(t *testing.T) {
+	t.Parallel()
+	rbf, err := NewRebuildable(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	assert.NoError(t, rbf.Add([]byte("foo")))
+	old := rbf.Filter()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = rbf.Rebuild(ctx, IteratorKeys(SliceIterator([][]byte{[]byte("foo")})), Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, old, rbf.Filter(), "A failed rebuild should keep the current filter")
+	assert.NoError(t, rbf.Add([]byte("bar")))
+	assert.Nil(t, rbf.state.Load().next, "Adds should stop writing to the dropped filter")
+
+	err = rbf.Rebuild(context.Background(), IteratorKeys(SliceIterator(nil)), Params{})
+	assert.Error(t, err, "Invalid parameters should be rejected")
+}
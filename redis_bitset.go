@@ -0,0 +1,90 @@
+package gobloom
+
+import (
+	"context"
+	"fmt"
+)
+
+var _ BitSet = (*RedisBitSet)(nil)
+
+// redisMaxBits is the maximum number of bits of a Redis string.
+const redisMaxBits = 1 << 32
+
+// RedisClient is the part of a Redis client used by RedisBitSet, so any client library can be used
+// through a small adapter, and this package doesn't depend on one.
+type RedisClient interface {
+	// Pipeline sends the commands in a single round trip and returns their replies, in order.
+	// It must return early with the context error when the context is done.
+	// Each command is a list of arguments, like ["SETBIT", "key", uint64(7), 1].
+	// Integer replies must be returned as int64.
+	Pipeline(ctx context.Context, cmds [][]any) ([]any, error)
+}
+
+// RedisBitSet is a BitSet stored in a Redis string with SETBIT and GETBIT, so several processes can
+// share one Bloom filter without the RedisBloom module. The probes of an operation are pipelined.
+// Redis strings are limited to 2^32 bits, so the filter size m must not exceed it.
+type RedisBitSet struct {
+	client RedisClient
+	key    string
+}
+
+// NewRedisBitSet creates a new BitSet stored in the Redis string at key.
+func NewRedisBitSet(client RedisClient, key string) (*RedisBitSet, error) {
+	if client == nil {
+		return nil, fmt.Errorf("redis client cannot be nil")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+	return &RedisBitSet{client: client, key: key}, nil
+}
+
+// Set sets the bits at the given positions with SETBIT.
+func (rbs *RedisBitSet) Set(ctx context.Context, positions []uint64) error {
+	_, err := rbs.pipeline(ctx, "SETBIT", positions, 1)
+	return err
+}
+
+// Test reports whether all the bits at the given positions are set, with GETBIT.
+func (rbs *RedisBitSet) Test(ctx context.Context, positions []uint64) (bool, error) {
+	replies, err := rbs.pipeline(ctx, "GETBIT", positions)
+	if err != nil {
+		return false, err
+	}
+	for _, reply := range replies {
+		bit, ok := reply.(int64)
+		if !ok {
+			return false, fmt.Errorf("unexpected GETBIT reply %v of type %T", reply, reply)
+		}
+		if bit == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// pipeline sends the command for each position, followed by the extra arguments.
+// It returns the first error reply, if any.
+func (rbs *RedisBitSet) pipeline(ctx context.Context, cmd string, positions []uint64, args ...any) ([]any, error) {
+	cmds := make([][]any, len(positions))
+	for i, pos := range positions {
+		if pos >= redisMaxBits {
+			return nil, fmt.Errorf("bit position %d exceeds the maximum size of a Redis string", pos)
+		}
+		cmds[i] = append([]any{cmd, rbs.key, pos}, args...)
+	}
+	replies, err := rbs.client.Pipeline(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	if len(replies) != len(cmds) {
+		return nil, fmt.Errorf("got %d replies for %d commands", len(replies), len(cmds))
+	}
+	// Redis reports the failure of each command in its reply, so one failed command fails the operation.
+	for _, reply := range replies {
+		if err, ok := reply.(error); ok {
+			return nil, err
+		}
+	}
+	return replies, nil
+}
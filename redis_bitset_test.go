@@ -0,0 +1,134 @@
+package gobloom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis is an in-memory RedisClient supporting SETBIT and GETBIT.
+type fakeRedis struct {
+	mu        sync.Mutex
+	bits      map[string]map[uint64]bool
+	pipelines int
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{bits: make(map[string]map[uint64]bool)}
+}
+
+func (r *fakeRedis) Pipeline(ctx context.Context, cmds [][]any) ([]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pipelines++
+	replies := make([]any, len(cmds))
+	for i, cmd := range cmds {
+		key, pos := cmd[1].(string), cmd[2].(uint64)
+		if r.bits[key] == nil {
+			r.bits[key] = make(map[uint64]bool)
+		}
+		old := int64(0)
+		if r.bits[key][pos] {
+			old = 1
+		}
+		switch cmd[0] {
+		case "SETBIT":
+			r.bits[key][pos] = cmd[3] == 1
+		case "GETBIT":
+		default:
+			return nil, fmt.Errorf("unknown command %v", cmd[0])
+		}
+		replies[i] = old
+	}
+	return replies, nil
+}
+
+func TestRedisBitSet_SharedFilter(t *testing.T) {
+	t.Parallel()
+	redis := newFakeRedis()
+	newFilter := func() *BitSetBloomFilter {
+		bits, err := NewRedisBitSet(redis, "filter")
+		assert.NoError(t, err)
+		bf, err := NewWithBitSet(Params{N: 1000, FalsePositiveRate: 0.01}, bits)
+		assert.NoError(t, err, "Failed to create Bloom filter")
+		return bf
+	}
+	a, b := newFilter(), newFilter()
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, a.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.Equal(t, 100, redis.pipelines, "Probes of an operation should be pipelined")
+	for i := 0; i < 100; i++ {
+		found, err := b.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, found, "Item added by another instance should be present")
+	}
+	found, err := b.Test([]byte("missing"))
+	assert.NoError(t, err)
+	assert.False(t, found, "Item should not be present")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = b.TestCtx(canceled, []byte("item-1"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, b.AddCtx(canceled, []byte("item-1")), context.Canceled)
+
+	// The bits are the same as an in-memory filter's.
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	ones := 0
+	for i := uint64(0); i < bf.M(); i++ {
+		if bf.UnsafeBits()[i/64]&(1<<(i%64)) != 0 {
+			ones++
+			assert.True(t, redis.bits["filter"][i], "Bit %d should be set", i)
+		}
+	}
+	assert.Len(t, redis.bits["filter"], ones)
+}
+
+func TestRedisBitSet_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := NewRedisBitSet(nil, "filter")
+	assert.Error(t, err)
+	_, err = NewRedisBitSet(newFakeRedis(), "")
+	assert.Error(t, err)
+	_, err = NewWithBitSet(Params{N: 1000, FalsePositiveRate: 0.01}, nil)
+	assert.Error(t, err)
+
+	bits, err := NewRedisBitSet(newFakeRedis(), "filter")
+	assert.NoError(t, err)
+	assert.Error(t, bits.Set(context.Background(), []uint64{redisMaxBits}), "Positions beyond the maximum string size should be rejected")
+}
+
+// errorRedis is a RedisClient replying to the second command of each pipeline with an error.
+type errorRedis struct{}
+
+func (errorRedis) Pipeline(_ context.Context, cmds [][]any) ([]any, error) {
+	replies := make([]any, len(cmds))
+	for i := range replies {
+		replies[i] = int64(1)
+	}
+	replies[1] = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	return replies, nil
+}
+
+func TestRedisBitSet_ErrorReply(t *testing.T) {
+	t.Parallel()
+	bits, err := NewRedisBitSet(errorRedis{}, "filter")
+	assert.NoError(t, err)
+	err = bits.Set(context.Background(), []uint64{1, 2, 3})
+	assert.ErrorContains(t, err, "WRONGTYPE", "Failed SETBIT commands should be reported")
+	_, err = bits.Test(context.Background(), []uint64{1, 2, 3})
+	assert.ErrorContains(t, err, "WRONGTYPE", "Failed GETBIT commands should be reported as Redis errors")
+}
@@ -0,0 +1,346 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+var _ Interface = (*RedisBloomFilter)(nil)
+
+// Options of RedisBloom filters, as stored in their dump header.
+const (
+	redisBloomOptNoRound   = 1 << 0 // The number of bits is not rounded up to a power of two
+	redisBloomOptEntsBits  = 1 << 1 // The capacity is given in bits
+	redisBloomOptForce64   = 1 << 2 // Positions are computed with 64-bit hashes
+	redisBloomOptNoScaling = 1 << 3 // No links are added when the filter is full
+
+	redisBloomDefaultExpansion = 2
+	redisBloomTighteningRatio  = 0.5
+	redisBloomMaxChunkSize     = 10 * 1024 * 1024
+	redisBloomHeaderSize       = 20 // size, nfilters, options, growth
+	redisBloomLinkSize         = 53 // bytes, bits, size, error, bpe, hashes, entries, n2
+	redisBloomHashSeed         = 0xc6a4a7935bd1e995
+)
+
+// RedisBloomFilter is a scalable Bloom filter that behaves like a RedisBloom filter, and can be
+// moved to and from Redis with BF.SCANDUMP and BF.LOADCHUNK.
+// It is a chain of links, each one a Bloom filter with a capacity and an error rate. Items are
+// added to the newest link, unless they are already present. When the newest link is full, a new
+// link is added with Expansion times its capacity and half its error rate.
+type RedisBloomFilter struct {
+	links   []redisBloomLink // The links of the chain, from the oldest to the newest
+	size    uint64           // The number of items added
+	options uint32           // The RedisBloom options of the filter
+	growth  uint32           // The capacity growth factor of new links
+	mutex   Mutex            // Mutex to ensure thread safety
+}
+
+// redisBloomLink is a link of a RedisBloom filter chain.
+type redisBloomLink struct {
+	bytes   uint64  // The size of the bit set in bytes
+	bits    uint64  // The size of the bit set in bits
+	size    uint64  // The number of items added to the link
+	error   float64 // The error rate of the link
+	bpe     float64 // The number of bits per entry
+	hashes  uint32  // The number of hash functions
+	entries uint64  // The capacity of the link
+	n2      uint8   // If not 0, the number of bits is 2^n2
+	bf      []byte  // The bit set
+}
+
+// ParamsRedisBloom represents the parameters for creating a new RedisBloom filter,
+// like the arguments of BF.RESERVE.
+type ParamsRedisBloom struct {
+	// Capacity is the number of elements expected to be added to the first link.
+	Capacity uint64
+	// ErrorRate is the acceptable false positive rate.
+	ErrorRate float64
+	// Expansion is the capacity growth factor of new links. Defaults to 2.
+	Expansion uint32
+	// NonScaling disables adding new links, Add returns ErrFilterFull once the capacity is reached.
+	NonScaling bool
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewRedisBloom creates a new RedisBloom filter.
+func NewRedisBloom(p ParamsRedisBloom) (*RedisBloomFilter, error) {
+	if p.Expansion == 0 {
+		p.Expansion = redisBloomDefaultExpansion
+	}
+	if p.LockType == LockTypeDefault {
+		p.LockType = LockTypeExclusive
+	}
+	if p.Capacity == 0 {
+		return nil, fmt.Errorf("capacity cannot be 0")
+	}
+	if p.ErrorRate <= 0 || p.ErrorRate >= 1 {
+		return nil, fmt.Errorf("error rate must be between 0 and 1")
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	options := uint32(redisBloomOptNoRound | redisBloomOptForce64)
+	if p.NonScaling {
+		options |= redisBloomOptNoScaling
+	}
+	rbf := &RedisBloomFilter{options: options, growth: p.Expansion, mutex: mu}
+	rbf.addLink(p.Capacity, p.ErrorRate)
+	return rbf, nil
+}
+
+// addLink adds a new link to the chain, sized like RedisBloom does.
+func (rbf *RedisBloomFilter) addLink(entries uint64, errorRate float64) {
+	l := redisBloomLink{
+		entries: entries,
+		error:   errorRate,
+		bpe:     -math.Log(errorRate) / (math.Ln2 * math.Ln2),
+	}
+	// Tiny links with a high error rate need less than a bit, they get one, like in RedisBloom.
+	bits := max(float64(entries)*l.bpe, 1)
+	if rbf.options&redisBloomOptNoRound == 0 {
+		l.n2 = uint8(math.Ceil(math.Log2(bits)))
+		l.bits = 1 << l.n2
+	} else {
+		l.bits = uint64(bits)
+	}
+	// RedisBloom allocates whole 64-bit words, and uses all their bits.
+	if l.bits%64 != 0 {
+		l.bytes = (l.bits/64 + 1) * 8
+	} else {
+		l.bytes = l.bits / 8
+	}
+	l.bits = l.bytes * 8
+	l.hashes = uint32(math.Ceil(math.Ln2 * l.bpe))
+	l.bf = make([]byte, l.bytes)
+	rbf.links = append(rbf.links, l)
+}
+
+// redisBloomHash computes the two base hashes RedisBloom derives positions from.
+func redisBloomHash(data []byte) (uint64, uint64) {
+	a := murmurHash64A(data, redisBloomHashSeed)
+	return a, murmurHash64A(data, a)
+}
+
+// position returns the i-th bit position of the base hashes in the link.
+func (l *redisBloomLink) position(a, b uint64, i uint64) uint64 {
+	x := a + i*b
+	if l.n2 > 0 {
+		return x & (uint64(1)<<l.n2 - 1)
+	}
+	return x % l.bits
+}
+
+func (l *redisBloomLink) test(a, b uint64) bool {
+	for i := uint64(0); i < uint64(l.hashes); i++ {
+		x := l.position(a, b, i)
+		if l.bf[x/8]&(1<<(x%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *redisBloomLink) add(a, b uint64) {
+	for i := uint64(0); i < uint64(l.hashes); i++ {
+		x := l.position(a, b, i)
+		l.bf[x/8] |= 1 << (x % 8)
+	}
+}
+
+// Add adds an item to the filter, unless it is already present.
+// It returns ErrFilterFull if the filter is non-scaling and has reached its capacity.
+func (rbf *RedisBloomFilter) Add(data []byte) error {
+	if rbf.mutex != nil {
+		rbf.mutex.WLock()
+		defer rbf.mutex.WUnlock()
+	}
+	a, b := redisBloomHash(data)
+	for i := len(rbf.links) - 1; i >= 0; i-- {
+		if rbf.links[i].test(a, b) {
+			return nil
+		}
+	}
+	cur := &rbf.links[len(rbf.links)-1]
+	if cur.size >= cur.entries {
+		if rbf.options&redisBloomOptNoScaling != 0 {
+			return ErrFilterFull
+		}
+		rbf.addLink(cur.entries*uint64(rbf.growth), cur.error*redisBloomTighteningRatio)
+		cur = &rbf.links[len(rbf.links)-1]
+	}
+	cur.add(a, b)
+	cur.size++
+	rbf.size++
+	return nil
+}
+
+// Test checks if an item is in the filter.
+func (rbf *RedisBloomFilter) Test(data []byte) (bool, error) {
+	if rbf.mutex != nil {
+		rbf.mutex.RLock()
+		defer rbf.mutex.RUnlock()
+	}
+	a, b := redisBloomHash(data)
+	for i := len(rbf.links) - 1; i >= 0; i-- {
+		if rbf.links[i].test(a, b) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ScanDump returns the chunk of the filter at the given iterator, like BF.SCANDUMP.
+// Start with iterator 0, and call it with the returned iterator until it returns 0.
+// Each returned chunk must be passed to LoadChunk with the iterator returned along with it.
+func (rbf *RedisBloomFilter) ScanDump(iter int64) (int64, []byte) {
+	if rbf.mutex != nil {
+		rbf.mutex.RLock()
+		defer rbf.mutex.RUnlock()
+	}
+	if iter == 0 {
+		return 1, rbf.header()
+	}
+	l, offset := rbf.linkAt(iter - 1)
+	if l == nil {
+		return 0, nil
+	}
+	n := l.bytes - offset
+	if n > redisBloomMaxChunkSize {
+		n = redisBloomMaxChunkSize
+	}
+	chunk := append([]byte(nil), l.bf[offset:offset+n]...)
+	return iter + int64(n), chunk
+}
+
+// LoadChunk loads a chunk returned by ScanDump, or by BF.SCANDUMP, like BF.LOADCHUNK.
+// The header chunk, with iterator 1, must be loaded first, it replaces the whole filter.
+// Only filters using 64-bit hashes, the default since RedisBloom 2.0, can be loaded.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value filter.
+func (rbf *RedisBloomFilter) LoadChunk(iter int64, data []byte) error {
+	if rbf.mutex == nil && len(rbf.links) == 0 {
+		rbf.mutex = &ExclusiveMutex{}
+	}
+	if rbf.mutex != nil {
+		rbf.mutex.WLock()
+		defer rbf.mutex.WUnlock()
+	}
+	if iter == 1 {
+		return rbf.loadHeader(data)
+	}
+	if len(rbf.links) == 0 {
+		return fmt.Errorf("%w: the header chunk must be loaded first", ErrInvalidEncoding)
+	}
+	l, offset := rbf.linkAt(iter - int64(len(data)) - 1)
+	if l == nil || offset+uint64(len(data)) > l.bytes {
+		return fmt.Errorf("%w: chunk out of bounds", ErrInvalidEncoding)
+	}
+	copy(l.bf[offset:], data)
+	return nil
+}
+
+// linkAt returns the link holding the byte at the given position of the concatenated bit sets,
+// and the offset of the byte in it.
+func (rbf *RedisBloomFilter) linkAt(pos int64) (*redisBloomLink, uint64) {
+	if pos < 0 {
+		return nil, 0
+	}
+	p := uint64(pos)
+	for i := range rbf.links {
+		if p < rbf.links[i].bytes {
+			return &rbf.links[i], p
+		}
+		p -= rbf.links[i].bytes
+	}
+	return nil, 0
+}
+
+// header encodes the header chunk, a packed little-endian C struct.
+func (rbf *RedisBloomFilter) header() []byte {
+	buf := make([]byte, 0, redisBloomHeaderSize+redisBloomLinkSize*len(rbf.links))
+	buf = binary.LittleEndian.AppendUint64(buf, rbf.size)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(rbf.links)))
+	buf = binary.LittleEndian.AppendUint32(buf, rbf.options)
+	buf = binary.LittleEndian.AppendUint32(buf, rbf.growth)
+	for _, l := range rbf.links {
+		buf = binary.LittleEndian.AppendUint64(buf, l.bytes)
+		buf = binary.LittleEndian.AppendUint64(buf, l.bits)
+		buf = binary.LittleEndian.AppendUint64(buf, l.size)
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(l.error))
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(l.bpe))
+		buf = binary.LittleEndian.AppendUint32(buf, l.hashes)
+		buf = binary.LittleEndian.AppendUint64(buf, l.entries)
+		buf = append(buf, l.n2)
+	}
+	return buf
+}
+
+// loadHeader replaces the filter with an empty one described by the header chunk.
+func (rbf *RedisBloomFilter) loadHeader(data []byte) error {
+	if len(data) < redisBloomHeaderSize {
+		return fmt.Errorf("%w: header too short", ErrInvalidEncoding)
+	}
+	size := binary.LittleEndian.Uint64(data)
+	nfilters := binary.LittleEndian.Uint32(data[8:])
+	options := binary.LittleEndian.Uint32(data[12:])
+	growth := binary.LittleEndian.Uint32(data[16:])
+	if nfilters == 0 || uint64(len(data)) != redisBloomHeaderSize+redisBloomLinkSize*uint64(nfilters) {
+		return fmt.Errorf("%w: header size does not match the number of links", ErrInvalidEncoding)
+	}
+	if options&redisBloomOptForce64 == 0 {
+		return fmt.Errorf("%w: only filters using 64-bit hashes are supported", ErrInvalidEncoding)
+	}
+	links := make([]redisBloomLink, nfilters)
+	for i := range links {
+		b := data[redisBloomHeaderSize+redisBloomLinkSize*i:]
+		l := redisBloomLink{
+			bytes:   binary.LittleEndian.Uint64(b),
+			bits:    binary.LittleEndian.Uint64(b[8:]),
+			size:    binary.LittleEndian.Uint64(b[16:]),
+			error:   math.Float64frombits(binary.LittleEndian.Uint64(b[24:])),
+			bpe:     math.Float64frombits(binary.LittleEndian.Uint64(b[32:])),
+			hashes:  binary.LittleEndian.Uint32(b[40:]),
+			entries: binary.LittleEndian.Uint64(b[44:]),
+			n2:      b[52],
+		}
+		if l.bits == 0 || l.bytes*8 < l.bits || l.hashes == 0 || l.n2 >= 64 {
+			return fmt.Errorf("%w: invalid link %d", ErrInvalidEncoding, i)
+		}
+		l.bf = make([]byte, l.bytes)
+		links[i] = l
+	}
+	rbf.links = links
+	rbf.size = size
+	rbf.options = options
+	rbf.growth = growth
+	return nil
+}
+
+// murmurHash64A is the 64-bit MurmurHash2 variant by Austin Appleby, used by RedisBloom.
+func murmurHash64A(data []byte, seed uint64) uint64 {
+	const (
+		m = 0xc6a4a7935bd1e995
+		r = 47
+	)
+	h := seed ^ (uint64(len(data)) * m)
+	for ; len(data) >= 8; data = data[8:] {
+		k := binary.LittleEndian.Uint64(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+	}
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint64(data[i]) << (8 * i)
+		}
+		h *= m
+	}
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
@@ -0,0 +1,92 @@
+package gobloom
+
+import (
+	"context"
+	"fmt"
+)
+
+var _ ContextInterface = (*RedisBloomClient)(nil)
+
+// RedisBloomClient is a filter hosted by a Redis server with the RedisBloom module, used with the
+// BF.ADD and BF.EXISTS commands, so in-memory and Redis hosted filters can be used interchangeably.
+type RedisBloomClient struct {
+	client RedisClient
+	key    string
+}
+
+// NewRedisBloomClient creates a new client for the RedisBloom filter at key.
+// If the filter doesn't exist, the server creates it with its default parameters on the first Add,
+// unless it is created first with Reserve.
+func NewRedisBloomClient(client RedisClient, key string) (*RedisBloomClient, error) {
+	if client == nil {
+		return nil, fmt.Errorf("redis client cannot be nil")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+	return &RedisBloomClient{client: client, key: key}, nil
+}
+
+// Reserve creates the filter on the server with BF.RESERVE. It fails if the filter already exists.
+// The lock type is ignored.
+func (rbc *RedisBloomClient) Reserve(ctx context.Context, p ParamsRedisBloom) error {
+	if p.Capacity == 0 {
+		return fmt.Errorf("capacity cannot be 0")
+	}
+	if p.ErrorRate <= 0 || p.ErrorRate >= 1 {
+		return fmt.Errorf("error rate must be between 0 and 1")
+	}
+	cmd := []any{"BF.RESERVE", rbc.key, p.ErrorRate, p.Capacity}
+	if p.Expansion != 0 {
+		cmd = append(cmd, "EXPANSION", p.Expansion)
+	}
+	if p.NonScaling {
+		cmd = append(cmd, "NONSCALING")
+	}
+	_, err := rbc.do(ctx, cmd...)
+	return err
+}
+
+// Add adds an item to the filter with BF.ADD.
+func (rbc *RedisBloomClient) Add(data []byte) error {
+	return rbc.AddCtx(context.Background(), data)
+}
+
+// Test checks if an item is in the filter with BF.EXISTS.
+func (rbc *RedisBloomClient) Test(data []byte) (bool, error) {
+	return rbc.TestCtx(context.Background(), data)
+}
+
+// AddCtx adds an item to the filter with BF.ADD, with a context passed to the client.
+func (rbc *RedisBloomClient) AddCtx(ctx context.Context, data []byte) error {
+	_, err := rbc.do(ctx, "BF.ADD", rbc.key, data)
+	return err
+}
+
+// TestCtx checks if an item is in the filter with BF.EXISTS, with a context passed to the client.
+func (rbc *RedisBloomClient) TestCtx(ctx context.Context, data []byte) (bool, error) {
+	reply, err := rbc.do(ctx, "BF.EXISTS", rbc.key, data)
+	if err != nil {
+		return false, err
+	}
+	exists, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected BF.EXISTS reply %v of type %T", reply, reply)
+	}
+	return exists == 1, nil
+}
+
+// do sends a single command and returns its reply.
+func (rbc *RedisBloomClient) do(ctx context.Context, cmd ...any) (any, error) {
+	replies, err := rbc.client.Pipeline(ctx, [][]any{cmd})
+	if err != nil {
+		return nil, err
+	}
+	if len(replies) != 1 {
+		return nil, fmt.Errorf("got %d replies for 1 command", len(replies))
+	}
+	if err, ok := replies[0].(error); ok {
+		return nil, err
+	}
+	return replies[0], nil
+}
@@ -0,0 +1,96 @@
+package gobloom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedisBloom is a RedisClient emulating the RedisBloom commands with RedisBloomFilter.
+type fakeRedisBloom struct {
+	filters map[string]*RedisBloomFilter
+	cmds    [][]any
+}
+
+func (r *fakeRedisBloom) Pipeline(ctx context.Context, cmds [][]any) ([]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	replies := make([]any, len(cmds))
+	for i, cmd := range cmds {
+		r.cmds = append(r.cmds, cmd)
+		key := cmd[1].(string)
+		f := r.filters[key]
+		switch cmd[0] {
+		case "BF.RESERVE":
+			if f != nil {
+				replies[i] = errors.New("ERR item exists")
+				continue
+			}
+			r.filters[key], _ = NewRedisBloom(ParamsRedisBloom{ErrorRate: cmd[2].(float64), Capacity: cmd[3].(uint64)})
+			replies[i] = "OK"
+		case "BF.ADD":
+			if f == nil {
+				f, _ = NewRedisBloom(ParamsRedisBloom{ErrorRate: 0.01, Capacity: 100})
+				r.filters[key] = f
+			}
+			replies[i] = int64(1)
+			if err := f.Add(cmd[2].([]byte)); err != nil {
+				replies[i] = err
+			}
+		case "BF.EXISTS":
+			replies[i] = int64(0)
+			if f != nil {
+				if b, _ := f.Test(cmd[2].([]byte)); b {
+					replies[i] = int64(1)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown command %v", cmd[0])
+		}
+	}
+	return replies, nil
+}
+
+func TestRedisBloomClient_AddAndTest(t *testing.T) {
+	t.Parallel()
+	redis := &fakeRedisBloom{filters: make(map[string]*RedisBloomFilter)}
+	var f Interface
+	f, err := NewRedisBloomClient(redis, "filter")
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.Add([]byte("foo")))
+	b, err := f.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present")
+	b, err = f.Test([]byte("bar"))
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should not be present")
+	assert.Equal(t, []any{"BF.ADD", "filter", []byte("foo")}, redis.cmds[0])
+}
+
+func TestRedisBloomClient_Reserve(t *testing.T) {
+	t.Parallel()
+	redis := &fakeRedisBloom{filters: make(map[string]*RedisBloomFilter)}
+	rbc, err := NewRedisBloomClient(redis, "filter")
+	assert.NoError(t, err)
+
+	p := ParamsRedisBloom{Capacity: 1000, ErrorRate: 0.001, Expansion: 4, NonScaling: true}
+	ctx := context.Background()
+	assert.NoError(t, rbc.Reserve(ctx, p))
+	assert.Equal(t, []any{"BF.RESERVE", "filter", 0.001, uint64(1000), "EXPANSION", uint32(4), "NONSCALING"}, redis.cmds[0])
+	assert.Error(t, rbc.Reserve(ctx, p), "Server errors should be returned")
+	assert.Error(t, rbc.Reserve(ctx, ParamsRedisBloom{ErrorRate: 0.01}), "Invalid parameters should be rejected")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, rbc.AddCtx(canceled, []byte("foo")), context.Canceled)
+	_, err = rbc.TestCtx(canceled, []byte("foo"))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = NewRedisBloomClient(nil, "filter")
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisBloomFilter_AddAndTest(t *testing.T) {
+	t.Parallel()
+	rbf, err := NewRedisBloom(ParamsRedisBloom{Capacity: 100, ErrorRate: 0.01})
+	assert.NoError(t, err, "Failed to create RedisBloom filter")
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, rbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.Greater(t, len(rbf.links), 1, "Expected the filter to scale")
+	assert.Equal(t, uint64(200), rbf.links[1].entries, "Expected the second link to double the capacity")
+	assert.Equal(t, 0.005, rbf.links[1].error, "Expected the second link to halve the error rate")
+
+	for i := 0; i < 1000; i++ {
+		b, err := rbf.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should be present", i)
+	}
+}
+
+func TestRedisBloomFilter_NonScaling(t *testing.T) {
+	t.Parallel()
+	rbf, err := NewRedisBloom(ParamsRedisBloom{Capacity: 10, ErrorRate: 0.01, NonScaling: true})
+	assert.NoError(t, err, "Failed to create RedisBloom filter")
+
+	var fullErr error
+	for i := 0; i < 100 && fullErr == nil; i++ {
+		fullErr = rbf.Add([]byte(fmt.Sprintf("item-%d", i)))
+	}
+	assert.ErrorIs(t, fullErr, ErrFilterFull)
+	assert.Len(t, rbf.links, 1)
+}
+
+func TestRedisBloomFilter_LinkSize(t *testing.T) {
+	t.Parallel()
+	// 100 entries at 1% need 958 bits, RedisBloom allocates 15 64-bit words.
+	rbf, err := NewRedisBloom(ParamsRedisBloom{Capacity: 100, ErrorRate: 0.01})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(120), rbf.links[0].bytes)
+	assert.Equal(t, uint64(960), rbf.links[0].bits)
+
+	// A link needing less than a bit gets a single word.
+	rbf, err = NewRedisBloom(ParamsRedisBloom{Capacity: 1, ErrorRate: 0.9})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), rbf.links[0].bytes)
+	assert.NoError(t, rbf.Add([]byte("foo")))
+	b, err := rbf.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b)
+}
+
+func TestRedisBloomFilter_ScanDumpLoadChunk(t *testing.T) {
+	t.Parallel()
+	rbf, err := NewRedisBloom(ParamsRedisBloom{Capacity: 100, ErrorRate: 0.01})
+	assert.NoError(t, err, "Failed to create RedisBloom filter")
+	for i := 0; i < 500; i++ {
+		assert.NoError(t, rbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	var restored RedisBloomFilter
+	chunks := 0
+	for iter, chunk := rbf.ScanDump(0); iter != 0; iter, chunk = rbf.ScanDump(iter) {
+		assert.NoError(t, restored.LoadChunk(iter, chunk))
+		chunks++
+	}
+	assert.Equal(t, len(rbf.links)+1, chunks, "Expected a header chunk and one chunk per link")
+	assert.Equal(t, rbf.links, restored.links)
+	assert.Equal(t, rbf.size, restored.size)
+
+	for i := 0; i < 500; i++ {
+		b, err := restored.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should be present after loading", i)
+	}
+}
+
+func TestRedisBloomFilter_LoadChunkInvalid(t *testing.T) {
+	t.Parallel()
+	var rbf RedisBloomFilter
+	assert.ErrorIs(t, rbf.LoadChunk(10, []byte{1, 2, 3}), ErrInvalidEncoding, "Chunks should not load before the header")
+	assert.ErrorIs(t, rbf.LoadChunk(1, []byte{1, 2, 3}), ErrInvalidEncoding)
+
+	src, err := NewRedisBloom(ParamsRedisBloom{Capacity: 100, ErrorRate: 0.01})
+	assert.NoError(t, err, "Failed to create RedisBloom filter")
+	_, header := src.ScanDump(0)
+	assert.NoError(t, rbf.LoadChunk(1, header))
+	assert.ErrorIs(t, rbf.LoadChunk(1<<40, []byte{1}), ErrInvalidEncoding)
+}
+
+func TestMurmurHash64A(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, uint64(0), murmurHash64A(nil, 0))
+	// Every tail length must be mixed in, so all prefixes hash differently.
+	data := []byte("0123456789abcdef")
+	seen := map[uint64]bool{}
+	for i := 0; i <= len(data); i++ {
+		seen[murmurHash64A(data[:i], redisBloomHashSeed)] = true
+	}
+	assert.Len(t, seen, len(data)+1)
+}
@@ -0,0 +1,194 @@
+package gobloom
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+var _ BitSet = (*RoaringBitSet)(nil)
+
+const (
+	// roaringChunkBits is the number of low bits of the positions stored in the containers.
+	roaringChunkBits = 16
+	// roaringMaxArray is the number of positions above which an array container becomes a bitmap container,
+	// the size where both take the same memory.
+	roaringMaxArray = 4096
+	// roaringBitmapWords is the number of words of a bitmap container.
+	roaringBitmapWords = 1 << roaringChunkBits / 64
+	// roaringContainerOverhead is the approximate memory of a container besides its positions.
+	roaringContainerOverhead = 64
+)
+
+// RoaringBitSet is an in-memory BitSet storing the set bits in a roaring bitmap: the positions are split in
+// chunks of 65536 bits, and the positions set in each chunk are stored in a sorted array while there are
+// few of them, and in a bitmap otherwise. A huge Bloom filter with few items added uses memory proportional
+// to its set bits rather than to its size, and grows as items are added.
+//
+// The positions of a Bloom filter are random, so once the filter fills up, most chunks become bitmaps, which
+// are no smaller than a plain bit set. The bit set then switches to a plain array of words, sized for the
+// largest position set, for good. Use it with NewWithBitSet or WithBitSetBackend.
+type RoaringBitSet struct {
+	mu         sync.RWMutex
+	keys       []uint64            // The sorted chunk numbers of the containers
+	containers []*roaringContainer // The containers, in the order of keys
+	count      uint64              // The number of set bits
+	size       uint64              // The approximate memory of the containers in bytes
+	maxPos     uint64              // The largest position set
+	dense      []uint64            // The bits once switched to a plain array, nil before
+}
+
+// roaringContainer holds the low bits of the positions set in a chunk, in array while there are at most
+// roaringMaxArray of them, and in bitmap otherwise.
+type roaringContainer struct {
+	array  []uint16
+	bitmap []uint64
+}
+
+// NewRoaringBitSet creates a new empty RoaringBitSet.
+func NewRoaringBitSet() *RoaringBitSet {
+	return &RoaringBitSet{}
+}
+
+// Set sets the bits at the given positions, switching to a plain array once the containers would use more memory.
+func (rbs *RoaringBitSet) Set(_ context.Context, positions []uint64) error {
+	rbs.mu.Lock()
+	defer rbs.mu.Unlock()
+	for _, pos := range positions {
+		rbs.set(pos)
+	}
+	if rbs.dense == nil && rbs.size >= rbs.denseSize() {
+		rbs.densify()
+	}
+	return nil
+}
+
+// Test reports whether all the bits at the given positions are set.
+func (rbs *RoaringBitSet) Test(_ context.Context, positions []uint64) (bool, error) {
+	rbs.mu.RLock()
+	defer rbs.mu.RUnlock()
+	for _, pos := range positions {
+		if !rbs.test(pos) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Count returns the number of set bits.
+func (rbs *RoaringBitSet) Count() uint64 {
+	rbs.mu.RLock()
+	defer rbs.mu.RUnlock()
+	return rbs.count
+}
+
+// Dense returns whether the bit set switched to a plain array of words.
+func (rbs *RoaringBitSet) Dense() bool {
+	rbs.mu.RLock()
+	defer rbs.mu.RUnlock()
+	return rbs.dense != nil
+}
+
+// MemoryUsage returns the approximate memory used by the bit set in bytes.
+func (rbs *RoaringBitSet) MemoryUsage() uint64 {
+	rbs.mu.RLock()
+	defer rbs.mu.RUnlock()
+	if rbs.dense != nil {
+		return 8 * uint64(len(rbs.dense))
+	}
+	return rbs.size
+}
+
+// denseSize returns the size in bytes of a plain array holding the largest position set.
+func (rbs *RoaringBitSet) denseSize() uint64 {
+	return 8 * (rbs.maxPos/64 + 1)
+}
+
+// set sets the bit at pos.
+func (rbs *RoaringBitSet) set(pos uint64) {
+	if rbs.dense != nil {
+		if i := pos / 64; i >= uint64(len(rbs.dense)) {
+			rbs.dense = append(rbs.dense, make([]uint64, i+1-uint64(len(rbs.dense)))...)
+		}
+		if rbs.dense[pos/64]&(1<<(pos%64)) == 0 {
+			rbs.dense[pos/64] |= 1 << (pos % 64)
+			rbs.count++
+		}
+		return
+	}
+	rbs.maxPos = max(rbs.maxPos, pos)
+	key, low := pos>>roaringChunkBits, uint16(pos)
+	i := sort.Search(len(rbs.keys), func(i int) bool { return rbs.keys[i] >= key })
+	if i == len(rbs.keys) || rbs.keys[i] != key {
+		rbs.keys = append(rbs.keys, 0)
+		copy(rbs.keys[i+1:], rbs.keys[i:])
+		rbs.keys[i] = key
+		rbs.containers = append(rbs.containers, nil)
+		copy(rbs.containers[i+1:], rbs.containers[i:])
+		rbs.containers[i] = &roaringContainer{}
+		rbs.size += roaringContainerOverhead
+	}
+	c := rbs.containers[i]
+	if c.bitmap != nil {
+		if c.bitmap[low/64]&(1<<(low%64)) == 0 {
+			c.bitmap[low/64] |= 1 << (low % 64)
+			rbs.count++
+		}
+		return
+	}
+	j := sort.Search(len(c.array), func(j int) bool { return c.array[j] >= low })
+	if j < len(c.array) && c.array[j] == low {
+		return
+	}
+	rbs.count++
+	if len(c.array) == roaringMaxArray {
+		c.bitmap = make([]uint64, roaringBitmapWords)
+		for _, v := range c.array {
+			c.bitmap[v/64] |= 1 << (v % 64)
+		}
+		c.bitmap[low/64] |= 1 << (low % 64)
+		rbs.size += 8*roaringBitmapWords - 2*uint64(len(c.array))
+		c.array = nil
+		return
+	}
+	c.array = append(c.array, 0)
+	copy(c.array[j+1:], c.array[j:])
+	c.array[j] = low
+	rbs.size += 2
+}
+
+// test reports whether the bit at pos is set.
+func (rbs *RoaringBitSet) test(pos uint64) bool {
+	if rbs.dense != nil {
+		return pos/64 < uint64(len(rbs.dense)) && rbs.dense[pos/64]&(1<<(pos%64)) != 0
+	}
+	key, low := pos>>roaringChunkBits, uint16(pos)
+	i := sort.Search(len(rbs.keys), func(i int) bool { return rbs.keys[i] >= key })
+	if i == len(rbs.keys) || rbs.keys[i] != key {
+		return false
+	}
+	c := rbs.containers[i]
+	if c.bitmap != nil {
+		return c.bitmap[low/64]&(1<<(low%64)) != 0
+	}
+	j := sort.Search(len(c.array), func(j int) bool { return c.array[j] >= low })
+	return j < len(c.array) && c.array[j] == low
+}
+
+// densify copies the containers to a plain array of words, and drops them.
+func (rbs *RoaringBitSet) densify() {
+	dense := make([]uint64, rbs.maxPos/64+1)
+	for i, key := range rbs.keys {
+		base := key << roaringChunkBits / 64
+		c := rbs.containers[i]
+		if c.bitmap != nil {
+			copy(dense[base:], c.bitmap)
+			continue
+		}
+		for _, v := range c.array {
+			dense[base+uint64(v)/64] |= 1 << (v % 64)
+		}
+	}
+	rbs.dense = dense
+	rbs.keys, rbs.containers, rbs.size = nil, nil, 0
+}
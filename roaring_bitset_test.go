@@ -0,0 +1,111 @@
+package gobloom
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoaringBitSet_SetTest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rbs := NewRoaringBitSet()
+	b, err := rbs.Test(ctx, []uint64{0})
+	assert.NoError(t, err)
+	assert.False(t, b, "An empty bit set should have no bits set")
+
+	assert.NoError(t, rbs.Set(ctx, []uint64{3, 1 << 40, 70000, 3}))
+	b, _ = rbs.Test(ctx, []uint64{3, 1 << 40, 70000})
+	assert.True(t, b)
+	b, _ = rbs.Test(ctx, []uint64{3, 4})
+	assert.False(t, b)
+	assert.Equal(t, uint64(3), rbs.Count(), "Bits set twice should be counted once")
+	assert.False(t, rbs.Dense(), "A sparse bit set should not switch to a plain array")
+	assert.Less(t, rbs.MemoryUsage(), uint64(1024), "A sparse bit set should use little memory")
+}
+
+func TestRoaringBitSet_Containers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rbs := NewRoaringBitSet()
+	// A single full chunk becomes a bitmap container, and then takes as much memory as a plain array.
+	positions := make([]uint64, 0, 1<<16)
+	for i := uint64(0); i < 1<<16; i += 2 {
+		positions = append(positions, 1<<16+i)
+	}
+	assert.NoError(t, rbs.Set(ctx, positions[:roaringMaxArray]))
+	assert.NotNil(t, rbs.containers[0].array)
+	assert.NoError(t, rbs.Set(ctx, positions[roaringMaxArray:roaringMaxArray+1]))
+	assert.Nil(t, rbs.containers[0].array, "Large containers should become bitmaps")
+	assert.NotNil(t, rbs.containers[0].bitmap)
+	assert.NoError(t, rbs.Set(ctx, positions))
+	b, _ := rbs.Test(ctx, positions)
+	assert.True(t, b)
+	b, _ = rbs.Test(ctx, []uint64{1<<16 + 1})
+	assert.False(t, b)
+	assert.Equal(t, uint64(len(positions)), rbs.Count())
+}
+
+func TestRoaringBitSet_Densify(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const m = 1 << 20
+	rbs := NewRoaringBitSet()
+	model := make(map[uint64]bool)
+	r := rand.New(rand.NewSource(1))
+	for !rbs.Dense() {
+		positions := []uint64{uint64(r.Int63n(m)), uint64(r.Int63n(m))}
+		for _, pos := range positions {
+			model[pos] = true
+		}
+		assert.NoError(t, rbs.Set(ctx, positions))
+	}
+	assert.LessOrEqual(t, rbs.MemoryUsage(), uint64(m/8), "The plain array should be sized for the largest position")
+	assert.Less(t, len(model), m/8, "The bit set should switch while the filter is sparse")
+	for i := 0; i < 1000; i++ {
+		positions := []uint64{uint64(r.Int63n(2 * m))}
+		model[positions[0]] = true
+		assert.NoError(t, rbs.Set(ctx, positions))
+	}
+	assert.Equal(t, uint64(len(model)), rbs.Count())
+	for pos := uint64(0); pos < 2*m; pos++ {
+		b, _ := rbs.Test(ctx, []uint64{pos})
+		if b != model[pos] {
+			assert.Equal(t, model[pos], b, "position %d", pos)
+			break
+		}
+	}
+}
+
+func TestRoaringBitSet_BloomFilter(t *testing.T) {
+	t.Parallel()
+	rbs := NewRoaringBitSet()
+	bf, err := NewWithOptions(1_000_000_000, 0.01, WithBitSetBackend(rbs))
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				assert.NoError(t, bf.Add([]byte(strconv.Itoa(w)+"-"+strconv.Itoa(i))))
+			}
+		}(w)
+	}
+	wg.Wait()
+	for w := 0; w < 4; w++ {
+		for i := 0; i < 250; i++ {
+			b, _ := bf.Test([]byte(strconv.Itoa(w) + "-" + strconv.Itoa(i)))
+			assert.True(t, b)
+		}
+	}
+	b, _ := bf.Test([]byte("missing"))
+	assert.False(t, b)
+	// A billion item filter takes over a gigabyte of bits, but only its set bits are stored.
+	assert.False(t, rbs.Dense())
+	assert.Less(t, rbs.MemoryUsage(), uint64(1<<20))
+}
@@ -0,0 +1,127 @@
+package gobloom
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ Interface = (*RotatingBloomFilter)(nil)
+
+// RotatingBloomFilter is a sliding-window filter made of several Bloom filters, each covering a
+// fixed time slice. Items are added to the newest filter and tested against all of them. On every
+// interval a fresh filter is rotated in and the oldest one is dropped, so items are forgotten
+// between (Filters-1)*Interval and Filters*Interval after they were added.
+type RotatingBloomFilter struct {
+	filters atomic.Pointer[[]*BloomFilter] // The filters, from the newest to the oldest
+	params  Params                         // The parameters used to create new filters
+	rotate  sync.Mutex                     // Serializes rotations
+	stop    chan struct{}                  // Closed to stop the rotation goroutine
+	once    sync.Once                      // Ensures stop is closed only once
+}
+
+// ParamsRotating represents the parameters for creating a new rotating Bloom filter.
+type ParamsRotating struct {
+	// N is the number of elements expected to be added during each interval.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate of each filter.
+	// Since items are tested against all filters, the overall rate is up to Filters times higher.
+	FalsePositiveRate float64
+	// Filters is the number of filters in the window, must be at least 2.
+	Filters uint64
+	// Interval is the time slice covered by each filter.
+	Interval time.Duration
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use for each filter. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewRotating creates a new rotating Bloom filter and starts rotating it on every interval.
+// Close must be called to stop the rotation once the filter is no longer used.
+func NewRotating(p ParamsRotating) (*RotatingBloomFilter, error) {
+	if p.Filters < 2 {
+		return nil, fmt.Errorf("invalid number of filters, must be at least 2, got %d", p.Filters)
+	}
+	if p.Interval <= 0 {
+		return nil, fmt.Errorf("invalid interval, must be greater than 0, got %s", p.Interval)
+	}
+	rbf := &RotatingBloomFilter{
+		params: Params{
+			N:                 p.N,
+			FalsePositiveRate: p.FalsePositiveRate,
+			Hasher:            p.Hasher,
+			LockType:          p.LockType,
+		},
+		stop: make(chan struct{}),
+	}
+	filters := make([]*BloomFilter, p.Filters)
+	for i := range filters {
+		bf, err := New(rbf.params)
+		if err != nil {
+			return nil, err
+		}
+		filters[i] = bf
+	}
+	rbf.filters.Store(&filters)
+
+	go rbf.run(p.Interval)
+	return rbf, nil
+}
+
+// run rotates the filter on every interval, until Close is called.
+func (rbf *RotatingBloomFilter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// The parameters were validated on creation, so creating a new filter cannot fail.
+			_ = rbf.Rotate()
+		case <-rbf.stop:
+			return
+		}
+	}
+}
+
+// Rotate atomically replaces the oldest filter with a fresh one.
+// It is called automatically on every interval, but can also be called manually.
+func (rbf *RotatingBloomFilter) Rotate() error {
+	bf, err := New(rbf.params)
+	if err != nil {
+		return err
+	}
+	rbf.rotate.Lock()
+	defer rbf.rotate.Unlock()
+	old := *rbf.filters.Load()
+	filters := make([]*BloomFilter, len(old))
+	filters[0] = bf
+	copy(filters[1:], old[:len(old)-1])
+	rbf.filters.Store(&filters)
+	return nil
+}
+
+// Close stops the rotation of the filter. The filter can still be used, but it no longer rotates.
+func (rbf *RotatingBloomFilter) Close() {
+	rbf.once.Do(func() { close(rbf.stop) })
+}
+
+// Add adds an item to the newest filter.
+func (rbf *RotatingBloomFilter) Add(data []byte) error {
+	return (*rbf.filters.Load())[0].Add(data)
+}
+
+// Test checks if an item is in any of the filters.
+func (rbf *RotatingBloomFilter) Test(data []byte) (bool, error) {
+	for _, bf := range *rbf.filters.Load() {
+		b, err := bf.Test(data)
+		if err != nil {
+			return false, err
+		}
+		if b {
+			return true, nil
+		}
+	}
+	return false, nil
+}
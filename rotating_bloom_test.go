@@ -0,0 +1,54 @@
+package gobloom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingBloomFilter_Rotate(t *testing.T) {
+	t.Parallel()
+	rbf, err := NewRotating(ParamsRotating{N: 1000, FalsePositiveRate: 0.01, Filters: 3, Interval: time.Hour})
+	assert.NoError(t, err, "Failed to create rotating Bloom filter")
+	defer rbf.Close()
+
+	item := []byte("test-item")
+	assert.NoError(t, rbf.Add(item))
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, rbf.Rotate())
+		b, err := rbf.Test(item)
+		assert.NoError(t, err)
+		assert.True(t, b, "Item should be present after %d rotations", i+1)
+	}
+
+	assert.NoError(t, rbf.Rotate())
+	b, err := rbf.Test(item)
+	assert.NoError(t, err)
+	assert.False(t, b, "Item should have expired")
+}
+
+func TestRotatingBloomFilter_Ticker(t *testing.T) {
+	t.Parallel()
+	rbf, err := NewRotating(ParamsRotating{N: 1000, FalsePositiveRate: 0.01, Filters: 2, Interval: 10 * time.Millisecond})
+	assert.NoError(t, err, "Failed to create rotating Bloom filter")
+	defer rbf.Close()
+
+	item := []byte("test-item")
+	assert.NoError(t, rbf.Add(item))
+	assert.Eventually(t, func() bool {
+		b, err := rbf.Test(item)
+		return err == nil && !b
+	}, time.Second, 5*time.Millisecond, "Item should expire after the filters rotate")
+}
+
+func TestNewRotating_InvalidParams(t *testing.T) {
+	t.Parallel()
+	_, err := NewRotating(ParamsRotating{N: 1000, FalsePositiveRate: 0.01, Filters: 1, Interval: time.Second})
+	assert.Error(t, err)
+	_, err = NewRotating(ParamsRotating{N: 1000, FalsePositiveRate: 0.01, Filters: 2})
+	assert.Error(t, err)
+	_, err = NewRotating(ParamsRotating{FalsePositiveRate: 0.01, Filters: 2, Interval: time.Second})
+	assert.Error(t, err)
+}
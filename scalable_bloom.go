@@ -4,19 +4,45 @@ import (
 	"errors"
 	"fmt"
 	"math" // Used for calculations needed by the Bloom filter
-	// Used for arbitrary-precision arithmetic for the bit set
+	"sync/atomic"
 )
 
-var _ Interface = (*ScalableBloomFilter)(nil)
+var (
+	_ Interface  = (*ScalableBloomFilter)(nil)
+	_ Serializer = (*ScalableBloomFilter)(nil)
+)
 
 // ScalableBloomFilter combines multiple BloomFilter slices to adapt to a growing number of elements.
 type ScalableBloomFilter struct {
-	filters  []*BloomFilter // A slice of BloomFilter pointers, representing each layer of the scalable filter
-	n        uint64         // The number of items that have been added
-	fpRate   float64        // The false positive rate for the current filter slice
-	fpGrowth float64        // Factor by which the false positive probability should increase for each additional filter slice
+	// The layers of the scalable filter, from the oldest to the newest. The slice is never modified,
+	// it is replaced by a copy when a layer is added, so Test reads it without locking.
+	layers   atomic.Pointer[[]*BloomFilter]
+	n        uint64  // The number of items that have been added
+	fpRate   float64 // The false positive rate for the current filter slice
+	fpGrowth float64 // Factor by which the false positive probability should increase for each additional filter slice
+
+	maxItems    uint64  // The expected maximum number of items, 0 to size new layers off the number of items added
+	layerGrowth float64 // Factor by which the capacity of each additional filter slice grows, when maxItems is set
+
+	lockType LockType // The lock type of the filter slices
+	mutex    Mutex    // Mutex guarding the filter slices and the number of items
+	observer Observer // The observer of the operations, nil if disabled
+
+	events atomic.Pointer[chan ScaleEvent] // The channel of the scale events, nil until ScaleEvents is called
 }
 
+// ScaleEvent describes the addition of a filter slice to a scalable Bloom filter.
+type ScaleEvent struct {
+	Layer             int     // The index of the new filter slice, from 0 for the oldest one
+	M                 uint64  // The number of bits of the new filter slice
+	K                 uint64  // The number of hash functions of the new filter slice
+	FalsePositiveRate float64 // The false positive rate the new filter slice was sized for
+	Items             uint64  // The number of items added to the scalable filter when the slice was added
+}
+
+// scaleEventsBuffer is the capacity of the channel returned by ScaleEvents.
+const scaleEventsBuffer = 64
+
 // ParamsScalable represents the parameters for creating a new scalable Bloom filter.
 type ParamsScalable struct {
 	// InitialSize is the estimated number of elements you expect to store in the bloom filter initially.
@@ -37,12 +63,29 @@ type ParamsScalable struct {
 	FalsePositiveGrowth float64
 	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
 	Hasher Hasher
-	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	// LockType is the lock type to use, for the scalable filter and its filter slices. Defaults to ExclusiveLock.
 	// The use of ReadWriteLock can improve performance when there are many concurrent reads.
 	// If you have much more writes, avoid using ReadWriteLock, cause it may lead to reader starvation.
+	// LockTypeAtomic is not supported.
 	LockType LockType
+	// MaxExpectedItems is a hint of the maximum number of items expected to be added. When set, each new
+	// filter slice is sized at LayerGrowth times the capacity of the previous one, without exceeding
+	// the items left to reach MaxExpectedItems, so fewer slices are needed and Test checks fewer slices.
+	// When not set, new filter slices are sized for the number of items added so far.
+	MaxExpectedItems uint64
+	// LayerGrowth is the factor by which the capacity of each new filter slice grows when MaxExpectedItems
+	// is set. Defaults to 2, and must be at least 1.
+	LayerGrowth float64
+	// Observer, if set, receives the operations of the filter, including the addition of filter slices.
+	Observer Observer
+	// Hooks are called with the operations of the filter, after the Observer. OnSaturation is never called,
+	// since the filter adds a slice instead, and SaturationThreshold is ignored.
+	Hooks Hooks
 }
 
+// defaultLayerGrowth is the default growth of the capacity of the filter slices, when MaxExpectedItems is set.
+const defaultLayerGrowth = 2
+
 // NewScalable creates a new scalable Bloom filter.
 // Best Practices:
 //
@@ -74,7 +117,14 @@ func NewScalable(p ParamsScalable) (*ScalableBloomFilter, error) {
 	if p.FalsePositiveGrowth <= 0 {
 		return nil, fmt.Errorf("invalid false positive growth rate, must be greater than 0, got %f", p.FalsePositiveGrowth)
 	}
+	if p.LayerGrowth < 1 {
+		return nil, fmt.Errorf("invalid layer growth, must be at least 1, got %f", p.LayerGrowth)
+	}
 
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
 	bf, err := New(Params{
 		N:                 p.InitialSize,
 		FalsePositiveRate: p.FalsePositiveRate,
@@ -86,12 +136,25 @@ func NewScalable(p ParamsScalable) (*ScalableBloomFilter, error) {
 	}
 
 	// Return a new scalable Bloom filter struct with the initialized slice and parameters.
-	return &ScalableBloomFilter{
-		filters:  []*BloomFilter{bf},    // Start with one filter slice
+	sbf := &ScalableBloomFilter{
 		fpRate:   p.FalsePositiveRate,   // Set the initial false positive rate
 		fpGrowth: p.FalsePositiveGrowth, // Set the growth rate for false positives as the filter scales
 		n:        0,                     // Initialize with zero elements added
-	}, nil
+
+		maxItems:    p.MaxExpectedItems,
+		layerGrowth: p.LayerGrowth,
+
+		lockType: p.LockType,
+		mutex:    mu,
+		observer: p.Hooks.observer(p.Observer, nil),
+	}
+	sbf.layers.Store(&[]*BloomFilter{bf}) // Start with one filter slice
+	return sbf, nil
+}
+
+// filters returns the current layers of the scalable filter, which must not be modified.
+func (sbf *ScalableBloomFilter) filters() []*BloomFilter {
+	return *sbf.layers.Load()
 }
 
 // applyDefaultsScalable applies the default values to the parameters if they are not set.
@@ -102,69 +165,193 @@ func applyDefaultsScalable(p *ParamsScalable) {
 	if p.LockType == LockTypeDefault {
 		p.LockType = LockTypeExclusive
 	}
+	if p.LayerGrowth == 0 {
+		p.LayerGrowth = defaultLayerGrowth
+	}
 }
 
 // Add inserts the given item into the scalable Bloom filter.
+// The item is added to the newest filter slice only, the older ones being full, and is hashed once.
 // If the current filter slice exceeds its capacity based on the growth rate, a new slice is added.
 func (sbf *ScalableBloomFilter) Add(data []byte) error {
-	// Add the item to all existing filter slices.
-	for _, filter := range sbf.filters {
-		for i := uint64(0); i < filter.k; i++ {
-			err := filter.Add(data)
-			if err != nil {
-				return err
-			}
-		}
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
 	}
+	filters := sbf.filters()
+	last := filters[len(filters)-1]
+	probes := getProbes(last.k)
+	defer probePool.Put(probes)
+	last.hash(data, *probes)
+	last.addHashes(*probes)
 
 	// Increment the total number of items added across all filter slices.
 	sbf.n++
+	if sbf.observer != nil {
+		sbf.observer.Added(1)
+	}
 
 	// Check the last filter's capacity, and if needed, add a new filter slice.
-	// The current implementation may be only adding new filters and not previous ones.
-	// We need to base the condition on the properties of the last filter and the current count of added items.
-	currentFilter := sbf.filters[len(sbf.filters)-1] // Get the most recent filter
-
-	// Use the correct threshold to decide when to add a new filter.
-	// This threshold should be defined by how full the current filter is.
-	currentCapacity := float64(currentFilter.m) * math.Log(sbf.fpGrowth) / math.Log(2)
-	// If the number of items exceeds the current capacity of the filter:
-	if float64(sbf.n) > currentCapacity {
-		newFpRate := sbf.fpRate * math.Pow(sbf.fpGrowth, float64(len(sbf.filters)))
+	if sbf.full() {
+		newFpRate := sbf.fpRate * math.Pow(sbf.fpGrowth, float64(len(filters)))
 		// Create and append the new filter slice.
-		nbf, _ := New(Params{N: sbf.n, FalsePositiveRate: newFpRate})
-		sbf.filters = append(sbf.filters, nbf)
+		nbf, err := New(Params{N: sbf.nextLayerSize(), FalsePositiveRate: newFpRate, Hasher: last.hasher, LockType: sbf.lockType})
+		if err != nil {
+			return err
+		}
+		// The layers are copied, as readers may be iterating over the current slice.
+		grown := make([]*BloomFilter, len(filters), len(filters)+1)
+		copy(grown, filters)
+		grown = append(grown, nbf)
+		sbf.layers.Store(&grown)
+		if sbf.observer != nil {
+			sbf.observer.Scaled(len(grown))
+		}
+		if events := sbf.events.Load(); events != nil {
+			select {
+			case *events <- ScaleEvent{Layer: len(grown) - 1, M: nbf.m, K: nbf.k, FalsePositiveRate: newFpRate, Items: sbf.n}:
+			default: // Nobody is keeping up with the events, they are dropped rather than blocking Add.
+			}
+		}
 	}
 	return nil
 }
 
+// ScaleEvents returns a channel receiving an event each time a filter slice is added, so growth can be
+// logged or alerted on. All the calls return the same channel, which has a buffer of 64 events and is
+// never closed. Add never waits for the channel, the events that don't fit in its buffer are dropped.
+func (sbf *ScalableBloomFilter) ScaleEvents() <-chan ScaleEvent {
+	if events := sbf.events.Load(); events != nil {
+		return *events
+	}
+	events := make(chan ScaleEvent, scaleEventsBuffer)
+	sbf.events.CompareAndSwap(nil, &events)
+	return *sbf.events.Load()
+}
+
+// full returns whether the last filter slice is full, and a new one must be added.
+func (sbf *ScalableBloomFilter) full() bool {
+	filters := sbf.filters()
+	currentFilter := filters[len(filters)-1] // Get the most recent filter
+	if sbf.maxItems == 0 {
+		// The threshold is based on the size of the last filter and the count of items added to all of them.
+		currentCapacity := float64(currentFilter.m) * math.Log(sbf.fpGrowth) / math.Log(2)
+		return float64(sbf.n) > currentCapacity
+	}
+	// Pre-sized slices are added when the previous ones are at capacity, so the last one holds the rest of the items.
+	var previous uint64
+	for _, filter := range filters[:len(filters)-1] {
+		previous += filter.Capacity()
+	}
+	return sbf.n-min(previous, sbf.n) > currentFilter.Capacity()
+}
+
+// nextLayerSize returns the number of items the next filter slice is sized for.
+func (sbf *ScalableBloomFilter) nextLayerSize() uint64 {
+	if sbf.maxItems == 0 {
+		return sbf.n
+	}
+	filters := sbf.filters()
+	var capacity uint64
+	for _, filter := range filters {
+		capacity += filter.Capacity()
+	}
+	prev := filters[len(filters)-1].Capacity()
+	size := uint64(math.Ceil(float64(prev) * sbf.layerGrowth))
+	// The last slice only needs to hold the items left to reach the expected maximum, unless it is exceeded.
+	if left := sbf.maxItems - min(capacity, sbf.maxItems); left > 0 && left < size {
+		size = max(left, prev)
+	}
+	if size == 0 {
+		size = 1
+	}
+	return size
+}
+
+// Stats returns a snapshot of the statistics of the scalable Bloom filter, summed over its layers.
+// K is the number of hash functions of the newest layer, EstimatedItems is the number of items added,
+// and EstimatedFalsePositiveRate is the probability that any layer reports a false positive.
+func (sbf *ScalableBloomFilter) Stats() Stats {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	var s Stats
+	fn := 1.0
+	for _, filter := range sbf.filters() {
+		fs := filter.Stats()
+		s.Bits += fs.Bits
+		s.SetBits += fs.SetBits
+		s.K = fs.K
+		s.MemoryBytes += fs.MemoryBytes
+		fn *= 1 - fs.EstimatedFalsePositiveRate
+	}
+	s.EstimatedItems = sbf.n
+	s.EstimatedFalsePositiveRate = 1 - fn
+	return s
+}
+
+// LayerStat holds the statistics of a layer of a scalable Bloom filter.
+type LayerStat struct {
+	M                 uint64  // The number of bits
+	K                 uint64  // The number of hash functions
+	FalsePositiveRate float64 // The false positive rate the layer was sized for
+	Items             uint64  // The estimated number of distinct items in the layer
+	FillRatio         float64 // The fraction of set bits
+}
+
+// LayerStats returns the statistics of each layer, from the oldest to the newest,
+// to see when and why the filter grew.
+func (sbf *ScalableBloomFilter) LayerStats() []LayerStat {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	filters := sbf.filters()
+	stats := make([]LayerStat, len(filters))
+	for i, filter := range filters {
+		x := filter.setBits()
+		stats[i] = LayerStat{
+			M:                 filter.m,
+			K:                 filter.k,
+			FalsePositiveRate: filter.fpRate,
+			Items:             estimateCount(x, filter.m, filter.k),
+			FillRatio:         float64(x) / float64(filter.m),
+		}
+	}
+	return stats
+}
+
+// Test checks if an item is in any filter slice of the scalable Bloom filter.
+// Slices sharing the same hasher share the hashes of the item, so it is hashed once in most cases.
+// It takes no lock at the scalable level, only the locks of the filter slices.
 func (sbf *ScalableBloomFilter) Test(data []byte) (bool, error) {
+	filters := sbf.filters()
+	k := uint64(0)
+	for _, filter := range filters {
+		k = max(k, filter.k)
+	}
+	probes := getProbes(k)
+	defer probePool.Put(probes)
+
 	// Check the item against all filter slices from the oldest to the newest.
-	for _, filter := range sbf.filters {
-		// Assume the item is in the filter until proven otherwise.
-		isPresent := true
-
-		// Use the same hash functions that were used to add the item.
-		for i := uint64(0); i < filter.k; i++ {
-			// If any of the bits corresponding to the item's hash values are not set, it's definitely not present in this filter.
-			b, err := filter.Test(data)
-			if err != nil {
-				return false, err
-			}
-			if !b {
-				isPresent = false
-				// We break out of the hash function loop as soon as we find a bit that is not set.
-				break
-			}
+	var hasher Hasher64
+	for _, filter := range filters {
+		// The i-th hash doesn't depend on the number of hashes, so the hashes are only computed again
+		// for slices using another hasher.
+		if !sameHasher64(filter.hasher64, hasher) {
+			hasher = filter.hasher64
+			filter.hash(data, *probes)
 		}
-
 		// If all the bits for this filter are set, then the item is potentially present (with some false positive rate).
-		if isPresent {
+		if filter.testHashes((*probes)[:filter.k]) {
+			observeTest(sbf.observer, true)
 			return true, nil
 		}
 		// Otherwise, continue checking the next filter to see if the item may be present there.
 	}
 
 	// If none of the filters had all bits set, the item is definitely not in the set.
+	observeTest(sbf.observer, false)
 	return false, nil
 }
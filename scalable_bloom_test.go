@@ -1,8 +1,12 @@
 package gobloom
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,12 +60,217 @@ func TestScalableBloomFilter_Scalability(t *testing.T) {
 	sbf, err := NewScalable(ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
 	assert.NoError(t, err, "Error initializing scalable Bloom filter")
 
-	initialNumFilters := len(sbf.filters)
+	initialNumFilters := len(sbf.filters())
 
 	// Add more elements to trigger scaling
 	for i := 0; i < 10000; i++ {
 		sbf.Add([]byte(strconv.Itoa(rand.Int())))
 	}
 
-	assert.NotEqual(t, len(sbf.filters), initialNumFilters, "Expected scalable Bloom filter to grow, but it didn't")
+	assert.NotEqual(t, len(sbf.filters()), initialNumFilters, "Expected scalable Bloom filter to grow, but it didn't")
+}
+
+func TestScalableBloomFilter_Stats(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Error initializing scalable Bloom filter")
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, sbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	s := sbf.Stats()
+	assert.Equal(t, uint64(100), s.EstimatedItems)
+	var bits, memory uint64
+	for _, filter := range sbf.filters() {
+		bits += filter.M()
+		memory += 8 * uint64(len(filter.UnsafeBits()))
+	}
+	assert.Equal(t, bits, s.Bits)
+	assert.Equal(t, memory, s.MemoryBytes)
+	assert.Greater(t, s.EstimatedFalsePositiveRate, 0.0)
+	assert.Less(t, s.EstimatedFalsePositiveRate, 0.01)
+}
+
+func TestScalableBloomFilter_LayerStats(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Error initializing scalable Bloom filter")
+	stats := sbf.LayerStats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, LayerStat{M: 959, K: 7, FalsePositiveRate: 0.01}, stats[0])
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, sbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	stats = sbf.LayerStats()
+	assert.Len(t, stats, len(sbf.filters()))
+	assert.Greater(t, len(stats), 1, "Filter should have grown")
+	for i, s := range stats {
+		assert.Equal(t, sbf.filters()[i].M(), s.M)
+		assert.Equal(t, sbf.filters()[i].K(), s.K)
+		assert.Greater(t, s.Items, uint64(0))
+		assert.Greater(t, s.FillRatio, 0.0)
+		if i > 0 {
+			assert.Greater(t, s.FalsePositiveRate, stats[i-1].FalsePositiveRate, "Each layer should tolerate a higher rate")
+		}
+	}
+}
+
+func TestScalableBloomFilter_MaxExpectedItems(t *testing.T) {
+	t.Parallel()
+	n := 20000
+	grow := func(p ParamsScalable) *ScalableBloomFilter {
+		sbf, err := NewScalable(p)
+		assert.NoError(t, err, "Error initializing scalable Bloom filter")
+		for i := 0; i < n; i++ {
+			assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+		}
+		for i := 0; i < n; i++ {
+			b, err := sbf.Test([]byte(strconv.Itoa(i)))
+			assert.NoError(t, err)
+			assert.True(t, b, "Item %d should be present", i)
+		}
+		return sbf
+	}
+	hinted := grow(ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2, MaxExpectedItems: uint64(n), LayerGrowth: 4})
+
+	// The layers are sized for 1000, 4000, and the 15000 items left.
+	assert.Len(t, hinted.filters(), 3)
+	for i, capacity := range []uint64{1000, 4000, 15000} {
+		assert.InDelta(t, capacity, hinted.filters()[i].Capacity(), float64(capacity)/50, "Layer %d should be pre-sized", i)
+	}
+
+	data, err := hinted.MarshalBinary()
+	assert.NoError(t, err)
+	var restored ScalableBloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, uint64(n), restored.maxItems)
+	assert.Equal(t, 4.0, restored.layerGrowth)
+
+	_, err = NewScalable(ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2, LayerGrowth: 0.5})
+	assert.Error(t, err, "Shrinking layers should be rejected")
+}
+
+func TestScalableBloomFilter_ScaleEvents(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 1000, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2,
+		MaxExpectedItems: 20000, LayerGrowth: 4})
+	assert.NoError(t, err)
+	events := sbf.ScaleEvents()
+	assert.Equal(t, events, sbf.ScaleEvents(), "The same channel should be returned")
+	for i := 0; i < 20000; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	filters := sbf.filters()
+	assert.Len(t, events, len(filters)-1, "Each new layer should be reported")
+	for i := 1; i < len(filters); i++ {
+		e := <-events
+		assert.Equal(t, i, e.Layer)
+		assert.Equal(t, filters[i].M(), e.M)
+		assert.Equal(t, filters[i].K(), e.K)
+		assert.InDelta(t, 0.01*math.Pow(2, float64(i)), e.FalsePositiveRate, 1e-12)
+		assert.Greater(t, e.Items, filters[i-1].Capacity()-1, "The layer should be added once the previous one is full")
+	}
+
+	// Events are dropped rather than blocking Add when nobody receives them.
+	sbf, err = NewScalable(ParamsScalable{InitialSize: 1, FalsePositiveRate: 0.01, FalsePositiveGrowth: 1.001,
+		MaxExpectedItems: 1, LayerGrowth: 1})
+	assert.NoError(t, err)
+	events = sbf.ScaleEvents()
+	for i := 0; i < 100000 && len(sbf.filters()) <= scaleEventsBuffer+1; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	assert.Greater(t, len(sbf.filters()), scaleEventsBuffer+1)
+	assert.Len(t, events, scaleEventsBuffer)
+}
+
+// countingHasher64 is a MurMur3Hasher counting how many times items are hashed.
+type countingHasher64 struct {
+	*MurMur3Hasher
+	calls atomic.Int64
+}
+
+func (h *countingHasher64) HashK(data []byte, out []uint64) {
+	h.calls.Add(1)
+	h.MurMur3Hasher.HashK(data, out)
+}
+
+func TestScalableBloomFilter_HashOnce(t *testing.T) {
+	t.Parallel()
+	hasher := &countingHasher64{MurMur3Hasher: NewMurMur3Hasher()}
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2, Hasher: hasher})
+	assert.NoError(t, err, "Error initializing scalable Bloom filter")
+	n := 5000
+	for i := 0; i < n; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	assert.Greater(t, len(sbf.filters()), 1, "Filter should have grown")
+	assert.Equal(t, int64(n), hasher.calls.Load(), "Each item should be hashed once when added")
+
+	// Only the newest layer is written, once per item.
+	oldest := sbf.filters()[0].Bits()
+	assert.NoError(t, sbf.Add([]byte("new-item")))
+	assert.Equal(t, oldest, sbf.filters()[0].Bits(), "Full layers should not be written to")
+
+	hasher.calls.Store(0)
+	for i := 0; i < n; i++ {
+		b, err := sbf.Test([]byte(strconv.Itoa(i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item %d should be present", i)
+		_, err = sbf.Test([]byte(fmt.Sprintf("missing-%d", i)))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int64(2*n), hasher.calls.Load(), "Each item should be hashed once when tested, whatever the number of layers")
+}
+
+func TestScalableBloomFilter_Concurrent(t *testing.T) {
+	t.Parallel()
+	for _, lockType := range []LockType{LockTypeExclusive, LockTypeReadWrite, LockTypeStriped} {
+		sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2, LockType: lockType})
+		assert.NoError(t, err, "Error initializing scalable Bloom filter")
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					item := []byte(fmt.Sprintf("%d-%d", g, i))
+					assert.NoError(t, sbf.Add(item))
+					b, err := sbf.Test(item)
+					assert.NoError(t, err)
+					assert.True(t, b, "Item should be present after being added")
+					_ = sbf.Stats()
+				}
+			}(g)
+		}
+		wg.Wait()
+		assert.Equal(t, uint64(4000), sbf.n, "Every item should be counted with lock type %d", lockType)
+		assert.Greater(t, len(sbf.filters()), 1, "Filter should have grown")
+	}
+
+	_, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2, LockType: LockTypeAtomic})
+	assert.ErrorIs(t, err, ErrInvalidLockType)
+}
+
+func TestScalableBloomFilter_TestWithoutLock(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 10, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Error initializing scalable Bloom filter")
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	layers := sbf.filters()
+
+	// Test doesn't wait for the scalable lock, held here as by a writer adding a layer.
+	sbf.mutex.WLock()
+	b, err := sbf.Test([]byte("42"))
+	sbf.mutex.WUnlock()
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present")
+
+	for i := 100; i < 1000; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	assert.Greater(t, len(sbf.filters()), len(layers), "Filter should have grown")
+	assert.Equal(t, layers, sbf.filters()[:len(layers)], "Growing should not modify the previous layer list")
 }
@@ -0,0 +1,73 @@
+package gobloom
+
+// Iterator yields the items of a data source, like the keys of a database table.
+type Iterator interface {
+	// Next returns the next item, and false once there are no more items or the iteration failed.
+	Next() ([]byte, bool)
+	// Err returns the error that stopped the iteration, nil if all the items were returned.
+	Err() error
+}
+
+// SliceIterator returns an Iterator over the items.
+func SliceIterator(items [][]byte) Iterator {
+	return &sliceIterator{items: items}
+}
+
+// sliceIterator is an Iterator over a slice of items.
+type sliceIterator struct {
+	items [][]byte
+}
+
+func (it *sliceIterator) Next() ([]byte, bool) {
+	if len(it.items) == 0 {
+		return nil, false
+	}
+	item := it.items[0]
+	it.items = it.items[1:]
+	return item, true
+}
+
+func (it *sliceIterator) Err() error {
+	return nil
+}
+
+// Compact rebuilds the layers of the scalable Bloom filter into a single layer sized for the number of
+// items added, at the false positive rate of the first layer, restoring the Test latency and false positive
+// rate after long periods of growth. Bloom filters don't keep their items, so they are read from source,
+// which must yield every item added to the filter. If it yields more items than were added, more layers
+// are added as needed. The new layers use the hasher of the newest layer.
+//
+// Add waits for the compaction, while Test keeps using the old layers until the new ones replace them.
+// If the source fails, the filter is left unchanged and its error is returned.
+func (sbf *ScalableBloomFilter) Compact(source Iterator) error {
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
+	}
+	filters := sbf.filters()
+	compacted, err := NewScalable(ParamsScalable{
+		InitialSize:         max(sbf.n, 1),
+		FalsePositiveRate:   sbf.fpRate,
+		FalsePositiveGrowth: sbf.fpGrowth,
+		Hasher:              filters[len(filters)-1].hasher,
+		LockType:            sbf.lockType,
+		MaxExpectedItems:    sbf.maxItems,
+		LayerGrowth:         sbf.layerGrowth,
+	})
+	if err != nil {
+		return err
+	}
+	// The temporary filter is not shared, so it needs no lock.
+	compacted.mutex = nil
+	for item, ok := source.Next(); ok; item, ok = source.Next() {
+		if err := compacted.Add(item); err != nil {
+			return err
+		}
+	}
+	if err := source.Err(); err != nil {
+		return err
+	}
+	sbf.layers.Store(compacted.layers.Load())
+	sbf.n = compacted.n
+	return nil
+}
@@ -0,0 +1,78 @@
+package gobloom
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScalableBloomFilter_Compact(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 1.5})
+	assert.NoError(t, err)
+	items := make([][]byte, 10000)
+	for i := range items {
+		items[i] = []byte(strconv.Itoa(i))
+		assert.NoError(t, sbf.Add(items[i]))
+	}
+	assert.Greater(t, len(sbf.filters()), 1)
+	before := sbf.Stats().EstimatedFalsePositiveRate
+
+	assert.NoError(t, sbf.Compact(SliceIterator(items)))
+	assert.Len(t, sbf.filters(), 1, "The layers should be compacted into one")
+	assert.Equal(t, uint64(len(items)), sbf.n)
+	assert.GreaterOrEqual(t, sbf.filters()[0].Capacity(), uint64(len(items)))
+	assert.Less(t, sbf.Stats().EstimatedFalsePositiveRate, before, "Compaction should lower the false positive rate")
+	for _, item := range items {
+		b, _ := sbf.Test(item)
+		assert.True(t, b, "Compacted filter should contain the items")
+	}
+
+	// The filter keeps growing after compaction.
+	for i := 0; i < 100000; i++ {
+		assert.NoError(t, sbf.Add([]byte("more-"+strconv.Itoa(i))))
+	}
+	assert.Greater(t, len(sbf.filters()), 1)
+}
+
+func TestScalableBloomFilter_CompactMoreItems(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err)
+	items := make([][]byte, 5000)
+	for i := range items {
+		items[i] = []byte(strconv.Itoa(i))
+	}
+	// The source holds more items than were added, so more layers are needed.
+	assert.NoError(t, sbf.Compact(SliceIterator(items)))
+	assert.Greater(t, len(sbf.filters()), 1)
+	for _, item := range items {
+		b, _ := sbf.Test(item)
+		assert.True(t, b)
+	}
+}
+
+// failingIterator yields some items, then fails.
+type failingIterator struct {
+	Iterator
+}
+
+func (it *failingIterator) Err() error {
+	return errors.New("source failed")
+}
+
+func TestScalableBloomFilter_CompactError(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 10, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+	layers := sbf.filters()
+	err = sbf.Compact(&failingIterator{SliceIterator([][]byte{[]byte("0")})})
+	assert.EqualError(t, err, "source failed")
+	assert.Equal(t, layers, sbf.filters(), "The filter should be unchanged")
+	assert.Equal(t, uint64(100), sbf.n)
+}
@@ -0,0 +1,102 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"text/tabwriter"
+)
+
+// Description is a diagnostic report of a scalable Bloom filter, with its parameters and the parameters,
+// sizes, and estimated error of each layer, for ops tooling and support tickets. It can be encoded as JSON,
+// and String formats it as text.
+type Description struct {
+	Items                      uint64             `json:"items"`                         // The number of items added
+	FalsePositiveRate          float64            `json:"false_positive_rate"`           // The false positive rate of the first layer
+	FalsePositiveGrowth        float64            `json:"false_positive_growth"`         // The growth of the rate of each new layer
+	MaxExpectedItems           uint64             `json:"max_expected_items,omitempty"`  // The expected maximum number of items, 0 if not set
+	LayerGrowth                float64            `json:"layer_growth,omitempty"`        // The growth of the capacity of each new layer, when MaxExpectedItems is set
+	Bits                       uint64             `json:"bits"`                          // The number of bits of all the layers
+	MemoryBytes                uint64             `json:"memory_bytes"`                  // The size of the bit sets of all the layers in bytes
+	EstimatedFalsePositiveRate float64            `json:"estimated_false_positive_rate"` // The probability that any layer reports a false positive
+	Layers                     []LayerDescription `json:"layers"`                        // The layers, from the oldest to the newest
+}
+
+// LayerDescription describes a layer of a scalable Bloom filter.
+type LayerDescription struct {
+	M                          uint64  `json:"m"`                             // The number of bits
+	K                          uint64  `json:"k"`                             // The number of hash functions
+	Hasher                     string  `json:"hasher"`                        // The name of the hasher, or its type if it has none
+	MemoryBytes                uint64  `json:"memory_bytes"`                  // The size of the bit set in bytes
+	Capacity                   uint64  `json:"capacity"`                      // The number of items the layer holds at its false positive rate
+	FalsePositiveRate          float64 `json:"false_positive_rate"`           // The false positive rate the layer was sized for
+	SetBits                    uint64  `json:"set_bits"`                      // The number of set bits
+	FillRatio                  float64 `json:"fill_ratio"`                    // The fraction of set bits
+	EstimatedItems             uint64  `json:"estimated_items"`               // The estimated number of distinct items in the layer
+	EstimatedFalsePositiveRate float64 `json:"estimated_false_positive_rate"` // The current false positive rate estimated from the fill ratio
+}
+
+// Describe returns a diagnostic report of the scalable Bloom filter and of each of its layers.
+func (sbf *ScalableBloomFilter) Describe() Description {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	d := Description{
+		Items:               sbf.n,
+		FalsePositiveRate:   sbf.fpRate,
+		FalsePositiveGrowth: sbf.fpGrowth,
+		MaxExpectedItems:    sbf.maxItems,
+	}
+	if sbf.maxItems > 0 {
+		d.LayerGrowth = sbf.layerGrowth
+	}
+	fn := 1.0
+	for _, filter := range sbf.filters() {
+		s := filter.Stats()
+		layer := LayerDescription{
+			M:                          s.Bits,
+			K:                          s.K,
+			Hasher:                     fmt.Sprintf("%T", filter.hasher),
+			MemoryBytes:                s.MemoryBytes,
+			Capacity:                   filter.Capacity(),
+			FalsePositiveRate:          filter.fpRate,
+			SetBits:                    s.SetBits,
+			FillRatio:                  float64(s.SetBits) / float64(s.Bits),
+			EstimatedItems:             s.EstimatedItems,
+			EstimatedFalsePositiveRate: s.EstimatedFalsePositiveRate,
+		}
+		if name, _, err := marshalHasher(filter.hasher); err == nil {
+			layer.Hasher = name
+		}
+		d.Layers = append(d.Layers, layer)
+		d.Bits += s.Bits
+		d.MemoryBytes += s.MemoryBytes
+		fn *= 1 - s.EstimatedFalsePositiveRate
+	}
+	d.EstimatedFalsePositiveRate = 1 - fn
+	return d
+}
+
+// String formats the report as a summary line followed by a table of the layers.
+func (d Description) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "scalable bloom filter: items=%d layers=%d bits=%d memory=%dB fp~%.4g (target %.4g, growth %g",
+		d.Items, len(d.Layers), d.Bits, d.MemoryBytes, d.EstimatedFalsePositiveRate, d.FalsePositiveRate, d.FalsePositiveGrowth)
+	if d.MaxExpectedItems > 0 {
+		fmt.Fprintf(&b, ", max items %d, layer growth %g", d.MaxExpectedItems, d.LayerGrowth)
+	}
+	b.WriteString(")\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "layer\tm\tk\tmemory\tcapacity\titems~\tfill\tfp target\tfp~\thasher\t")
+	for i, l := range d.Layers {
+		items := fmt.Sprint(l.EstimatedItems)
+		if l.EstimatedItems == math.MaxUint64 {
+			items = "full"
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d\t%dB\t%d\t%s\t%.1f%%\t%.4g\t%.4g\t%s\t\n",
+			i, l.M, l.K, l.MemoryBytes, l.Capacity, items, 100*l.FillRatio, l.FalsePositiveRate, l.EstimatedFalsePositiveRate, l.Hasher)
+	}
+	_ = w.Flush()
+	return b.String()
+}
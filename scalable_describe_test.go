@@ -0,0 +1,55 @@
+package gobloom
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScalableBloomFilter_Describe(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2,
+		MaxExpectedItems: 1000, LayerGrowth: 2})
+	assert.NoError(t, err)
+	for i := 0; i < 500; i++ {
+		assert.NoError(t, sbf.Add([]byte(strconv.Itoa(i))))
+	}
+
+	d := sbf.Describe()
+	stats := sbf.Stats()
+	assert.Equal(t, uint64(500), d.Items)
+	assert.Equal(t, 0.01, d.FalsePositiveRate)
+	assert.Equal(t, 2.0, d.FalsePositiveGrowth)
+	assert.Equal(t, uint64(1000), d.MaxExpectedItems)
+	assert.Equal(t, 2.0, d.LayerGrowth)
+	assert.Equal(t, stats.Bits, d.Bits)
+	assert.Equal(t, stats.MemoryBytes, d.MemoryBytes)
+	assert.InDelta(t, stats.EstimatedFalsePositiveRate, d.EstimatedFalsePositiveRate, 1e-12)
+	assert.Len(t, d.Layers, len(sbf.filters()))
+	for i, l := range sbf.LayerStats() {
+		layer := d.Layers[i]
+		assert.Equal(t, l.M, layer.M)
+		assert.Equal(t, l.K, layer.K)
+		assert.Equal(t, l.FalsePositiveRate, layer.FalsePositiveRate)
+		assert.Equal(t, l.FillRatio, layer.FillRatio)
+		assert.Equal(t, l.Items, layer.EstimatedItems)
+		assert.Equal(t, sbf.filters()[i].Capacity(), layer.Capacity)
+		assert.Equal(t, murmur3HasherName, layer.Hasher)
+	}
+
+	text := d.String()
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	assert.Len(t, lines, 2+len(d.Layers), "The report should have a summary, a header, and a line per layer")
+	assert.Contains(t, lines[0], "items=500")
+	assert.Contains(t, lines[0], "max items 1000")
+	assert.Contains(t, lines[1], "capacity")
+
+	data, err := json.Marshal(d)
+	assert.NoError(t, err)
+	var decoded Description
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, d, decoded)
+}
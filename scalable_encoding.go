@@ -0,0 +1,153 @@
+package gobloom
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*ScalableBloomFilter)(nil)
+	_ encoding.BinaryUnmarshaler = (*ScalableBloomFilter)(nil)
+)
+
+// MarshalBinary encodes the scalable Bloom filter with the wire format, including every layer.
+// The layers must share one hasher, implementing NamedHasher.
+//
+// The parameter block holds the number of items, the false positive rate and growth, the
+// number of layers, and when MaxExpectedItems is set, the maximum number of items and the layer growth.
+// The payload holds, for each layer, its false positive rate followed by the
+// layer encoded as a Bloom filter, as a length-prefixed byte string.
+func (sbf *ScalableBloomFilter) MarshalBinary() ([]byte, error) {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, sbf.n)
+	params = binary.AppendUvarint(params, math.Float64bits(sbf.fpRate))
+	params = binary.AppendUvarint(params, math.Float64bits(sbf.fpGrowth))
+	filters := sbf.filters()
+	if err := checkHashers(filters); err != nil {
+		return nil, err
+	}
+	params = binary.AppendUvarint(params, uint64(len(filters)))
+	if sbf.maxItems > 0 {
+		params = binary.AppendUvarint(params, sbf.maxItems)
+		params = binary.AppendUvarint(params, math.Float64bits(sbf.layerGrowth))
+	}
+	var payload []byte
+	for _, bf := range filters {
+		layer, err := bf.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		payload = binary.AppendUvarint(payload, math.Float64bits(bf.fpRate))
+		payload = appendBytes(payload, layer)
+	}
+	return encodeFilter(filterTypeScalable, params, payload), nil
+}
+
+// UnmarshalBinary restores a scalable Bloom filter encoded with MarshalBinary, whose layers share one hasher.
+// The hasher must be registered with RegisterHasher, unless they are hashers provided by this package.
+// The filter and its layers use ExclusiveLock, unless it was created with another lock type.
+func (sbf *ScalableBloomFilter) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeScalable, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	n := r.uvarint()
+	fpRate := math.Float64frombits(r.uvarint())
+	fpGrowth := math.Float64frombits(r.uvarint())
+	layers := r.uvarint()
+	var maxItems uint64
+	layerGrowth := float64(defaultLayerGrowth)
+	if len(r.data) != 0 {
+		maxItems = r.uvarint()
+		layerGrowth = math.Float64frombits(r.uvarint())
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if fpRate <= 0 || fpRate >= 1 || fpGrowth <= 0 || layers == 0 || !(layerGrowth >= 1) {
+		return fmt.Errorf("%w: invalid parameters", ErrInvalidEncoding)
+	}
+
+	// Every layer takes at least two bytes, so the payload bounds the number of layers.
+	if layers > uint64(len(payload)) {
+		return fmt.Errorf("%w: payload too short for %d layers", ErrInvalidEncoding, layers)
+	}
+	filters := make([]*BloomFilter, layers)
+	r = byteReader{data: payload}
+	for i := range filters {
+		layerFpRate := math.Float64frombits(r.uvarint())
+		layer := r.bytes()
+		if r.err != nil {
+			return r.err
+		}
+		bf, err := sbf.newDecodedLayer()
+		if err != nil {
+			return err
+		}
+		if err := bf.UnmarshalBinary(layer); err != nil {
+			return fmt.Errorf("layer %d: %w", i, err)
+		}
+		bf.fpRate = layerFpRate
+		filters[i] = bf
+	}
+	if len(r.data) != 0 {
+		return fmt.Errorf("%w: unexpected data after the last layer", ErrInvalidEncoding)
+	}
+	if err := shareHasher(filters); err != nil {
+		return err
+	}
+
+	if sbf.mutex == nil && sbf.lockType == LockTypeDefault {
+		sbf.mutex = &ExclusiveMutex{}
+		sbf.lockType = LockTypeExclusive
+	}
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
+	}
+	sbf.layers.Store(&filters)
+	sbf.n = n
+	sbf.fpRate = fpRate
+	sbf.fpGrowth = fpGrowth
+	sbf.maxItems = maxItems
+	sbf.layerGrowth = layerGrowth
+	return nil
+}
+
+// checkHashers returns an error if the layers don't share one hasher. Test hashes an item once for each
+// distinct hasher, so the layers of an encoded filter must all use the hasher of the first layer.
+func checkHashers(filters []*BloomFilter) error {
+	for i, bf := range filters[1:] {
+		if !sameHasher(filters[0].hasher, bf.hasher) {
+			return fmt.Errorf("layer %d uses another hasher than the first layer", i+1)
+		}
+	}
+	return nil
+}
+
+// shareHasher makes the decoded layers share the hasher of the first layer, failing with ErrInvalidEncoding
+// if a layer uses another hasher.
+func shareHasher(filters []*BloomFilter) error {
+	if err := checkHashers(filters); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+	for _, bf := range filters[1:] {
+		bf.hasher, bf.hasher64 = filters[0].hasher, filters[0].hasher64
+	}
+	return nil
+}
+
+// newDecodedLayer returns the Bloom filter a layer is decoded into. Decoding keeps the lock type of the
+// filter, so the layers of a filter created with a lock type are decoded into filters with that lock type.
+func (sbf *ScalableBloomFilter) newDecodedLayer() (*BloomFilter, error) {
+	if sbf.lockType == LockTypeDefault {
+		return &BloomFilter{}, nil
+	}
+	return newBloomFilter(1, 1, Params{Hasher: NewMurMur3Hasher(), LockType: sbf.lockType})
+}
@@ -0,0 +1,107 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScalableBloomFilter_MarshalBinary(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Failed to create scalable Bloom filter")
+	for i := 0; i < 2000; i++ {
+		assert.NoError(t, sbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.Greater(t, len(sbf.filters()), 1, "Expected multiple layers")
+
+	data, err := sbf.MarshalBinary()
+	assert.NoError(t, err)
+
+	var restored ScalableBloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, sbf.n, restored.n)
+	assert.Equal(t, sbf.fpRate, restored.fpRate)
+	assert.Equal(t, sbf.fpGrowth, restored.fpGrowth)
+	assert.Len(t, restored.filters(), len(sbf.filters()))
+	for i, bf := range sbf.filters() {
+		assert.Equal(t, bf.m, restored.filters()[i].m, "Layer %d", i)
+		assert.Equal(t, bf.k, restored.filters()[i].k, "Layer %d", i)
+		assert.Equal(t, bf.fpRate, restored.filters()[i].fpRate, "Layer %d", i)
+		assert.Equal(t, bf.bitSet, restored.filters()[i].bitSet, "Layer %d", i)
+	}
+
+	for i := 0; i < 2000; i++ {
+		b, err := restored.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item 'item-%d' should be present after restoring", i)
+	}
+
+	again, err := restored.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, data, again, "Restored filter should encode identically")
+
+	assert.Error(t, restored.UnmarshalBinary(data[:len(data)-1]))
+}
+
+func TestScalableBloomFilter_UnmarshalBinaryLayers(t *testing.T) {
+	t.Parallel()
+	// encode encodes a scalable filter with the given layers, encoded as Bloom filters.
+	encode := func(layers ...[]byte) []byte {
+		params := binary.AppendUvarint(nil, 0)
+		params = binary.AppendUvarint(params, math.Float64bits(0.01))
+		params = binary.AppendUvarint(params, math.Float64bits(2))
+		params = binary.AppendUvarint(params, uint64(len(layers)))
+		var payload []byte
+		for _, layer := range layers {
+			payload = binary.AppendUvarint(payload, math.Float64bits(0.01))
+			payload = appendBytes(payload, layer)
+		}
+		return encodeFilter(filterTypeScalable, params, payload)
+	}
+	layer := func(h Hasher) []byte {
+		bf, err := New(Params{N: 100, FalsePositiveRate: 0.01, Hasher: h})
+		assert.NoError(t, err, "Failed to create Bloom filter")
+		data, err := bf.MarshalBinary()
+		assert.NoError(t, err)
+		return data
+	}
+
+	var sbf ScalableBloomFilter
+	assert.NoError(t, sbf.UnmarshalBinary(encode(layer(NewMurMur3Hasher()), layer(NewMurMur3Hasher()))))
+	assert.Same(t, sbf.filters()[0].hasher, sbf.filters()[1].hasher, "Decoded layers should share their hasher")
+
+	assert.ErrorIs(t, sbf.UnmarshalBinary(encode(layer(NewMurMur3Hasher()), layer(NewFNVHasher()))), ErrInvalidEncoding,
+		"Layers with different hashers should be rejected")
+	params := binary.AppendUvarint(nil, 64)
+	params = binary.AppendUvarint(params, 1<<40)
+	params, err := appendHasher(params, NewMurMur3Hasher())
+	assert.NoError(t, err)
+	assert.ErrorIs(t, sbf.UnmarshalBinary(encode(layer(NewMurMur3Hasher()), encodeFilter(filterTypeBloom, params, make([]byte, 8)))),
+		ErrInvalidEncoding, "Layers with a huge k should be rejected")
+}
+
+func TestScalableBloomFilter_UnmarshalBinaryLockType(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewScalable(ParamsScalable{InitialSize: 100, FalsePositiveRate: 0.01, FalsePositiveGrowth: 2})
+	assert.NoError(t, err, "Failed to create scalable Bloom filter")
+	data, err := sbf.MarshalBinary()
+	assert.NoError(t, err)
+
+	var zero ScalableBloomFilter
+	assert.NoError(t, zero.UnmarshalBinary(data))
+	assert.IsType(t, &ExclusiveMutex{}, zero.filters()[0].mutex, "Layers of a zero value filter should get the default lock")
+
+	rw, err := NewScalable(ParamsScalable{InitialSize: 10, FalsePositiveRate: 0.1, FalsePositiveGrowth: 2, LockType: LockTypeReadWrite})
+	assert.NoError(t, err, "Failed to create scalable Bloom filter")
+	assert.NoError(t, rw.UnmarshalBinary(data))
+	assert.IsType(t, &ReadWriteMutex{}, rw.filters()[0].mutex, "Layers should keep the lock type of the filter")
+
+	unlocked, err := NewScalable(ParamsScalable{InitialSize: 10, FalsePositiveRate: 0.1, FalsePositiveGrowth: 2, LockType: LockTypeNone})
+	assert.NoError(t, err, "Failed to create scalable Bloom filter")
+	assert.NoError(t, unlocked.UnmarshalBinary(data))
+	assert.Nil(t, unlocked.filters()[0].mutex, "Layers of a filter created with LockTypeNone should stay unlocked")
+}
@@ -0,0 +1,103 @@
+package gobloom
+
+import "fmt"
+
+var _ Interface = (*ShardedBloomFilter)(nil)
+
+// defaultShards is the number of shards of a sharded Bloom filter, when not set.
+const defaultShards = 16
+
+// ShardedBloomFilter is made of independent Bloom filters, the shards, each item being added to
+// and tested against the one selected by its hash. Every shard has its own lock, so concurrent
+// writers mostly lock different shards, and writes scale with the number of cores.
+type ShardedBloomFilter struct {
+	shards []*BloomFilter // The shards, all with the same parameters
+	hasher Hasher64       // The hasher of the shards, which also selects the shard
+	k      uint64         // The number of hash functions of the shards
+}
+
+// ParamsSharded represents the parameters for creating a new sharded Bloom filter.
+type ParamsSharded struct {
+	// N is the number of elements expected to be added to the filter, spread over the shards.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate.
+	FalsePositiveRate float64
+	// Shards is the number of shards. Defaults to 16.
+	// It should be a few times the number of concurrent writers, so they rarely contend for a shard.
+	Shards uint64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use for each shard. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewSharded creates a new sharded Bloom filter, each shard being sized for its share of the items.
+func NewSharded(p ParamsSharded) (*ShardedBloomFilter, error) {
+	if p.Shards == 0 {
+		p.Shards = defaultShards
+	}
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	if p.N < p.Shards {
+		return nil, fmt.Errorf("invalid number of elements, must be at least the number of shards %d, got %d", p.Shards, p.N)
+	}
+	params := Params{
+		N:                 (p.N + p.Shards - 1) / p.Shards,
+		FalsePositiveRate: p.FalsePositiveRate,
+		Hasher:            p.Hasher,
+		LockType:          p.LockType,
+	}
+	shards := make([]*BloomFilter, p.Shards)
+	for i := range shards {
+		bf, err := New(params)
+		if err != nil {
+			return nil, err
+		}
+		shards[i] = bf
+	}
+	return &ShardedBloomFilter{shards: shards, hasher: shards[0].hasher64, k: shards[0].k}, nil
+}
+
+// shard hashes the data into probes, and returns the shard of the data.
+// Probes must hold k+1 hashes, the last one selecting the shard.
+func (s *ShardedBloomFilter) shard(data []byte, probes []uint64) *BloomFilter {
+	s.hasher.HashK(data, probes)
+	return s.shards[probes[s.k]%uint64(len(s.shards))]
+}
+
+// Add adds an item to its shard, locking only that shard.
+func (s *ShardedBloomFilter) Add(data []byte) error {
+	probes := getProbes(s.k + 1)
+	defer probePool.Put(probes)
+	s.shard(data, *probes).addHashes((*probes)[:s.k])
+	return nil
+}
+
+// Test checks if an item is in its shard, locking only that shard.
+func (s *ShardedBloomFilter) Test(data []byte) (bool, error) {
+	probes := getProbes(s.k + 1)
+	defer probePool.Put(probes)
+	return s.shard(data, *probes).testHashes((*probes)[:s.k]), nil
+}
+
+// Shards returns the number of shards.
+func (s *ShardedBloomFilter) Shards() int {
+	return len(s.shards)
+}
+
+// Stats returns a snapshot of the statistics of the sharded Bloom filter, summed over its shards.
+// EstimatedFalsePositiveRate is the average over the shards, as an item is tested against one of them.
+func (s *ShardedBloomFilter) Stats() Stats {
+	var st Stats
+	for _, bf := range s.shards {
+		fs := bf.Stats()
+		st.Bits += fs.Bits
+		st.SetBits += fs.SetBits
+		st.K = fs.K
+		st.EstimatedItems += fs.EstimatedItems
+		st.EstimatedFalsePositiveRate += fs.EstimatedFalsePositiveRate / float64(len(s.shards))
+		st.MemoryBytes += fs.MemoryBytes
+	}
+	return st
+}
@@ -0,0 +1,70 @@
+package gobloom
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedBloomFilter_AddAndTest(t *testing.T) {
+	t.Parallel()
+	s, err := NewSharded(ParamsSharded{N: 10000, FalsePositiveRate: 0.01, Shards: 8})
+	assert.NoError(t, err, "Failed to create sharded Bloom filter")
+	assert.Equal(t, 8, s.Shards())
+
+	for i := 0; i < 10000; i++ {
+		assert.NoError(t, s.Add([]byte(strconv.Itoa(i))))
+	}
+	for i := 0; i < 10000; i++ {
+		b, err := s.Test([]byte(strconv.Itoa(i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item %d should be present", i)
+	}
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if b, _ := s.Test([]byte(fmt.Sprintf("missing-%d", i))); b {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 200, "False positive rate should be close to 1%%")
+
+	// The items are spread over the shards.
+	for i, bf := range s.shards {
+		assert.InDelta(t, 1250, bf.ApproximateCount(), 250, "Shard %d should hold its share of the items", i)
+	}
+	assert.InDelta(t, 10000, s.Stats().EstimatedItems, 500)
+}
+
+func TestShardedBloomFilter_Concurrent(t *testing.T) {
+	t.Parallel()
+	s, err := NewSharded(ParamsSharded{N: 8000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create sharded Bloom filter")
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				item := []byte(fmt.Sprintf("%d-%d", g, i))
+				assert.NoError(t, s.Add(item))
+				b, err := s.Test(item)
+				assert.NoError(t, err)
+				assert.True(t, b, "Item should be present after being added")
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestNewSharded_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := NewSharded(ParamsSharded{N: 4, FalsePositiveRate: 0.01, Shards: 8})
+	assert.Error(t, err, "Fewer elements than shards should be rejected")
+	_, err = NewSharded(ParamsSharded{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeAtomic})
+	assert.NoError(t, err, "Shards should accept any lock type of BloomFilter")
+	_, err = NewSharded(ParamsSharded{N: 1000, FalsePositiveRate: 2})
+	assert.Error(t, err)
+}
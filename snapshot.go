@@ -0,0 +1,97 @@
+package gobloom
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"fmt"
+	"io"
+)
+
+// Uploader uploads a snapshot to an object store, like S3 or GCS, through a small adapter,
+// so this package doesn't depend on a client library. The object key is chosen by the adapter.
+type Uploader interface {
+	// Upload stores the size bytes read from body as the snapshot.
+	Upload(ctx context.Context, body io.Reader, size int64) error
+}
+
+// Downloader downloads a snapshot uploaded with an Uploader from an object store.
+type Downloader interface {
+	// Download returns the content of the snapshot. The caller closes it.
+	Download(ctx context.Context) (io.ReadCloser, error)
+}
+
+// SnapshotTo encodes a filter with MarshalBinary and uploads it, so it can be restored with RestoreFrom.
+// Any filter of this package can be snapshotted.
+func SnapshotTo(ctx context.Context, u Uploader, f encoding.BinaryMarshaler) error {
+	data, err := f.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return u.Upload(ctx, bytes.NewReader(data), int64(len(data)))
+}
+
+// RestoreFrom downloads a snapshot uploaded with SnapshotTo and restores it into f with UnmarshalBinary.
+// The snapshot is validated with its checksum, so a corrupt snapshot returns ErrCorruptFilter.
+func RestoreFrom(ctx context.Context, d Downloader, f encoding.BinaryUnmarshaler) error {
+	body, err := d.Download(ctx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.UnmarshalBinary(data)
+}
+
+// Snapshot returns a consistent copy of the Bloom filter, with its own bit set and lock.
+func (bf *BloomFilter) Snapshot() *BloomFilter {
+	if bf.mutex != nil {
+		bf.mutex.RLock()
+		defer bf.mutex.RUnlock()
+	}
+	bitSet := bf.copyBits()
+	var mu Mutex
+	switch m := bf.mutex.(type) {
+	case *ExclusiveMutex:
+		mu = &ExclusiveMutex{}
+	case *ReadWriteMutex:
+		mu = &ReadWriteMutex{}
+	case *StripedMutex:
+		mu = NewStripedMutex(len(m.stripes))
+	}
+	return &BloomFilter{
+		m:        bf.m,
+		k:        bf.k,
+		fpRate:   bf.fpRate,
+		bitSet:   bitSet,
+		hasher:   bf.hasher,
+		hasher64: bf.hasher64,
+		mutex:    mu,
+		atomic:   bf.atomic,
+	}
+}
+
+// Restore atomically replaces the bit set of the Bloom filter with a copy of the snapshot's, which must
+// have been taken from a filter with the same parameters and hasher. The copy is made before the filter is
+// locked, so readers are only blocked for the time of the swap.
+func (bf *BloomFilter) Restore(snapshot *BloomFilter) error {
+	if snapshot.m != bf.m || snapshot.k != bf.k {
+		return fmt.Errorf("incompatible filters, m=%d k=%d and m=%d k=%d", bf.m, bf.k, snapshot.m, snapshot.k)
+	}
+	bitSet := snapshot.Bits()
+	if bf.mutex != nil {
+		bf.mutex.WLock()
+		defer bf.mutex.WUnlock()
+	}
+	bf.bitSet = bitSet
+	for i := range bf.stamps {
+		bf.stamps[i] = bf.generation
+	}
+	return nil
+}
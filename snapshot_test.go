@@ -0,0 +1,85 @@
+package gobloom
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryObject is an in-memory Uploader and Downloader.
+type memoryObject struct {
+	data []byte
+}
+
+func (o *memoryObject) Upload(ctx context.Context, body io.Reader, size int64) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return io.ErrUnexpectedEOF
+	}
+	o.data = data
+	return nil
+}
+
+func (o *memoryObject) Download(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(o.data)), nil
+}
+
+func TestSnapshotToRestoreFrom(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+
+	object := &memoryObject{}
+	assert.NoError(t, SnapshotTo(ctx, object, bf))
+	var restored BloomFilter
+	assert.NoError(t, RestoreFrom(ctx, object, &restored))
+	b, err := restored.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after restoring")
+
+	object.data[len(object.data)/2] ^= 1
+	assert.ErrorIs(t, RestoreFrom(ctx, object, &restored), ErrCorruptFilter)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, RestoreFrom(canceled, object, &restored), context.Canceled)
+}
+
+func TestBloomFilter_SnapshotRestore(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, LockType: LockTypeReadWrite})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+
+	snapshot := bf.Snapshot()
+	assert.IsType(t, &ReadWriteMutex{}, snapshot.mutex)
+	assert.NoError(t, bf.Add([]byte("bar")))
+	b, err := snapshot.Test([]byte("bar"))
+	assert.NoError(t, err)
+	assert.False(t, b, "Changes after the snapshot should not be in it")
+
+	assert.NoError(t, bf.Restore(snapshot))
+	b, err = bf.Test([]byte("bar"))
+	assert.NoError(t, err)
+	assert.False(t, b, "Changes after the snapshot should be rolled back")
+	b, err = bf.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after restoring")
+
+	assert.NoError(t, snapshot.Add([]byte("baz")))
+	b, err = bf.Test([]byte("baz"))
+	assert.NoError(t, err)
+	assert.False(t, b, "The restored filter should not share the snapshot's bit set")
+
+	other, err := New(Params{N: 2000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Error(t, bf.Restore(other), "Snapshots of incompatible filters should not restore")
+}
@@ -0,0 +1,183 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"math/rand"
+)
+
+var (
+	_ Interface  = (*SpectralBloomFilter)(nil)
+	_ Clearer    = (*SpectralBloomFilter)(nil)
+	_ Serializer = (*SpectralBloomFilter)(nil)
+)
+
+// SpectralBloomFilter is a Bloom filter that keeps a counter per position to estimate
+// how many times each item was added.
+// Estimates use minimum selection: the smallest counter of an item is the estimate,
+// since every other counter may also have been incremented by colliding items.
+// Estimates never underestimate the true count.
+type SpectralBloomFilter struct {
+	m        uint64   // The number of counters
+	counters []uint64 // The counters, one per position
+	k        uint64   // The number of hash functions to use
+	hasher   Hasher   // The hash provider the hash functions come from
+	hasher64 Hasher64 // The hasher computing the hashes of an item
+	mutex    Mutex    // Mutex to ensure thread safety
+
+	rand          *rand.Rand // Random source of the rounding of Decay
+	deterministic bool       // Whether rand is seeded with decayDeterministicSeed, see Params
+}
+
+// NewSpectral creates a new spectral Bloom filter with the given parameters.
+// N is the number of distinct elements expected to be added.
+func NewSpectral(p Params) (*SpectralBloomFilter, error) {
+	applyDefaults(&p)
+	if err := validateParams(p); err != nil {
+		return nil, err
+	}
+	m, k := getOptimalParams(p.N, p.FalsePositiveRate)
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	return &SpectralBloomFilter{
+		m:        m,
+		k:        k,
+		counters: make([]uint64, m),
+		hasher:   p.Hasher,
+		hasher64: asHasher64(p.Hasher),
+		mutex:    mu,
+
+		rand:          newDecayRand(p.Deterministic),
+		deterministic: p.Deterministic,
+	}, nil
+}
+
+// Add adds an occurrence of an item to the spectral Bloom filter.
+func (sbf *SpectralBloomFilter) Add(data []byte) error {
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
+	}
+	probes := pooledLocations(sbf.hasher64, data, sbf.k, sbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	for _, l := range locs {
+		sbf.counters[l]++
+	}
+	return nil
+}
+
+// Test checks if an item is in the spectral Bloom filter.
+func (sbf *SpectralBloomFilter) Test(data []byte) (bool, error) {
+	c, err := sbf.Count(data)
+	return c > 0, err
+}
+
+// Count returns the estimated number of times an item was added to the spectral Bloom filter.
+func (sbf *SpectralBloomFilter) Count(data []byte) (uint64, error) {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	probes := pooledLocations(sbf.hasher64, data, sbf.k, sbf.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	min := sbf.counters[locs[0]]
+	for _, l := range locs[1:] {
+		if sbf.counters[l] < min {
+			min = sbf.counters[l]
+		}
+	}
+	return min, nil
+}
+
+// MarshalBinary encodes the spectral Bloom filter with the wire format.
+// The parameter block holds m, k, and the hasher. The payload is the counters.
+func (sbf *SpectralBloomFilter) MarshalBinary() ([]byte, error) {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	params := binary.AppendUvarint(nil, sbf.m)
+	params = binary.AppendUvarint(params, sbf.k)
+	params, err := appendHasher(params, sbf.hasher)
+	if err != nil {
+		return nil, err
+	}
+	params = appendDeterministic(params, sbf.deterministic)
+	return encodeFilter(filterTypeSpectral, params, appendWords(nil, sbf.counters)), nil
+}
+
+// UnmarshalBinary restores a spectral Bloom filter encoded with MarshalBinary.
+// The lock type of the filter is kept, it defaults to ExclusiveLock for a zero value filter.
+func (sbf *SpectralBloomFilter) UnmarshalBinary(data []byte) error {
+	params, payload, err := decodeFilter(filterTypeSpectral, data)
+	if err != nil {
+		return err
+	}
+	r := byteReader{data: params}
+	m := r.uvarint()
+	k := r.uvarint()
+	hasher, err := r.hasher()
+	if err != nil {
+		return err
+	}
+	deterministic, err := r.deterministic()
+	if err != nil {
+		return err
+	}
+	if err := checkMK(m, k); err != nil {
+		return err
+	}
+	if err := checkPayload(payload, 8, m); err != nil {
+		return err
+	}
+	if sbf.mutex == nil && sbf.m == 0 {
+		sbf.mutex = &ExclusiveMutex{}
+	}
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
+	}
+	sbf.m = m
+	sbf.k = k
+	sbf.counters = readWords(payload)
+	sbf.hasher = hasher
+	sbf.hasher64 = asHasher64(hasher)
+	// Deterministic filters are seeded again, so every decoded copy decays the same way.
+	if deterministic || sbf.rand == nil {
+		sbf.rand = newDecayRand(deterministic)
+	}
+	sbf.deterministic = deterministic
+	return nil
+}
+
+// Decay multiplies the counters by factor, between 0 and 1, so older additions weigh less than recent ones,
+// for exponentially decayed frequencies in streaming jobs. Counters are rounded up or down at random, see
+// CountingBloomFilter.Decay, so Count estimates the decayed count of an item on average, but may now
+// underestimate it, since its counters are rounded independently.
+func (sbf *SpectralBloomFilter) Decay(factor float64) error {
+	if err := validateDecay(factor); err != nil {
+		return err
+	}
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
+	}
+	for i, c := range sbf.counters {
+		if c != 0 {
+			sbf.counters[i] = decayCounter(c, factor, sbf.rand)
+		}
+	}
+	return nil
+}
+
+// Clear removes all the items from the spectral Bloom filter, keeping its parameters.
+func (sbf *SpectralBloomFilter) Clear() {
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
+	}
+	clear(sbf.counters)
+}
@@ -0,0 +1,73 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpectralBloomFilter_Count(t *testing.T) {
+	t.Parallel()
+	n := uint64(1000)
+	sbf, err := NewSpectral(Params{N: n, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create spectral Bloom filter")
+
+	for i := uint64(0); i < n; i++ {
+		for j := uint64(0); j <= i%5; j++ {
+			assert.NoError(t, sbf.Add([]byte(fmt.Sprintf("item-%d", i))))
+		}
+	}
+
+	exact := 0
+	for i := uint64(0); i < n; i++ {
+		c, err := sbf.Count([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, c, i%5+1, "Count should never underestimate")
+		if c == i%5+1 {
+			exact++
+		}
+	}
+	assert.Greater(t, exact, int(n)*95/100, "Most counts should be exact")
+}
+
+func TestSpectralBloomFilter_Test(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewSpectral(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create spectral Bloom filter")
+
+	assert.NoError(t, sbf.Add([]byte("foo")))
+	b, err := sbf.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b)
+
+	c, err := sbf.Count([]byte("bar"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), c)
+}
+
+func TestSpectralBloomFilter_Decay(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewSpectral(Params{N: 100, FalsePositiveRate: 0.001})
+	assert.NoError(t, err)
+	for i := 0; i < 10000; i++ {
+		assert.NoError(t, sbf.Add([]byte("hot")))
+	}
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, sbf.Add([]byte("cold")))
+	}
+	assert.NoError(t, sbf.Decay(0.1))
+	hot, _ := sbf.Count([]byte("hot"))
+	assert.InDelta(t, 1000, hot, 1, "Large counts should decay by the factor")
+	cold, _ := sbf.Count([]byte("cold"))
+	assert.LessOrEqual(t, cold, uint64(2))
+
+	assert.NoError(t, sbf.Decay(1))
+	unchanged, _ := sbf.Count([]byte("hot"))
+	assert.Equal(t, hot, unchanged, "A factor of 1 should not change the counters")
+	assert.NoError(t, sbf.Decay(0))
+	b, _ := sbf.Test([]byte("hot"))
+	assert.False(t, b, "A factor of 0 should forget every item")
+	assert.Error(t, sbf.Decay(math.NaN()))
+}
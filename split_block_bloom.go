@@ -0,0 +1,146 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+var (
+	_ Interface = (*SplitBlockBloomFilter)(nil)
+	_ Clearer   = (*SplitBlockBloomFilter)(nil)
+)
+
+// Sizes of a split block Bloom filter, as in the Parquet specification.
+const (
+	sbbfBlockBytes = 32        // The size of a block, eight 32-bit words
+	sbbfMinBytes   = 32        // The minimum size of a filter, one block
+	sbbfMaxBytes   = 128 << 20 // The maximum size of a filter, 128MiB
+)
+
+// sbbfSalts are the salts of the eight hash functions of a block, one per word, from the Parquet specification.
+var sbbfSalts = [8]uint32{
+	0x47b6137b, 0x44974d91, 0x8824ad5b, 0xa2b7289d,
+	0x705495c7, 0x2df1424b, 0x9efc4947, 0x5c6bfb31,
+}
+
+// SplitBlockBloomFilter is a split block Bloom filter (SBBF), the Bloom filter of Parquet files. Its bits
+// are split into blocks of 256 bits, and an item sets one bit in each of the eight 32-bit words of a
+// single block, so adding and testing an item touches a single cache line.
+//
+// Items are hashed with 64-bit xxHash with a seed of 0, like in Parquet, so the filter is compatible with
+// the column filters written by other Parquet implementations, see WriteParquet. Parquet hashes the plain
+// encoding of values: the bytes of BYTE_ARRAY values, or the little-endian bytes of INT32 and INT64 values.
+// Values hashed otherwise can be added and tested with AddHash and TestHash.
+type SplitBlockBloomFilter struct {
+	blocks []uint32 // The blocks, 8 consecutive words each
+	mutex  Mutex    // Mutex to ensure thread safety
+}
+
+// ParamsSplitBlock represents the parameters for creating a new split block Bloom filter.
+type ParamsSplitBlock struct {
+	// N is the number of distinct elements expected to be added.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate.
+	FalsePositiveRate float64
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewSplitBlock creates a new split block Bloom filter sized for the given parameters. Its size is
+// a power of two between 32 bytes and 128MiB, as Parquet readers expect.
+func NewSplitBlock(p ParamsSplitBlock) (*SplitBlockBloomFilter, error) {
+	if p.N == 0 {
+		return nil, fmt.Errorf("number of elements cannot be 0")
+	}
+	if p.FalsePositiveRate <= 0 || p.FalsePositiveRate >= 1 {
+		return nil, fmt.Errorf("false positive rate must be between 0 and 1")
+	}
+	return newSplitBlock(splitBlockBytes(p.N, p.FalsePositiveRate), p.LockType)
+}
+
+// newSplitBlock creates a new, empty split block Bloom filter of numBytes bytes.
+func newSplitBlock(numBytes uint64, lockType LockType) (*SplitBlockBloomFilter, error) {
+	if lockType == LockTypeDefault {
+		lockType = LockTypeExclusive
+	}
+	mu, err := NewMutex(lockType)
+	if err != nil {
+		return nil, err
+	}
+	return &SplitBlockBloomFilter{blocks: make([]uint32, numBytes/4), mutex: mu}, nil
+}
+
+// splitBlockBytes returns the size in bytes of a split block Bloom filter holding n items with a false
+// positive rate of p, which is m = -8n/ln(1-p^(1/8)) bits, rounded up to a power of two.
+func splitBlockBytes(n uint64, p float64) uint64 {
+	m := -8 * float64(n) / math.Log(1-math.Pow(p, 1.0/8))
+	size := uint64(math.Ceil(m / 8))
+	if size >= sbbfMaxBytes {
+		return sbbfMaxBytes
+	}
+	if size <= sbbfMinBytes {
+		return sbbfMinBytes
+	}
+	return 1 << bits.Len64(size-1)
+}
+
+// Add adds an item to the split block Bloom filter.
+func (sbf *SplitBlockBloomFilter) Add(data []byte) error {
+	sbf.AddHash(xxhash.Sum64(data))
+	return nil
+}
+
+// Test checks if an item is in the split block Bloom filter.
+func (sbf *SplitBlockBloomFilter) Test(data []byte) (bool, error) {
+	return sbf.TestHash(xxhash.Sum64(data)), nil
+}
+
+// AddHash adds an item given by its 64-bit hash to the split block Bloom filter.
+func (sbf *SplitBlockBloomFilter) AddHash(h uint64) {
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
+	}
+	block := sbf.block(h)
+	for i, salt := range sbbfSalts {
+		block[i] |= 1 << (uint32(h) * salt >> 27)
+	}
+}
+
+// TestHash checks if an item given by its 64-bit hash is in the split block Bloom filter.
+func (sbf *SplitBlockBloomFilter) TestHash(h uint64) bool {
+	if sbf.mutex != nil {
+		sbf.mutex.RLock()
+		defer sbf.mutex.RUnlock()
+	}
+	block := sbf.block(h)
+	for i, salt := range sbbfSalts {
+		if block[i]&(1<<(uint32(h)*salt>>27)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// block returns the block of the hash, selected by its most significant 32 bits.
+func (sbf *SplitBlockBloomFilter) block(h uint64) []uint32 {
+	i := (h >> 32) * uint64(len(sbf.blocks)/8) >> 32
+	return sbf.blocks[i*8 : i*8+8]
+}
+
+// NumBytes returns the size of the bit set in bytes.
+func (sbf *SplitBlockBloomFilter) NumBytes() uint64 {
+	return 4 * uint64(len(sbf.blocks))
+}
+
+// Clear removes all the items from the split block Bloom filter.
+func (sbf *SplitBlockBloomFilter) Clear() {
+	if sbf.mutex != nil {
+		sbf.mutex.WLock()
+		defer sbf.mutex.WUnlock()
+	}
+	clear(sbf.blocks)
+}
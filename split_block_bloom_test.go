@@ -0,0 +1,51 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitBlockBloomFilter_AddTest(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewSplitBlock(ParamsSplitBlock{N: 10000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create split block Bloom filter")
+	for i := 0; i < 10000; i++ {
+		assert.NoError(t, sbf.Add([]byte("item-"+strconv.Itoa(i))))
+	}
+	for i := 0; i < 10000; i++ {
+		b, err := sbf.Test([]byte("item-" + strconv.Itoa(i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Added items should test positive")
+	}
+	var fp int
+	for i := 0; i < 10000; i++ {
+		if b, _ := sbf.Test([]byte("other-" + strconv.Itoa(i))); b {
+			fp++
+		}
+	}
+	assert.Less(t, fp, 200, "False positive rate should be close to 1%")
+
+	sbf.Clear()
+	b, _ := sbf.Test([]byte("item-1"))
+	assert.False(t, b, "Cleared filter should be empty")
+}
+
+func TestSplitBlockBloomFilter_Size(t *testing.T) {
+	t.Parallel()
+	sbf, err := NewSplitBlock(ParamsSplitBlock{N: 1, FalsePositiveRate: 0.5})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(32), sbf.NumBytes(), "Filters should have at least one block")
+
+	sbf, err = NewSplitBlock(ParamsSplitBlock{N: 1000000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err)
+	size := sbf.NumBytes()
+	assert.Zero(t, size&(size-1), "Size should be a power of two")
+	assert.GreaterOrEqual(t, size*8, uint64(9_000_000), "Size should hold the items at the false positive rate")
+
+	_, err = NewSplitBlock(ParamsSplitBlock{FalsePositiveRate: 0.01})
+	assert.Error(t, err)
+	_, err = NewSplitBlock(ParamsSplitBlock{N: 10, FalsePositiveRate: 1})
+	assert.Error(t, err)
+}
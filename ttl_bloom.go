@@ -0,0 +1,154 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	_ Interface = (*TTLBloomFilter)(nil)
+	_ Clearer   = (*TTLBloomFilter)(nil)
+)
+
+// TTLBloomFilter is a Bloom filter whose items expire after a time to live. The bits are replaced by
+// cells holding the coarse time bucket they were last set in, and Test only honors the cells set within
+// the TTL, so items expire individually, without resetting the filter. An item is present for between
+// TTL and TTL+Resolution after it was last added.
+//
+// Cells set by expired items are reused by the items added later, so the false positive rate is the one
+// of a Bloom filter holding the items added within the TTL. Each cell takes 32 bits, instead of one.
+type TTLBloomFilter struct {
+	m          uint64   // The number of cells
+	k          uint64   // The number of hash functions
+	cells      []uint32 // The time bucket each cell was last set in, starting at 1, 0 if never set
+	hasher64   Hasher64 // The hasher computing the hashes of an item
+	resolution time.Duration
+	window     uint32    // The number of time buckets an item is present for, counting the one it was added in
+	start      time.Time // The start of the first time bucket
+	now        func() time.Time
+	mutex      Mutex // Mutex to ensure thread safety
+}
+
+// ParamsTTL represents the parameters for creating a new TTL Bloom filter.
+type ParamsTTL struct {
+	// N is the number of elements expected to be added during a TTL.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate.
+	FalsePositiveRate float64
+	// TTL is the time after which the items expire.
+	TTL time.Duration
+	// Resolution is the duration of the time buckets. Defaults to TTL/16. Items expire between TTL and
+	// TTL+Resolution after they were added. The filter works for 2^32 buckets, over 136 years at one second.
+	Resolution time.Duration
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewTTL creates a new TTL Bloom filter.
+func NewTTL(p ParamsTTL) (*TTLBloomFilter, error) {
+	applyDefaultsTTL(&p)
+	if p.N == 0 {
+		return nil, fmt.Errorf("number of elements cannot be 0")
+	}
+	if p.FalsePositiveRate <= 0 || p.FalsePositiveRate >= 1 {
+		return nil, fmt.Errorf("false positive rate must be between 0 and 1")
+	}
+	if p.TTL <= 0 {
+		return nil, fmt.Errorf("invalid TTL, must be greater than 0, got %s", p.TTL)
+	}
+	if p.Resolution <= 0 || p.Resolution > p.TTL {
+		return nil, fmt.Errorf("invalid resolution, must be between 0 and the TTL, got %s", p.Resolution)
+	}
+	// Items added at the end of a bucket need one more bucket to be present for the whole TTL.
+	window := (p.TTL+p.Resolution-1)/p.Resolution + 1
+	if window > math.MaxUint32/2 {
+		return nil, fmt.Errorf("resolution %s is too fine for the TTL %s", p.Resolution, p.TTL)
+	}
+	mu, err := NewMutex(p.LockType)
+	if err != nil {
+		return nil, err
+	}
+	m, k := getOptimalParams(p.N, p.FalsePositiveRate)
+	now := time.Now
+	return &TTLBloomFilter{
+		m:          m,
+		k:          k,
+		cells:      make([]uint32, m),
+		hasher64:   asHasher64(p.Hasher),
+		resolution: p.Resolution,
+		window:     uint32(window),
+		start:      now(),
+		now:        now,
+		mutex:      mu,
+	}, nil
+}
+
+// applyDefaultsTTL applies the default values to the parameters if they are not set.
+func applyDefaultsTTL(p *ParamsTTL) {
+	if p.Resolution == 0 {
+		p.Resolution = max(p.TTL/16, 1)
+	}
+	if p.Hasher == nil {
+		p.Hasher = NewMurMur3Hasher()
+	}
+	if p.LockType == LockTypeDefault {
+		p.LockType = LockTypeExclusive
+	}
+}
+
+// bucket returns the current time bucket, starting at 1.
+func (tbf *TTLBloomFilter) bucket() uint32 {
+	elapsed := max(tbf.now().Sub(tbf.start), 0)
+	return uint32(min(uint64(elapsed/tbf.resolution)+1, math.MaxUint32))
+}
+
+// Add adds an item to the TTL Bloom filter, or renews its TTL if it was already added.
+func (tbf *TTLBloomFilter) Add(data []byte) error {
+	probes := pooledLocations(tbf.hasher64, data, tbf.k, tbf.m)
+	defer probePool.Put(probes)
+	if tbf.mutex != nil {
+		tbf.mutex.WLock()
+		defer tbf.mutex.WUnlock()
+	}
+	bucket := tbf.bucket()
+	for _, loc := range *probes {
+		tbf.cells[loc] = max(tbf.cells[loc], bucket)
+	}
+	return nil
+}
+
+// Test checks if an item was added to the TTL Bloom filter within the TTL.
+func (tbf *TTLBloomFilter) Test(data []byte) (bool, error) {
+	probes := pooledLocations(tbf.hasher64, data, tbf.k, tbf.m)
+	defer probePool.Put(probes)
+	if tbf.mutex != nil {
+		tbf.mutex.RLock()
+		defer tbf.mutex.RUnlock()
+	}
+	bucket := tbf.bucket()
+	for _, loc := range *probes {
+		// Buckets are never 0, so cells never set are expired too. Cells from a later bucket, set before
+		// the clock went back, are not.
+		if cell := tbf.cells[loc]; cell == 0 || (bucket > cell && bucket-cell >= tbf.window) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// TTL returns the time items are present for at least, rounded up to a whole number of time buckets.
+func (tbf *TTLBloomFilter) TTL() time.Duration {
+	return time.Duration(tbf.window-1) * tbf.resolution
+}
+
+// Clear removes all the items from the TTL Bloom filter.
+func (tbf *TTLBloomFilter) Clear() {
+	if tbf.mutex != nil {
+		tbf.mutex.WLock()
+		defer tbf.mutex.WUnlock()
+	}
+	clear(tbf.cells)
+}
@@ -0,0 +1,90 @@
+package gobloom
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTTLBloomFilter_Expiration(t *testing.T) {
+	t.Parallel()
+	tbf, err := NewTTL(ParamsTTL{N: 1000, FalsePositiveRate: 0.01, TTL: 10 * time.Minute, Resolution: time.Minute})
+	assert.NoError(t, err, "Failed to create TTL Bloom filter")
+	assert.Equal(t, 10*time.Minute, tbf.TTL())
+	now := time.Now()
+	tbf.now = func() time.Time { return now }
+	tbf.start = now
+
+	// The items are added in the middle of their time buckets.
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, tbf.Add([]byte("old")))
+	now = now.Add(5 * time.Minute)
+	assert.NoError(t, tbf.Add([]byte("new")))
+
+	// The items are present for the whole TTL, and expire individually within a resolution.
+	now = now.Add(5 * time.Minute) // 10:30
+	for _, item := range []string{"old", "new"} {
+		b, err := tbf.Test([]byte(item))
+		assert.NoError(t, err)
+		assert.True(t, b, "%q should be present within the TTL", item)
+	}
+	now = now.Add(30 * time.Second) // 11:00
+	b, _ := tbf.Test([]byte("old"))
+	assert.False(t, b, "The old item should have expired")
+	b, _ = tbf.Test([]byte("new"))
+	assert.True(t, b, "The new item should still be present")
+
+	// Adding an item again renews its TTL.
+	assert.NoError(t, tbf.Add([]byte("new")))
+	now = now.Add(10*time.Minute + 59*time.Second) // 21:59
+	b, _ = tbf.Test([]byte("new"))
+	assert.True(t, b)
+	now = now.Add(time.Second) // 22:00
+	b, _ = tbf.Test([]byte("new"))
+	assert.False(t, b)
+}
+
+func TestTTLBloomFilter_FalsePositiveRate(t *testing.T) {
+	t.Parallel()
+	tbf, err := NewTTL(ParamsTTL{N: 1000, FalsePositiveRate: 0.01, TTL: time.Hour})
+	assert.NoError(t, err)
+	now := time.Now()
+	tbf.now = func() time.Time { return now }
+	tbf.start = now
+
+	// Many more items than N go through the filter, but only N are live at any time.
+	for round := 0; round < 10; round++ {
+		for i := 0; i < 1000; i++ {
+			assert.NoError(t, tbf.Add([]byte(strconv.Itoa(round)+"-"+strconv.Itoa(i))))
+		}
+		now = now.Add(time.Hour + time.Hour/16)
+	}
+	now = now.Add(-time.Hour - time.Hour/16)
+	for i := 0; i < 1000; i++ {
+		b, _ := tbf.Test([]byte("9-" + strconv.Itoa(i)))
+		assert.True(t, b, "Live items should be present")
+	}
+	fp := 0
+	for i := 0; i < 10000; i++ {
+		if b, _ := tbf.Test([]byte("missing-" + strconv.Itoa(i))); b {
+			fp++
+		}
+	}
+	assert.Less(t, float64(fp)/10000, 0.02, "Expired items should not raise the false positive rate")
+
+	tbf.Clear()
+	b, _ := tbf.Test([]byte("9-0"))
+	assert.False(t, b)
+}
+
+func TestNewTTL_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := NewTTL(ParamsTTL{N: 1000, FalsePositiveRate: 0.01})
+	assert.Error(t, err, "The TTL is required")
+	_, err = NewTTL(ParamsTTL{N: 1000, FalsePositiveRate: 0.01, TTL: time.Minute, Resolution: time.Hour})
+	assert.Error(t, err, "The resolution cannot exceed the TTL")
+	_, err = NewTTL(ParamsTTL{N: 1000, FalsePositiveRate: 0.01, TTL: 1000 * time.Hour, Resolution: time.Nanosecond})
+	assert.Error(t, err, "Too many buckets should be rejected")
+}
@@ -0,0 +1,60 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Typed is a type-safe wrapper around a filter, encoding the values of type T to bytes with a keyer,
+// so values don't have to be encoded manually on every call.
+type Typed[T any] struct {
+	filter Interface
+	key    func(T) []byte
+}
+
+// NewTyped wraps the filter so values of type T are encoded with key before being added or tested.
+// Keyers are provided for common types: StringKey, IntKey, Uint64Key and UUIDKey.
+// The same keyer must always be used with a filter, or encoded values won't match.
+func NewTyped[T any](filter Interface, key func(T) []byte) (*Typed[T], error) {
+	if filter == nil || key == nil {
+		return nil, fmt.Errorf("filter and key cannot be nil")
+	}
+	return &Typed[T]{filter: filter, key: key}, nil
+}
+
+// Add adds a value to the filter.
+func (t *Typed[T]) Add(v T) error {
+	return t.filter.Add(t.key(v))
+}
+
+// Test checks if a value is in the filter.
+func (t *Typed[T]) Test(v T) (bool, error) {
+	return t.filter.Test(t.key(v))
+}
+
+// Filter returns the wrapped filter.
+func (t *Typed[T]) Filter() Interface {
+	return t.filter
+}
+
+// StringKey encodes a string as its bytes.
+func StringKey(s string) []byte {
+	return []byte(s)
+}
+
+// IntKey encodes an int as 8 little-endian bytes, the same on every platform.
+func IntKey(v int) []byte {
+	return binary.LittleEndian.AppendUint64(nil, uint64(v))
+}
+
+// Uint64Key encodes a uint64 as 8 little-endian bytes.
+func Uint64Key(v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(nil, v)
+}
+
+// UUIDKey encodes a UUID as its 16 bytes. It accepts any type based on [16]byte, like uuid.UUID
+// from github.com/google/uuid, for example NewTyped(filter, UUIDKey[uuid.UUID]).
+func UUIDKey[U ~[16]byte](id U) []byte {
+	b := [16]byte(id)
+	return b[:]
+}
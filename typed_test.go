@@ -0,0 +1,58 @@
+package gobloom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUUID [16]byte
+
+func TestTyped_AddAndTest(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	ids, err := NewTyped(bf, Uint64Key)
+	assert.NoError(t, err)
+	assert.Same(t, bf, ids.Filter())
+
+	for i := uint64(0); i < 100; i++ {
+		assert.NoError(t, ids.Add(i))
+	}
+	for i := uint64(0); i < 100; i++ {
+		b, err := ids.Test(i)
+		assert.NoError(t, err)
+		assert.True(t, b, "Value %d should be present", i)
+	}
+	b, err := ids.Test(1000)
+	assert.NoError(t, err)
+	assert.False(t, b, "Value should not be present")
+
+	// The keyers of the same integer value are interchangeable.
+	ints, err := NewTyped(bf, IntKey)
+	assert.NoError(t, err)
+	b, err = ints.Test(42)
+	assert.NoError(t, err)
+	assert.True(t, b, "IntKey and Uint64Key should encode positive values the same")
+
+	_, err = NewTyped[string](bf, nil)
+	assert.Error(t, err)
+}
+
+func TestTyped_Keyers(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []byte("foo"), StringKey("foo"))
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, IntKey(-1))
+	assert.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0}, Uint64Key(1))
+
+	id := testUUID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	assert.Equal(t, id[:], UUIDKey(id))
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	uuids, err := NewTyped(bf, UUIDKey[testUUID])
+	assert.NoError(t, err)
+	assert.NoError(t, uuids.Add(id))
+	b, err := uuids.Test(id)
+	assert.NoError(t, err)
+	assert.True(t, b, "UUID should be present")
+}
@@ -0,0 +1,127 @@
+package gobloom
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync"
+)
+
+var _ Interface = (*WALBloomFilter)(nil)
+
+// WALBloomFilter is a Bloom filter that appends the bit positions of every added item to a write-ahead log,
+// so a crashed process can reconstruct the exact filter by replaying the log with ReplayWAL, which is faster
+// than adding the source data again. The log is typically truncated whenever a snapshot is taken.
+//
+// The log starts with a header, made of the length as a uvarint of an empty filter encoded with the
+// wire format, whose parameter block holds m and k. Each record holds the k bit positions of an item
+// as little-endian uint64s, followed by their CRC-32C checksum.
+type WALBloomFilter struct {
+	*BloomFilter
+	log    io.Writer
+	record []byte     // The buffer records are encoded in, reused since writers must not retain it
+	mu     sync.Mutex // Serializes writes to the log
+}
+
+// NewWAL wraps a Bloom filter so every item added to it is appended to the log.
+// The header is written to the log first, so log must be empty.
+// Writes are not buffered, Sync should be called to flush the log to stable storage when it supports it.
+func NewWAL(bf *BloomFilter, log io.Writer) (*WALBloomFilter, error) {
+	if bf == nil || log == nil {
+		return nil, fmt.Errorf("filter and log cannot be nil")
+	}
+	if _, err := log.Write(walHeader(bf.m, bf.k)); err != nil {
+		return nil, err
+	}
+	return &WALBloomFilter{BloomFilter: bf, log: log}, nil
+}
+
+// walHeader returns the header of a log for a filter with the given parameters.
+func walHeader(m, k uint64) []byte {
+	params := binary.AppendUvarint(nil, m)
+	params = binary.AppendUvarint(params, k)
+	return appendBytes(nil, encodeFilter(filterTypeBloomWAL, params, nil))
+}
+
+// Add appends the positions of the item to the log, then adds it to the Bloom filter.
+func (w *WALBloomFilter) Add(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	probes := pooledLocations(w.hasher64, data, w.k, w.m)
+	defer probePool.Put(probes)
+	locs := *probes
+	record := appendWords(w.record[:0], locs)
+	record = binary.LittleEndian.AppendUint32(record, crc32.Checksum(record, wireChecksumTable))
+	w.record = record
+	if _, err := w.log.Write(record); err != nil {
+		return err
+	}
+	w.setLocations(locs)
+	return nil
+}
+
+// Sync flushes the log to stable storage, if it has a Sync method, like os.File.
+func (w *WALBloomFilter) Sync() error {
+	if s, ok := w.log.(interface{ Sync() error }); ok {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return s.Sync()
+	}
+	return nil
+}
+
+// ReplayWAL adds the items recorded in a log written by a WALBloomFilter to a Bloom filter, which must have
+// the same parameters and hasher as the logged one. A truncated last record, left by a crash while writing
+// it, is ignored. A record whose checksum does not match returns ErrCorruptFilter.
+func ReplayWAL(bf *BloomFilter, log io.Reader) error {
+	r := bufio.NewReader(log)
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("%w: reading header: %w", ErrInvalidEncoding, err)
+	}
+	if size > 1<<10 {
+		return fmt.Errorf("%w: header is too large", ErrInvalidEncoding)
+	}
+	header := make([]byte, size)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("%w: reading header: %w", ErrInvalidEncoding, err)
+	}
+	params, _, err := decodeFilter(filterTypeBloomWAL, header)
+	if err != nil {
+		return err
+	}
+	pr := byteReader{data: params}
+	m := pr.uvarint()
+	k := pr.uvarint()
+	if pr.err != nil {
+		return pr.err
+	}
+	if m != bf.m || k != bf.k {
+		return fmt.Errorf("incompatible filters, m=%d k=%d and m=%d k=%d", bf.m, bf.k, m, k)
+	}
+
+	record := make([]byte, 8*k+4)
+	for {
+		_, err := io.ReadFull(r, record)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		end := len(record) - 4
+		if binary.LittleEndian.Uint32(record[end:]) != crc32.Checksum(record[:end], wireChecksumTable) {
+			return fmt.Errorf("%w: %w: record checksum mismatch", ErrInvalidEncoding, ErrCorruptFilter)
+		}
+		locs := readWords(record[:end])
+		for _, loc := range locs {
+			if loc >= m {
+				return fmt.Errorf("%w: bit position %d exceeds m", ErrInvalidEncoding, loc)
+			}
+		}
+		bf.setLocations(locs)
+	}
+}
@@ -0,0 +1,54 @@
+package gobloom
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWALBloomFilter_Replay(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	var log bytes.Buffer
+	w, err := NewWAL(bf, &log)
+	assert.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, w.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	assert.NoError(t, w.Sync())
+
+	replayed, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, ReplayWAL(replayed, bytes.NewReader(log.Bytes())))
+	assert.Equal(t, bf.Bits(), replayed.Bits())
+
+	// A record truncated by a crash is ignored.
+	truncated, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, ReplayWAL(truncated, bytes.NewReader(log.Bytes()[:log.Len()-1])))
+	b, err := truncated.Test([]byte("item-98"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Complete records should be replayed")
+}
+
+func TestWALBloomFilter_ReplayInvalid(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	var log bytes.Buffer
+	w, err := NewWAL(bf, &log)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Add([]byte("foo")))
+
+	other, err := New(Params{N: 2000, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.Error(t, ReplayWAL(other, bytes.NewReader(log.Bytes())), "Logs of incompatible filters should not replay")
+
+	corrupt := bytes.Clone(log.Bytes())
+	corrupt[len(corrupt)-5] ^= 1
+	assert.ErrorIs(t, ReplayWAL(bf, bytes.NewReader(corrupt)), ErrCorruptFilter)
+	assert.ErrorIs(t, ReplayWAL(bf, bytes.NewReader([]byte("garbage"))), ErrInvalidEncoding)
+}
@@ -0,0 +1,147 @@
+package gobloom
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+var (
+	_ Interface = (*WeightedBloomFilter)(nil)
+	_ Clearer   = (*WeightedBloomFilter)(nil)
+)
+
+// WeightedBloomFilter is a Bloom filter whose items get a number of hash functions proportional to
+// their weight, given by a weight function. In workloads with skewed query distributions, giving more
+// hash functions to the frequently queried or high-importance items lowers their false positive rate,
+// at the expense of the rare ones, which lowers the false positive rate weighted by the queries.
+//
+// The weight function must return the same weight for an item every time it is called, since the
+// item is tested with as many hash functions as it was added with.
+type WeightedBloomFilter struct {
+	m        uint64               // The number of bits
+	k        uint64               // The number of hash functions of items of weight 1
+	maxK     uint64               // The maximum number of hash functions of an item
+	bitSet   []uint64             // The bit array
+	weight   func([]byte) float64 // The weight of an item
+	hasher   Hasher               // The hash provider the hash functions come from
+	hasher64 Hasher64             // The hasher computing the hashes of an item
+	mutex    Mutex                // Mutex to ensure thread safety
+}
+
+// ParamsWeighted represents the parameters for creating a new weighted Bloom filter.
+type ParamsWeighted struct {
+	// N is the number of elements expected to be added.
+	N uint64
+	// FalsePositiveRate is the acceptable false positive rate of items of weight 1.
+	FalsePositiveRate float64
+	// Weight returns the weight of an item, which multiplies the optimal number of hash functions.
+	// Weights above 1 lower the false positive rate of the item, weights below 1 raise it.
+	Weight func(data []byte) float64
+	// MaxK is the maximum number of hash functions of an item. Defaults to 4 times the optimal number.
+	MaxK uint64
+	// Hasher is the hash provider to use. Defaults to MurMur3Hasher.
+	Hasher Hasher
+	// LockType is the lock type to use. Defaults to ExclusiveLock.
+	LockType LockType
+}
+
+// NewWeighted creates a new weighted Bloom filter with the given parameters.
+func NewWeighted(p ParamsWeighted) (*WeightedBloomFilter, error) {
+	params := Params{N: p.N, FalsePositiveRate: p.FalsePositiveRate, Hasher: p.Hasher, LockType: p.LockType}
+	applyDefaults(&params)
+	if err := validateParams(params); err != nil {
+		return nil, err
+	}
+	if p.Weight == nil {
+		return nil, fmt.Errorf("weight function cannot be nil")
+	}
+	m, k := getOptimalParams(params.N, params.FalsePositiveRate)
+	if p.MaxK == 0 {
+		p.MaxK = 4 * k
+	}
+	mu, err := NewMutex(params.LockType)
+	if err != nil {
+		return nil, err
+	}
+	return &WeightedBloomFilter{
+		m:        m,
+		k:        k,
+		maxK:     p.MaxK,
+		bitSet:   make([]uint64, (m+63)/64),
+		weight:   p.Weight,
+		hasher:   params.Hasher,
+		hasher64: asHasher64(params.Hasher),
+		mutex:    mu,
+	}, nil
+}
+
+// K returns the number of hash functions of the item, the optimal number multiplied by its weight,
+// rounded, and between 1 and the maximum number of hash functions.
+func (wbf *WeightedBloomFilter) K(data []byte) uint64 {
+	w := wbf.weight(data)
+	if math.IsNaN(w) || w <= 0 {
+		return 1
+	}
+	k := math.Round(float64(wbf.k) * w)
+	if k >= float64(wbf.maxK) {
+		return wbf.maxK
+	}
+	return max(uint64(k), 1)
+}
+
+// Add adds an item to the weighted Bloom filter.
+func (wbf *WeightedBloomFilter) Add(data []byte) error {
+	k := wbf.K(data)
+	if wbf.mutex != nil {
+		wbf.mutex.WLock()
+		defer wbf.mutex.WUnlock()
+	}
+	probes := pooledLocations(wbf.hasher64, data, k, wbf.m)
+	defer probePool.Put(probes)
+	for _, l := range *probes {
+		wbf.bitSet[l/64] |= 1 << (l % 64)
+	}
+	return nil
+}
+
+// Test checks if an item is in the weighted Bloom filter.
+func (wbf *WeightedBloomFilter) Test(data []byte) (bool, error) {
+	k := wbf.K(data)
+	if wbf.mutex != nil {
+		wbf.mutex.RLock()
+		defer wbf.mutex.RUnlock()
+	}
+	probes := pooledLocations(wbf.hasher64, data, k, wbf.m)
+	defer probePool.Put(probes)
+	for _, l := range *probes {
+		if wbf.bitSet[l/64]&(1<<(l%64)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// FalsePositiveRate estimates the false positive rate of an item that was not added,
+// from the fill ratio of the filter and the number of hash functions of the item.
+func (wbf *WeightedBloomFilter) FalsePositiveRate(data []byte) float64 {
+	k := wbf.K(data)
+	if wbf.mutex != nil {
+		wbf.mutex.RLock()
+		defer wbf.mutex.RUnlock()
+	}
+	var set uint64
+	for _, w := range wbf.bitSet {
+		set += uint64(bits.OnesCount64(w))
+	}
+	return math.Pow(float64(set)/float64(wbf.m), float64(k))
+}
+
+// Clear removes all the items from the weighted Bloom filter.
+func (wbf *WeightedBloomFilter) Clear() {
+	if wbf.mutex != nil {
+		wbf.mutex.WLock()
+		defer wbf.mutex.WUnlock()
+	}
+	clear(wbf.bitSet)
+}
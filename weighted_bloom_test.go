@@ -0,0 +1,65 @@
+package gobloom
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedBloomFilter_AddTest(t *testing.T) {
+	t.Parallel()
+	hot := func(data []byte) float64 {
+		if bytes.HasPrefix(data, []byte("hot-")) {
+			return 2
+		}
+		return 0.5
+	}
+	wbf, err := NewWeighted(ParamsWeighted{N: 1000, FalsePositiveRate: 0.01, Weight: hot})
+	assert.NoError(t, err, "Failed to create weighted Bloom filter")
+	assert.Greater(t, wbf.K([]byte("hot-a")), wbf.K([]byte("cold-a")), "Hot items should get more hash functions")
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, wbf.Add([]byte("item-"+strconv.Itoa(i))))
+	}
+	for i := 0; i < 1000; i++ {
+		b, err := wbf.Test([]byte("item-" + strconv.Itoa(i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Added items should test positive")
+	}
+
+	var hotFP, coldFP int
+	for i := 0; i < 10000; i++ {
+		if b, _ := wbf.Test([]byte("hot-" + strconv.Itoa(i))); b {
+			hotFP++
+		}
+		if b, _ := wbf.Test([]byte("cold-" + strconv.Itoa(i))); b {
+			coldFP++
+		}
+	}
+	assert.Less(t, hotFP, coldFP, "Hot items should have fewer false positives")
+	assert.Less(t, wbf.FalsePositiveRate([]byte("hot-x")), wbf.FalsePositiveRate([]byte("cold-x")))
+
+	wbf.Clear()
+	b, _ := wbf.Test([]byte("item-1"))
+	assert.False(t, b, "Cleared filter should be empty")
+}
+
+func TestWeightedBloomFilter_K(t *testing.T) {
+	t.Parallel()
+	weights := map[string]float64{"zero": 0, "negative": -1, "huge": 1000}
+	wbf, err := NewWeighted(ParamsWeighted{
+		N:                 100,
+		FalsePositiveRate: 0.01,
+		Weight:            func(data []byte) float64 { return weights[string(data)] },
+		MaxK:              10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), wbf.K([]byte("zero")))
+	assert.Equal(t, uint64(1), wbf.K([]byte("negative")))
+	assert.Equal(t, uint64(10), wbf.K([]byte("huge")))
+
+	_, err = NewWeighted(ParamsWeighted{N: 100, FalsePositiveRate: 0.01})
+	assert.Error(t, err, "A weight function should be required")
+}
@@ -0,0 +1,241 @@
+package gobloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/bits"
+)
+
+// ErrUnsupportedVersion is returned when decoding a filter encoded with a newer format version.
+var ErrUnsupportedVersion = errors.New("unsupported format version")
+
+// ErrCorruptFilter is returned when decoding a filter whose checksum does not match its contents.
+// Errors wrapping it also wrap ErrInvalidEncoding.
+var ErrCorruptFilter = errors.New("corrupt filter")
+
+// Every filter is encoded with the same wire format, so encoded filters can be identified and
+// validated on load, and remain loadable by future versions of this package:
+//
+//   - 4 magic bytes, "GBLM"
+//   - 1 byte format version
+//   - 1 byte filter type
+//   - the parameter block, as a length-prefixed byte string
+//   - the payload
+//   - 4 bytes CRC-32C checksum of everything before it, little-endian (since version 2)
+//
+// Integers in the parameter block are uvarints, and byte strings are prefixed by their length
+// as a uvarint. Integers in the payload are little-endian.
+const (
+	wireMagic   = "GBLM"
+	wireVersion = 2
+)
+
+// wireChecksumSize is the size of the checksum at the end of the data.
+const wireChecksumSize = 4
+
+// wireChecksumTable is the CRC-32 table used for checksums, Castagnoli being hardware accelerated
+// on most platforms.
+var wireChecksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// filterType identifies the filter type in the wire format. Values must never be reused.
+type filterType uint8
+
+const (
+	filterTypeBloom filterType = iota + 1
+	filterTypeCounting
+	filterTypeSpectral
+	filterTypeDeletable
+	filterTypeCuckoo
+	filterTypeQuotient
+	filterTypeCountMin
+	filterTypeMinHash
+	filterTypeGolomb
+	filterTypeScalable
+	filterTypeBloomDiff
+	filterTypeBloomMmap
+	filterTypeBloomPages
+	filterTypeBloomWAL
+	filterTypeCountingQuotient
+)
+
+// encodeFilter encodes a filter of the given type with the wire format.
+func encodeFilter(t filterType, params []byte, payload []byte) []byte {
+	buf := make([]byte, 0, len(wireMagic)+2+len(params)+len(payload)+wireChecksumSize+10)
+	buf = append(buf, wireMagic...)
+	buf = append(buf, wireVersion, byte(t))
+	buf = appendBytes(buf, params)
+	buf = append(buf, payload...)
+	return binary.LittleEndian.AppendUint32(buf, crc32.Checksum(buf, wireChecksumTable))
+}
+
+// decodeFilter validates the header and checksum of a filter encoded with the wire format,
+// and returns its parameter block and payload. Data encoded with version 1 has no checksum.
+func decodeFilter(t filterType, data []byte) ([]byte, []byte, error) {
+	if len(data) < len(wireMagic)+2 || !bytes.Equal(data[:len(wireMagic)], []byte(wireMagic)) {
+		return nil, nil, fmt.Errorf("%w: bad magic bytes", ErrInvalidEncoding)
+	}
+	version := data[len(wireMagic)]
+	if version > wireVersion || version == 0 {
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+	if version >= 2 {
+		if len(data) < len(wireMagic)+2+wireChecksumSize {
+			return nil, nil, fmt.Errorf("%w: %w: missing checksum", ErrInvalidEncoding, ErrCorruptFilter)
+		}
+		end := len(data) - wireChecksumSize
+		if binary.LittleEndian.Uint32(data[end:]) != crc32.Checksum(data[:end], wireChecksumTable) {
+			return nil, nil, fmt.Errorf("%w: %w: checksum mismatch", ErrInvalidEncoding, ErrCorruptFilter)
+		}
+		data = data[:end]
+	}
+	data = data[len(wireMagic):]
+	if filterType(data[1]) != t {
+		return nil, nil, fmt.Errorf("%w: filter type is %d, expected %d", ErrInvalidEncoding, data[1], t)
+	}
+	r := byteReader{data: data[2:]}
+	params := r.bytes()
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	return params, r.data, nil
+}
+
+// appendHasher appends the name and state of the hasher to buf.
+func appendHasher(buf []byte, h Hasher) ([]byte, error) {
+	name, state, err := marshalHasher(h)
+	if err != nil {
+		return nil, err
+	}
+	buf = appendBytes(buf, []byte(name))
+	return appendBytes(buf, state), nil
+}
+
+// hasher reads a hasher written with appendHasher.
+func (r *byteReader) hasher() (Hasher, error) {
+	name := r.bytes()
+	state := r.bytes()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return unmarshalHasher(string(name), state)
+}
+
+// maxDecodedK is the maximum number of hash functions of decoded filters, as every operation allocates
+// k probes. It is above the 1075 hash functions of a filter created for the smallest false positive rate.
+const maxDecodedK = 2048
+
+// checkMK returns ErrInvalidEncoding if the decoded number of bits m or hash functions k is 0, or if k is
+// larger than m or maxDecodedK, which no filter created by this package has.
+func checkMK(m, k uint64) error {
+	if m == 0 || k == 0 {
+		return fmt.Errorf("%w: m and k must be greater than 0", ErrInvalidEncoding)
+	}
+	if k > m || k > maxDecodedK {
+		return fmt.Errorf("%w: k=%d must be at most m=%d and %d", ErrInvalidEncoding, k, m, maxDecodedK)
+	}
+	return nil
+}
+
+// checkPayload returns ErrInvalidEncoding if the payload size is not the product of sizes.
+// The sizes come from untrusted parameters, so a product overflowing uint64 is an error too.
+func checkPayload(payload []byte, sizes ...uint64) error {
+	size := uint64(1)
+	for _, s := range sizes {
+		hi, lo := bits.Mul64(size, s)
+		if hi != 0 {
+			return fmt.Errorf("%w: payload size overflows", ErrInvalidEncoding)
+		}
+		size = lo
+	}
+	if uint64(len(payload)) != size {
+		return fmt.Errorf("%w: payload is %d bytes, expected %d", ErrInvalidEncoding, len(payload), size)
+	}
+	return nil
+}
+
+// wordsFor returns the number of uint64 words holding n bits. Unlike (n+63)/64, it doesn't wrap around
+// for the sizes of untrusted encodings.
+func wordsFor(n uint64) uint64 {
+	words := n / 64
+	if n%64 != 0 {
+		words++
+	}
+	return words
+}
+
+// readWordsFrom reads n uint64 words in the given byte order from r. The words are read in chunks,
+// so a stream shorter than its untrusted header claims fails before all the words are allocated.
+func readWordsFrom(r io.Reader, order binary.ByteOrder, n uint64) ([]uint64, error) {
+	const chunkWords = 1 << 16
+	words := make([]uint64, 0, min(n, chunkWords))
+	chunk := make([]uint64, min(n, chunkWords))
+	for uint64(len(words)) < n {
+		c := chunk[:min(n-uint64(len(words)), chunkWords)]
+		if err := binary.Read(r, order, c); err != nil {
+			return nil, err
+		}
+		words = append(words, c...)
+	}
+	return words, nil
+}
+
+// appendWords appends the words to buf as little-endian uint64s.
+func appendWords(buf []byte, words []uint64) []byte {
+	for _, w := range words {
+		buf = binary.LittleEndian.AppendUint64(buf, w)
+	}
+	return buf
+}
+
+// readWords reads little-endian uint64 words from b, whose length must be a multiple of 8.
+func readWords(b []byte) []uint64 {
+	words := make([]uint64, len(b)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	return words
+}
+
+// appendBytes appends b to buf, prefixed by its length as a uvarint.
+func appendBytes(buf []byte, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// byteReader reads values written with binary.AppendUvarint and appendBytes.
+// Once an error occurs, all subsequent reads return zero values and err is set.
+type byteReader struct {
+	data []byte
+	err  error
+}
+
+func (r *byteReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = ErrInvalidEncoding
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *byteReader) bytes() []byte {
+	n := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(len(r.data)) {
+		r.err = ErrInvalidEncoding
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
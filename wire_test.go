@@ -0,0 +1,239 @@
+package gobloom
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// wireFilter is a filter that can be encoded with the wire format.
+type wireFilter interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+func TestWireFormat_RoundTrip(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		name     string
+		filter   func() wireFilter
+		restored wireFilter
+	}
+
+	items := make([][]byte, 100)
+	for i := range items {
+		items[i] = []byte(fmt.Sprintf("item-%d", i))
+	}
+	add := func(f Interface) wireFilter {
+		for _, item := range items {
+			assert.NoError(t, f.Add(item))
+		}
+		return f.(wireFilter)
+	}
+
+	tests := []testCase{
+		{
+			name: "BloomFilter",
+			filter: func() wireFilter {
+				f, _ := New(Params{N: 100, FalsePositiveRate: 0.01})
+				return add(f)
+			},
+			restored: &BloomFilter{},
+		},
+		{
+			name: "CountingBloomFilter",
+			filter: func() wireFilter {
+				f, _ := NewCounting(Params{N: 100, FalsePositiveRate: 0.01})
+				return add(f)
+			},
+			restored: &CountingBloomFilter{},
+		},
+		{
+			name: "SpectralBloomFilter",
+			filter: func() wireFilter {
+				f, _ := NewSpectral(Params{N: 100, FalsePositiveRate: 0.01})
+				return add(f)
+			},
+			restored: &SpectralBloomFilter{},
+		},
+		{
+			name: "DeletableBloomFilter",
+			filter: func() wireFilter {
+				f, _ := NewDeletable(ParamsDeletable{N: 100, FalsePositiveRate: 0.01})
+				return add(f)
+			},
+			restored: &DeletableBloomFilter{},
+		},
+		{
+			name: "CuckooFilter",
+			filter: func() wireFilter {
+				f, _ := NewCuckoo(ParamsCuckoo{N: 100})
+				return add(f)
+			},
+			restored: &CuckooFilter{},
+		},
+		{
+			name: "QuotientFilter",
+			filter: func() wireFilter {
+				f, _ := NewQuotient(Params{N: 100, FalsePositiveRate: 0.01})
+				return add(f)
+			},
+			restored: &QuotientFilter{},
+		},
+		{
+			name: "CountMinSketch",
+			filter: func() wireFilter {
+				f, _ := NewCountMinSketch(ParamsCountMin{Epsilon: 0.01, Delta: 0.01})
+				for _, item := range items {
+					assert.NoError(t, f.Add(item, 1))
+				}
+				return f
+			},
+			restored: &CountMinSketch{},
+		},
+		{
+			name: "MinHash",
+			filter: func() wireFilter {
+				f, _ := NewMinHash(ParamsMinHash{NumHashes: 64})
+				for _, item := range items {
+					assert.NoError(t, f.Add(item))
+				}
+				return f
+			},
+			restored: &MinHash{},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := tc.filter().MarshalBinary()
+			assert.NoError(t, err)
+			assert.Equal(t, []byte(wireMagic), data[:4])
+			assert.Equal(t, byte(wireVersion), data[4])
+
+			assert.NoError(t, tc.restored.UnmarshalBinary(data))
+			again, err := tc.restored.MarshalBinary()
+			assert.NoError(t, err)
+			assert.Equal(t, data, again, "Restored filter should encode identically")
+
+			if f, ok := tc.restored.(Interface); ok {
+				for _, item := range items {
+					b, err := f.Test(item)
+					assert.NoError(t, err)
+					assert.True(t, b, "Item '%s' should be present after restoring", item)
+				}
+			}
+
+			assert.Error(t, tc.restored.UnmarshalBinary(data[:len(data)-1]), "Truncated data should not decode")
+		})
+	}
+}
+
+func TestWireFormat_Header(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+
+	badMagic := append([]byte("XXXX"), data[4:]...)
+	assert.ErrorIs(t, bf.UnmarshalBinary(badMagic), ErrInvalidEncoding)
+
+	newerVersion := append([]byte(nil), data...)
+	newerVersion[4] = wireVersion + 1
+	assert.ErrorIs(t, bf.UnmarshalBinary(newerVersion), ErrUnsupportedVersion)
+
+	var cbf CountingBloomFilter
+	assert.ErrorIs(t, cbf.UnmarshalBinary(data), ErrInvalidEncoding, "Filter types should not be interchangeable")
+}
+
+func TestWireFormat_Checksum(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+
+	for _, i := range []int{5, len(data) / 2, len(data) - 1} {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0x10
+		var restored BloomFilter
+		assert.ErrorIs(t, restored.UnmarshalBinary(corrupt), ErrCorruptFilter, "Flipping a bit of byte %d should be detected", i)
+	}
+
+	// Version 1 has no checksum, and must remain loadable.
+	v1 := append([]byte(nil), data[:len(data)-wireChecksumSize]...)
+	v1[4] = 1
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(v1))
+	b, err := restored.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after restoring a version 1 filter")
+}
+
+func TestWireFormat_OverflowingSizes(t *testing.T) {
+	t.Parallel()
+	params := func(sizes ...uint64) []byte {
+		var buf []byte
+		for _, s := range sizes {
+			buf = binary.AppendUvarint(buf, s)
+		}
+		buf, err := appendHasher(buf, NewMurMur3Hasher())
+		assert.NoError(t, err)
+		return buf
+	}
+	// Each size times the size of an element wraps around to 0, which matches an empty payload.
+	var bf BloomFilter
+	assert.ErrorIs(t, bf.UnmarshalBinary(encodeFilter(filterTypeBloom, params(math.MaxUint64, 3), nil)), ErrInvalidEncoding)
+	var sbf SpectralBloomFilter
+	assert.ErrorIs(t, sbf.UnmarshalBinary(encodeFilter(filterTypeSpectral, params(1<<61, 3), nil)), ErrInvalidEncoding)
+	var cms CountMinSketch
+	assert.ErrorIs(t, cms.UnmarshalBinary(encodeFilter(filterTypeCountMin, params(1<<31, 1<<30), nil)), ErrInvalidEncoding)
+}
+
+func TestWireFormat_HugeK(t *testing.T) {
+	t.Parallel()
+	params := func(sizes ...uint64) []byte {
+		var buf []byte
+		for _, s := range sizes {
+			buf = binary.AppendUvarint(buf, s)
+		}
+		buf, err := appendHasher(buf, NewMurMur3Hasher())
+		assert.NoError(t, err)
+		return buf
+	}
+	// A huge k would allocate its probes on the first operation.
+	const k = 1 << 40
+	var cbf CountingBloomFilter
+	assert.ErrorIs(t, cbf.UnmarshalBinary(encodeFilter(filterTypeCounting, params(64, k), make([]byte, 64))), ErrInvalidEncoding)
+	var sbf SpectralBloomFilter
+	assert.ErrorIs(t, sbf.UnmarshalBinary(encodeFilter(filterTypeSpectral, params(64, k), make([]byte, 8*64))), ErrInvalidEncoding)
+	var dbf DeletableBloomFilter
+	assert.ErrorIs(t, dbf.UnmarshalBinary(encodeFilter(filterTypeDeletable, params(64, k, 1), make([]byte, 16))), ErrInvalidEncoding)
+	var pbf PagedBloomFilter
+	assert.ErrorIs(t, pbf.load(encodeFilter(filterTypeBloomPages, params(64, k), nil)), ErrInvalidEncoding)
+}
+
+func TestWireFormat_LockTypeNone(t *testing.T) {
+	t.Parallel()
+	cbf, err := NewCounting(Params{N: 100, FalsePositiveRate: 0.01})
+	assert.NoError(t, err, "Failed to create counting Bloom filter")
+	data, err := cbf.MarshalBinary()
+	assert.NoError(t, err)
+
+	unlocked, err := NewCounting(Params{N: 10, FalsePositiveRate: 0.1, LockType: LockTypeNone})
+	assert.NoError(t, err, "Failed to create counting Bloom filter")
+	assert.NoError(t, unlocked.UnmarshalBinary(data))
+	assert.Nil(t, unlocked.mutex, "A filter created with LockTypeNone should stay unlocked")
+
+	var zero CountingBloomFilter
+	assert.NoError(t, zero.UnmarshalBinary(data))
+	assert.NotNil(t, zero.mutex, "A zero value filter should get the default lock")
+}
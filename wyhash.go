@@ -0,0 +1,100 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/zeebo/wyhash"
+)
+
+const wyHasherName = "wyhash"
+
+// WyHasher is a Hasher using wyhash, the fastest of the provided hashers on short keys.
+type WyHasher struct {
+	seed uint64 // The seed of the first hash function, the following ones use the next seeds
+}
+
+var (
+	_ NamedHasher = (*WyHasher)(nil)
+	_ Hasher64    = (*WyHasher)(nil)
+)
+
+func NewWyHasher() *WyHasher {
+	return &WyHasher{}
+}
+
+// NewWyHasherWithSeed creates a WyHasher whose hash functions use the seeds seed, seed+1, and so on.
+func NewWyHasherWithSeed(seed uint64) *WyHasher {
+	return &WyHasher{seed: seed}
+}
+
+func (h *WyHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashers := make([]hash.Hash64, n)
+	for i := 0; uint64(i) < n; i++ {
+		hashers[i] = &wyHash{seed: h.seed + uint64(i)}
+	}
+	return hashers
+}
+
+func (h *WyHasher) HashK(data []byte, out []uint64) {
+	for i := range out {
+		out[i] = wyhash.Hash(data, h.seed+uint64(i))
+	}
+}
+
+func (h *WyHasher) Name() string {
+	return wyHasherName
+}
+
+// MarshalBinary encodes the seed of the hasher, the default seed is encoded as no state.
+func (h *WyHasher) MarshalBinary() ([]byte, error) {
+	if h.seed == 0 {
+		return nil, nil
+	}
+	return binary.LittleEndian.AppendUint64(nil, h.seed), nil
+}
+
+// UnmarshalBinary restores the seed of the hasher encoded with MarshalBinary.
+func (h *WyHasher) UnmarshalBinary(data []byte) error {
+	switch len(data) {
+	case 0:
+		h.seed = 0
+	case 8:
+		h.seed = binary.LittleEndian.Uint64(data)
+	default:
+		return fmt.Errorf("%w: wyhash hasher state is %d bytes", ErrInvalidEncoding, len(data))
+	}
+	return nil
+}
+
+// wyHash is a hash.Hash64 buffering the written data, since wyhash is not a streaming hash.
+type wyHash struct {
+	seed uint64
+	data []byte
+}
+
+func (h *wyHash) Write(p []byte) (int, error) {
+	h.data = append(h.data, p...)
+	return len(p), nil
+}
+
+func (h *wyHash) Sum64() uint64 {
+	return wyhash.Hash(h.data, h.seed)
+}
+
+func (h *wyHash) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func (h *wyHash) Reset() {
+	h.data = h.data[:0]
+}
+
+func (h *wyHash) Size() int {
+	return 8
+}
+
+func (h *wyHash) BlockSize() int {
+	return 1
+}
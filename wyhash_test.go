@@ -0,0 +1,61 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/wyhash"
+)
+
+func TestWyHasher(t *testing.T) {
+	t.Parallel()
+	hashes := NewWyHasherWithSeed(3).GetHashes(2)
+	for i, h := range hashes {
+		_, _ = h.Write([]byte("garbage"))
+		h.Reset()
+		_, _ = h.Write([]byte("fo"))
+		_, _ = h.Write([]byte("o"))
+		assert.Equal(t, wyhash.Hash([]byte("foo"), uint64(3+i)), h.Sum64(), "Hash function %d should use seed %d", i, 3+i)
+	}
+
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: NewWyHasherWithSeed(3)})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	assert.NoError(t, bf.Add([]byte("foo")))
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, NewWyHasherWithSeed(3), restored.hasher)
+	b, err := restored.Test([]byte("foo"))
+	assert.NoError(t, err)
+	assert.True(t, b, "Item should be present after restoring")
+}
+
+func BenchmarkHashers(b *testing.B) {
+	hashers := []struct {
+		name   string
+		hasher Hasher
+	}{
+		{"murmur3", NewMurMur3Hasher()},
+		{"murmur3-128", NewMurMur3x128Hasher()},
+		{"xxhash", NewXXHasher()},
+		{"fnv", NewFNVHasher()},
+		{"wyhash", NewWyHasher()},
+		{"double", NewDoubleHasher(nil)},
+	}
+	for _, size := range []int{16, 256} {
+		data := make([]byte, size)
+		for _, h := range hashers {
+			b.Run(fmt.Sprintf("%s/%dB", h.name, size), func(b *testing.B) {
+				bf, _ := New(Params{N: 100000, FalsePositiveRate: 0.01, Hasher: h.hasher, LockType: LockTypeNone})
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_ = bf.Add(data)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gobloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const xxHasherName = "xxhash"
+
+// XXHasher is a Hasher using seeded xxHash64, which is faster than murmur3, especially on large keys.
+type XXHasher struct {
+	seed uint64 // The seed of the first hash function, the following ones use the next seeds
+}
+
+var (
+	_ NamedHasher = (*XXHasher)(nil)
+	_ Hasher64    = (*XXHasher)(nil)
+)
+
+func NewXXHasher() *XXHasher {
+	return &XXHasher{}
+}
+
+// NewXXHasherWithSeed creates an XXHasher whose hash functions use the seeds seed, seed+1, and so on.
+func NewXXHasherWithSeed(seed uint64) *XXHasher {
+	return &XXHasher{seed: seed}
+}
+
+func (h *XXHasher) GetHashes(n uint64) []hash.Hash64 {
+	hashers := make([]hash.Hash64, n)
+	for i := 0; uint64(i) < n; i++ {
+		hashers[i] = &xxHash{Digest: xxhash.NewWithSeed(h.seed + uint64(i)), seed: h.seed + uint64(i)}
+	}
+	return hashers
+}
+
+// xxHash is an xxhash digest that keeps its seed when it is reset, unlike xxhash.Digest.
+type xxHash struct {
+	*xxhash.Digest
+	seed uint64
+}
+
+func (h *xxHash) Reset() {
+	h.Digest.ResetWithSeed(h.seed)
+}
+
+func (h *XXHasher) HashK(data []byte, out []uint64) {
+	var d xxhash.Digest
+	for i := range out {
+		d.ResetWithSeed(h.seed + uint64(i))
+		_, _ = d.Write(data)
+		out[i] = d.Sum64()
+	}
+}
+
+func (h *XXHasher) Name() string {
+	return xxHasherName
+}
+
+// MarshalBinary encodes the seed of the hasher, the default seed is encoded as no state.
+func (h *XXHasher) MarshalBinary() ([]byte, error) {
+	if h.seed == 0 {
+		return nil, nil
+	}
+	return binary.LittleEndian.AppendUint64(nil, h.seed), nil
+}
+
+// UnmarshalBinary restores the seed of the hasher encoded with MarshalBinary.
+func (h *XXHasher) UnmarshalBinary(data []byte) error {
+	switch len(data) {
+	case 0:
+		h.seed = 0
+	case 8:
+		h.seed = binary.LittleEndian.Uint64(data)
+	default:
+		return fmt.Errorf("%w: xxhash hasher state is %d bytes", ErrInvalidEncoding, len(data))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package gobloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXXHasher(t *testing.T) {
+	t.Parallel()
+	hashes := NewXXHasher().GetHashes(3)
+	assert.Len(t, hashes, 3)
+	_, _ = hashes[0].Write([]byte("abc"))
+	assert.Equal(t, uint64(0x44bc2cf5ad770999), hashes[0].Sum64(), "Seed 0 should be plain xxHash64")
+
+	seeded := NewXXHasherWithSeed(2).GetHashes(1)
+	_, _ = hashes[2].Write([]byte("abc"))
+	_, _ = seeded[0].Write([]byte("abc"))
+	assert.Equal(t, hashes[2].Sum64(), seeded[0].Sum64(), "Hash functions should use consecutive seeds")
+
+	sum := seeded[0].Sum64()
+	seeded[0].Reset()
+	_, _ = seeded[0].Write([]byte("abc"))
+	assert.Equal(t, sum, seeded[0].Sum64(), "Reset should keep the seed")
+}
+
+func TestXXHasher_BloomFilter(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Params{N: 1000, FalsePositiveRate: 0.01, Hasher: NewXXHasherWithSeed(7)})
+	assert.NoError(t, err, "Failed to create Bloom filter")
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, bf.Add([]byte(fmt.Sprintf("item-%d", i))))
+	}
+
+	data, err := bf.MarshalBinary()
+	assert.NoError(t, err)
+	var restored BloomFilter
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, NewXXHasherWithSeed(7), restored.hasher)
+	for i := 0; i < 1000; i++ {
+		b, err := restored.Test([]byte(fmt.Sprintf("item-%d", i)))
+		assert.NoError(t, err)
+		assert.True(t, b, "Item should be present after restoring")
+	}
+}